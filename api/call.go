@@ -21,6 +21,7 @@ import (
 	"encoding/json"
 	"io/ioutil"
 	"net/http"
+	"time"
 
 	"github.com/insolar/insolar/core/utils"
 	"github.com/insolar/insolar/cryptography"
@@ -96,6 +97,7 @@ func (ar *Runner) callHandler() func(http.ResponseWriter, *http.Request) {
 
 		params := request{}
 		resp := answer{}
+		status := http.StatusOK
 
 		traceid := utils.RandTraceID()
 		ctx, inslog := inslogger.WithTraceField(context.Background(), traceid)
@@ -107,6 +109,7 @@ func (ar *Runner) callHandler() func(http.ResponseWriter, *http.Request) {
 				res = []byte(`{"error": "can't marshal answer to json'"}`)
 			}
 			response.Header().Add("Content-Type", "application/json")
+			response.WriteHeader(status)
 			_, err = response.Write(res)
 			if err != nil {
 				inslog.Errorf("Can't write response\n")
@@ -146,6 +149,11 @@ func (ar *Runner) callHandler() func(http.ResponseWriter, *http.Request) {
 			inslog.Error(errors.Wrap(err, "[ CallHandler ] Can't marshal args"))
 			return
 		}
+		var options []core.SendOption
+		if ar.cfg.CallTimeout > 0 {
+			deadline := time.Now().Add(time.Duration(ar.cfg.CallTimeout) * time.Second)
+			options = append(options, core.SendOptionDeadline(deadline))
+		}
 		res, err := ar.MessageBus.Send(
 			ctx,
 			&message.CallMethod{
@@ -153,8 +161,12 @@ func (ar *Runner) callHandler() func(http.ResponseWriter, *http.Request) {
 				Method:    "Call",
 				Arguments: args,
 			},
+			options...,
 		)
 		if err != nil {
+			if errors.Cause(err) == core.ErrDeadlineExceeded {
+				status = http.StatusGatewayTimeout
+			}
 			resp.Error = err.Error()
 			inslog.Error(errors.Wrap(err, "[ CallHandler ] Can't send message to message bus"))
 			return
@@ -19,8 +19,12 @@ package api
 import (
 	"context"
 	"net/http"
+	"strconv"
 
 	"github.com/insolar/insolar/core"
+	"github.com/insolar/insolar/core/utils"
+	"github.com/insolar/insolar/instrumentation/inslogger"
+	"github.com/pkg/errors"
 )
 
 // StorageExporterArgs is arguments that StorageExporter service accepts.
@@ -91,3 +95,62 @@ func (s *StorageExporterService) Export(r *http.Request, args *StorageExporterAr
 
 	return nil
 }
+
+type flushWriter struct {
+	w http.ResponseWriter
+}
+
+func (fw flushWriter) Write(p []byte) (int, error) {
+	n, err := fw.w.Write(p)
+	if f, ok := fw.w.(http.Flusher); ok {
+		f.Flush()
+	}
+	return n, err
+}
+
+// exportHandler streams storage records as newline delimited JSON.
+//
+//   Query parameters:
+//     pulse  - pulse number from which export should start ("0" to export from the beginning);
+//     after  - record ID from the last received line, records of the pulse up to and including it are skipped;
+//     follow - if "true", new pulses are streamed as they close until the client disconnects.
+//
+//   Every line of the response is a record:
+//   {
+//     "Pulse": int, // Pulse number of the record. Use it as "pulse" parameter to resume export.
+//     "ID": str, // Record ID. Use it as "after" parameter to resume export.
+//     "Type": str, // Constant record type.
+//     "Data": { ... }, // Structured record data.
+//     "Payload": { ... }|null // Additional data related to the record (e.g. Object's memory).
+//   }
+func (ar *Runner) exportHandler() func(http.ResponseWriter, *http.Request) {
+	return func(response http.ResponseWriter, req *http.Request) {
+		ctx, inslog := inslogger.WithTraceField(req.Context(), utils.RandTraceID())
+
+		var cursor core.ExportCursor
+		query := req.URL.Query()
+		if pulse := query.Get("pulse"); pulse != "" {
+			pn, err := strconv.ParseUint(pulse, 10, 32)
+			if err != nil {
+				http.Error(response, "[ Export ] Bad pulse number", http.StatusBadRequest)
+				return
+			}
+			cursor.Pulse = core.PulseNumber(pn)
+		}
+		if after := query.Get("after"); after != "" {
+			id, err := core.NewRecordIDFromBase58(after)
+			if err != nil {
+				http.Error(response, "[ Export ] Bad record ID", http.StatusBadRequest)
+				return
+			}
+			cursor.After = id
+		}
+		follow := query.Get("follow") == "true"
+
+		response.Header().Add("Content-Type", "application/x-ndjson")
+		err := ar.StorageExporter.Stream(ctx, flushWriter{w: response}, cursor, follow)
+		if err != nil && err != context.Canceled {
+			inslog.Error(errors.Wrap(err, "[ Export ] Failed to stream records"))
+		}
+	}
+}
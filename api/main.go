@@ -217,6 +217,7 @@ func (ar *Runner) Start(ctx context.Context) error {
 	http.HandleFunc(ar.cfg.Info, ar.infoHandler())
 	http.HandleFunc(ar.cfg.Call, ar.callHandler())
 	http.Handle(ar.cfg.RPC, ar.rpcServer)
+	http.HandleFunc(ar.cfg.Export, ar.exportHandler())
 	inslog := inslogger.FromContext(ctx)
 	inslog.Info("Starting ApiRunner ...")
 	inslog.Info("Config: ", ar.cfg)
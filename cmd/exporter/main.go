@@ -19,26 +19,51 @@ package main
 import (
 	"context"
 	"flag"
+	"fmt"
 	"net/http"
+	"os"
 
 	"github.com/gorilla/rpc/v2"
 
 	"github.com/gorilla/rpc/v2/json2"
 	"github.com/insolar/insolar/api"
 	"github.com/insolar/insolar/configuration"
+	"github.com/insolar/insolar/core"
 	"github.com/insolar/insolar/ledger/exporter"
 	"github.com/insolar/insolar/ledger/storage"
 	"github.com/insolar/insolar/platformpolicy"
 )
 
+var (
+	stream = flag.Bool("stream", false, "write records to stdout as newline delimited JSON instead of serving RPC")
+	pulse  = flag.Uint("pulse", 0, "pulse number to start streaming from")
+	after  = flag.String("after", "", "record ID to resume streaming after (records of the pulse up to it are skipped)")
+	follow = flag.Bool("follow", false, "keep streaming new pulses as they close")
+	keys   = flag.String("keyfile", "", "encryption key file of encrypted storage")
+)
+
+func usage() {
+	fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [flags] [data directory]\n", os.Args[0])
+	fmt.Fprintln(flag.CommandLine.Output(), "Data directory defaults to the current directory.")
+	flag.PrintDefaults()
+}
+
 func main() {
 	// FIXME: this is a temporary implementation. Make me pretty!
 	ctx := context.Background()
+	flag.Usage = usage
+	flag.Parse()
 
 	// Ledger
 	ledgerConf := configuration.NewLedger()
-	ledgerConf.Storage.DataDirectory = flag.Arg(1)
-	db, err := storage.NewDB(ledgerConf, nil)
+	// Data directory is the first positional argument. Arguments were not parsed before streaming flags were added,
+	// so the current directory was always used.
+	ledgerConf.Storage.DataDirectory = flag.Arg(0)
+	if *keys != "" {
+		ledgerConf.Storage.Encryption.Enabled = true
+		ledgerConf.Storage.Encryption.KeyFile = *keys
+	}
+	db, err := storage.NewDB(ledgerConf, nil, nil)
 	if err != nil {
 		panic(err)
 	}
@@ -49,6 +74,21 @@ func main() {
 		panic(err)
 	}
 
+	if *stream {
+		cursor := core.ExportCursor{Pulse: core.PulseNumber(*pulse)}
+		if *after != "" {
+			cursor.After, err = core.NewRecordIDFromBase58(*after)
+			if err != nil {
+				panic(err)
+			}
+		}
+		err = exp.Stream(ctx, os.Stdout, cursor, *follow)
+		if err != nil {
+			panic(err)
+		}
+		return
+	}
+
 	// API
 	apiConf := configuration.NewAPIRunner()
 	apiRunner, err := api.NewRunner(&apiConf)
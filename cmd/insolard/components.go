@@ -26,6 +26,7 @@ import (
 	"github.com/insolar/insolar/consensus/phases"
 	"github.com/insolar/insolar/core"
 	"github.com/insolar/insolar/core/delegationtoken"
+	"github.com/insolar/insolar/core/wire"
 	"github.com/insolar/insolar/cryptography"
 	"github.com/insolar/insolar/genesis"
 	"github.com/insolar/insolar/genesisdataprovider"
@@ -146,8 +147,11 @@ func initComponents(
 	networkCoordinator, err := networkcoordinator.New()
 	checkError(ctx, err, "failed to start NetworkCoordinator")
 
-	_, err = manager.NewVersionManager(cfg.VersionManager)
+	versionManager, err := manager.NewVersionManager(cfg.VersionManager)
 	checkError(ctx, err, "failed to load VersionManager: ")
+	err = versionManager.Start(ctx)
+	checkError(ctx, err, "failed to start VersionManager: ")
+	wire.SetGate(versionManager)
 
 	// move to logic runner ??
 	err = logicRunner.OnPulse(ctx, *pulsar.NewPulse(cfg.Pulsar.NumberDelta, 0, &entropygenerator.StandardEntropyGenerator{}))
@@ -163,7 +167,7 @@ func initComponents(
 		nodeNetwork,
 	)
 
-	components := ledger.GetLedgerComponents(cfg.Ledger)
+	components := ledger.GetLedgerComponents(cfg.Ledger, keyStore)
 	ld := ledger.Ledger{} // TODO: remove me with cmOld
 	components = append(components, []interface{}{
 		nw,
@@ -0,0 +1,382 @@
+/*
+ *    Copyright 2018 Insolar
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ */
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"github.com/ugorji/go/codec"
+
+	"github.com/insolar/insolar/core"
+	"github.com/insolar/insolar/instrumentation/inslogger"
+	"github.com/insolar/insolar/ledger/record"
+	"github.com/insolar/insolar/ledger/storage"
+)
+
+var jsonOutput bool
+
+var lifelineStates = map[record.State]string{
+	record.StateUndefined:    "undefined",
+	record.StateActivation:   "activation",
+	record.StateAmend:        "amend",
+	record.StateDeactivation: "deactivation",
+}
+
+type recordView struct {
+	ID    *core.RecordID
+	Pulse core.PulseNumber
+	Type  string
+	Data  map[string]interface{} `json:",omitempty"`
+}
+
+type lifelineView struct {
+	Ref                 string
+	LatestState         *core.RecordID
+	LatestStateApproved *core.RecordID
+	ChildPointer        *core.RecordID
+	Parent              string
+	Delegates           map[string]string
+	State               string
+}
+
+type blobView struct {
+	ID     *core.RecordID
+	Size   int
+	Hex    string                 `json:",omitempty"`
+	Memory map[string]interface{} `json:",omitempty"`
+}
+
+type dropView struct {
+	Pulse    core.PulseNumber
+	PrevHash string
+	Hash     string
+	// Linked is false if previous hash does not match hash of the previous drop.
+	Linked bool
+}
+
+// inspectContext opens storage read-only for inspection commands.
+func inspectContext() (context.Context, *storage.DB) {
+	return inslogger.ContextWithTrace(context.Background(), "ledgerctl"), openDB(true)
+}
+
+// output prints v as indented JSON if --json flag is set, otherwise calls text.
+func output(v interface{}, text func(w io.Writer) error) {
+	if jsonOutput {
+		out, err := json.MarshalIndent(v, "", "    ")
+		check("failed to marshal output:", err)
+		fmt.Println(string(out))
+		return
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	check("failed to print output:", text(w))
+	check("failed to print output:", w.Flush())
+}
+
+// printFields prints fields as sorted "key value" lines. Nested values are printed as JSON.
+func printFields(w io.Writer, fields map[string]interface{}) error {
+	keys := make([]string, 0, len(fields))
+	for k := range fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		value := fields[k]
+		switch value.(type) {
+		case map[string]interface{}, []interface{}:
+			buf, err := json.Marshal(value)
+			if err != nil {
+				return err
+			}
+			value = string(buf)
+		}
+		if _, err := fmt.Fprintf(w, "  %v\t%v\n", k, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// recordFields returns record fields by name. Fields of embedded structs are promoted, references and ids are
+// converted to base58 strings.
+func recordFields(rec record.Record) map[string]interface{} {
+	fields := map[string]interface{}{}
+	var walk func(v reflect.Value)
+	walk = func(v reflect.Value) {
+		for v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				return
+			}
+			v = v.Elem()
+		}
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			field, value := t.Field(i), v.Field(i)
+			if field.PkgPath != "" {
+				continue
+			}
+			if field.Anonymous && value.Kind() == reflect.Struct {
+				walk(value)
+				continue
+			}
+			fields[field.Name] = fieldValue(value)
+		}
+	}
+	walk(reflect.ValueOf(rec))
+	return fields
+}
+
+func fieldValue(v reflect.Value) interface{} {
+	if v.Kind() == reflect.Ptr && v.IsNil() {
+		return nil
+	}
+	if v.CanAddr() {
+		if s, ok := v.Addr().Interface().(fmt.Stringer); ok && v.Kind() != reflect.Ptr {
+			return s.String()
+		}
+	}
+	if s, ok := v.Interface().(fmt.Stringer); ok {
+		return s.String()
+	}
+	if b, ok := v.Interface().([]byte); ok {
+		return hex.EncodeToString(b)
+	}
+	return v.Interface()
+}
+
+func parseID(str string) *core.RecordID {
+	id, err := core.NewRecordIDFromBase58(str)
+	check("failed to parse record id:", err)
+	return id
+}
+
+func recordCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "record <id>",
+		Short: "print record by base58 record id",
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			id := parseID(args[0])
+			ctx, db := inspectContext()
+			defer db.Close()
+
+			rec, err := db.GetRecord(ctx, id)
+			check("failed to fetch record:", err)
+			view := recordView{ID: id, Pulse: id.Pulse(), Type: strings.Title(rec.Type().String()), Data: recordFields(rec)}
+			output(view, func(w io.Writer) error {
+				fmt.Fprintf(w, "ID\t%v\nPulse\t%v\nType\t%v\nData\n", view.ID, view.Pulse, view.Type)
+				return printFields(w, view.Data)
+			})
+		},
+	}
+}
+
+func lifelineCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "lifeline <reference>",
+		Short: "print object lifeline by base58 object reference",
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			ref := core.NewRefFromBase58(args[0])
+			if ref.IsEmpty() {
+				check("failed to parse reference:", errors.New("invalid reference"))
+			}
+			ctx, db := inspectContext()
+			defer db.Close()
+
+			idx, err := db.GetObjectIndex(ctx, ref.Record(), false)
+			check("failed to fetch lifeline:", err)
+			view := lifelineView{
+				Ref:                 ref.String(),
+				LatestState:         idx.LatestState,
+				LatestStateApproved: idx.LatestStateApproved,
+				ChildPointer:        idx.ChildPointer,
+				Parent:              idx.Parent.String(),
+				Delegates:           map[string]string{},
+				State:               lifelineStates[idx.State],
+			}
+			for image, delegate := range idx.Delegates {
+				view.Delegates[image.String()] = delegate.String()
+			}
+			output(view, func(w io.Writer) error {
+				fmt.Fprintf(w, "Ref\t%v\nState\t%v\nLatestState\t%v\nLatestStateApproved\t%v\nChildPointer\t%v\nParent\t%v\n",
+					view.Ref, view.State, view.LatestState, view.LatestStateApproved, view.ChildPointer, view.Parent)
+				images := make([]string, 0, len(view.Delegates))
+				for image := range view.Delegates {
+					images = append(images, image)
+				}
+				sort.Strings(images)
+				for _, image := range images {
+					fmt.Fprintf(w, "Delegate\t%v -> %v\n", image, view.Delegates[image])
+				}
+				return nil
+			})
+		},
+	}
+}
+
+func blobCmd() *cobra.Command {
+	var decode bool
+	cmd := &cobra.Command{
+		Use:   "blob <id>",
+		Short: "dump blob by base58 blob id as hex or decoded object memory",
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			id := parseID(args[0])
+			ctx, db := inspectContext()
+			defer db.Close()
+
+			blob, err := db.GetBlob(ctx, id)
+			check("failed to fetch blob:", err)
+			view := blobView{ID: id, Size: len(blob)}
+			if decode {
+				h := &codec.CborHandle{}
+				h.MapType = reflect.TypeOf(map[string]interface{}(nil))
+				err = codec.NewDecoderBytes(blob, h).Decode(&view.Memory)
+				check("failed to decode object memory:", err)
+			} else {
+				view.Hex = hex.EncodeToString(blob)
+			}
+			output(view, func(w io.Writer) error {
+				fmt.Fprintf(w, "ID\t%v\nSize\t%v\n", view.ID, view.Size)
+				if !decode {
+					_, err := fmt.Fprint(w, hex.Dump(blob))
+					return err
+				}
+				fmt.Fprintln(w, "Memory")
+				return printFields(w, view.Memory)
+			})
+		},
+	}
+	cmd.Flags().BoolVar(&decode, "decode", false, "decode blob as object memory")
+	return cmd
+}
+
+func pulseCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "pulse <number>",
+		Short: "list records of the pulse",
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			pn, err := strconv.ParseUint(args[0], 10, 32)
+			check("failed to parse pulse number:", err)
+			ctx, db := inspectContext()
+			defer db.Close()
+
+			records := []recordView{}
+			err = db.IterateRecords(ctx, core.PulseNumber(pn), func(id core.RecordID, rec record.Record) error {
+				records = append(records, recordView{
+					ID:    &id,
+					Pulse: id.Pulse(),
+					Type:  strings.Title(rec.Type().String()),
+				})
+				return nil
+			})
+			check("failed to iterate records:", err)
+			output(records, func(w io.Writer) error {
+				fmt.Fprintln(w, "ID\tTYPE")
+				for _, r := range records {
+					fmt.Fprintf(w, "%v\t%v\n", r.ID, r.Type)
+				}
+				return nil
+			})
+		},
+	}
+}
+
+func dropsCmd() *cobra.Command {
+	var (
+		from  uint32
+		limit int
+	)
+	cmd := &cobra.Command{
+		Use:   "drops",
+		Short: "show jet drop chain starting from pulse",
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			ctx, db := inspectContext()
+			defer db.Close()
+
+			drops, err := dropChain(ctx, db, core.PulseNumber(from), limit)
+			check("failed to fetch jet drops:", err)
+			output(drops, func(w io.Writer) error {
+				fmt.Fprintln(w, "PULSE\tPREV HASH\tHASH\tLINKED")
+				for _, d := range drops {
+					fmt.Fprintf(w, "%v\t%v\t%v\t%v\n", d.Pulse, d.PrevHash, d.Hash, d.Linked)
+				}
+				return nil
+			})
+		},
+	}
+	cmd.Flags().Uint32Var(&from, "from", uint32(core.FirstPulseNumber), "pulse number to start from")
+	cmd.Flags().IntVar(&limit, "limit", 100, "maximum number of drops to show (non-positive means no limit)")
+	return cmd
+}
+
+// dropChain walks pulses from provided one and returns their jet drops until the first pulse without drop.
+func dropChain(ctx context.Context, db *storage.DB, from core.PulseNumber, limit int) ([]dropView, error) {
+	drops := []dropView{}
+	var prevHash []byte
+	if from > core.FirstPulseNumber {
+		pulse, err := db.GetPulse(ctx, from)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to fetch pulse %v", from)
+		}
+		if pulse.Prev != nil {
+			if prev, err := db.GetDrop(ctx, *pulse.Prev); err == nil {
+				prevHash = prev.Hash
+			}
+		}
+	}
+
+	current := &from
+	for current != nil && (limit <= 0 || len(drops) < limit) {
+		drop, err := db.GetDrop(ctx, *current)
+		if err == storage.ErrNotFound {
+			break
+		}
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to fetch jet drop for pulse %v", *current)
+		}
+		drops = append(drops, dropView{
+			Pulse:    *current,
+			PrevHash: hex.EncodeToString(drop.PrevHash),
+			Hash:     hex.EncodeToString(drop.Hash),
+			Linked:   prevHash == nil || bytes.Equal(prevHash, drop.PrevHash),
+		})
+		prevHash = drop.Hash
+
+		pulse, err := db.GetPulse(ctx, *current)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to fetch pulse %v", *current)
+		}
+		current = pulse.Next
+	}
+	return drops, nil
+}
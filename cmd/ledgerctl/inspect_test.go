@@ -0,0 +1,177 @@
+/*
+ *    Copyright 2018 Insolar
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ */
+
+package main
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/insolar/insolar/core"
+	"github.com/insolar/insolar/instrumentation/inslogger"
+	"github.com/insolar/insolar/ledger/jetdrop"
+	"github.com/insolar/insolar/ledger/record"
+	"github.com/insolar/insolar/ledger/storage/storagetest"
+	"github.com/insolar/insolar/testutils"
+)
+
+func TestRecordFields(t *testing.T) {
+	domain := testutils.RandomRef()
+	parent := testutils.RandomRef()
+	memory := testutils.RandomID()
+
+	tests := []struct {
+		name     string
+		rec      record.Record
+		expected map[string]interface{}
+	}{
+		{
+			name: "embedded structs are promoted",
+			rec: &record.ObjectActivateRecord{
+				SideEffectRecord:  record.SideEffectRecord{Domain: domain},
+				ObjectStateRecord: record.ObjectStateRecord{Memory: &memory, IsPrototype: true},
+				Parent:            parent,
+			},
+			expected: map[string]interface{}{
+				"Domain":      domain.String(),
+				"Request":     core.RecordRef{}.String(),
+				"Memory":      memory.String(),
+				"Image":       core.RecordRef{}.String(),
+				"IsPrototype": true,
+				"Parent":      parent.String(),
+				"IsDelegate":  false,
+			},
+		},
+		{
+			name: "nil pointers",
+			rec:  &record.CodeRecord{MachineType: core.MachineTypeGoPlugin},
+			expected: map[string]interface{}{
+				"Domain":      core.RecordRef{}.String(),
+				"Request":     core.RecordRef{}.String(),
+				"Code":        nil,
+				"MachineType": core.MachineTypeGoPlugin,
+			},
+		},
+		{
+			name: "bytes are hex encoded",
+			rec:  &record.CallRequest{Payload: []byte{0xca, 0xfe}},
+			expected: map[string]interface{}{
+				"Payload": "cafe",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, recordFields(tt.rec))
+		})
+	}
+}
+
+func TestPrintFields(t *testing.T) {
+	tests := []struct {
+		name     string
+		fields   map[string]interface{}
+		expected string
+	}{
+		{
+			name:     "empty",
+			fields:   map[string]interface{}{},
+			expected: "",
+		},
+		{
+			name:     "sorted by key",
+			fields:   map[string]interface{}{"b": 2, "a": "1"},
+			expected: "  a\t1\n  b\t2\n",
+		},
+		{
+			name: "nested values as json",
+			fields: map[string]interface{}{
+				"map":   map[string]interface{}{"k": "v"},
+				"slice": []interface{}{1, "2"},
+			},
+			expected: "  map\t{\"k\":\"v\"}\n  slice\t[1,\"2\"]\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			require.NoError(t, printFields(&buf, tt.fields))
+			assert.Equal(t, tt.expected, buf.String())
+		})
+	}
+}
+
+func TestDropChain(t *testing.T) {
+	ctx := inslogger.TestContext(t)
+	db, cleaner := storagetest.TmpDB(ctx, t, storagetest.InMemory())
+	defer cleaner()
+
+	// Drops of three closed pulses, the last one is not linked to the previous one.
+	var (
+		pulses []core.PulseNumber
+		drops  []*jetdrop.JetDrop
+	)
+	for i := 0; i < 3; i++ {
+		pn, err := db.GetLatestPulseNumber(ctx)
+		require.NoError(t, err)
+		pulse, err := db.GetPulse(ctx, pn)
+		require.NoError(t, err)
+		prev, err := db.GetDrop(ctx, *pulse.Prev)
+		require.NoError(t, err)
+		prevHash := prev.Hash
+		if i == 2 {
+			prevHash = []byte("broken")
+		}
+		drop, _, err := db.CreateDrop(ctx, pn, prevHash)
+		require.NoError(t, err)
+		require.NoError(t, db.SetDrop(ctx, drop))
+		require.NoError(t, db.AddPulse(ctx, core.Pulse{PulseNumber: pn + 1}))
+		pulses = append(pulses, pn)
+		drops = append(drops, drop)
+	}
+	open := pulses[2] + 1
+	view := func(i int, linked bool) dropView {
+		return dropView{
+			Pulse:    pulses[i],
+			PrevHash: hex.EncodeToString(drops[i].PrevHash),
+			Hash:     hex.EncodeToString(drops[i].Hash),
+			Linked:   linked,
+		}
+	}
+
+	tests := []struct {
+		name     string
+		from     core.PulseNumber
+		limit    int
+		expected []dropView
+	}{
+		{"whole chain", pulses[0], 0, []dropView{view(0, true), view(1, true), view(2, false)}},
+		{"limited", pulses[0], 2, []dropView{view(0, true), view(1, true)}},
+		{"checked against previous pulse", pulses[1], 0, []dropView{view(1, true), view(2, false)}},
+		{"pulse without drop", open, 0, []dropView{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chain, err := dropChain(ctx, db, tt.from, tt.limit)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, chain)
+		})
+	}
+}
@@ -0,0 +1,176 @@
+/*
+ *    Copyright 2018 Insolar
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ */
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/dgraph-io/badger"
+	"github.com/spf13/cobra"
+
+	"github.com/insolar/insolar/configuration"
+	"github.com/insolar/insolar/instrumentation/inslogger"
+	"github.com/insolar/insolar/ledger/exporter"
+	"github.com/insolar/insolar/ledger/storage"
+	"github.com/insolar/insolar/platformpolicy"
+)
+
+var (
+	dataDir string
+	keyFile string
+)
+
+func check(msg string, err error) {
+	if err != nil {
+		fmt.Fprintln(os.Stderr, msg, err)
+		os.Exit(1)
+	}
+}
+
+// openDB opens ledger storage in data directory. Node should be stopped.
+//
+// Read-only storage is not written, so it fails to open if encryption of plain values was not finished.
+func openDB(readOnly bool) *storage.DB {
+	conf := configuration.NewLedger()
+	conf.Storage.DataDirectory = dataDir
+	if keyFile != "" {
+		conf.Storage.Encryption.Enabled = true
+		conf.Storage.Encryption.KeyFile = keyFile
+	}
+	opts := badger.DefaultOptions
+	opts.ReadOnly = readOnly
+	db, err := storage.NewDB(conf, &opts, nil)
+	check("failed to open storage:", err)
+	db.PlatformCryptographyScheme = platformpolicy.NewPlatformCryptographyScheme()
+	return db
+}
+
+func snapshotCmd() *cobra.Command {
+	var output string
+	cmd := &cobra.Command{
+		Use:   "snapshot",
+		Short: "write ledger snapshot (records, blobs, lifelines, pulses and jet drops) to file",
+		Run: func(cmd *cobra.Command, args []string) {
+			ctx := inslogger.ContextWithTrace(context.Background(), "ledgerctl")
+			out, err := os.OpenFile(output, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+			check("failed to open output file:", err)
+			defer out.Close()
+
+			db := openDB(true)
+			defer db.Close()
+
+			count, err := db.Snapshot(ctx, out)
+			check("snapshot failed:", err)
+			fmt.Printf("snapshot is written: %v entries\n", count)
+		},
+	}
+	cmd.Flags().StringVarP(&output, "output", "o", "ledger.snapshot", "snapshot file")
+	return cmd
+}
+
+func restoreCmd() *cobra.Command {
+	var input string
+	cmd := &cobra.Command{
+		Use:   "restore",
+		Short: "restore ledger from snapshot file into empty data directory and verify jet drops",
+		Run: func(cmd *cobra.Command, args []string) {
+			ctx := inslogger.ContextWithTrace(context.Background(), "ledgerctl")
+			in, err := os.Open(input)
+			check("failed to open input file:", err)
+			defer in.Close()
+
+			db := openDB(false)
+			defer db.Close()
+
+			count, err := db.Restore(ctx, in)
+			check("restore failed:", err)
+			fmt.Printf("snapshot is restored: %v entries\n", count)
+		},
+	}
+	cmd.Flags().StringVarP(&input, "input", "i", "ledger.snapshot", "snapshot file")
+	return cmd
+}
+
+func importCmd() *cobra.Command {
+	var input string
+	cmd := &cobra.Command{
+		Use:   "import",
+		Short: "import records, blobs and lifelines from NDJSON export stream (use - for stdin)",
+		Run: func(cmd *cobra.Command, args []string) {
+			ctx := inslogger.ContextWithTrace(context.Background(), "ledgerctl")
+			in := os.Stdin
+			if input != "-" {
+				f, err := os.Open(input)
+				check("failed to open input file:", err)
+				defer f.Close()
+				in = f
+			}
+
+			db := openDB(false)
+			defer db.Close()
+			check("failed to init storage:", db.Init(ctx))
+
+			count, err := exporter.NewImporter(db).Import(ctx, in)
+			check("import failed:", err)
+			fmt.Printf("export is imported: %v records\n", count)
+		},
+	}
+	cmd.Flags().StringVarP(&input, "input", "i", "ledger.ndjson", "export stream file")
+	return cmd
+}
+
+func verifyCmd() *cobra.Command {
+	var readOnly bool
+	cmd := &cobra.Command{
+		Use:   "verify",
+		Short: "check jet drop hashes and links between lifelines, records and blobs, print JSON report",
+		Run: func(cmd *cobra.Command, args []string) {
+			ctx := inslogger.ContextWithTrace(context.Background(), "ledgerctl")
+			db := openDB(readOnly)
+			report, err := db.Verify(ctx)
+			closeErr := db.Close()
+			check("verify failed:", err)
+			check("failed to close storage:", closeErr)
+
+			out, err := json.MarshalIndent(report, "", "    ")
+			check("failed to marshal report:", err)
+			fmt.Println(string(out))
+			if !report.OK() {
+				os.Exit(2)
+			}
+		},
+	}
+	cmd.Flags().BoolVar(&readOnly, "readonly", true,
+		"open storage read-only (set to false if storage was not closed properly and needs log replay)")
+	return cmd
+}
+
+func main() {
+	rootCmd := &cobra.Command{
+		Use:   "ledgerctl",
+		Short: "offline tools for ledger storage (node should be stopped)",
+	}
+	rootCmd.PersistentFlags().StringVarP(&dataDir, "data", "d", "./data", "ledger data directory")
+	rootCmd.PersistentFlags().StringVar(&keyFile, "key-file", "", "encryption key file of encrypted storage")
+	rootCmd.PersistentFlags().BoolVar(&jsonOutput, "json", false, "print inspection output as JSON")
+	rootCmd.AddCommand(snapshotCmd(), restoreCmd(), importCmd(), verifyCmd())
+	rootCmd.AddCommand(recordCmd(), lifelineCmd(), blobCmd(), pulseCmd(), dropsCmd())
+	check("", rootCmd.Execute())
+}
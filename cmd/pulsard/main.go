@@ -124,7 +124,7 @@ func initPulsar(ctx context.Context, cfg configuration.Configuration) (*pulsar.P
 	cm.Register(cryptographyScheme, keyStore, keyProcessor)
 	cm.Inject(cryptographyService)
 
-	storage, err := pulsarstorage.NewStorageBadger(cfg.Pulsar, nil)
+	storage, err := pulsarstorage.NewStorageBadger(cfg.Pulsar, nil, keyStore)
 	if err != nil {
 		inslogger.FromContext(ctx).Fatal(err)
 		panic(err)
@@ -27,6 +27,9 @@ type APIRunner struct {
 	Info     string
 	Call     string
 	RPC      string
+	Export   string
+	// CallTimeout is the maximum time in seconds to wait for the called contract reply. Zero disables the limit.
+	CallTimeout int
 }
 
 // NewAPIRunner creates new api config
@@ -37,6 +40,9 @@ func NewAPIRunner() APIRunner {
 		Info:     "/api/v1/info",
 		Call:     "/api/v1/call",
 		RPC:      "/api/rpc",
+		Export:   "/api/export",
+
+		CallTimeout: 60,
 	}
 }
 
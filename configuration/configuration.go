@@ -31,6 +31,7 @@ type Configuration struct {
 	Host            HostNetwork
 	Node            NodeNetwork
 	Service         ServiceNetwork
+	MessageBus      MessageBus
 	Ledger          Ledger
 	Log             Log
 	Metrics         Metrics
@@ -55,6 +56,7 @@ func NewConfiguration() Configuration {
 		Host:            NewHostNetwork(),
 		Node:            NewNodeNetwork(),
 		Service:         NewServiceNetwork(),
+		MessageBus:      NewMessageBus(),
 		Ledger:          NewLedger(),
 		Log:             NewLog(),
 		Metrics:         NewMetrics(),
@@ -27,11 +27,40 @@ type Storage struct {
 	// TxRetriesOnConflict defines how many retries on transaction conflicts
 	// storage update methods should do.
 	TxRetriesOnConflict int
+	// BlobCompression defines compression of stored blobs: "none" or "gzip".
+	// Blobs are decompressed transparently regardless of the current setting.
+	BlobCompression string
+	// Encryption defines encryption of stored data at rest.
+	Encryption StorageEncryption
+}
+
+// StorageEncryption configures encryption of storage data at rest.
+type StorageEncryption struct {
+	// Enabled switches encryption of stored values on. Values written before are encrypted when storage is opened.
+	// Once encrypted, storage could not be opened with encryption disabled.
+	Enabled bool
+	// KeyFile is a path to file with hex encoded 256-bit keys, one per line. The last key encrypts new data,
+	// previous keys are kept to read data until it is re-encrypted after rotation.
+	// If empty, the key is derived from node's private key in keystore.
+	KeyFile string
 }
 
 // JetCoordinator holds configuration for JetCoordinator.
 type JetCoordinator struct {
 	RoleCounts map[int]int
+	// SplitThreshold is a number of records per pulse. Jets with more records are split in two. Zero disables split.
+	SplitThreshold int
+	// MergeThreshold is a number of records per pulse. Sibling jets with fewer records in total are merged.
+	MergeThreshold int
+}
+
+// ArtifactManager holds configuration for artifact manager client.
+type ArtifactManager struct {
+	// CodeCacheSize is a maximum number of cached code descriptors. Code never changes, so descriptors are kept
+	// until evicted by newer ones. Zero disables the cache.
+	CodeCacheSize int
+	// ObjectCacheSize is a maximum number of objects cached within current pulse. Zero disables the cache.
+	ObjectCacheSize int
 }
 
 // Ledger holds configuration for ledger.
@@ -42,6 +71,16 @@ type Ledger struct {
 	JetCoordinator JetCoordinator
 	// HeavyReplication defines replication to heavy storage node.
 	HeavyReplication HeavyReplication
+	// ArtifactManager defines artifact manager client configuration.
+	ArtifactManager ArtifactManager
+
+	// NodeHistoryDepth defines for how many pulses active node lists are kept in storage.
+	// Non-positive value means the history is never truncated.
+	NodeHistoryDepth int
+
+	// LightChainLimit defines for how many pulses records, blobs and local data are kept on light material node
+	// after replication to heavy material node. Non-positive value means the data is never pruned.
+	LightChainLimit int
 }
 
 // HeavyReplication configures replication to heavy node
@@ -55,6 +94,7 @@ func NewLedger() Ledger {
 		Storage: Storage{
 			DataDirectory:       "./data",
 			TxRetriesOnConflict: 3,
+			BlobCompression:     "none",
 		},
 
 		JetCoordinator: JetCoordinator{
@@ -65,6 +105,16 @@ func NewLedger() Ledger {
 				int(core.RoleVirtualValidator): 1,
 				int(core.RoleLightValidator):   1,
 			},
+			SplitThreshold: 1000,
+			MergeThreshold: 100,
+		},
+
+		ArtifactManager: ArtifactManager{
+			CodeCacheSize:   1000,
+			ObjectCacheSize: 10000,
 		},
+
+		NodeHistoryDepth: 1000,
+		LightChainLimit:  10,
 	}
 }
@@ -0,0 +1,29 @@
+/*
+ *    Copyright 2018 Insolar
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ */
+
+package configuration
+
+// MessageBus holds configuration for MessageBus.
+type MessageBus struct {
+	// MaxRedirects is the maximum number of redirect replies followed for one message.
+	// Zero means the default value, negative value disables redirects.
+	MaxRedirects int
+}
+
+// NewMessageBus creates new default configuration for MessageBus.
+func NewMessageBus() MessageBus {
+	return MessageBus{MaxRedirects: 3}
+}
@@ -19,7 +19,10 @@
 package delegationtoken
 
 import (
+	"encoding/gob"
+
 	"github.com/insolar/insolar/core"
+	"github.com/insolar/insolar/core/wire"
 	"github.com/pkg/errors"
 )
 
@@ -42,15 +45,49 @@ func (t *PendingExecution) Verify(parcel core.Parcel) (bool, error) {
 	}
 }
 
-// GetObjectRedirect is a redirect token for the GetObject method
+// GetObjectRedirect is a redirect token for the GetObject method and for objects forwarded by GetObjects
 type GetObjectRedirect struct {
 	Signature []byte
+	// Issuer is the node that signed the redirect.
+	Issuer core.RecordRef `wire:",since=2"`
 }
 
 func (t *GetObjectRedirect) Type() core.DelegationTokenType {
 	return core.DTTypeGetObjectRedirect
 }
 
+// Verify checks that the message can be redirected with the token. Signature of the issuer is checked by the
+// token factory because it requires the issuer's public key.
 func (t *GetObjectRedirect) Verify(parcel core.Parcel) (bool, error) {
-	panic("")
+	switch mt := parcel.Message().Type(); mt {
+	case core.TypeGetObject, core.TypeGetObjects:
+		return true, nil
+	default:
+		return false, errors.Errorf("Message of type %s can't be delegated with %s token", mt, t.Type())
+	}
+}
+
+func getEmptyToken(t core.DelegationTokenType) (core.DelegationToken, error) {
+	switch t {
+	case core.DTTypePendingExecution:
+		return &PendingExecution{}, nil
+	case core.DTTypeGetObjectRedirect:
+		return &GetObjectRedirect{}, nil
+	default:
+		return nil, errors.Errorf("unimplemented delegation token type %d", t)
+	}
+}
+
+func init() {
+	gob.Register(&PendingExecution{})
+	gob.Register(&GetObjectRedirect{})
+
+	wire.RegisterInterface((*core.DelegationToken)(nil), wire.Implementations{
+		TypeOf: func(v interface{}) byte {
+			return byte(v.(core.DelegationToken).Type())
+		},
+		New: func(t byte) (interface{}, error) {
+			return getEmptyToken(core.DelegationTokenType(t))
+		},
+	})
 }
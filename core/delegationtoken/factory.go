@@ -22,10 +22,12 @@ import (
 
 	"github.com/insolar/insolar/core"
 	"github.com/insolar/insolar/core/message"
+	"github.com/pkg/errors"
 )
 
 type delegationTokenFactory struct {
 	Cryptography core.CryptographyService `inject:""`
+	NodeNetwork  core.NodeNetwork         `inject:""`
 }
 
 func NewDelegationTokenFactory() core.DelegationTokenFactory {
@@ -52,20 +54,62 @@ func (f *delegationTokenFactory) IssuePendingExecution(
 	return token, nil
 }
 
+// IssueGetObjectRedirect issues token for GetObject redirect or for GetObjects forwarded to another node.
 func (f *delegationTokenFactory) IssueGetObjectRedirect(sender *core.RecordRef, redirectedMessage core.Message) (core.DelegationToken, error) {
-	parsedMessage := redirectedMessage.(*message.GetObject)
-	dataForSign := append(sender.Bytes(), message.ToBytes(parsedMessage)...)
-	sign, err := f.Cryptography.Sign(dataForSign)
+	switch redirectedMessage.(type) {
+	case *message.GetObject, *message.GetObjects:
+	default:
+		return nil, errors.Errorf("message of type %s can't be redirected", redirectedMessage.Type())
+	}
+	data, err := redirectSignData(sender, redirectedMessage)
+	if err != nil {
+		return nil, err
+	}
+	sign, err := f.Cryptography.Sign(data)
 	if err != nil {
 		return nil, err
 	}
-	return &GetObjectRedirect{Signature: sign.Bytes()}, nil
+	return &GetObjectRedirect{
+		Signature: sign.Bytes(),
+		Issuer:    f.NodeNetwork.GetOrigin().ID(),
+	}, nil
 }
 
 func (f *delegationTokenFactory) Verify(parcel core.Parcel) (bool, error) {
-	if parcel.DelegationToken() == nil {
+	token := parcel.DelegationToken()
+	if token == nil {
 		return false, nil
 	}
 
-	return parcel.DelegationToken().Verify(parcel)
+	valid, err := token.Verify(parcel)
+	if err != nil || !valid {
+		return valid, err
+	}
+	if redirect, ok := token.(*GetObjectRedirect); ok {
+		return f.verifyGetObjectRedirect(redirect, parcel), nil
+	}
+	return true, nil
+}
+
+// verifyGetObjectRedirect checks that the token was signed by an active node for the sender and the message of
+// the parcel.
+func (f *delegationTokenFactory) verifyGetObjectRedirect(token *GetObjectRedirect, parcel core.Parcel) bool {
+	issuer := f.NodeNetwork.GetActiveNode(token.Issuer)
+	if issuer == nil {
+		return false
+	}
+	sender := parcel.GetSender()
+	data, err := redirectSignData(&sender, parcel.Message())
+	if err != nil {
+		return false
+	}
+	return f.Cryptography.Verify(issuer.PublicKey(), core.SignatureFromBytes(token.Signature), data)
+}
+
+func redirectSignData(sender *core.RecordRef, msg core.Message) ([]byte, error) {
+	encoded, err := message.ToBytes(msg)
+	if err != nil {
+		return nil, err
+	}
+	return append(sender.Bytes(), encoded...), nil
 }
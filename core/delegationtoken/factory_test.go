@@ -0,0 +1,89 @@
+/*
+ *    Copyright 2018 Insolar
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ */
+
+package delegationtoken
+
+import (
+	"bytes"
+	"crypto"
+	"testing"
+
+	"github.com/insolar/insolar/core"
+	"github.com/insolar/insolar/core/message"
+	"github.com/insolar/insolar/testutils"
+	"github.com/insolar/insolar/testutils/network"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDelegationTokenFactory_GetObjectRedirect(t *testing.T) {
+	issuerRef := testutils.RandomRef()
+	issuer := network.NewNodeMock(t)
+	issuer.IDFunc = func() core.RecordRef { return issuerRef }
+	issuer.PublicKeyFunc = func() crypto.PublicKey { return "issuer key" }
+
+	nodeNet := network.NewNodeNetworkMock(t)
+	nodeNet.GetOriginFunc = func() core.Node { return issuer }
+	nodeNet.GetActiveNodeFunc = func(ref core.RecordRef) core.Node {
+		if ref == issuerRef {
+			return issuer
+		}
+		return nil
+	}
+	// Signature is the signed data itself.
+	cs := testutils.NewCryptographyServiceMock(t)
+	cs.SignFunc = func(data []byte) (*core.Signature, error) {
+		sign := core.SignatureFromBytes(data)
+		return &sign, nil
+	}
+	cs.VerifyFunc = func(key crypto.PublicKey, sign core.Signature, data []byte) bool {
+		return key == "issuer key" && bytes.Equal(sign.Bytes(), data)
+	}
+	factory := &delegationTokenFactory{Cryptography: cs, NodeNetwork: nodeNet}
+
+	sender := testutils.RandomRef()
+	msg := &message.GetObject{Head: testutils.RandomRef()}
+	token, err := factory.IssueGetObjectRedirect(&sender, msg)
+	require.NoError(t, err)
+	require.Equal(t, issuerRef, token.(*GetObjectRedirect).Issuer)
+
+	t.Run("valid", func(t *testing.T) {
+		valid, err := factory.Verify(&message.Parcel{Sender: sender, Msg: msg, Token: token})
+		require.NoError(t, err)
+		assert.True(t, valid)
+	})
+	t.Run("another sender", func(t *testing.T) {
+		valid, err := factory.Verify(&message.Parcel{Sender: testutils.RandomRef(), Msg: msg, Token: token})
+		require.NoError(t, err)
+		assert.False(t, valid)
+	})
+	t.Run("another message", func(t *testing.T) {
+		other := &message.GetObject{Head: testutils.RandomRef()}
+		valid, err := factory.Verify(&message.Parcel{Sender: sender, Msg: other, Token: token})
+		require.NoError(t, err)
+		assert.False(t, valid)
+	})
+	t.Run("unknown issuer", func(t *testing.T) {
+		forged := &GetObjectRedirect{Signature: token.(*GetObjectRedirect).Signature, Issuer: testutils.RandomRef()}
+		valid, err := factory.Verify(&message.Parcel{Sender: sender, Msg: msg, Token: forged})
+		require.NoError(t, err)
+		assert.False(t, valid)
+	})
+	t.Run("not redirectable message", func(t *testing.T) {
+		_, err := factory.Verify(&message.Parcel{Sender: sender, Msg: &message.GetCode{}, Token: token})
+		assert.Error(t, err)
+	})
+}
@@ -25,4 +25,14 @@ var (
 	ErrDeactivated = errors.New("object is deactivated")
 	// ErrStateNotAvailable returned when requested object is deactivated.
 	ErrStateNotAvailable = errors.New("object state is not available")
+	// ErrStateRejected returned when new object state is based on the state rejected by validators.
+	ErrStateRejected = errors.New("object state is rejected by validation")
+	// ErrHeavySyncInProgress returned when heavy sync range is locked by another node.
+	ErrHeavySyncInProgress = errors.New("heavy node sync in progress")
+	// ErrDeadlineExceeded returned when message reply is not received before message deadline.
+	ErrDeadlineExceeded = errors.New("message deadline exceeded")
+	// ErrQuorumNotReached returned when not enough message recipients handled the message.
+	ErrQuorumNotReached = errors.New("quorum is not reached")
+	// ErrObjectNotFound returned when requested object is not found.
+	ErrObjectNotFound = errors.New("object is not found")
 )
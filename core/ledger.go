@@ -18,6 +18,7 @@ package core
 
 import (
 	"context"
+	"io"
 )
 
 // JetRole is number representing a node role.
@@ -62,6 +63,18 @@ type PulseManager interface {
 	Set(context.Context, Pulse) error
 }
 
+// RetentionPolicy removes data which is not required to be stored on the node anymore.
+type RetentionPolicy interface {
+	// Apply removes stale data according to the policy. Pulse is the latest closed pulse.
+	Apply(ctx context.Context, pulse PulseNumber) error
+}
+
+// DescriptorCache caches ledger descriptors on the client side.
+type DescriptorCache interface {
+	// ResetPulse drops cached data which is valid only within the previous pulse.
+	ResetPulse(ctx context.Context, pulse PulseNumber)
+}
+
 // JetCoordinator provides methods for calculating Jet affinity
 // (e.g. to which Jet a message should be sent).
 type JetCoordinator interface {
@@ -73,6 +86,10 @@ type JetCoordinator interface {
 
 	// GetActiveNodes return active nodes for specified pulse.
 	GetActiveNodes(pulse PulseNumber) ([]Node, error)
+
+	// UpdateJetTree reports load of jets executed by the node in the closed pulse to jet tree authority, which
+	// agrees jet tree for the next pulse.
+	UpdateJetTree(ctx context.Context, closed, next PulseNumber) error
 }
 
 // ArtifactManager is a high level storage interface.
@@ -91,6 +108,11 @@ type ArtifactManager interface {
 	// When fetching object, validity can be specified.
 	RegisterValidation(ctx context.Context, object RecordRef, state RecordID, isValid bool, validationMessages []Message) error
 
+	// GetObjectStatus returns validation status of provided object.
+	//
+	// States rejected by validators are removed from object's history and can not be used as previous states.
+	GetObjectStatus(ctx context.Context, head RecordRef) (*ObjectStatus, error)
+
 	// RegisterResult saves VM method call result.
 	RegisterResult(ctx context.Context, request RecordRef, payload []byte) (*RecordID, error)
 
@@ -101,9 +123,21 @@ type ArtifactManager interface {
 
 	// GetObject returns descriptor for provided state.
 	//
-	// If provided state is nil, the latest state will be returned (with deactivation check). Returned descriptor will
-	// provide methods for fetching all related data.
-	GetObject(ctx context.Context, head RecordRef, state *RecordID, approved bool) (ObjectDescriptor, error)
+	// If provided state is nil, the latest state will be returned (with deactivation check). If pulse is provided,
+	// the newest state created at or before this pulse will be returned. Returned descriptor will provide methods for
+	// fetching all related data.
+	GetObject(
+		ctx context.Context,
+		head RecordRef,
+		state *RecordID,
+		pulse *PulseNumber,
+		approved bool,
+	) (ObjectDescriptor, error)
+
+	// GetObjects returns descriptors for provided objects in one request.
+	//
+	// Descriptors are returned in the order of provided heads. Fails if any of the objects can not be fetched.
+	GetObjects(ctx context.Context, heads []ObjectHead, approved bool) ([]ObjectDescriptor, error)
 
 	// GetDelegate returns provided object's delegate reference for provided type.
 	//
@@ -116,6 +150,31 @@ type ArtifactManager interface {
 	// During iteration children refs will be fetched from remote source (parent object).
 	GetChildren(ctx context.Context, parent RecordRef, pulse *PulseNumber) (RefIterator, error)
 
+	// GetChildrenPage returns a page of children refs filtered on the parent's side.
+	//
+	// Children are listed from the latest to the earliest one. Next page should be requested with From set to the
+	// returned cursor.
+	GetChildrenPage(ctx context.Context, parent RecordRef, query ChildrenQuery) (*ChildrenPage, error)
+
+	// CountChildren returns the number of children matching the query. Limit of the query is ignored.
+	CountChildren(ctx context.Context, parent RecordRef, query ChildrenQuery) (int, error)
+
+	// GetHistory returns object's state history iterator.
+	//
+	// States are iterated from the latest to the earliest one. If pulse is provided, states created after it are
+	// skipped. During iteration states will be fetched from remote source (object's executor).
+	GetHistory(ctx context.Context, head RecordRef, pulse *PulseNumber) (ObjectHistoryIterator, error)
+
+	// GetRecordProof returns Merkle inclusion proof of the record in the jet drop of its pulse.
+	//
+	// Proof is available only after the pulse of the record is closed.
+	GetRecordProof(ctx context.Context, id RecordID) (*RecordProof, error)
+
+	// GetObjectsByPrototype returns a page of activated object heads of provided prototype.
+	//
+	// Objects are ordered by head id. Next page should be requested with After set to the returned cursor.
+	GetObjectsByPrototype(ctx context.Context, query ObjectsQuery) (*ObjectsPage, error)
+
 	// DeclareType creates new type record in storage.
 	//
 	// Type is a contract interface. It contains one method signature.
@@ -228,6 +287,110 @@ type RefIterator interface {
 	HasNext() bool
 }
 
+// ObjectHistoryEntry represents a single object state in object's history.
+type ObjectHistoryEntry struct {
+	// State is an id of the state record.
+	State RecordID
+	// Pulse is a pulse number the state was created in.
+	Pulse PulseNumber
+	// Request is a reference to the request which produced the state.
+	Request RecordRef
+	// Memory is an object memory for the state. It is empty for deactivation.
+	Memory []byte
+	// Deactivated is true if the state deactivates the object.
+	Deactivated bool
+}
+
+// ObjectHistoryIterator is used for iteration over object states.
+type ObjectHistoryIterator interface {
+	Next() (*ObjectHistoryEntry, error)
+	HasNext() bool
+}
+
+// RecordProof is a Merkle inclusion proof of a record in a jet drop.
+type RecordProof struct {
+	// Record is an id of the proved record.
+	Record RecordID
+	// DropHash is a hash of the jet drop containing the record.
+	DropHash []byte
+	// Path is a list of sibling hashes from the record up to the drop hash.
+	Path []RecordProofStep
+}
+
+// RecordProofStep is a single step of Merkle inclusion proof.
+type RecordProofStep struct {
+	// Hash is a hash of the sibling tree node.
+	Hash []byte
+	// Left is true if the sibling is a left node.
+	Left bool
+}
+
+// ObjectsQuery is a filter and a page for listing objects by prototype.
+type ObjectsQuery struct {
+	// Prototype is a prototype of listed objects.
+	Prototype RecordRef
+	// Parent filters objects by parent if set.
+	Parent *RecordRef
+	// Pulse selects objects active on the pulse if set, otherwise currently active objects are selected.
+	Pulse *PulseNumber
+	// After is a head id of the last object of the previous page.
+	After *RecordID
+	// Limit is a maximum number of objects in the page.
+	Limit int
+}
+
+// ObjectsPage is a page of objects listed by prototype.
+type ObjectsPage struct {
+	// Heads are references of the objects.
+	Heads []RecordRef
+	// Next is a cursor for the next page. It is nil for the last page.
+	Next *RecordID
+}
+
+// ChildrenQuery is a filter and a page for listing object children.
+type ChildrenQuery struct {
+	// Prototype filters children by prototype if set. Children known to be deactivated are skipped as well.
+	Prototype *RecordRef
+	// Pulse skips children created after the pulse if set.
+	Pulse *PulseNumber
+	// From is a child record id to continue listing from. Listing starts from the latest child if not set.
+	From *RecordID
+	// Limit is a maximum number of children in the page.
+	Limit int
+}
+
+// ChildrenPage is a page of object children.
+type ChildrenPage struct {
+	// Refs are references of the children heads.
+	Refs []RecordRef
+	// Next is a cursor for the next page. It is nil for the last page.
+	Next *RecordID
+}
+
+// ObjectHead identifies object state requested in a batch. The latest state is requested if State is nil.
+type ObjectHead struct {
+	Head  RecordRef
+	State *RecordID
+}
+
+// ObjectValidation is a result of object state validation.
+type ObjectValidation struct {
+	State     RecordID
+	IsValid   bool
+	Validator RecordRef
+	Signature []byte
+}
+
+// ObjectStatus describes validation status of an object.
+type ObjectStatus struct {
+	LatestState         *RecordID
+	LatestStateApproved *RecordID
+	// Pending is true if the latest state is not approved by validators yet.
+	Pending bool
+	// Validations are results of object states validation in order they were registered.
+	Validations []ObjectValidation
+}
+
 // LocalStorage allows a node to save local data.
 //go:generate minimock -i github.com/insolar/insolar/core.LocalStorage -o ../testutils -s _mock.go
 type LocalStorage interface {
@@ -254,8 +417,22 @@ type StorageExportResult struct {
 	Size     int
 }
 
+// ExportCursor points to a position in storage export stream.
+type ExportCursor struct {
+	// Pulse is a pulse to start export from.
+	Pulse PulseNumber
+	// After is the last exported record of the pulse. If provided, records of the pulse up to and including it are
+	// skipped.
+	After *RecordID
+}
+
 // StorageExporter provides methods for fetching data view from storage.
 type StorageExporter interface {
 	// Export returns data view from storage.
 	Export(ctx context.Context, fromPulse PulseNumber, size int) (*StorageExportResult, error)
+
+	// Stream writes records of closed pulses starting from cursor to w as newline delimited JSON.
+	//
+	// If follow is true, new pulses are written as they close until context is cancelled.
+	Stream(ctx context.Context, w io.Writer, from ExportCursor, follow bool) error
 }
@@ -19,13 +19,13 @@ package message
 
 import (
 	"bytes"
-	"encoding/gob"
 	"io"
 	"io/ioutil"
 
 	"github.com/pkg/errors"
 
 	"github.com/insolar/insolar/core"
+	"github.com/insolar/insolar/core/wire"
 )
 
 // GetEmptyMessage constructs specified message
@@ -53,6 +53,20 @@ func getEmptyMessage(mt core.MessageType) (core.Message, error) {
 		return &GetDelegate{}, nil
 	case core.TypeGetChildren:
 		return &GetChildren{}, nil
+	case core.TypeGetHistory:
+		return &GetHistory{}, nil
+	case core.TypeGetRecordProof:
+		return &GetRecordProof{}, nil
+	case core.TypeGetObjectsByPrototype:
+		return &GetObjectsByPrototype{}, nil
+	case core.TypeGetObjects:
+		return &GetObjects{}, nil
+	case core.TypeGetObjectStatus:
+		return &GetObjectStatus{}, nil
+	case core.TypeGetJetTree:
+		return &GetJetTree{}, nil
+	case core.TypeJetLoad:
+		return &JetLoad{}, nil
 	case core.TypeUpdateObject:
 		return &UpdateObject{}, nil
 	case core.TypeRegisterChild:
@@ -61,6 +75,10 @@ func getEmptyMessage(mt core.MessageType) (core.Message, error) {
 		return &JetDrop{}, nil
 	case core.TypeSetRecord:
 		return &SetRecord{}, nil
+	case core.TypeValidateRecord:
+		return &ValidateRecord{}, nil
+	case core.TypeSetBlob:
+		return &SetBlob{}, nil
 
 	// Bootstrap
 	case core.TypeBootstrapRequest:
@@ -84,23 +102,26 @@ func MustSerializeBytes(msg core.Message) []byte {
 }
 
 // Serialize returns io.Reader on buffer with encoded core.Message.
+//
+// Message is encoded as its type followed by wire encoding of the message.
 func Serialize(msg core.Message) (io.Reader, error) {
-	buff := &bytes.Buffer{}
-	_, err := buff.Write([]byte{byte(msg.Type())})
+	encoded, err := wire.Marshal(msg)
 	if err != nil {
 		return nil, err
 	}
-
-	enc := gob.NewEncoder(buff)
-	err = enc.Encode(msg)
-	return buff, err
+	buff := bytes.NewBuffer(make([]byte, 0, len(encoded)+1))
+	buff.WriteByte(byte(msg.Type()))
+	buff.Write(encoded)
+	return buff, nil
 }
 
 // Deserialize returns decoded message.
 func Deserialize(buff io.Reader) (core.Parcel, error) {
-	b := make([]byte, 1)
-	_, err := buff.Read(b)
+	b, err := ioutil.ReadAll(buff)
 	if err != nil {
+		return nil, err
+	}
+	if len(b) < 1 {
 		return nil, errors.New("too short slice for deserialize message")
 	}
 
@@ -108,78 +129,68 @@ func Deserialize(buff io.Reader) (core.Parcel, error) {
 	if err != nil {
 		return nil, err
 	}
-	enc := gob.NewDecoder(buff)
-	if err = enc.Decode(msg); err != nil {
+	if err = wire.Unmarshal(b[1:], msg); err != nil {
 		return nil, err
 	}
 	return &Parcel{Msg: msg}, nil
 }
 
-// ToBytes deserialize a core.Message to bytes.
-func ToBytes(msg core.Message) []byte {
+// ToBytes serializes a core.Message to bytes.
+func ToBytes(msg core.Message) ([]byte, error) {
 	reqBuff, err := Serialize(msg)
 	if err != nil {
-		panic(errors.Wrap(err, "failed to serialize message"))
+		return nil, errors.Wrap(err, "failed to serialize message")
 	}
-	buff, err := ioutil.ReadAll(reqBuff)
-	if err != nil {
-		panic(errors.Wrap(err, "failed to serialize message"))
-	}
-	return buff
+	return ioutil.ReadAll(reqBuff)
 }
 
 // SerializeParcel returns io.Reader on buffer with encoded core.Parcel.
 func SerializeParcel(parcel core.Parcel) (io.Reader, error) {
-	buff := &bytes.Buffer{}
-	enc := gob.NewEncoder(buff)
-	err := enc.Encode(parcel)
-	return buff, err
+	signed, ok := parcel.(*Parcel)
+	if !ok {
+		return nil, errors.Errorf("can't serialize parcel of type %T", parcel)
+	}
+	encoded, err := wire.Marshal(signed)
+	if err != nil {
+		return nil, err
+	}
+	return bytes.NewBuffer(encoded), nil
 }
 
 // DeserializeParcel returns decoded signed message.
 func DeserializeParcel(buff io.Reader) (core.Parcel, error) {
+	b, err := ioutil.ReadAll(buff)
+	if err != nil {
+		return nil, err
+	}
 	var signed Parcel
-	enc := gob.NewDecoder(buff)
-	err := enc.Decode(&signed)
-	return &signed, err
+	if err = wire.Unmarshal(b, &signed); err != nil {
+		return nil, err
+	}
+	// Signature is verified against the received message encoding, not the encoding of decoded message.
+	signed.payload, err = wire.RawField(b, &signed, "Msg")
+	if err != nil {
+		return nil, err
+	}
+	return &signed, nil
 }
 
-// ParcelToBytes deserialize a core.Parcel to bytes.
-func ParcelToBytes(msg core.Parcel) []byte {
+// ParcelToBytes serializes a core.Parcel to bytes.
+func ParcelToBytes(msg core.Parcel) ([]byte, error) {
 	reqBuff, err := SerializeParcel(msg)
 	if err != nil {
-		panic("failed to serialize message: " + err.Error())
+		return nil, errors.Wrap(err, "failed to serialize parcel")
 	}
-	buf, err := ioutil.ReadAll(reqBuff)
-	if err != nil {
-		panic("failed to serialize message: " + err.Error())
-	}
-	return buf
+	return ioutil.ReadAll(reqBuff)
 }
 
 func init() {
-	// Bootstrap
-	gob.Register(&GenesisRequest{})
-
-	// Logicrunner
-	gob.Register(&CallConstructor{})
-	gob.Register(&CallMethod{})
-	gob.Register(&ExecutorResults{})
-	gob.Register(&ValidateCaseBind{})
-	gob.Register(&ValidationResults{})
-
-	// Ledger
-	gob.Register(&GetCode{})
-	gob.Register(&GetObject{})
-	gob.Register(&GetDelegate{})
-	gob.Register(&UpdateObject{})
-	gob.Register(&RegisterChild{})
-	gob.Register(&JetDrop{})
-	gob.Register(&SetRecord{})
-
-	// Bootstrap
-	gob.Register(&GenesisRequest{})
-	gob.Register(&Parcel{})
-	gob.Register(core.RecordRef{})
-	gob.Register(&GetChildren{})
+	wire.RegisterInterface((*core.Message)(nil), wire.Implementations{
+		TypeOf: func(v interface{}) byte {
+			return byte(v.(core.Message).Type())
+		},
+		New: func(t byte) (interface{}, error) {
+			return getEmptyMessage(core.MessageType(t))
+		},
+	})
 }
@@ -36,10 +36,14 @@ func (e *HeavyPayload) Type() core.MessageType {
 }
 
 // HeavyStartStop carries heavy replication start/stop signal with pulse range.
+//
+// DropHashes holds jet drop hashes of the range pulses signed by the sender with the start signal. Replicated drops
+// are verified against them.
 type HeavyStartStop struct {
-	Begin    core.PulseNumber
-	End      core.PulseNumber
-	Finished bool
+	Begin      core.PulseNumber
+	End        core.PulseNumber
+	Finished   bool
+	DropHashes map[core.PulseNumber][]byte
 }
 
 // GetCaller implementation of Message interface.
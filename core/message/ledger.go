@@ -17,8 +17,6 @@
 package message
 
 import (
-	"encoding/gob"
-
 	"github.com/insolar/insolar/core"
 )
 
@@ -58,7 +56,8 @@ func (e *GetCode) Type() core.MessageType {
 type GetObject struct {
 	ledgerMessage
 	Head     core.RecordRef
-	State    *core.RecordID // If nil, will fetch the latest state.
+	State    *core.RecordID    // If nil, will fetch the latest state.
+	Pulse    *core.PulseNumber // If set, will fetch the newest state at or before the pulse (starting from State).
 	Approved bool
 }
 
@@ -67,6 +66,20 @@ func (e *GetObject) Type() core.MessageType {
 	return core.TypeGetObject
 }
 
+// GetObjects retrieves a batch of objects.
+type GetObjects struct {
+	ledgerMessage
+	Objects  []core.ObjectHead
+	Approved bool
+	// Redirected is set for objects forwarded by another node. Such objects are not forwarded again.
+	Redirected bool
+}
+
+// Type implementation of Message interface.
+func (e *GetObjects) Type() core.MessageType {
+	return core.TypeGetObjects
+}
+
 // GetDelegate retrieves object represented as provided type.
 type GetDelegate struct {
 	ledgerMessage
@@ -113,6 +126,10 @@ type GetChildren struct {
 	FromChild *core.RecordID
 	FromPulse *core.PulseNumber
 	Amount    int
+	// Prototype filters children by prototype if set.
+	Prototype *core.RecordRef `wire:",since=2"`
+	// Count requests the number of matching children instead of their references.
+	Count bool `wire:",since=2"`
 }
 
 // Type implementation of Message interface.
@@ -120,6 +137,42 @@ func (e *GetChildren) Type() core.MessageType {
 	return core.TypeGetChildren
 }
 
+// GetHistory retrieves a chunk of object's state history.
+type GetHistory struct {
+	ledgerMessage
+	Object    core.RecordRef
+	FromState *core.RecordID
+	FromPulse *core.PulseNumber
+	Amount    int
+}
+
+// Type implementation of Message interface.
+func (e *GetHistory) Type() core.MessageType {
+	return core.TypeGetHistory
+}
+
+// GetRecordProof retrieves Merkle inclusion proof of a record in its jet drop.
+type GetRecordProof struct {
+	ledgerMessage
+	Record core.RecordID
+}
+
+// Type implementation of Message interface.
+func (e *GetRecordProof) Type() core.MessageType {
+	return core.TypeGetRecordProof
+}
+
+// GetObjectsByPrototype retrieves a page of activated objects by their prototype.
+type GetObjectsByPrototype struct {
+	ledgerMessage
+	Query core.ObjectsQuery
+}
+
+// Type implementation of Message interface.
+func (e *GetObjectsByPrototype) Type() core.MessageType {
+	return core.TypeGetObjectsByPrototype
+}
+
 // JetDrop spreads jet drop
 type JetDrop struct {
 	ledgerMessage
@@ -149,6 +202,40 @@ func (*ValidateRecord) Type() core.MessageType {
 	return core.TypeValidateRecord
 }
 
+// GetObjectStatus retrieves object validation status.
+type GetObjectStatus struct {
+	ledgerMessage
+	Head core.RecordRef
+}
+
+// Type implementation of Message interface.
+func (*GetObjectStatus) Type() core.MessageType {
+	return core.TypeGetObjectStatus
+}
+
+// GetJetTree retrieves jet tree agreed at the end of the pulse from jet tree authority of the pulse.
+type GetJetTree struct {
+	ledgerMessage
+	Pulse core.PulseNumber
+}
+
+// Type implementation of Message interface.
+func (*GetJetTree) Type() core.MessageType {
+	return core.TypeGetJetTree
+}
+
+// JetLoad reports load of jets executed by the sender in the closed pulse to jet tree authority of the pulse.
+type JetLoad struct {
+	ledgerMessage
+	Pulse core.PulseNumber
+	Loads map[core.RecordID]int
+}
+
+// Type implementation of Message interface.
+func (*JetLoad) Type() core.MessageType {
+	return core.TypeJetLoad
+}
+
 // SetBlob saves blob in storage.
 type SetBlob struct {
 	ledgerMessage
@@ -157,11 +244,6 @@ type SetBlob struct {
 	Memory    []byte
 }
 
-func init() {
-	gob.Register(&SetBlob{})
-	gob.Register(&ValidateRecord{})
-}
-
 // Type implementation of Message interface.
 func (*SetBlob) Type() core.MessageType {
 	return core.TypeSetBlob
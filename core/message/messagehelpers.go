@@ -21,6 +21,24 @@ func ExtractTarget(msg core.Message) core.RecordRef {
 		return t.RecordRef
 	case *GetChildren:
 		return t.Parent
+	case *GetHistory:
+		return t.Object
+	case *GetRecordProof:
+		return *core.NewRecordRef(core.RecordID{}, t.Record)
+	case *GetObjectsByPrototype:
+		// Index entries are written by light executor of the object jet, so the query reaches all objects while they
+		// share a jet with the prototype.
+		return t.Query.Prototype
+	case *GetObjects:
+		if len(t.Objects) == 0 {
+			return core.RecordRef{}
+		}
+		return t.Objects[0].Head
+	case *GetObjectStatus:
+		return t.Head
+	case *GetJetTree, *JetLoad:
+		// Jet tree messages are sent to jet tree authority by destination.
+		return core.RecordRef{}
 	case *GetCode:
 		return t.Code
 	case *GetDelegate:
@@ -64,6 +82,18 @@ func ExtractRole(msg core.Message) core.JetRole {
 		return core.RoleVirtualExecutor
 	case *GetChildren:
 		return core.RoleLightExecutor
+	case *GetHistory:
+		return core.RoleLightExecutor
+	case *GetRecordProof:
+		return core.RoleLightExecutor
+	case *GetObjectsByPrototype:
+		return core.RoleLightExecutor
+	case *GetObjects:
+		return core.RoleLightExecutor
+	case *GetObjectStatus:
+		return core.RoleLightExecutor
+	case *GetJetTree, *JetLoad:
+		return core.RoleLightExecutor
 	case *GetCode:
 		return core.RoleLightExecutor
 	case *GetDelegate:
@@ -121,6 +151,19 @@ func ExtractAllowedSenderObjectAndRole(msg core.Message) (*core.RecordRef, core.
 		return nil, 0
 	case *GetChildren:
 		return nil, 0
+	case *GetHistory:
+		return nil, 0
+	case *GetRecordProof:
+		return nil, 0
+	case *GetObjectsByPrototype:
+		return nil, 0
+	case *GetObjects:
+		return nil, 0
+	case *GetObjectStatus:
+		return nil, 0
+	case *GetJetTree, *JetLoad:
+		// Jet tree authority checks the sender by itself.
+		return nil, 0
 	case *GetCode:
 		return nil, 0
 	case *GetDelegate:
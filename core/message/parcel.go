@@ -19,6 +19,8 @@ package message
 import (
 	"context"
 	"crypto"
+	"encoding/binary"
+	"time"
 
 	"github.com/insolar/insolar/core"
 	"github.com/insolar/insolar/instrumentation/inslogger"
@@ -38,6 +40,10 @@ type Parcel struct {
 	LogTraceID    string
 	TraceSpanData []byte
 	Token         core.DelegationToken
+	Deadline      time.Time `wire:",since=2"`
+
+	// payload is the message encoding the parcel was received with.
+	payload []byte
 }
 
 // Message returns current instance's message
@@ -74,6 +80,29 @@ func (sm *Parcel) GetSender() core.RecordRef {
 	return sm.Sender
 }
 
+// GetDeadline returns time after which the message should not be handled.
+func (sm *Parcel) GetDeadline() time.Time {
+	return sm.Deadline
+}
+
+// SignedData returns data covered by parcel signature: message encoding followed by deadline.
+//
+// Received parcel returns the message encoding exactly as it was received.
+func (sm *Parcel) SignedData() ([]byte, error) {
+	payload := sm.payload
+	if payload == nil {
+		var err error
+		if payload, err = ToBytes(sm.Msg); err != nil {
+			return nil, err
+		}
+	}
+	var deadline [8]byte
+	if !sm.Deadline.IsZero() {
+		binary.BigEndian.PutUint64(deadline[:], uint64(sm.Deadline.UnixNano()))
+	}
+	return append(payload[:len(payload):len(payload)], deadline[:]...), nil
+}
+
 func (sm *Parcel) AddDelegationToken(token core.DelegationToken) {
 	sm.Token = token
 }
@@ -19,12 +19,19 @@ package message
 import (
 	"bytes"
 	"context"
+	"encoding/hex"
 	"testing"
+	"time"
 
+	"github.com/pkg/errors"
 	"github.com/stretchr/testify/require"
 
+	"github.com/insolar/insolar/configuration"
+	"github.com/insolar/insolar/core"
+	"github.com/insolar/insolar/core/wire"
 	"github.com/insolar/insolar/instrumentation/inslogger"
 	"github.com/insolar/insolar/instrumentation/instracer"
+	"github.com/insolar/insolar/version/manager"
 )
 
 func TestSerializeSigned(t *testing.T) {
@@ -36,9 +43,11 @@ func TestSerializeSigned(t *testing.T) {
 		Signature: nil,
 	}
 
-	signMsgOut, err := DeserializeParcel(bytes.NewBuffer(ParcelToBytes(signMsgIn)))
+	signMsgOut, err := DeserializeParcel(bytes.NewBuffer(parcelBytes(t, signMsgIn)))
 	require.NoError(t, err)
 
+	// Received parcel keeps the message encoding for signature check.
+	signMsgIn.payload = messageBytes(t, msg)
 	require.Equal(t, signMsgIn, signMsgOut)
 	require.Equal(t, signMsgIn.Message(), signMsgOut.Message())
 }
@@ -53,7 +62,7 @@ func TestSerializeSignedFail(t *testing.T) {
 		Signature: nil,
 	}
 
-	signMsgOut, err := Deserialize(bytes.NewBuffer(ParcelToBytes(signMsgIn)))
+	signMsgOut, err := Deserialize(bytes.NewBuffer(parcelBytes(t, signMsgIn)))
 	require.Error(t, err)
 	require.Nil(t, signMsgOut)
 }
@@ -74,7 +83,7 @@ func TestSerializeSignedWithContext(t *testing.T) {
 		LogTraceID:    inslogger.TraceID(ctxIn),
 	}
 
-	signMsgOut, err := DeserializeParcel(bytes.NewBuffer(ParcelToBytes(signMsgIn)))
+	signMsgOut, err := DeserializeParcel(bytes.NewBuffer(parcelBytes(t, signMsgIn)))
 	require.NoError(t, err)
 
 	ctxOut := signMsgOut.Context(context.Background())
@@ -82,3 +91,68 @@ func TestSerializeSignedWithContext(t *testing.T) {
 	require.Equal(t, inslogger.TraceID(ctxIn), inslogger.TraceID(ctxOut))
 	require.Equal(t, instracer.GetBaggage(ctxIn), instracer.GetBaggage(ctxOut))
 }
+
+func messageBytes(t *testing.T, msg core.Message) []byte {
+	buf, err := ToBytes(msg)
+	require.NoError(t, err)
+	return buf
+}
+
+func parcelBytes(t *testing.T, parcel core.Parcel) []byte {
+	buf, err := ParcelToBytes(parcel)
+	require.NoError(t, err)
+	return buf
+}
+
+func goldenParcel() *Parcel {
+	return &Parcel{
+		Msg:       &GetChildren{Amount: 10, Count: true},
+		Signature: []byte{1, 2},
+		Deadline:  time.Unix(1, 0).UTC(),
+	}
+}
+
+func TestSerialize_Golden(t *testing.T) {
+	require.Equal(t, "0802050114070101", hex.EncodeToString(messageBytes(t, goldenParcel().Msg)))
+	require.Equal(t, "020208080205011407010103020102070580a8d6b907", hex.EncodeToString(parcelBytes(t, goldenParcel())))
+}
+
+func TestDeserialize_GoldenWithUnknownFields(t *testing.T) {
+	// Field 15 is unknown and should be skipped.
+	data, err := hex.DecodeString("08020501140701010f01ff")
+	require.NoError(t, err)
+	parcel, err := Deserialize(bytes.NewBuffer(data))
+	require.NoError(t, err)
+	require.Equal(t, goldenParcel().Msg, parcel.Message())
+
+	data, err = hex.DecodeString("020208080205011407010103020102070580a8d6b9070f01ff")
+	require.NoError(t, err)
+	parcel, err = DeserializeParcel(bytes.NewBuffer(data))
+	require.NoError(t, err)
+	expected := goldenParcel()
+	expected.payload = messageBytes(t, expected.Msg)
+	require.Equal(t, expected, parcel)
+}
+
+func TestSerializeParcel_GatedVersion(t *testing.T) {
+	vm, err := manager.NewVersionManager(configuration.NewVersionManager())
+	require.NoError(t, err)
+	wire.SetGate(vm)
+	defer wire.SetGate(nil)
+
+	// Fields of the second versions can't be sent until the version manager enables them.
+	_, err = SerializeParcel(goldenParcel())
+	require.Equal(t, wire.ErrVersionNotAllowed, errors.Cause(err))
+	_, err = ParcelToBytes(goldenParcel())
+	require.Equal(t, wire.ErrVersionNotAllowed, errors.Cause(err))
+	_, err = goldenParcel().SignedData()
+	require.Equal(t, wire.ErrVersionNotAllowed, errors.Cause(err))
+	v1 := &Parcel{Msg: &GetChildren{Amount: 10}, Signature: []byte{1, 2}}
+	require.Equal(t, "010205080105011403020102", hex.EncodeToString(parcelBytes(t, v1)))
+
+	_, err = vm.Add("message.getchildren.v2", "v0.3.0", "")
+	require.NoError(t, err)
+	_, err = vm.Add("message.parcel.v2", "v0.3.0", "")
+	require.NoError(t, err)
+	require.Equal(t, "020208080205011407010103020102070580a8d6b907", hex.EncodeToString(parcelBytes(t, goldenParcel())))
+}
@@ -19,6 +19,7 @@ package core
 import (
 	"context"
 	"io"
+	"time"
 )
 
 // Arguments is a dedicated type for arguments, that represented as bynary cbored blob
@@ -53,6 +54,8 @@ type Parcel interface {
 	Context(context.Context) context.Context
 
 	DelegationToken() DelegationToken
+	// GetDeadline returns time after which the message should not be handled. Zero time means no deadline.
+	GetDeadline() time.Time
 }
 
 // Reply for an `Message`
@@ -61,6 +64,19 @@ type Reply interface {
 	Type() ReplyType
 }
 
+// RedirectReply is a reply that asks sender to resend the message to another node.
+//
+// MessageBus follows such replies by itself, so callers receive the reply of the final node.
+type RedirectReply interface {
+	Reply
+	// GetReceiver returns node the message should be resent to.
+	GetReceiver() *RecordRef
+	// GetToken returns delegation token that should be attached to the resent message. Can be nil.
+	GetToken() DelegationToken
+	// Redirected returns message that should be sent to the receiver instead of provided one.
+	Redirected(genericMsg Message) (Message, error)
+}
+
 // MessageBus interface
 //go:generate minimock -i github.com/insolar/insolar/core.MessageBus -o ../testutils -s _mock.go
 type MessageBus interface {
@@ -70,6 +86,12 @@ type MessageBus interface {
 	Register(p MessageType, handler MessageHandler) error
 	// MustRegister is a Register wrapper that panics if an error was returned.
 	MustRegister(p MessageType, handler MessageHandler)
+	// AddSendMiddleware appends middleware to the send chain. If types are provided, middleware is applied only to
+	// messages of these types.
+	AddSendMiddleware(middleware Middleware, types ...MessageType)
+	// AddDeliverMiddleware appends middleware to the deliver chain. If types are provided, middleware is applied only
+	// to messages of these types.
+	AddDeliverMiddleware(middleware Middleware, types ...MessageType)
 
 	// NewPlayer creates a new player from stream. This is a very long operation, as it saves replies in storage until the
 	// stream is exhausted.
@@ -114,6 +136,10 @@ func ContextWithMessageBus(ctx context.Context, bus MessageBus) context.Context
 // MessageHandler is a function for message handling. It should be registered via Register method.
 type MessageHandler func(context.Context, Parcel) (Reply, error)
 
+// Middleware wraps message handler with additional logic. Middlewares run in the order they were added. Middleware can
+// short-circuit the chain by returning a reply or an error without calling the next handler.
+type Middleware func(next MessageHandler) MessageHandler
+
 //go:generate stringer -type=MessageType
 const (
 	// Logicrunner
@@ -151,6 +177,20 @@ const (
 	TypeValidateRecord
 	// TypeSetBlob saves blob in storage.
 	TypeSetBlob
+	// TypeGetHistory retrieves object's state history.
+	TypeGetHistory
+	// TypeGetRecordProof retrieves Merkle inclusion proof of a record.
+	TypeGetRecordProof
+	// TypeGetObjectsByPrototype retrieves activated objects by prototype.
+	TypeGetObjectsByPrototype
+	// TypeGetObjects retrieves a batch of objects.
+	TypeGetObjects
+	// TypeGetObjectStatus retrieves object validation status.
+	TypeGetObjectStatus
+	// TypeGetJetTree retrieves jet tree agreed at the end of a pulse.
+	TypeGetJetTree
+	// TypeJetLoad reports load of jets in a closed pulse to jet tree authority.
+	TypeJetLoad
 
 	// Heavy replication
 
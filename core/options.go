@@ -1,8 +1,45 @@
 package core
 
+import (
+	"time"
+)
+
 type SendOptions struct {
 	Receiver *RecordRef
 	Token    DelegationToken
+	// Deadline is the time after which sender stops waiting for reply and receiver's context is cancelled.
+	// Receiver compares it with its own clock, so clock skew between nodes shifts the deadline on receiver.
+	Deadline time.Time
+	// Quorum is a rule for collecting replies from several recipients. Replies are not collected if not set.
+	Quorum Quorum
+}
+
+// Quorum is a rule defining how many recipients of a message should handle it successfully.
+type Quorum byte
+
+const (
+	// QuorumNone means message is sent by cascade without waiting for replies.
+	QuorumNone Quorum = iota
+	// QuorumAny requires at least one recipient to handle the message.
+	QuorumAny
+	// QuorumMajority requires more than half of recipients to handle the message.
+	QuorumMajority
+	// QuorumAll requires all recipients to handle the message.
+	QuorumAll
+)
+
+// Required returns number of recipients required to handle the message.
+func (q Quorum) Required(recipients int) int {
+	switch q {
+	case QuorumAny:
+		return 1
+	case QuorumMajority:
+		return recipients/2 + 1
+	case QuorumAll:
+		return recipients
+	default:
+		return 0
+	}
 }
 
 type SendOption func(*SendOptions)
@@ -17,4 +54,22 @@ func SendOptionToken(token DelegationToken) SendOption {
 	return func(args *SendOptions) {
 		args.Token = token
 	}
+}
+
+// SendOptionQuorum makes MessageBus collect replies from all recipients of the message and check them against provided
+// quorum. Replies are collected until quorum is reached or can't be reached anymore, or message deadline passes.
+func SendOptionQuorum(quorum Quorum) SendOption {
+	return func(args *SendOptions) {
+		args.Quorum = quorum
+	}
+}
+
+// SendOptionDeadline sets message deadline. If reply is not received before deadline, ErrDeadlineExceeded is returned.
+//
+// Deadline is absolute time. Node clocks are expected to be synchronized, deadlines should be set with a margin
+// larger than the expected clock skew between nodes.
+func SendOptionDeadline(deadline time.Time) SendOption {
+	return func(args *SendOptions) {
+		args.Deadline = deadline
+	}
 }
\ No newline at end of file
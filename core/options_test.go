@@ -0,0 +1,31 @@
+/*
+ *    Copyright 2018 Insolar
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ */
+
+package core
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQuorum_Required(t *testing.T) {
+	assert.Equal(t, 0, QuorumNone.Required(5))
+	assert.Equal(t, 1, QuorumAny.Required(5))
+	assert.Equal(t, 3, QuorumMajority.Required(5))
+	assert.Equal(t, 3, QuorumMajority.Required(4))
+	assert.Equal(t, 5, QuorumAll.Required(5))
+}
@@ -22,6 +22,7 @@ import (
 	"encoding/json"
 
 	"github.com/jbenet/go-base58"
+	"github.com/pkg/errors"
 )
 
 const (
@@ -49,6 +50,17 @@ func NewRecordID(pulse PulseNumber, hash []byte) *RecordID {
 	return &id
 }
 
+// NewRecordIDFromBase58 deserializes RecordID from base58 encoded string.
+func NewRecordIDFromBase58(str string) (*RecordID, error) {
+	decoded := base58.Decode(str)
+	if len(decoded) != RecordIDSize {
+		return nil, errors.New("bad record id size")
+	}
+	var id RecordID
+	copy(id[:], decoded)
+	return &id, nil
+}
+
 // Bytes returns byte slice of RecordID.
 func (id *RecordID) Bytes() []byte {
 	return id[:]
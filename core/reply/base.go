@@ -24,6 +24,7 @@ import (
 	"io/ioutil"
 
 	"github.com/insolar/insolar/core"
+	"github.com/insolar/insolar/core/wire"
 	"github.com/pkg/errors"
 )
 
@@ -56,6 +57,37 @@ const (
 	TypeID
 	// TypeChildren is a reply for fetching objects children in chunks.
 	TypeChildren
+	// TypeHistory is a reply for fetching object state history in chunks.
+	TypeHistory
+	// TypeRecordProof is a reply with Merkle inclusion proof of a record.
+	TypeRecordProof
+	// TypeGetCodeRedirect is a redirect reply for get code.
+	TypeGetCodeRedirect
+	// TypeGetChildrenRedirect is a redirect reply for get children.
+	TypeGetChildrenRedirect
+	// TypeObjectsPage is a reply with a page of objects by prototype.
+	TypeObjectsPage
+	// TypeObjects is a reply for batch objects fetching.
+	TypeObjects
+	// TypeObjectStatus is a reply with object validation status.
+	TypeObjectStatus
+
+	// Messagebus
+
+	// TypeMulti is an aggregated reply of a message sent to several nodes.
+	TypeMulti
+
+	// Jet coordinator
+
+	// TypeJetTree is a reply with encoded jet tree.
+	TypeJetTree
+
+	// Ledger redirects
+
+	// TypeGetHistoryRedirect is a redirect reply for get history.
+	TypeGetHistoryRedirect
+	// TypeGetRecordProofRedirect is a redirect reply for get record proof.
+	TypeGetRecordProofRedirect
 )
 
 // ErrType is used to determine and compare reply errors.
@@ -65,6 +97,12 @@ const (
 	// ErrDeactivated returned when requested object is deactivated.
 	ErrDeactivated = iota + 1
 	ErrStateNotAvailable
+	// ErrHeavySyncInProgress returned when heavy sync range is locked by another node.
+	ErrHeavySyncInProgress
+	// ErrStateRejected returned when new object state is based on the state rejected by validators.
+	ErrStateRejected
+	// ErrObjectNotFound returned when requested object is not found.
+	ErrObjectNotFound
 )
 
 func getEmptyReply(t core.ReplyType) (core.Reply, error) {
@@ -83,6 +121,30 @@ func getEmptyReply(t core.ReplyType) (core.Reply, error) {
 		return &ID{}, nil
 	case TypeChildren:
 		return &Children{}, nil
+	case TypeHistory:
+		return &History{}, nil
+	case TypeRecordProof:
+		return &RecordProof{}, nil
+	case TypeGetObjectRedirect:
+		return &GetObjectRedirectReply{}, nil
+	case TypeGetCodeRedirect:
+		return &GetCodeRedirect{}, nil
+	case TypeGetChildrenRedirect:
+		return &GetChildrenRedirect{}, nil
+	case TypeGetHistoryRedirect:
+		return &GetHistoryRedirect{}, nil
+	case TypeGetRecordProofRedirect:
+		return &GetRecordProofRedirect{}, nil
+	case TypeObjectsPage:
+		return &ObjectsPage{}, nil
+	case TypeObjects:
+		return &Objects{}, nil
+	case TypeObjectStatus:
+		return &ObjectStatus{}, nil
+	case TypeJetTree:
+		return &JetTree{}, nil
+	case TypeMulti:
+		return &Multi{}, nil
 	case TypeError:
 		return &Error{}, nil
 	case TypeOK:
@@ -93,23 +155,26 @@ func getEmptyReply(t core.ReplyType) (core.Reply, error) {
 }
 
 // Serialize returns encoded reply.
+//
+// Reply is encoded as its type followed by wire encoding of the reply.
 func Serialize(reply core.Reply) (io.Reader, error) {
-	buff := &bytes.Buffer{}
-	_, err := buff.Write([]byte{byte(reply.Type())})
+	encoded, err := wire.Marshal(reply)
 	if err != nil {
 		return nil, err
 	}
-
-	enc := gob.NewEncoder(buff)
-	err = enc.Encode(reply)
-	return buff, err
+	buff := bytes.NewBuffer(make([]byte, 0, len(encoded)+1))
+	buff.WriteByte(byte(reply.Type()))
+	buff.Write(encoded)
+	return buff, nil
 }
 
 // Deserialize returns decoded reply.
 func Deserialize(buff io.Reader) (core.Reply, error) {
-	b := make([]byte, 1)
-	_, err := buff.Read(b)
+	b, err := ioutil.ReadAll(buff)
 	if err != nil {
+		return nil, err
+	}
+	if len(b) < 1 {
 		return nil, errors.New("too short input to deserialize a message reply")
 	}
 
@@ -117,25 +182,29 @@ func Deserialize(buff io.Reader) (core.Reply, error) {
 	if err != nil {
 		return nil, err
 	}
-	enc := gob.NewDecoder(buff)
-	err = enc.Decode(reply)
+	err = wire.Unmarshal(b[1:], reply)
 	return reply, err
 }
 
-// ToBytes deserializes reply to bytes.
-func ToBytes(rep core.Reply) []byte {
+// ToBytes serializes reply to bytes.
+func ToBytes(rep core.Reply) ([]byte, error) {
 	repBuff, err := Serialize(rep)
 	if err != nil {
-		panic("failed to serialize reply")
+		return nil, errors.Wrap(err, "failed to serialize reply")
 	}
-	buff, err := ioutil.ReadAll(repBuff)
-	if err != nil {
-		panic("failed to serialize reply")
-	}
-	return buff
+	return ioutil.ReadAll(repBuff)
 }
 
 func init() {
+	wire.RegisterInterface((*core.Reply)(nil), wire.Implementations{
+		TypeOf: func(v interface{}) byte {
+			return byte(v.(core.Reply).Type())
+		},
+		New: func(t byte) (interface{}, error) {
+			return getEmptyReply(core.ReplyType(t))
+		},
+	})
+
 	gob.Register(&CallMethod{})
 	gob.Register(&CallConstructor{})
 	gob.Register(&Code{})
@@ -143,6 +212,17 @@ func init() {
 	gob.Register(&Delegate{})
 	gob.Register(&ID{})
 	gob.Register(&Children{})
+	gob.Register(&History{})
+	gob.Register(&RecordProof{})
+	gob.Register(&GetObjectRedirectReply{})
+	gob.Register(&GetCodeRedirect{})
+	gob.Register(&GetChildrenRedirect{})
+	gob.Register(&GetHistoryRedirect{})
+	gob.Register(&GetRecordProofRedirect{})
+	gob.Register(&ObjectsPage{})
+	gob.Register(&Objects{})
+	gob.Register(&ObjectStatus{})
+	gob.Register(&Multi{})
 	gob.Register(&Error{})
 	gob.Register(&OK{})
 }
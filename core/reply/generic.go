@@ -44,6 +44,61 @@ func (e *Error) Error() error {
 		return core.ErrDeactivated
 	case ErrStateNotAvailable:
 		return core.ErrStateNotAvailable
+	case ErrHeavySyncInProgress:
+		return core.ErrHeavySyncInProgress
+	case ErrStateRejected:
+		return core.ErrStateRejected
+	case ErrObjectNotFound:
+		return core.ErrObjectNotFound
 	}
 	return core.ErrUnknown
-}
\ No newline at end of file
+}
+
+// NodeReply is a reply received from one of message recipients.
+type NodeReply struct {
+	Node  core.RecordRef
+	Reply core.Reply
+	// Error is a delivery or handling error. Empty if node replied successfully.
+	Error string
+}
+
+// Succeeded returns true if node handled the message without error.
+func (r *NodeReply) Succeeded() bool {
+	if r.Error != "" || r.Reply == nil {
+		return false
+	}
+	_, isErr := r.Reply.(*Error)
+	return !isErr
+}
+
+// Multi is an aggregated reply of a message sent to several nodes. Replies are in the order of recipients.
+type Multi struct {
+	Replies []NodeReply
+}
+
+// Type implementation of Reply interface.
+func (e *Multi) Type() core.ReplyType {
+	return TypeMulti
+}
+
+// Succeeded returns nodes that handled the message without error.
+func (e *Multi) Succeeded() []core.RecordRef {
+	var nodes []core.RecordRef
+	for i := range e.Replies {
+		if e.Replies[i].Succeeded() {
+			nodes = append(nodes, e.Replies[i].Node)
+		}
+	}
+	return nodes
+}
+
+// Failed returns nodes that failed to handle the message or did not reply in time.
+func (e *Multi) Failed() []core.RecordRef {
+	var nodes []core.RecordRef
+	for i := range e.Replies {
+		if !e.Replies[i].Succeeded() {
+			nodes = append(nodes, e.Replies[i].Node)
+		}
+	}
+	return nodes
+}
@@ -71,9 +71,74 @@ func (e *ID) Type() core.ReplyType {
 type Children struct {
 	Refs     []core.RecordRef
 	NextFrom *core.RecordID
+	// Count is the number of matching children if it was requested.
+	Count int `wire:",since=2"`
 }
 
 // Type implementation of Reply interface.
 func (e *Children) Type() core.ReplyType {
 	return TypeChildren
 }
+
+// History is a chunk of object state history.
+type History struct {
+	States   []core.ObjectHistoryEntry
+	NextFrom *core.RecordID
+}
+
+// Type implementation of Reply interface.
+func (e *History) Type() core.ReplyType {
+	return TypeHistory
+}
+
+// RecordProof is Merkle inclusion proof of a record.
+type RecordProof struct {
+	Proof core.RecordProof
+}
+
+// Type implementation of Reply interface.
+func (e *RecordProof) Type() core.ReplyType {
+	return TypeRecordProof
+}
+
+// ObjectsPage is a page of objects by prototype.
+type ObjectsPage struct {
+	Page core.ObjectsPage
+}
+
+// Type implementation of Reply interface.
+func (e *ObjectsPage) Type() core.ReplyType {
+	return TypeObjectsPage
+}
+
+// Objects is a reply for batch objects fetching.
+//
+// Every item is a reply for the object with the same index in request: Object, Error or GetObjectRedirectReply.
+type Objects struct {
+	Objects []core.Reply
+}
+
+// Type implementation of Reply interface.
+func (e *Objects) Type() core.ReplyType {
+	return TypeObjects
+}
+
+// ObjectStatus is a reply with object validation status.
+type ObjectStatus struct {
+	Status core.ObjectStatus
+}
+
+// Type implementation of Reply interface.
+func (e *ObjectStatus) Type() core.ReplyType {
+	return TypeObjectStatus
+}
+
+// JetTree is a reply with encoded jet tree.
+type JetTree struct {
+	Tree []byte
+}
+
+// Type implementation of Reply interface.
+func (e *JetTree) Type() core.ReplyType {
+	return TypeJetTree
+}
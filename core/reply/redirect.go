@@ -3,6 +3,7 @@ package reply
 import (
 	"github.com/insolar/insolar/core"
 	"github.com/insolar/insolar/core/message"
+	"github.com/pkg/errors"
 )
 
 // GetObjectRedirectReply is a redirect-reply for get object
@@ -33,6 +34,212 @@ func (r *GetObjectRedirectReply) RecreateMessage(msg *message.GetObject) *messag
 	return &message.GetObject{
 		State:    r.StateID,
 		Head:     msg.Head,
+		Pulse:    msg.Pulse,
 		Approved: msg.Approved,
 	}
 }
+
+// GetReceiver returns node reference to send message to
+func (r *GetObjectRedirectReply) GetReceiver() *core.RecordRef {
+	return r.To
+}
+
+// GetToken returns delegation token
+func (r *GetObjectRedirectReply) GetToken() core.DelegationToken {
+	return r.Token
+}
+
+// Redirected recreates provided message for redirect destination
+func (r *GetObjectRedirectReply) Redirected(genericMsg core.Message) (core.Message, error) {
+	msg, ok := genericMsg.(*message.GetObject)
+	if !ok {
+		return nil, errUnexpectedRedirect(r, genericMsg)
+	}
+	return r.RecreateMessage(msg), nil
+}
+
+// GetCodeRedirect is a redirect-reply for get code
+type GetCodeRedirect struct {
+	To *core.RecordRef
+}
+
+// NewGetCodeRedirect return new GetCodeRedirect
+func NewGetCodeRedirect(to *core.RecordRef) *GetCodeRedirect {
+	return &GetCodeRedirect{
+		To: to,
+	}
+}
+
+// Type returns type of the reply
+func (r *GetCodeRedirect) Type() core.ReplyType {
+	return TypeGetCodeRedirect
+}
+
+// RecreateMessage recreates the message for redirect destination
+func (r *GetCodeRedirect) RecreateMessage(msg *message.GetCode) *message.GetCode {
+	return &message.GetCode{
+		Code: msg.Code,
+	}
+}
+
+// GetReceiver returns node reference to send message to
+func (r *GetCodeRedirect) GetReceiver() *core.RecordRef {
+	return r.To
+}
+
+// GetToken returns delegation token
+func (r *GetCodeRedirect) GetToken() core.DelegationToken {
+	return nil
+}
+
+// Redirected recreates provided message for redirect destination
+func (r *GetCodeRedirect) Redirected(genericMsg core.Message) (core.Message, error) {
+	msg, ok := genericMsg.(*message.GetCode)
+	if !ok {
+		return nil, errUnexpectedRedirect(r, genericMsg)
+	}
+	return r.RecreateMessage(msg), nil
+}
+
+// GetChildrenRedirect is a redirect-reply for get children
+type GetChildrenRedirect struct {
+	To *core.RecordRef
+}
+
+// NewGetChildrenRedirect return new GetChildrenRedirect
+func NewGetChildrenRedirect(to *core.RecordRef) *GetChildrenRedirect {
+	return &GetChildrenRedirect{
+		To: to,
+	}
+}
+
+// Type returns type of the reply
+func (r *GetChildrenRedirect) Type() core.ReplyType {
+	return TypeGetChildrenRedirect
+}
+
+// RecreateMessage recreates the message for redirect destination
+func (r *GetChildrenRedirect) RecreateMessage(msg *message.GetChildren) *message.GetChildren {
+	return &message.GetChildren{
+		Parent:    msg.Parent,
+		FromChild: msg.FromChild,
+		FromPulse: msg.FromPulse,
+		Amount:    msg.Amount,
+		Prototype: msg.Prototype,
+		Count:     msg.Count,
+	}
+}
+
+// GetReceiver returns node reference to send message to
+func (r *GetChildrenRedirect) GetReceiver() *core.RecordRef {
+	return r.To
+}
+
+// GetToken returns delegation token
+func (r *GetChildrenRedirect) GetToken() core.DelegationToken {
+	return nil
+}
+
+// Redirected recreates provided message for redirect destination
+func (r *GetChildrenRedirect) Redirected(genericMsg core.Message) (core.Message, error) {
+	msg, ok := genericMsg.(*message.GetChildren)
+	if !ok {
+		return nil, errUnexpectedRedirect(r, genericMsg)
+	}
+	return r.RecreateMessage(msg), nil
+}
+
+// GetHistoryRedirect is a redirect-reply for get history
+type GetHistoryRedirect struct {
+	To        *core.RecordRef
+	FromState *core.RecordID
+}
+
+// NewGetHistoryRedirect return new GetHistoryRedirect
+func NewGetHistoryRedirect(to *core.RecordRef, fromState *core.RecordID) *GetHistoryRedirect {
+	return &GetHistoryRedirect{
+		To:        to,
+		FromState: fromState,
+	}
+}
+
+// Type returns type of the reply
+func (r *GetHistoryRedirect) Type() core.ReplyType {
+	return TypeGetHistoryRedirect
+}
+
+// RecreateMessage recreates the message for redirect destination
+func (r *GetHistoryRedirect) RecreateMessage(msg *message.GetHistory) *message.GetHistory {
+	return &message.GetHistory{
+		Object:    msg.Object,
+		FromState: r.FromState,
+		FromPulse: msg.FromPulse,
+		Amount:    msg.Amount,
+	}
+}
+
+// GetReceiver returns node reference to send message to
+func (r *GetHistoryRedirect) GetReceiver() *core.RecordRef {
+	return r.To
+}
+
+// GetToken returns delegation token
+func (r *GetHistoryRedirect) GetToken() core.DelegationToken {
+	return nil
+}
+
+// Redirected recreates provided message for redirect destination
+func (r *GetHistoryRedirect) Redirected(genericMsg core.Message) (core.Message, error) {
+	msg, ok := genericMsg.(*message.GetHistory)
+	if !ok {
+		return nil, errUnexpectedRedirect(r, genericMsg)
+	}
+	return r.RecreateMessage(msg), nil
+}
+
+// GetRecordProofRedirect is a redirect-reply for get record proof
+type GetRecordProofRedirect struct {
+	To *core.RecordRef
+}
+
+// NewGetRecordProofRedirect return new GetRecordProofRedirect
+func NewGetRecordProofRedirect(to *core.RecordRef) *GetRecordProofRedirect {
+	return &GetRecordProofRedirect{
+		To: to,
+	}
+}
+
+// Type returns type of the reply
+func (r *GetRecordProofRedirect) Type() core.ReplyType {
+	return TypeGetRecordProofRedirect
+}
+
+// RecreateMessage recreates the message for redirect destination
+func (r *GetRecordProofRedirect) RecreateMessage(msg *message.GetRecordProof) *message.GetRecordProof {
+	return &message.GetRecordProof{
+		Record: msg.Record,
+	}
+}
+
+// GetReceiver returns node reference to send message to
+func (r *GetRecordProofRedirect) GetReceiver() *core.RecordRef {
+	return r.To
+}
+
+// GetToken returns delegation token
+func (r *GetRecordProofRedirect) GetToken() core.DelegationToken {
+	return nil
+}
+
+// Redirected recreates provided message for redirect destination
+func (r *GetRecordProofRedirect) Redirected(genericMsg core.Message) (core.Message, error) {
+	msg, ok := genericMsg.(*message.GetRecordProof)
+	if !ok {
+		return nil, errUnexpectedRedirect(r, genericMsg)
+	}
+	return r.RecreateMessage(msg), nil
+}
+
+func errUnexpectedRedirect(rep core.Reply, msg core.Message) error {
+	return errors.Errorf("can't redirect message of type %s with reply of type %d", msg.Type(), rep.Type())
+}
@@ -0,0 +1,40 @@
+/*
+ *    Copyright 2018 Insolar
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ */
+
+package reply
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSerialize_Golden(t *testing.T) {
+	rep := &Multi{Replies: []NodeReply{
+		{Reply: &Children{Count: 3}},
+		{Error: "timeout"},
+	}}
+	buff, err := Serialize(rep)
+	require.NoError(t, err)
+	data := buff.(*bytes.Buffer).Bytes()
+	require.Equal(t, "120101120802050a020301060a030774696d656f7574", hex.EncodeToString(data))
+
+	decoded, err := Deserialize(bytes.NewBuffer(data))
+	require.NoError(t, err)
+	require.Equal(t, rep, decoded)
+}
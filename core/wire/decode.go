@@ -0,0 +1,293 @@
+/*
+ *    Copyright 2018 Insolar
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ */
+
+package wire
+
+import (
+	"encoding/binary"
+	"math"
+	"reflect"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+func readUvarint(data []byte) (uint64, []byte, error) {
+	x, n := binary.Uvarint(data)
+	if n <= 0 {
+		return 0, nil, ErrTruncated
+	}
+	return x, data[n:], nil
+}
+
+// readPayload reads length prefixed payload.
+func readPayload(data []byte) ([]byte, []byte, error) {
+	l, data, err := readUvarint(data)
+	if err != nil {
+		return nil, nil, err
+	}
+	if uint64(len(data)) < l {
+		return nil, nil, ErrTruncated
+	}
+	return data[:l], data[l:], nil
+}
+
+// readElem reads element of slice, array or map. Returns nil payload for nil element and non-nil payload otherwise.
+func readElem(data []byte) ([]byte, []byte, error) {
+	l, data, err := readUvarint(data)
+	if err != nil {
+		return nil, nil, err
+	}
+	if l == 0 {
+		return nil, data, nil
+	}
+	if uint64(len(data)) < l-1 {
+		return nil, nil, ErrTruncated
+	}
+	return data[:l-1], data[l-1:], nil
+}
+
+func decodeVersioned(data []byte, v reflect.Value) error {
+	s, err := schemaOf(v.Type())
+	if err != nil {
+		return err
+	}
+	// Fields of newer versions are skipped as unknown, so the version is only checked for presence.
+	_, data, err = readUvarint(data)
+	if err != nil {
+		return err
+	}
+	return decodeStruct(data, v, s)
+}
+
+// readField returns payload of the field with provided number from versioned struct encoding.
+func readField(data []byte, num uint64) ([]byte, error) {
+	_, data, err := readUvarint(data)
+	if err != nil {
+		return nil, err
+	}
+	for len(data) > 0 {
+		n, rest, err := readUvarint(data)
+		if err != nil {
+			return nil, err
+		}
+		payload, rest, err := readPayload(rest)
+		if err != nil {
+			return nil, err
+		}
+		if n == num {
+			return payload, nil
+		}
+		data = rest
+	}
+	return nil, nil
+}
+
+func decodeStruct(data []byte, v reflect.Value, s *schema) error {
+	for len(data) > 0 {
+		num, rest, err := readUvarint(data)
+		if err != nil {
+			return err
+		}
+		payload, rest, err := readPayload(rest)
+		if err != nil {
+			return err
+		}
+		data = rest
+
+		i, ok := s.byNum[num]
+		if !ok {
+			continue
+		}
+		f := s.fields[i]
+		if err := decodeValue(payload, v.Field(f.index)); err != nil {
+			return errors.Wrapf(err, "%s.%s", v.Type(), v.Type().Field(f.index).Name)
+		}
+	}
+	return nil
+}
+
+func decodeValue(data []byte, v reflect.Value) error {
+	switch v.Kind() {
+	case reflect.Bool:
+		if len(data) != 1 {
+			return errors.New("wire: invalid bool")
+		}
+		v.SetBool(data[0] != 0)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		x, n := binary.Varint(data)
+		if n != len(data) || v.OverflowInt(x) {
+			return errors.Errorf("wire: invalid %s", v.Type())
+		}
+		v.SetInt(x)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		x, n := binary.Uvarint(data)
+		if n != len(data) || v.OverflowUint(x) {
+			return errors.Errorf("wire: invalid %s", v.Type())
+		}
+		v.SetUint(x)
+	case reflect.Float32, reflect.Float64:
+		if len(data) != 8 {
+			return errors.Errorf("wire: invalid %s", v.Type())
+		}
+		v.SetFloat(math.Float64frombits(binary.LittleEndian.Uint64(data)))
+	case reflect.String:
+		v.SetString(string(data))
+	case reflect.Slice:
+		return decodeSlice(data, v)
+	case reflect.Array:
+		return decodeArray(data, v)
+	case reflect.Map:
+		return decodeMap(data, v)
+	case reflect.Struct:
+		if v.Type() == timeType {
+			x, n := binary.Varint(data)
+			if n != len(data) {
+				return errors.New("wire: invalid time")
+			}
+			v.Set(reflect.ValueOf(time.Unix(0, x).UTC()))
+			return nil
+		}
+		s, err := schemaOf(v.Type())
+		if err != nil {
+			return err
+		}
+		return decodeStruct(data, v, s)
+	case reflect.Ptr:
+		elem := reflect.New(v.Type().Elem())
+		if err := decodeValue(data, elem.Elem()); err != nil {
+			return err
+		}
+		v.Set(elem)
+	case reflect.Interface:
+		return decodeInterface(data, v)
+	default:
+		return errors.Errorf("wire: unsupported type %s", v.Type())
+	}
+	return nil
+}
+
+func decodeSlice(data []byte, v reflect.Value) error {
+	t := v.Type()
+	if t.Elem().Kind() == reflect.Uint8 {
+		b := reflect.MakeSlice(t, len(data), len(data))
+		reflect.Copy(b, reflect.ValueOf(data))
+		v.Set(b)
+		return nil
+	}
+
+	slice := reflect.MakeSlice(t, 0, 0)
+	for len(data) > 0 {
+		payload, rest, err := readElem(data)
+		if err != nil {
+			return err
+		}
+		data = rest
+		elem := reflect.New(t.Elem()).Elem()
+		if payload != nil {
+			if err := decodeValue(payload, elem); err != nil {
+				return err
+			}
+		}
+		slice = reflect.Append(slice, elem)
+	}
+	v.Set(slice)
+	return nil
+}
+
+func decodeArray(data []byte, v reflect.Value) error {
+	if v.Type().Elem().Kind() == reflect.Uint8 {
+		if len(data) != v.Len() {
+			return errors.Errorf("wire: invalid %s length %d", v.Type(), len(data))
+		}
+		for i, b := range data {
+			v.Index(i).SetUint(uint64(b))
+		}
+		return nil
+	}
+
+	for i := 0; len(data) > 0; i++ {
+		if i >= v.Len() {
+			return errors.Errorf("wire: too many elements for %s", v.Type())
+		}
+		payload, rest, err := readElem(data)
+		if err != nil {
+			return err
+		}
+		data = rest
+		if payload != nil {
+			if err := decodeValue(payload, v.Index(i)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func decodeMap(data []byte, v reflect.Value) error {
+	t := v.Type()
+	m := reflect.MakeMap(t)
+	for len(data) > 0 {
+		keyData, rest, err := readElem(data)
+		if err != nil {
+			return err
+		}
+		valueData, rest, err := readElem(rest)
+		if err != nil {
+			return err
+		}
+		data = rest
+
+		key := reflect.New(t.Key()).Elem()
+		if keyData != nil {
+			if err := decodeValue(keyData, key); err != nil {
+				return err
+			}
+		}
+		value := reflect.New(t.Elem()).Elem()
+		if valueData != nil {
+			if err := decodeValue(valueData, value); err != nil {
+				return err
+			}
+		}
+		m.SetMapIndex(key, value)
+	}
+	v.Set(m)
+	return nil
+}
+
+func decodeInterface(data []byte, v reflect.Value) error {
+	impl, ok := implementations(v.Type())
+	if !ok {
+		return errors.Errorf("wire: interface %s is not registered", v.Type())
+	}
+	if len(data) == 0 {
+		return ErrTruncated
+	}
+	value, err := impl.New(data[0])
+	if err != nil {
+		return err
+	}
+	rv := reflect.ValueOf(value)
+	if rv.Kind() != reflect.Ptr || rv.Elem().Kind() != reflect.Struct || !rv.Type().Implements(v.Type()) {
+		return errors.Errorf("wire: %T is not a struct pointer implementing %s", value, v.Type())
+	}
+	if err := decodeVersioned(data[1:], rv.Elem()); err != nil {
+		return err
+	}
+	v.Set(rv)
+	return nil
+}
@@ -0,0 +1,225 @@
+/*
+ *    Copyright 2018 Insolar
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ */
+
+package wire
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"reflect"
+	"sort"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+func appendUvarint(buf []byte, x uint64) []byte {
+	var tmp [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(tmp[:], x)
+	return append(buf, tmp[:n]...)
+}
+
+func appendVarint(buf []byte, x int64) []byte {
+	var tmp [binary.MaxVarintLen64]byte
+	n := binary.PutVarint(tmp[:], x)
+	return append(buf, tmp[:n]...)
+}
+
+// appendVersioned appends schema version allowed by the gate and struct fields of this version.
+func appendVersioned(buf []byte, v reflect.Value) ([]byte, error) {
+	s, err := schemaOf(v.Type())
+	if err != nil {
+		return nil, err
+	}
+	version := allowedVersion(v.Type(), s.version)
+	buf = appendUvarint(buf, version)
+	return appendStruct(buf, v, s, version)
+}
+
+// appendStruct appends struct fields of provided schema version. Fields of newer versions must be empty, they are not
+// silently dropped.
+func appendStruct(buf []byte, v reflect.Value, s *schema, version uint64) ([]byte, error) {
+	for _, f := range s.fields {
+		fv := v.Field(f.index)
+		if isZero(fv) {
+			continue
+		}
+		if f.since > version {
+			return nil, errors.Wrapf(
+				ErrVersionNotAllowed, "%s.%s requires %s", v.Type(), v.Type().Field(f.index).Name,
+				versionKey(v.Type(), f.since),
+			)
+		}
+		payload, err := appendValue(nil, fv)
+		if err != nil {
+			return nil, errors.Wrapf(err, "%s.%s", v.Type(), v.Type().Field(f.index).Name)
+		}
+		buf = appendUvarint(buf, f.num)
+		buf = appendUvarint(buf, uint64(len(payload)))
+		buf = append(buf, payload...)
+	}
+	return buf, nil
+}
+
+// appendElem appends element of slice, array or map prefixed with its length plus one. Zero prefix marks nil element.
+func appendElem(buf []byte, v reflect.Value) ([]byte, error) {
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if v.IsNil() {
+			return append(buf, 0), nil
+		}
+	}
+	payload, err := appendValue(nil, v)
+	if err != nil {
+		return nil, err
+	}
+	buf = appendUvarint(buf, uint64(len(payload))+1)
+	return append(buf, payload...), nil
+}
+
+func appendValue(buf []byte, v reflect.Value) ([]byte, error) {
+	switch v.Kind() {
+	case reflect.Bool:
+		if v.Bool() {
+			return append(buf, 1), nil
+		}
+		return append(buf, 0), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return appendVarint(buf, v.Int()), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return appendUvarint(buf, v.Uint()), nil
+	case reflect.Float32, reflect.Float64:
+		var tmp [8]byte
+		binary.LittleEndian.PutUint64(tmp[:], math.Float64bits(v.Float()))
+		return append(buf, tmp[:]...), nil
+	case reflect.String:
+		return append(buf, v.String()...), nil
+	case reflect.Slice, reflect.Array:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			for i := 0; i < v.Len(); i++ {
+				buf = append(buf, byte(v.Index(i).Uint()))
+			}
+			return buf, nil
+		}
+		var err error
+		for i := 0; i < v.Len(); i++ {
+			buf, err = appendElem(buf, v.Index(i))
+			if err != nil {
+				return nil, err
+			}
+		}
+		return buf, nil
+	case reflect.Map:
+		return appendMap(buf, v)
+	case reflect.Struct:
+		if v.Type() == timeType {
+			return appendVarint(buf, v.Interface().(time.Time).UnixNano()), nil
+		}
+		// Nested struct has its own schema version.
+		s, err := schemaOf(v.Type())
+		if err != nil {
+			return nil, err
+		}
+		return appendStruct(buf, v, s, allowedVersion(v.Type(), s.version))
+	case reflect.Ptr:
+		return appendValue(buf, v.Elem())
+	case reflect.Interface:
+		return appendInterface(buf, v)
+	default:
+		return nil, errors.Errorf("wire: unsupported type %s", v.Type())
+	}
+}
+
+// appendMap appends map entries sorted by encoded keys, so the encoding is deterministic.
+func appendMap(buf []byte, v reflect.Value) ([]byte, error) {
+	type entry struct {
+		key, value []byte
+	}
+	entries := make([]entry, 0, v.Len())
+	for _, key := range v.MapKeys() {
+		k, err := appendElem(nil, key)
+		if err != nil {
+			return nil, err
+		}
+		val, err := appendElem(nil, v.MapIndex(key))
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry{key: k, value: val})
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return bytes.Compare(entries[i].key, entries[j].key) < 0
+	})
+	for _, e := range entries {
+		buf = append(buf, e.key...)
+		buf = append(buf, e.value...)
+	}
+	return buf, nil
+}
+
+// appendInterface appends implementation type byte and versioned value for registered interfaces.
+func appendInterface(buf []byte, v reflect.Value) ([]byte, error) {
+	impl, ok := implementations(v.Type())
+	if !ok {
+		return nil, errors.Errorf("wire: interface %s is not registered", v.Type())
+	}
+	concrete := v.Elem()
+	if concrete.Kind() != reflect.Ptr || concrete.Elem().Kind() != reflect.Struct {
+		return nil, errors.Errorf("wire: %s implementation %s is not a struct pointer", v.Type(), concrete.Type())
+	}
+	buf = append(buf, impl.TypeOf(v.Interface()))
+	return appendVersioned(buf, concrete.Elem())
+}
+
+// isZero returns true if value is omitted from encoding.
+func isZero(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Bool:
+		return !v.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int() == 0
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return v.Uint() == 0
+	case reflect.Float32, reflect.Float64:
+		return math.Float64bits(v.Float()) == 0
+	case reflect.String:
+		return v.Len() == 0
+	case reflect.Slice, reflect.Map:
+		return v.Len() == 0
+	case reflect.Ptr, reflect.Interface:
+		return v.IsNil()
+	case reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			if !isZero(v.Index(i)) {
+				return false
+			}
+		}
+		return true
+	case reflect.Struct:
+		if v.Type() == timeType {
+			return v.Interface().(time.Time).IsZero()
+		}
+		for i := 0; i < v.NumField(); i++ {
+			if v.Type().Field(i).PkgPath == "" && !isZero(v.Field(i)) {
+				return false
+			}
+		}
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,104 @@
+/*
+ *    Copyright 2018 Insolar
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ */
+
+package wire
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+var timeType = reflect.TypeOf(time.Time{})
+
+type field struct {
+	num   uint64
+	index int
+	since uint64
+}
+
+type schema struct {
+	fields  []field
+	byNum   map[uint64]int
+	version uint64
+}
+
+var schemas sync.Map
+
+// schemaOf returns cached schema of struct type.
+func schemaOf(t reflect.Type) (*schema, error) {
+	if s, ok := schemas.Load(t); ok {
+		return s.(*schema), nil
+	}
+	s, err := buildSchema(t)
+	if err != nil {
+		return nil, err
+	}
+	schemas.Store(t, s)
+	return s, nil
+}
+
+func buildSchema(t reflect.Type) (*schema, error) {
+	s := &schema{byNum: map[uint64]int{}, version: 1}
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		f := field{num: uint64(i + 1), index: i, since: 1}
+		tag := sf.Tag.Get("wire")
+		if tag == "-" {
+			continue
+		}
+		opts := strings.Split(tag, ",")
+		if opts[0] != "" {
+			num, err := strconv.ParseUint(opts[0], 10, 64)
+			if err != nil || num == 0 {
+				return nil, errors.Errorf("wire: invalid field number in %s.%s", t, sf.Name)
+			}
+			f.num = num
+		}
+		for _, opt := range opts[1:] {
+			if !strings.HasPrefix(opt, "since=") {
+				return nil, errors.Errorf("wire: unknown option %q in %s.%s", opt, t, sf.Name)
+			}
+			since, err := strconv.ParseUint(strings.TrimPrefix(opt, "since="), 10, 64)
+			if err != nil || since == 0 {
+				return nil, errors.Errorf("wire: invalid version in %s.%s", t, sf.Name)
+			}
+			f.since = since
+		}
+		// Unexported fields can't be set, but they keep field numbers of the following fields.
+		if sf.PkgPath != "" {
+			continue
+		}
+		if _, ok := s.byNum[f.num]; ok {
+			return nil, errors.Errorf("wire: duplicate field number %d in %s", f.num, t)
+		}
+		s.byNum[f.num] = len(s.fields)
+		s.fields = append(s.fields, f)
+		s.version = maxVersion(s.version, f.since)
+	}
+	return s, nil
+}
+
+func maxVersion(a, b uint64) uint64 {
+	if a > b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,160 @@
+/*
+ *    Copyright 2018 Insolar
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ */
+
+// Package wire is a versioned binary codec for messages, replies and parcels.
+//
+// Encoded value starts with schema version of its type followed by fields. Every field is encoded as its number, payload
+// length and payload, so decoders skip fields they don't know and networks with different node versions keep working.
+// Fields with zero values are omitted.
+//
+// Fields are numbered in the order of declaration starting from one. Number can be set explicitly with `wire:"N"` tag,
+// `wire:"-"` excludes the field. New fields should be appended to the end of the struct with `wire:",since=N"` tag,
+// where N is the new schema version of the type. Schema version of a type is the maximum version of its own fields,
+// nested structs and interface values have their own versions.
+//
+// Fields of a new schema version can be set only if Gate allows the version, otherwise encoding fails with
+// ErrVersionNotAllowed. It allows new fields to be enabled after all nodes of the network are able to read them.
+// Version manager can be used as a gate, the feature key is the lowercase type name with the version, e.g.
+// "message.getchildren.v2".
+//
+// Empty interfaces are not supported, interface types should be registered with RegisterInterface.
+package wire
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+var (
+	// ErrTruncated is returned when encoded data ends unexpectedly.
+	ErrTruncated = errors.New("wire: truncated data")
+	// ErrVersionNotAllowed is returned when a field of schema version not allowed by the gate is set.
+	ErrVersionNotAllowed = errors.New("wire: schema version is not allowed")
+)
+
+// Gate decides if schema version can be used for encoding. Feature key is provided in "package.type.vN" form.
+type Gate interface {
+	IsAvailable(key string) bool
+}
+
+var (
+	gateLock sync.RWMutex
+	gate     Gate
+)
+
+// SetGate sets gate for new schema versions. If gate is nil, the latest versions are used.
+func SetGate(g Gate) {
+	gateLock.Lock()
+	gate = g
+	gateLock.Unlock()
+}
+
+// versionKey returns gate feature key for schema version of provided type.
+func versionKey(t reflect.Type, version uint64) string {
+	return fmt.Sprintf("%s.v%d", strings.ToLower(t.String()), version)
+}
+
+// allowedVersion returns the latest schema version of provided type allowed by the gate.
+func allowedVersion(t reflect.Type, latest uint64) uint64 {
+	gateLock.RLock()
+	g := gate
+	gateLock.RUnlock()
+	if g == nil {
+		return latest
+	}
+	version := latest
+	for version > 1 && !g.IsAvailable(versionKey(t, version)) {
+		version--
+	}
+	return version
+}
+
+// Implementations describes how values of an interface type are encoded. Implementation type is encoded as one byte.
+type Implementations struct {
+	// TypeOf returns type byte of the value.
+	TypeOf func(v interface{}) byte
+	// New returns pointer to the new empty value of provided type.
+	New func(t byte) (interface{}, error)
+}
+
+var (
+	registryLock sync.RWMutex
+	registry     = map[reflect.Type]Implementations{}
+)
+
+// RegisterInterface registers implementations of interface type. Fields of registered interface types are encoded as
+// implementation type byte followed by the encoded value.
+//
+// ifacePtr is a nil pointer to the interface, e.g. (*core.Message)(nil).
+func RegisterInterface(ifacePtr interface{}, impl Implementations) {
+	registryLock.Lock()
+	registry[reflect.TypeOf(ifacePtr).Elem()] = impl
+	registryLock.Unlock()
+}
+
+func implementations(t reflect.Type) (Implementations, bool) {
+	registryLock.RLock()
+	impl, ok := registry[t]
+	registryLock.RUnlock()
+	return impl, ok
+}
+
+// Marshal returns encoding of the struct pointed by v.
+func Marshal(v interface{}) ([]byte, error) {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return nil, errors.Errorf("wire: can't marshal %T, struct pointer expected", v)
+	}
+	return appendVersioned(nil, rv.Elem())
+}
+
+// Unmarshal decodes data into the struct pointed by v. Unknown fields are skipped.
+func Unmarshal(data []byte, v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return errors.Errorf("wire: can't unmarshal to %T, struct pointer expected", v)
+	}
+	return decodeVersioned(data, rv.Elem())
+}
+
+// RawField returns encoded payload of the struct field as it is in data produced by Marshal. Nil is returned if the
+// field is omitted.
+//
+// v is a pointer to the struct, e.g. (*message.Parcel)(nil).
+func RawField(data []byte, v interface{}, name string) ([]byte, error) {
+	t := reflect.TypeOf(v)
+	if t == nil || t.Kind() != reflect.Ptr || t.Elem().Kind() != reflect.Struct {
+		return nil, errors.Errorf("wire: can't read field of %T, struct pointer expected", v)
+	}
+	sf, ok := t.Elem().FieldByName(name)
+	if !ok || len(sf.Index) != 1 {
+		return nil, errors.Errorf("wire: %s has no field %s", t.Elem(), name)
+	}
+	s, err := schemaOf(t.Elem())
+	if err != nil {
+		return nil, err
+	}
+	for _, f := range s.fields {
+		if f.index == sf.Index[0] {
+			return readField(data, f.num)
+		}
+	}
+	return nil, errors.Errorf("wire: field %s.%s is not encoded", t.Elem(), name)
+}
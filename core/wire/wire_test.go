@@ -0,0 +1,223 @@
+/*
+ *    Copyright 2018 Insolar
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ */
+
+package wire
+
+import (
+	"encoding/hex"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/insolar/insolar/configuration"
+	"github.com/insolar/insolar/version/manager"
+)
+
+type testShape interface {
+	Kind() byte
+}
+
+type testSquare struct {
+	Side int
+}
+
+func (*testSquare) Kind() byte { return 1 }
+
+type testCircle struct {
+	Radius float64
+}
+
+func (*testCircle) Kind() byte { return 2 }
+
+func init() {
+	RegisterInterface((*testShape)(nil), Implementations{
+		TypeOf: func(v interface{}) byte {
+			return v.(testShape).Kind()
+		},
+		New: func(t byte) (interface{}, error) {
+			switch t {
+			case 1:
+				return &testSquare{}, nil
+			case 2:
+				return &testCircle{}, nil
+			default:
+				return nil, errors.Errorf("unknown shape %d", t)
+			}
+		},
+	})
+}
+
+type testNested struct {
+	ID   [4]byte
+	Tags []string
+}
+
+type testAll struct {
+	Bool    bool
+	Int     int
+	Int8    int8
+	Uint64  uint64
+	Float   float64
+	String  string
+	Bytes   []byte
+	Array   [3]byte
+	Ptr     *testNested
+	Nested  testNested
+	Slice   []*testNested
+	Map     map[string]uint32
+	Time    time.Time
+	Shape   testShape
+	Shapes  []testShape
+	skipped int
+	Skipped int `wire:"-"`
+}
+
+type testV1 struct {
+	Name   string
+	Amount int
+}
+
+type testV2 struct {
+	Name   string
+	Amount int
+	Extra  []byte `wire:",since=2"`
+}
+
+type testOuter struct {
+	Inner testV2
+}
+
+func TestMarshal_RoundTrip(t *testing.T) {
+	in := &testAll{
+		Bool:    true,
+		Int:     -42,
+		Int8:    -8,
+		Uint64:  1 << 63,
+		Float:   3.5,
+		String:  "insolar",
+		Bytes:   []byte{1, 2, 3},
+		Array:   [3]byte{4, 5, 6},
+		Ptr:     &testNested{ID: [4]byte{1}, Tags: []string{"a", ""}},
+		Nested:  testNested{Tags: []string{"b"}},
+		Slice:   []*testNested{{ID: [4]byte{2}}, nil},
+		Map:     map[string]uint32{"x": 1, "y": 0},
+		Time:    time.Unix(1540000000, 123).UTC(),
+		Shape:   &testCircle{Radius: 1.5},
+		Shapes:  []testShape{&testSquare{Side: 2}, nil},
+		Skipped: 1,
+	}
+	data, err := Marshal(in)
+	require.NoError(t, err)
+
+	out := &testAll{}
+	err = Unmarshal(data, out)
+	require.NoError(t, err)
+
+	in.Skipped = 0
+	assert.Equal(t, in, out)
+}
+
+func TestMarshal_Golden(t *testing.T) {
+	data, err := Marshal(&testV1{Name: "ab", Amount: -2})
+	require.NoError(t, err)
+	assert.Equal(t, "0101026162020103", hex.EncodeToString(data))
+
+	data, err = Marshal(&testV2{Name: "ab", Extra: []byte{0xff}})
+	require.NoError(t, err)
+	assert.Equal(t, "02010261620301ff", hex.EncodeToString(data))
+}
+
+func TestUnmarshal_SkipsUnknownFields(t *testing.T) {
+	data, err := Marshal(&testV2{Name: "ab", Amount: 3, Extra: []byte{0xff}})
+	require.NoError(t, err)
+
+	out := &testV1{}
+	err = Unmarshal(data, out)
+	require.NoError(t, err)
+	assert.Equal(t, &testV1{Name: "ab", Amount: 3}, out)
+}
+
+func TestMarshal_GatesNewFields(t *testing.T) {
+	vm, err := manager.NewVersionManager(configuration.NewVersionManager())
+	require.NoError(t, err)
+	SetGate(vm)
+	defer SetGate(nil)
+
+	data, err := Marshal(&testV2{Name: "ab"})
+	require.NoError(t, err)
+	assert.Equal(t, "0101026162", hex.EncodeToString(data))
+
+	in := &testV2{Name: "ab", Extra: []byte{0xff}}
+	_, err = Marshal(in)
+	assert.Equal(t, ErrVersionNotAllowed, errors.Cause(err))
+	_, err = Marshal(&testOuter{Inner: *in})
+	assert.Equal(t, ErrVersionNotAllowed, errors.Cause(err))
+
+	_, err = vm.Add("wire.testV2.v2", "v0.3.0", "Extra field in test struct")
+	require.NoError(t, err)
+	data, err = Marshal(in)
+	require.NoError(t, err)
+	assert.Equal(t, "02010261620301ff", hex.EncodeToString(data))
+
+	// Nested struct is encoded with its own version, outer type doesn't have new fields.
+	data, err = Marshal(&testOuter{Inner: *in})
+	require.NoError(t, err)
+	assert.Equal(t, "010107010261620301ff", hex.EncodeToString(data))
+}
+
+func TestRawField(t *testing.T) {
+	data, err := Marshal(&testOuter{Inner: testV2{Name: "ab"}})
+	require.NoError(t, err)
+
+	raw, err := RawField(data, (*testOuter)(nil), "Inner")
+	require.NoError(t, err)
+	assert.Equal(t, "01026162", hex.EncodeToString(raw))
+
+	raw, err = RawField(data, (*testV2)(nil), "Extra")
+	require.NoError(t, err)
+	assert.Nil(t, raw)
+
+	_, err = RawField(data, (*testOuter)(nil), "Unknown")
+	assert.Error(t, err)
+}
+
+func TestUnmarshal_Errors(t *testing.T) {
+	data, err := Marshal(&testV1{Name: "ab", Amount: -2})
+	require.NoError(t, err)
+
+	err = Unmarshal(data[:len(data)-2], &testV1{})
+	assert.Equal(t, ErrTruncated, errors.Cause(err))
+
+	err = Unmarshal(nil, &testV1{})
+	assert.Equal(t, ErrTruncated, errors.Cause(err))
+
+	err = Unmarshal(data, testV1{})
+	assert.Error(t, err)
+
+	_, err = Marshal(&struct {
+		A int
+		B int `wire:"1"`
+	}{})
+	assert.Error(t, err)
+
+	_, err = Marshal(&struct {
+		Untyped interface{}
+	}{Untyped: "value"})
+	assert.Error(t, err)
+}
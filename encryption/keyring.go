@@ -0,0 +1,208 @@
+/*
+ *    Copyright 2018 Insolar
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ */
+
+// Package encryption provides encryption of storage data at rest.
+//
+// Values are encrypted with AES-256-GCM. Every encrypted value carries id of the key it was encrypted with,
+// so keys could be rotated: new key encrypts new values while previous keys are kept to read old ones until
+// they are re-encrypted. Storage key of the value is authenticated with it, so encrypted value could not be moved
+// to another key.
+//
+// Decryption is strict: values without encryption header are rejected. Storages which already have plain values
+// should encrypt them all in an explicit migration before the key ring is used for reading.
+package encryption
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/insolar/insolar/configuration"
+	"github.com/insolar/insolar/core"
+	"github.com/insolar/insolar/platformpolicy"
+)
+
+const (
+	// KeySize is a size of encryption key in bytes.
+	KeySize = 32
+
+	keyIDSize = 4
+	version   = byte(1)
+)
+
+// header marks encrypted values.
+var header = []byte{0, 'E', 'N', 'C', version}
+
+var (
+	// ErrUnknownKey is returned if value is encrypted with a key missing in key ring.
+	ErrUnknownKey = errors.New("value is encrypted with unknown key")
+	// ErrNoKeys is returned if key ring is created without keys.
+	ErrNoKeys = errors.New("no encryption keys provided")
+	// ErrNotEncrypted is returned on decryption of value without encryption header.
+	ErrNotEncrypted = errors.New("value is not encrypted")
+)
+
+type key struct {
+	id   []byte
+	aead cipher.AEAD
+}
+
+// KeyRing holds storage encryption keys. The last key encrypts new values, all keys decrypt.
+type KeyRing struct {
+	keys   map[string]*key
+	active *key
+}
+
+// NewKeyRing creates key ring from raw keys. The last key is active.
+func NewKeyRing(secrets ...[]byte) (*KeyRing, error) {
+	if len(secrets) == 0 {
+		return nil, ErrNoKeys
+	}
+	ring := &KeyRing{keys: map[string]*key{}}
+	for _, secret := range secrets {
+		if len(secret) != KeySize {
+			return nil, errors.Errorf("invalid key size %v, expected %v", len(secret), KeySize)
+		}
+		block, err := aes.NewCipher(secret)
+		if err != nil {
+			return nil, err
+		}
+		aead, err := cipher.NewGCM(block)
+		if err != nil {
+			return nil, err
+		}
+		fingerprint := sha256.Sum256(secret)
+		k := &key{id: fingerprint[:keyIDSize], aead: aead}
+		ring.keys[string(k.id)] = k
+		ring.active = k
+	}
+	return ring, nil
+}
+
+// LoadKeyFile creates key ring from file with hex encoded keys, one per line.
+// Empty lines and lines starting with '#' are ignored.
+func LoadKeyFile(path string) (*KeyRing, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to open key file")
+	}
+	defer f.Close()
+
+	var secrets [][]byte
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		secret, err := hex.DecodeString(line)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to decode key")
+		}
+		secrets = append(secrets, secret)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, errors.Wrap(err, "failed to read key file")
+	}
+	return NewKeyRing(secrets...)
+}
+
+// DeriveKey derives encryption key from node's private key.
+func DeriveKey(keyStore core.KeyStore) ([]byte, error) {
+	privateKey, err := keyStore.GetPrivateKey("")
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get private key")
+	}
+	exported, err := platformpolicy.NewKeyProcessor().ExportPrivateKey(privateKey)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to export private key")
+	}
+	secret := sha256.Sum256(append([]byte("insolar storage encryption"), exported...))
+	return secret[:], nil
+}
+
+// NewKeyRingFromConfig creates key ring from key file or from keystore if key file is not set.
+//
+// Returns nil if encryption is disabled.
+func NewKeyRingFromConfig(conf configuration.StorageEncryption, keyStore core.KeyStore) (*KeyRing, error) {
+	if !conf.Enabled {
+		return nil, nil
+	}
+	if conf.KeyFile != "" {
+		return LoadKeyFile(conf.KeyFile)
+	}
+	if keyStore == nil {
+		return nil, errors.New("encryption key file is not set and keystore is not provided")
+	}
+	secret, err := DeriveKey(keyStore)
+	if err != nil {
+		return nil, err
+	}
+	return NewKeyRing(secret)
+}
+
+// Encrypt encrypts value stored by provided storage key with active key.
+func (r *KeyRing) Encrypt(storageKey, plain []byte) ([]byte, error) {
+	nonceSize := r.active.aead.NonceSize()
+	prefixSize := len(header) + keyIDSize + nonceSize
+	out := make([]byte, prefixSize, prefixSize+len(plain)+r.active.aead.Overhead())
+	copy(out, header)
+	copy(out[len(header):], r.active.id)
+	nonce := out[len(header)+keyIDSize:]
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return r.active.aead.Seal(out, nonce, plain, storageKey), nil
+}
+
+// Decrypt decrypts value stored by provided storage key. Values without encryption header are rejected.
+func (r *KeyRing) Decrypt(storageKey, data []byte) ([]byte, error) {
+	if !IsEncrypted(data) {
+		return nil, ErrNotEncrypted
+	}
+	k, ok := r.keys[string(data[len(header):len(header)+keyIDSize])]
+	if !ok {
+		return nil, ErrUnknownKey
+	}
+	body := data[len(header)+keyIDSize:]
+	nonceSize := k.aead.NonceSize()
+	if len(body) < nonceSize {
+		return nil, errors.New("encrypted value is too short")
+	}
+	plain, err := k.aead.Open(nil, body[:nonceSize], body[nonceSize:], storageKey)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to decrypt value")
+	}
+	return plain, nil
+}
+
+// IsActual checks if value is encrypted with active key, i.e. it does not need re-encryption.
+func (r *KeyRing) IsActual(data []byte) bool {
+	return IsEncrypted(data) && bytes.Equal(data[len(header):len(header)+keyIDSize], r.active.id)
+}
+
+// IsEncrypted checks if value has encryption header.
+func IsEncrypted(data []byte) bool {
+	return len(data) >= len(header)+keyIDSize && bytes.HasPrefix(data, header)
+}
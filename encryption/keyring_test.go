@@ -0,0 +1,122 @@
+/*
+ *    Copyright 2018 Insolar
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ */
+
+package encryption
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/insolar/insolar/configuration"
+	"github.com/insolar/insolar/keystore"
+)
+
+func randomKey(t *testing.T) []byte {
+	key := make([]byte, KeySize)
+	_, err := rand.Read(key)
+	require.NoError(t, err)
+	return key
+}
+
+func TestKeyRing_EncryptDecrypt(t *testing.T) {
+	oldKey, newKey := randomKey(t), randomKey(t)
+	oldRing, err := NewKeyRing(oldKey)
+	require.NoError(t, err)
+	ring, err := NewKeyRing(oldKey, newKey)
+	require.NoError(t, err)
+
+	storageKey, value := []byte("key"), []byte("value")
+	encrypted, err := oldRing.Encrypt(storageKey, value)
+	require.NoError(t, err)
+	assert.True(t, IsEncrypted(encrypted))
+	assert.True(t, oldRing.IsActual(encrypted))
+	assert.False(t, ring.IsActual(encrypted))
+
+	decrypted, err := ring.Decrypt(storageKey, encrypted)
+	require.NoError(t, err)
+	assert.Equal(t, value, decrypted)
+
+	encrypted, err = ring.Encrypt(storageKey, value)
+	require.NoError(t, err)
+	assert.True(t, ring.IsActual(encrypted))
+	_, err = oldRing.Decrypt(storageKey, encrypted)
+	assert.Equal(t, ErrUnknownKey, err)
+
+	// Value moved to another storage key is rejected.
+	_, err = ring.Decrypt([]byte("another key"), encrypted)
+	assert.Error(t, err)
+
+	// Tampered value is rejected.
+	encrypted[len(encrypted)-1] ^= 0xff
+	_, err = ring.Decrypt(storageKey, encrypted)
+	assert.Error(t, err)
+
+	// Plain value is rejected.
+	_, err = ring.Decrypt(storageKey, value)
+	assert.Equal(t, ErrNotEncrypted, err)
+	assert.False(t, ring.IsActual(value))
+
+	_, err = NewKeyRing()
+	assert.Equal(t, ErrNoKeys, err)
+	_, err = NewKeyRing([]byte("short"))
+	assert.Error(t, err)
+}
+
+func TestNewKeyRingFromConfig(t *testing.T) {
+	dir, err := ioutil.TempDir("", "keys")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	oldKey, newKey := randomKey(t), randomKey(t)
+	path := filepath.Join(dir, "storage.keys")
+	content := "# rotated keys\n" + hex.EncodeToString(oldKey) + "\n\n" + hex.EncodeToString(newKey) + "\n"
+	require.NoError(t, ioutil.WriteFile(path, []byte(content), 0600))
+
+	ring, err := NewKeyRingFromConfig(configuration.StorageEncryption{Enabled: false, KeyFile: path}, nil)
+	require.NoError(t, err)
+	assert.Nil(t, ring)
+
+	ring, err = NewKeyRingFromConfig(configuration.StorageEncryption{Enabled: true, KeyFile: path}, nil)
+	require.NoError(t, err)
+	newRing, err := NewKeyRing(newKey)
+	require.NoError(t, err)
+	encrypted, err := ring.Encrypt([]byte("key"), []byte("value"))
+	require.NoError(t, err)
+	assert.True(t, newRing.IsActual(encrypted))
+
+	_, err = NewKeyRingFromConfig(configuration.StorageEncryption{Enabled: true}, nil)
+	assert.Error(t, err)
+
+	// Key derived from keystore is stable.
+	keyStore, err := keystore.NewKeyStore("../keystore/testdata/keys.json")
+	require.NoError(t, err)
+	ring, err = NewKeyRingFromConfig(configuration.StorageEncryption{Enabled: true}, keyStore)
+	require.NoError(t, err)
+	encrypted, err = ring.Encrypt([]byte("key"), []byte("value"))
+	require.NoError(t, err)
+	secret, err := DeriveKey(keyStore)
+	require.NoError(t, err)
+	derived, err := NewKeyRing(secret)
+	require.NoError(t, err)
+	assert.True(t, derived.IsActual(encrypted))
+}
@@ -31,6 +31,9 @@ import (
 
 const (
 	getChildrenChunkSize = 10 * 1000
+	maxChildrenScan      = 100 * 1000
+	getHistoryChunkSize  = 100
+	maxObjectsPageSize   = 1000
 )
 
 // LedgerArtifactManager provides concrete API to storage for processing module.
@@ -40,6 +43,31 @@ type LedgerArtifactManager struct {
 	PlatformCryptographyScheme core.PlatformCryptographyScheme `inject:""`
 
 	getChildrenChunkSize int
+	getHistoryChunkSize  int
+
+	cache *descriptorCache
+}
+
+type amOptions struct {
+	codeCacheSize   int
+	objectCacheSize int
+}
+
+// Option provides functional option for LedgerArtifactManager.
+type Option func(*amOptions)
+
+// CodeCacheSize sets maximum number of code descriptors cached by the client. Zero disables the cache.
+func CodeCacheSize(size int) Option {
+	return func(opts *amOptions) {
+		opts.codeCacheSize = size
+	}
+}
+
+// ObjectCacheSize sets maximum number of objects cached by the client within a pulse. Zero disables the cache.
+func ObjectCacheSize(size int) Option {
+	return func(opts *amOptions) {
+		opts.objectCacheSize = size
+	}
 }
 
 // State returns hash state for artifact manager.
@@ -49,8 +77,22 @@ func (m *LedgerArtifactManager) State() ([]byte, error) {
 }
 
 // NewArtifactManger creates new manager instance.
-func NewArtifactManger(db *storage.DB) *LedgerArtifactManager {
-	return &LedgerArtifactManager{db: db, getChildrenChunkSize: getChildrenChunkSize}
+func NewArtifactManger(db *storage.DB, options ...Option) *LedgerArtifactManager {
+	opts := &amOptions{}
+	for _, o := range options {
+		o(opts)
+	}
+	return &LedgerArtifactManager{
+		db:                   db,
+		getChildrenChunkSize: getChildrenChunkSize,
+		getHistoryChunkSize:  getHistoryChunkSize,
+		cache:                newDescriptorCache(opts.codeCacheSize, opts.objectCacheSize),
+	}
+}
+
+// ResetPulse drops objects cached within the previous pulse. Code stays cached since it never changes.
+func (m *LedgerArtifactManager) ResetPulse(ctx context.Context, pulse core.PulseNumber) {
+	m.cache.resetPulse(pulse)
 }
 
 // GenesisRef returns the root record reference.
@@ -68,10 +110,14 @@ func (m *LedgerArtifactManager) RegisterRequest(
 	var err error
 	defer instrument(ctx, "RegisterRequest").err(&err).end()
 
+	payload, err := message.ParcelToBytes(parcel)
+	if err != nil {
+		return nil, err
+	}
 	id, err := m.setRecord(
 		ctx,
 		&record.CallRequest{
-			Payload: message.ParcelToBytes(parcel),
+			Payload: payload,
 		},
 		message.ExtractTarget(parcel.Message()),
 	)
@@ -87,10 +133,12 @@ func (m *LedgerArtifactManager) GetCode(
 	var err error
 	defer instrument(ctx, "GetCode").err(&err).end()
 
-	genericReact, err := m.bus(ctx).Send(
-		ctx,
-		&message.GetCode{Code: code},
-	)
+	if cached := m.cache.getCode(ctx, code); cached != nil {
+		return newCodeDescriptor(ctx, code, cached), nil
+	}
+
+	getCodeMsg := &message.GetCode{Code: code}
+	genericReact, err := m.bus(ctx).Send(ctx, getCodeMsg)
 	if err != nil {
 		return nil, err
 	}
@@ -100,24 +148,31 @@ func (m *LedgerArtifactManager) GetCode(
 		err = ErrUnexpectedReply
 		return nil, err
 	}
+	m.cache.setCode(code, react)
+
+	return newCodeDescriptor(ctx, code, react), nil
+}
 
+func newCodeDescriptor(ctx context.Context, ref core.RecordRef, rep *reply.Code) *CodeDescriptor {
 	desc := CodeDescriptor{
 		ctx:         ctx,
-		ref:         code,
-		machineType: react.MachineType,
+		ref:         ref,
+		machineType: rep.MachineType,
 	}
-	desc.cache.code = react.Code
-	return &desc, nil
+	desc.cache.code = rep.Code
+	return &desc
 }
 
 // GetObject returns descriptor for provided state.
 //
-// If provided state is nil, the latest state will be returned (with deactivation check). Returned descriptor will
-// provide methods for fetching all related data.
+// If provided state is nil, the latest state will be returned (with deactivation check). If pulse is provided,
+// the newest state created at or before this pulse will be returned. Returned descriptor will provide methods for
+// fetching all related data.
 func (m *LedgerArtifactManager) GetObject(
 	ctx context.Context,
 	head core.RecordRef,
 	state *core.RecordID,
+	pulse *core.PulseNumber,
 	approved bool,
 ) (core.ObjectDescriptor, error) {
 	var (
@@ -126,11 +181,19 @@ func (m *LedgerArtifactManager) GetObject(
 	)
 	defer instrument(ctx, "GetObject").err(&err).end()
 
+	cacheKey := newObjectCacheKey(state, pulse, approved)
+	if cached := m.cache.getObject(ctx, head, cacheKey); cached != nil {
+		desc, err = m.objectDescriptor(ctx, cached)
+		return desc, err
+	}
+
 	getObjectMsg := &message.GetObject{
 		Head:     head,
 		State:    state,
+		Pulse:    pulse,
 		Approved: approved,
 	}
+	fetch := m.cache.startFetch()
 	genericReact, err := m.bus(ctx).Send(
 		ctx,
 		getObjectMsg,
@@ -139,16 +202,106 @@ func (m *LedgerArtifactManager) GetObject(
 		return nil, err
 	}
 
-	if genericReact.Type() == reply.TypeGetObjectRedirect {
-		genericReact, err = m.makeRedirect(ctx, genericReact, getObjectMsg)
+	desc, err = m.objectDescriptor(ctx, genericReact)
+	if err != nil {
+		return nil, err
+	}
+	m.cache.setObject(head, cacheKey, genericReact.(*reply.Object), fetch)
+	return desc, nil
+}
+
+// GetObjects returns descriptors for provided objects in one request.
+//
+// Objects stored on other nodes are fetched by the receiving node, redirects it could not follow are followed
+// for every object separately. Descriptors are returned in the order of provided heads.
+func (m *LedgerArtifactManager) GetObjects(
+	ctx context.Context,
+	heads []core.ObjectHead,
+	approved bool,
+) ([]core.ObjectDescriptor, error) {
+	var err error
+	defer instrument(ctx, "GetObjects").err(&err).end()
+
+	if len(heads) == 0 {
+		return nil, nil
+	}
+
+	replies := make([]core.Reply, len(heads))
+	var (
+		missing []core.ObjectHead
+		indexes []int
+	)
+	for i, head := range heads {
+		if cached := m.cache.getObject(ctx, head.Head, newObjectCacheKey(head.State, nil, approved)); cached != nil {
+			replies[i] = cached
+			continue
+		}
+		missing = append(missing, head)
+		indexes = append(indexes, i)
+	}
+
+	fetch := m.cache.startFetch()
+	if len(missing) > 0 {
+		var rep *reply.Objects
+		rep, err = m.sendGetObjects(ctx, missing, approved)
 		if err != nil {
 			return nil, err
 		}
+		for j, i := range indexes {
+			replies[i] = rep.Objects[j]
+		}
+	}
+
+	descs := make([]core.ObjectDescriptor, 0, len(heads))
+	for i, item := range replies {
+		if redirect, ok := item.(core.RedirectReply); ok {
+			item, err = m.followRedirect(ctx, redirect, &message.GetObject{
+				Head:     heads[i].Head,
+				State:    heads[i].State,
+				Approved: approved,
+			})
+			if err != nil {
+				return nil, err
+			}
+		}
+		var desc *ObjectDescriptor
+		desc, err = m.objectDescriptor(ctx, item)
+		if err != nil {
+			err = errors.Wrapf(err, "failed to fetch object %v", heads[i].Head)
+			return nil, err
+		}
+		m.cache.setObject(heads[i].Head, newObjectCacheKey(heads[i].State, nil, approved), item.(*reply.Object), fetch)
+		descs = append(descs, desc)
+	}
+	return descs, nil
+}
+
+func (m *LedgerArtifactManager) sendGetObjects(
+	ctx context.Context, heads []core.ObjectHead, approved bool,
+) (*reply.Objects, error) {
+	genericReact, err := m.bus(ctx).Send(ctx, &message.GetObjects{Objects: heads, Approved: approved})
+	if err != nil {
+		return nil, err
 	}
 
 	switch r := genericReact.(type) {
+	case *reply.Objects:
+		if len(r.Objects) != len(heads) {
+			return nil, ErrUnexpectedReply
+		}
+		return r, nil
+	case *reply.Error:
+		return nil, r.Error()
+	default:
+		return nil, ErrUnexpectedReply
+	}
+}
+
+// objectDescriptor creates object descriptor from object reply.
+func (m *LedgerArtifactManager) objectDescriptor(ctx context.Context, rep core.Reply) (*ObjectDescriptor, error) {
+	switch r := rep.(type) {
 	case *reply.Object:
-		desc = &ObjectDescriptor{
+		return &ObjectDescriptor{
 			ctx:          ctx,
 			am:           m,
 			head:         r.Head,
@@ -158,39 +311,28 @@ func (m *LedgerArtifactManager) GetObject(
 			childPointer: r.ChildPointer,
 			memory:       r.Memory,
 			parent:       r.Parent,
-		}
+		}, nil
 	case *reply.Error:
-		err = r.Error()
+		return nil, r.Error()
 	default:
-		err = ErrUnexpectedReply
+		return nil, ErrUnexpectedReply
 	}
-	return desc, err
 }
 
-func (m *LedgerArtifactManager) makeRedirect(ctx context.Context, response core.Reply, messageForRedirect *message.GetObject) (core.Reply, error) {
-	// TODO Need to be replaced with constant
-	maxCountOfReply := 2
-
-	for maxCountOfReply > 0 {
-		redirectResponse := response.(*reply.GetObjectRedirectReply)
-
-		redirectedMessage := redirectResponse.RecreateMessage(messageForRedirect)
-
-		genericReact, err := m.bus(ctx).Send(
-			ctx,
-			redirectedMessage,
-			core.SendOptionToken(redirectResponse.Token),
-			core.SendOptionDestination(redirectResponse.To),
-		)
-
-		if genericReact.Type() != reply.TypeGetObjectRedirect {
-			return response, err
-		}
-
-		maxCountOfReply--
+// followRedirect sends redirected message to the redirect destination. Further redirects are followed by MessageBus.
+func (m *LedgerArtifactManager) followRedirect(
+	ctx context.Context, redirect core.RedirectReply, msg core.Message,
+) (core.Reply, error) {
+	redirected, err := redirect.Redirected(msg)
+	if err != nil {
+		return nil, err
 	}
-
-	return nil, errors.New("object not found")
+	return m.bus(ctx).Send(
+		ctx,
+		redirected,
+		core.SendOptionDestination(redirect.GetReceiver()),
+		core.SendOptionToken(redirect.GetToken()),
+	)
 }
 
 // GetDelegate returns provided object's delegate reference for provided prototype.
@@ -234,6 +376,131 @@ func (m *LedgerArtifactManager) GetChildren(
 	return iter, err
 }
 
+// GetChildrenPage returns a page of children refs filtered on the parent's side.
+//
+// Children are listed from the latest to the earliest one. Use Next from returned page as From in subsequent
+// query to fetch the next page. Page may be shorter than the limit (even empty) while Next is not nil if too many
+// children were filtered out.
+func (m *LedgerArtifactManager) GetChildrenPage(
+	ctx context.Context, parent core.RecordRef, query core.ChildrenQuery,
+) (*core.ChildrenPage, error) {
+	var err error
+	defer instrument(ctx, "GetChildrenPage").err(&err).end()
+
+	limit := query.Limit
+	if limit <= 0 || limit > m.getChildrenChunkSize {
+		limit = m.getChildrenChunkSize
+	}
+	rep, err := fetchChildren(ctx, m.bus(ctx), &message.GetChildren{
+		Parent:    parent,
+		FromChild: query.From,
+		FromPulse: query.Pulse,
+		Amount:    limit,
+		Prototype: query.Prototype,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &core.ChildrenPage{Refs: rep.Refs, Next: rep.NextFrom}, nil
+}
+
+// CountChildren returns the number of children matching the query. Limit of the query is ignored.
+//
+// Children are counted in chunks, every request scans a limited number of children.
+func (m *LedgerArtifactManager) CountChildren(
+	ctx context.Context, parent core.RecordRef, query core.ChildrenQuery,
+) (int, error) {
+	var err error
+	defer instrument(ctx, "CountChildren").err(&err).end()
+
+	msg := &message.GetChildren{
+		Parent:    parent,
+		FromChild: query.From,
+		FromPulse: query.Pulse,
+		Prototype: query.Prototype,
+		Count:     true,
+	}
+	count := 0
+	for {
+		var rep *reply.Children
+		rep, err = fetchChildren(ctx, m.bus(ctx), msg)
+		if err != nil {
+			return 0, err
+		}
+		count += rep.Count
+		if rep.NextFrom == nil {
+			return count, nil
+		}
+		msg.FromChild = rep.NextFrom
+	}
+}
+
+// GetHistory returns object's state history iterator.
+//
+// States are iterated from the latest to the earliest one. During iteration states will be fetched from remote
+// source (object's executor).
+func (m *LedgerArtifactManager) GetHistory(
+	ctx context.Context, head core.RecordRef, pulse *core.PulseNumber,
+) (core.ObjectHistoryIterator, error) {
+	var err error
+	defer instrument(ctx, "GetHistory").err(&err).end()
+	iter, err := NewHistoryIterator(ctx, m.bus(ctx), head, pulse, m.getHistoryChunkSize)
+	return iter, err
+}
+
+// GetRecordProof returns Merkle inclusion proof of the record in the jet drop of its pulse.
+//
+// Proof is available only after the pulse of the record is closed.
+func (m *LedgerArtifactManager) GetRecordProof(
+	ctx context.Context, id core.RecordID,
+) (*core.RecordProof, error) {
+	var err error
+	defer instrument(ctx, "GetRecordProof").err(&err).end()
+
+	genericReact, err := m.bus(ctx).Send(ctx, &message.GetRecordProof{Record: id})
+	if err != nil {
+		return nil, err
+	}
+
+	switch rep := genericReact.(type) {
+	case *reply.RecordProof:
+		return &rep.Proof, nil
+	case *reply.Error:
+		err = rep.Error()
+		return nil, err
+	default:
+		err = ErrUnexpectedReply
+		return nil, err
+	}
+}
+
+// GetObjectsByPrototype returns a page of activated objects created from provided prototype.
+//
+// Query may be narrowed by parent and by pulse the objects were active on. Use Next from returned page as After
+// in subsequent query to fetch the next page.
+func (m *LedgerArtifactManager) GetObjectsByPrototype(
+	ctx context.Context, query core.ObjectsQuery,
+) (*core.ObjectsPage, error) {
+	var err error
+	defer instrument(ctx, "GetObjectsByPrototype").err(&err).end()
+
+	genericReact, err := m.bus(ctx).Send(ctx, &message.GetObjectsByPrototype{Query: query})
+	if err != nil {
+		return nil, err
+	}
+
+	switch rep := genericReact.(type) {
+	case *reply.ObjectsPage:
+		return &rep.Page, nil
+	case *reply.Error:
+		err = rep.Error()
+		return nil, err
+	default:
+		err = ErrUnexpectedReply
+		return nil, err
+	}
+}
+
 // DeclareType creates new type record in storage.
 //
 // Type is a contract interface. It contains one method signature.
@@ -427,6 +694,10 @@ func (m *LedgerArtifactManager) RegisterValidation(
 	var err error
 	defer instrument(ctx, "RegisterValidation").err(&err).end()
 
+	// Validation changes approved state, so it is not served from cache while it is in progress.
+	m.cache.dropObject(object)
+	defer m.cache.dropObject(object)
+
 	msg := message.ValidateRecord{
 		Object:             object,
 		State:              state,
@@ -437,6 +708,29 @@ func (m *LedgerArtifactManager) RegisterValidation(
 	return err
 }
 
+// GetObjectStatus returns validation status of provided object.
+//
+// States rejected by validators are removed from object's history and can not be used as previous states.
+func (m *LedgerArtifactManager) GetObjectStatus(ctx context.Context, head core.RecordRef) (*core.ObjectStatus, error) {
+	var err error
+	defer instrument(ctx, "GetObjectStatus").err(&err).end()
+
+	genericReact, err := m.bus(ctx).Send(ctx, &message.GetObjectStatus{Head: head})
+	if err != nil {
+		return nil, err
+	}
+
+	switch rep := genericReact.(type) {
+	case *reply.ObjectStatus:
+		return &rep.Status, nil
+	case *reply.Error:
+		err = rep.Error()
+	default:
+		err = ErrUnexpectedReply
+	}
+	return nil, err
+}
+
 // RegisterResult saves VM method call result.
 func (m *LedgerArtifactManager) RegisterResult(
 	ctx context.Context, request core.RecordRef, payload []byte,
@@ -465,7 +759,7 @@ func (m *LedgerArtifactManager) activateObject(
 	asDelegate bool,
 	memory []byte,
 ) (core.ObjectDescriptor, error) {
-	parentDesc, err := m.GetObject(ctx, parent, nil, false)
+	parentDesc, err := m.GetObject(ctx, parent, nil, nil, false)
 	if err != nil {
 		return nil, err
 	}
@@ -506,12 +800,16 @@ func (m *LedgerArtifactManager) activateObject(
 	if asDelegate {
 		asType = &prototype
 	}
+	childRec := record.ChildRecord{
+		Ref:       object,
+		PrevChild: prevChild,
+	}
+	if !isPrototype {
+		childRec.Prototype = &prototype
+	}
 	_, err = m.registerChild(
 		ctx,
-		&record.ChildRecord{
-			Ref:       object,
-			PrevChild: prevChild,
-		},
+		&childRec,
 		parent,
 		object,
 		asType,
@@ -645,6 +943,11 @@ func (m *LedgerArtifactManager) sendUpdateObject(
 	object core.RecordRef,
 	memory []byte,
 ) (*reply.Object, error) {
+	// Cached states are stale regardless of the result. They are dropped before the update, so they are not served
+	// while it is in progress, and after it, so states fetched during the update are not kept.
+	m.cache.dropObject(object)
+	defer m.cache.dropObject(object)
+
 	var wg sync.WaitGroup
 	wg.Add(2)
 
@@ -683,6 +986,9 @@ func (m *LedgerArtifactManager) sendUpdateObject(
 		return nil, blobError
 	}
 
+	if errReply, ok := genericReact.(*reply.Error); ok {
+		return nil, errReply.Error()
+	}
 	rep, ok := genericReact.(*reply.Object)
 	if !ok {
 		return nil, ErrUnexpectedReply
@@ -702,6 +1008,10 @@ func (m *LedgerArtifactManager) registerChild(
 	child core.RecordRef,
 	asType *core.RecordRef,
 ) (*core.RecordID, error) {
+	// Parent's child pointer is changed.
+	m.cache.dropObject(parent)
+	defer m.cache.dropObject(parent)
+
 	genericReact, err := m.bus(ctx).Send(
 		ctx,
 		&message.RegisterChild{
@@ -22,14 +22,18 @@ import (
 	"testing"
 
 	"github.com/insolar/insolar/core"
+	"github.com/insolar/insolar/core/delegationtoken"
 	"github.com/insolar/insolar/core/message"
 	"github.com/insolar/insolar/core/reply"
 	"github.com/insolar/insolar/instrumentation/inslogger"
 	"github.com/insolar/insolar/ledger/index"
+	"github.com/insolar/insolar/ledger/jetdrop"
 	"github.com/insolar/insolar/ledger/record"
 	"github.com/insolar/insolar/ledger/storage"
 	"github.com/insolar/insolar/ledger/storage/storagetest"
+	"github.com/insolar/insolar/network/nodenetwork"
 	"github.com/insolar/insolar/platformpolicy"
+	"github.com/insolar/insolar/testutils"
 	"github.com/insolar/insolar/testutils/testmessagebus"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -77,7 +81,9 @@ func getTestData(t *testing.T) (
 		db:                         db,
 		DefaultBus:                 mb,
 		getChildrenChunkSize:       100,
+		getHistoryChunkSize:        100,
 		PlatformCryptographyScheme: scheme,
+		cache:                      newDescriptorCache(0, 0),
 	}
 
 	return ctx, db, &am, cleaner
@@ -93,7 +99,9 @@ func TestLedgerArtifactManager_RegisterRequest(t *testing.T) {
 	assert.NoError(t, err)
 	rec, err := db.GetRecord(ctx, id)
 	assert.NoError(t, err)
-	assert.Equal(t, message.ParcelToBytes(&parcel), rec.(*record.CallRequest).Payload)
+	payload, err := message.ParcelToBytes(&parcel)
+	require.NoError(t, err)
+	assert.Equal(t, payload, rec.(*record.CallRequest).Payload)
 }
 
 func TestLedgerArtifactManager_DeclareType(t *testing.T) {
@@ -294,7 +302,7 @@ func TestLedgerArtifactManager_GetObject_VerifiesRecords(t *testing.T) {
 	defer cleaner()
 
 	objID := genRandomID(0)
-	_, err := am.GetObject(ctx, *genRefWithID(objID), nil, false)
+	_, err := am.GetObject(ctx, *genRefWithID(objID), nil, nil, false)
 	assert.NotNil(t, err)
 
 	deactivateID, _ := db.SetRecord(ctx, core.GenesisPulse.PulseNumber, &record.DeactivationRecord{})
@@ -303,7 +311,7 @@ func TestLedgerArtifactManager_GetObject_VerifiesRecords(t *testing.T) {
 	}
 	db.SetObjectIndex(ctx, objID, &objectIndex)
 
-	_, err = am.GetObject(ctx, *genRefWithID(objID), nil, false)
+	_, err = am.GetObject(ctx, *genRefWithID(objID), nil, nil, false)
 	assert.Equal(t, core.ErrDeactivated, err)
 }
 
@@ -346,7 +354,7 @@ func TestLedgerArtifactManager_GetObject_ReturnsCorrectDescriptors(t *testing.T)
 	}
 	db.SetObjectIndex(ctx, objRef.Record(), &objectIndex)
 
-	objDesc, err := am.GetObject(ctx, *objRef, nil, false)
+	objDesc, err := am.GetObject(ctx, *objRef, nil, nil, false)
 	assert.NoError(t, err)
 	expectedObjDesc := &ObjectDescriptor{
 		ctx: ctx,
@@ -364,6 +372,612 @@ func TestLedgerArtifactManager_GetObject_ReturnsCorrectDescriptors(t *testing.T)
 	assert.Equal(t, *expectedObjDesc, *objDesc.(*ObjectDescriptor))
 }
 
+func TestLedgerArtifactManager_GetObject_ByPulse(t *testing.T) {
+	t.Parallel()
+	ctx, db, am, cleaner := getTestData(t)
+	defer cleaner()
+
+	pulse1 := core.GenesisPulse.PulseNumber + 1
+	pulse2 := core.GenesisPulse.PulseNumber + 3
+	objID, objIndex, states := setObjectHistory(ctx, t, db, pulse1, pulse2)
+	err := db.SetObjectIndex(ctx, objID, objIndex)
+	require.NoError(t, err)
+	objRef := *genRefWithID(objID)
+
+	pn := core.GenesisPulse.PulseNumber
+	_, err = am.GetObject(ctx, objRef, nil, &pn, false)
+	assert.Equal(t, core.ErrStateNotAvailable, err)
+
+	pn = pulse1
+	desc, err := am.GetObject(ctx, objRef, nil, &pn, false)
+	require.NoError(t, err)
+	assert.Equal(t, states[0], *desc.StateID())
+	assert.Equal(t, []byte{1}, desc.Memory())
+
+	pn = pulse2 - 1
+	desc, err = am.GetObject(ctx, objRef, nil, &pn, false)
+	require.NoError(t, err)
+	assert.Equal(t, states[0], *desc.StateID())
+
+	pn = pulse2 + 1
+	desc, err = am.GetObject(ctx, objRef, nil, &pn, false)
+	require.NoError(t, err)
+	assert.Equal(t, states[1], *desc.StateID())
+	assert.Equal(t, []byte{2}, desc.Memory())
+}
+
+func TestMessageHandler_HandleGetObject_RedirectsByPulse(t *testing.T) {
+	t.Parallel()
+	ctx, db, _, cleaner := getTestData(t)
+	defer cleaner()
+
+	pulse1 := core.GenesisPulse.PulseNumber + 1
+	pulse2 := core.GenesisPulse.PulseNumber + 3
+	objID, objIndex, states := setObjectHistory(ctx, t, db, pulse1, pulse2)
+	err := db.SetObjectIndex(ctx, objID, objIndex)
+	require.NoError(t, err)
+
+	// Latest state is not stored on this node.
+	missingState := genRandomID(pulse2 + 1)
+	objIndex.LatestState = missingState
+	err = db.SetObjectIndex(ctx, objID, objIndex)
+	require.NoError(t, err)
+
+	executor := *genRandomRef(0)
+	handler := MessageHandler{
+		db:                     db,
+		recent:                 storage.NewRecentStorage(1),
+		JetCoordinator:         &testJetCoordinator{executor: executor},
+		DelegationTokenFactory: &testDelegationTokenFactory{},
+	}
+
+	pn := pulse2
+	msg := message.GetObject{Head: *genRefWithID(objID), Pulse: &pn}
+	rep, err := handler.handleGetObject(ctx, pulse2, &message.Parcel{Msg: &msg})
+	require.NoError(t, err)
+	redirect, ok := rep.(*reply.GetObjectRedirectReply)
+	require.True(t, ok)
+	assert.Equal(t, executor, *redirect.To)
+	assert.Equal(t, *missingState, *redirect.StateID)
+	assert.NotNil(t, redirect.Token)
+
+	// Redirected message keeps the pulse and continues from the missing state.
+	redirected := redirect.RecreateMessage(&msg)
+	assert.Equal(t, &pn, redirected.Pulse)
+	assert.Equal(t, missingState, redirected.State)
+
+	// A node holding older states resolves state from provided one.
+	msg = message.GetObject{Head: *genRefWithID(objID), State: &states[1], Pulse: &pn}
+	rep, err = handler.handleGetObject(ctx, pulse2, &message.Parcel{Msg: &msg})
+	require.NoError(t, err)
+	obj, ok := rep.(*reply.Object)
+	require.True(t, ok)
+	assert.Equal(t, states[1], obj.State)
+}
+
+func TestLedgerArtifactManager_GetObjects(t *testing.T) {
+	t.Parallel()
+	ctx, db, am, cleaner := getTestData(t)
+	defer cleaner()
+
+	parentID, err := db.SetRecord(ctx, core.GenesisPulse.PulseNumber, &record.ObjectActivateRecord{
+		SideEffectRecord: record.SideEffectRecord{Domain: *genRandomRef(0)},
+	})
+	require.NoError(t, err)
+	err = db.SetObjectIndex(ctx, parentID, &index.ObjectLifeline{LatestState: parentID})
+	require.NoError(t, err)
+	parent := *genRefWithID(parentID)
+
+	var objects []core.ObjectDescriptor
+	for i := 0; i < 3; i++ {
+		obj, err := am.ActivateObject(ctx, domainRef, *genRandomRef(0), parent, *genRandomRef(0), false, []byte{byte(i)})
+		require.NoError(t, err)
+		objects = append(objects, obj)
+	}
+	first := objects[1]
+	updated, err := am.UpdateObject(ctx, domainRef, *genRandomRef(0), first, []byte{42})
+	require.NoError(t, err)
+
+	descs, err := am.GetObjects(ctx, []core.ObjectHead{
+		{Head: *objects[2].HeadRef()},
+		{Head: *first.HeadRef(), State: first.StateID()},
+		{Head: *objects[0].HeadRef()},
+		{Head: *first.HeadRef()},
+	}, false)
+	require.NoError(t, err)
+	require.Len(t, descs, 4)
+	assert.Equal(t, *objects[2].HeadRef(), *descs[0].HeadRef())
+	assert.Equal(t, []byte{2}, descs[0].Memory())
+	assert.Equal(t, *first.StateID(), *descs[1].StateID())
+	assert.Equal(t, []byte{1}, descs[1].Memory())
+	assert.Equal(t, *objects[0].HeadRef(), *descs[2].HeadRef())
+	assert.Equal(t, *updated.StateID(), *descs[3].StateID())
+	assert.Equal(t, []byte{42}, descs[3].Memory())
+
+	descs, err = am.GetObjects(ctx, nil, false)
+	assert.NoError(t, err)
+	assert.Empty(t, descs)
+
+	_, err = am.DeactivateObject(ctx, domainRef, *genRandomRef(0), objects[0])
+	require.NoError(t, err)
+	_, err = am.GetObjects(ctx, []core.ObjectHead{{Head: *objects[2].HeadRef()}, {Head: *objects[0].HeadRef()}}, false)
+	assert.Error(t, err)
+}
+
+func TestMessageHandler_HandleGetObjects_ForwardsByNode(t *testing.T) {
+	t.Parallel()
+	ctx, db, _, cleaner := getTestData(t)
+	defer cleaner()
+
+	pulse := core.GenesisPulse.PulseNumber + 1
+	objID, objIndex, states := setObjectHistory(ctx, t, db, pulse, pulse+1)
+	err := db.SetObjectIndex(ctx, objID, objIndex)
+	require.NoError(t, err)
+	local := *genRefWithID(objID)
+	missing := *genRandomRef(0)
+
+	executor := *genRandomRef(0)
+	mb := testutils.NewMessageBusMock(t)
+	mb.SendFunc = func(ctx context.Context, msg core.Message, opts ...core.SendOption) (core.Reply, error) {
+		options := core.SendOptions{}
+		for _, opt := range opts {
+			opt(&options)
+		}
+		require.NotNil(t, options.Receiver)
+		assert.Equal(t, executor, *options.Receiver)
+		assert.NotNil(t, options.Token)
+
+		forwarded, ok := msg.(*message.GetObjects)
+		require.True(t, ok)
+		assert.True(t, forwarded.Redirected)
+		require.Len(t, forwarded.Objects, 1)
+		assert.Equal(t, missing, forwarded.Objects[0].Head)
+		return &reply.Objects{Objects: []core.Reply{&reply.Object{Head: missing}}}, nil
+	}
+	handler := MessageHandler{
+		db:                     db,
+		recent:                 storage.NewRecentStorage(1),
+		JetCoordinator:         &testJetCoordinator{executor: executor},
+		DelegationTokenFactory: &testDelegationTokenFactory{},
+		Bus:                    mb,
+		NodeNet:                nodenetwork.NewNodeKeeper(nodenetwork.NewNode(*genRandomRef(0), nil, nil, 0, "", "")),
+	}
+
+	msg := message.GetObjects{Objects: []core.ObjectHead{{Head: local}, {Head: missing}}}
+	rep, err := handler.handleGetObjects(ctx, pulse+1, &message.Parcel{Msg: &msg})
+	require.NoError(t, err)
+	objs, ok := rep.(*reply.Objects)
+	require.True(t, ok)
+	require.Len(t, objs.Objects, 2)
+	obj, ok := objs.Objects[0].(*reply.Object)
+	require.True(t, ok)
+	assert.Equal(t, states[1], obj.State)
+	obj, ok = objs.Objects[1].(*reply.Object)
+	require.True(t, ok)
+	assert.Equal(t, missing, obj.Head)
+	assert.Equal(t, uint64(1), mb.SendCounter)
+
+	// Forwarded requests are not redirected again, missing objects fail separately.
+	msg = message.GetObjects{Objects: []core.ObjectHead{{Head: missing}, {Head: local}}, Redirected: true}
+	rep, err = handler.handleGetObjects(ctx, pulse+1, &message.Parcel{Msg: &msg})
+	require.NoError(t, err)
+	objs, ok = rep.(*reply.Objects)
+	require.True(t, ok)
+	require.Len(t, objs.Objects, 2)
+	assert.Equal(t, &reply.Error{ErrType: reply.ErrObjectNotFound}, objs.Objects[0])
+	assert.IsType(t, &reply.Object{}, objs.Objects[1])
+	assert.Equal(t, uint64(1), mb.SendCounter)
+
+	// Objects could not be redirected without executor.
+	handler.JetCoordinator = &testJetCoordinator{}
+	msg = message.GetObjects{Objects: []core.ObjectHead{{Head: missing}}}
+	_, err = handler.handleGetObjects(ctx, pulse+1, &message.Parcel{Msg: &msg})
+	assert.Error(t, err)
+}
+
+func TestLedgerArtifactManager_Cache(t *testing.T) {
+	t.Parallel()
+	ctx, db, am, cleaner := getTestData(t)
+	defer cleaner()
+
+	mb := testutils.NewMessageBusMock(t)
+	mb.SendFunc = am.DefaultBus.Send
+	am.DefaultBus = mb
+	am.cache = newDescriptorCache(10, 1)
+
+	codeID, err := am.DeployCode(ctx, domainRef, *genRandomRef(0), []byte{1, 2, 3}, core.MachineTypeBuiltin)
+	require.NoError(t, err)
+	codeRef := *genRefWithID(codeID)
+
+	parentID, err := db.SetRecord(ctx, core.GenesisPulse.PulseNumber, &record.ObjectActivateRecord{
+		SideEffectRecord: record.SideEffectRecord{Domain: *genRandomRef(0)},
+	})
+	require.NoError(t, err)
+	err = db.SetObjectIndex(ctx, parentID, &index.ObjectLifeline{LatestState: parentID})
+	require.NoError(t, err)
+	parent := *genRefWithID(parentID)
+	objA, err := am.ActivateObject(ctx, domainRef, *genRandomRef(0), parent, *genRandomRef(0), false, []byte{1})
+	require.NoError(t, err)
+	objB, err := am.ActivateObject(ctx, domainRef, *genRandomRef(0), parent, *genRandomRef(0), false, []byte{2})
+	require.NoError(t, err)
+
+	sends := func(f func()) uint64 {
+		before := mb.SendCounter
+		f()
+		return mb.SendCounter - before
+	}
+	getObject := func(head core.RecordRef) core.ObjectDescriptor {
+		desc, err := am.GetObject(ctx, head, nil, nil, false)
+		require.NoError(t, err)
+		return desc
+	}
+
+	t.Run("code is cached across pulses", func(t *testing.T) {
+		assert.Equal(t, uint64(1), sends(func() {
+			for i := 0; i < 3; i++ {
+				desc, err := am.GetCode(ctx, codeRef)
+				require.NoError(t, err)
+				code, err := desc.Code()
+				require.NoError(t, err)
+				assert.Equal(t, []byte{1, 2, 3}, code)
+			}
+		}))
+		am.ResetPulse(ctx, core.GenesisPulse.PulseNumber+1)
+		assert.Equal(t, uint64(0), sends(func() {
+			_, err := am.GetCode(ctx, codeRef)
+			require.NoError(t, err)
+		}))
+	})
+
+	t.Run("objects are cached within pulse", func(t *testing.T) {
+		assert.Equal(t, uint64(1), sends(func() {
+			getObject(*objA.HeadRef())
+			getObject(*objA.HeadRef())
+		}))
+		am.ResetPulse(ctx, core.GenesisPulse.PulseNumber+1)
+		assert.Equal(t, uint64(1), sends(func() {
+			getObject(*objA.HeadRef())
+		}))
+	})
+
+	t.Run("changed object is dropped from cache", func(t *testing.T) {
+		getObject(*objA.HeadRef())
+		updated, err := am.UpdateObject(ctx, domainRef, *genRandomRef(0), objA, []byte{3})
+		require.NoError(t, err)
+		desc := getObject(*objA.HeadRef())
+		assert.Equal(t, *updated.StateID(), *desc.StateID())
+		assert.Equal(t, []byte{3}, desc.Memory())
+	})
+
+	t.Run("descriptors don't share cached data", func(t *testing.T) {
+		desc := getObject(*objA.HeadRef())
+		desc.Memory()[0] = 42
+		assert.Equal(t, []byte{3}, getObject(*objA.HeadRef()).Memory())
+
+		codeDesc, err := am.GetCode(ctx, codeRef)
+		require.NoError(t, err)
+		code, err := codeDesc.Code()
+		require.NoError(t, err)
+		code[0] = 42
+		codeDesc, err = am.GetCode(ctx, codeRef)
+		require.NoError(t, err)
+		code, err = codeDesc.Code()
+		require.NoError(t, err)
+		assert.Equal(t, []byte{1, 2, 3}, code)
+	})
+
+	t.Run("least recently used object is evicted", func(t *testing.T) {
+		getObject(*objA.HeadRef())
+		assert.Equal(t, uint64(2), sends(func() {
+			getObject(*objB.HeadRef())
+			getObject(*objA.HeadRef())
+		}))
+	})
+
+	send := mb.SendFunc
+	concurrent := map[string]func(){
+		"object fetched before pulse change is not cached": func() {
+			am.ResetPulse(ctx, core.GenesisPulse.PulseNumber+2)
+		},
+		"object fetched before object change is not cached": func() {
+			am.cache.dropObject(*objB.HeadRef())
+		},
+	}
+	for name, change := range concurrent {
+		t.Run(name, func(t *testing.T) {
+			defer func() { mb.SendFunc = send }()
+			mb.SendFunc = func(ctx context.Context, msg core.Message, opts ...core.SendOption) (core.Reply, error) {
+				change()
+				return send(ctx, msg, opts...)
+			}
+			am.ResetPulse(ctx, core.GenesisPulse.PulseNumber+1)
+			getObject(*objB.HeadRef())
+			mb.SendFunc = send
+			assert.Equal(t, uint64(1), sends(func() {
+				getObject(*objB.HeadRef())
+			}))
+		})
+	}
+}
+
+func TestMessageHandler_RedirectsPrunedToHeavy(t *testing.T) {
+	t.Parallel()
+	ctx, db, _, cleaner := getTestData(t)
+	defer cleaner()
+
+	pulse1 := core.GenesisPulse.PulseNumber + 1
+	pulse2 := core.GenesisPulse.PulseNumber + 2
+	pulse3 := core.GenesisPulse.PulseNumber + 3
+	for _, pn := range []core.PulseNumber{pulse1, pulse2, pulse3} {
+		err := db.AddPulse(ctx, core.Pulse{PulseNumber: pn})
+		require.NoError(t, err)
+	}
+
+	objID, objIndex, _ := setObjectHistory(ctx, t, db, pulse1, pulse2)
+	childID, err := db.SetRecord(ctx, pulse1, &record.ChildRecord{Ref: *genRandomRef(pulse1)})
+	require.NoError(t, err)
+	objIndex.ChildPointer = childID
+	err = db.SetObjectIndex(ctx, objID, objIndex)
+	require.NoError(t, err)
+	codeBlob, err := db.SetBlob(ctx, pulse1, []byte{1, 2, 3})
+	require.NoError(t, err)
+	codeID, err := db.SetRecord(ctx, pulse1, &record.CodeRecord{Code: codeBlob})
+	require.NoError(t, err)
+
+	err = db.Prune(ctx, pulse3)
+	require.NoError(t, err)
+
+	heavy := *genRandomRef(0)
+	handler := MessageHandler{
+		db:                     db,
+		recent:                 storage.NewRecentStorage(1),
+		JetCoordinator:         &testJetCoordinator{executor: *genRandomRef(0), heavy: heavy},
+		DelegationTokenFactory: &testDelegationTokenFactory{},
+	}
+
+	// Latest state is kept.
+	rep, err := handler.handleGetObject(ctx, pulse3, &message.Parcel{
+		Msg: &message.GetObject{Head: *genRefWithID(objID)},
+	})
+	require.NoError(t, err)
+	obj, ok := rep.(*reply.Object)
+	require.True(t, ok)
+	assert.Equal(t, *objIndex.LatestState, obj.State)
+	assert.Equal(t, []byte{2}, obj.Memory)
+
+	rep, err = handler.handleGetObject(ctx, pulse3, &message.Parcel{
+		Msg: &message.GetObject{Head: *genRefWithID(objID), State: objID},
+	})
+	require.NoError(t, err)
+	objRedirect, ok := rep.(*reply.GetObjectRedirectReply)
+	require.True(t, ok)
+	assert.Equal(t, heavy, *objRedirect.To)
+	assert.Equal(t, *objID, *objRedirect.StateID)
+	assert.Nil(t, objRedirect.Token)
+
+	rep, err = handler.handleGetCode(ctx, pulse3, &message.Parcel{
+		Msg: &message.GetCode{Code: *genRefWithID(codeID)},
+	})
+	require.NoError(t, err)
+	codeRedirect, ok := rep.(*reply.GetCodeRedirect)
+	require.True(t, ok)
+	assert.Equal(t, heavy, *codeRedirect.To)
+
+	rep, err = handler.handleGetChildren(ctx, pulse3, &message.Parcel{
+		Msg: &message.GetChildren{Parent: *genRefWithID(objID), Amount: 10},
+	})
+	require.NoError(t, err)
+	childrenRedirect, ok := rep.(*reply.GetChildrenRedirect)
+	require.True(t, ok)
+	assert.Equal(t, heavy, *childrenRedirect.To)
+
+	// Kept states are returned, history continues from the pruned state on heavy material node.
+	rep, err = handler.handleGetHistory(ctx, pulse3, &message.Parcel{
+		Msg: &message.GetHistory{Object: *genRefWithID(objID), Amount: 10},
+	})
+	require.NoError(t, err)
+	history, ok := rep.(*reply.History)
+	require.True(t, ok)
+	require.Equal(t, 1, len(history.States))
+	assert.Equal(t, *objIndex.LatestState, history.States[0].State)
+	assert.Equal(t, objID, history.NextFrom)
+
+	rep, err = handler.handleGetHistory(ctx, pulse3, &message.Parcel{
+		Msg: &message.GetHistory{Object: *genRefWithID(objID), FromState: history.NextFrom, Amount: 10},
+	})
+	require.NoError(t, err)
+	historyRedirect, ok := rep.(*reply.GetHistoryRedirect)
+	require.True(t, ok)
+	assert.Equal(t, heavy, *historyRedirect.To)
+	assert.Equal(t, objID, historyRedirect.FromState)
+
+	rep, err = handler.handleGetRecordProof(ctx, pulse3, &message.Parcel{
+		Msg: &message.GetRecordProof{Record: *objIndex.LatestState},
+	})
+	require.NoError(t, err)
+	proofRedirect, ok := rep.(*reply.GetRecordProofRedirect)
+	require.True(t, ok)
+	assert.Equal(t, heavy, *proofRedirect.To)
+
+	// Data which is not pruned is not redirected.
+	_, err = handler.handleGetCode(ctx, pulse3, &message.Parcel{
+		Msg: &message.GetCode{Code: *genRefWithID(genRandomID(pulse3))},
+	})
+	assert.Error(t, err)
+}
+
+func TestMessageHandler_PrototypeIndex(t *testing.T) {
+	t.Parallel()
+	ctx, db, _, cleaner := getTestData(t)
+	defer cleaner()
+
+	handler := MessageHandler{db: db, recent: storage.NewRecentStorage(1)}
+	pulse := core.GenesisPulse.PulseNumber
+	parent := *genRandomRef(0)
+
+	update := func(head core.RecordRef, rec record.Record) *core.RecordID {
+		rep, err := handler.handleUpdateObject(ctx, pulse, &message.Parcel{
+			Msg: &message.UpdateObject{Record: record.SerializeRecord(rec), Object: head},
+		})
+		require.NoError(t, err)
+		obj, ok := rep.(*reply.Object)
+		require.True(t, ok, "unexpected reply %#v", rep)
+		return &obj.State
+	}
+	activate := func(head, prototype core.RecordRef) *core.RecordID {
+		return update(head, &record.ObjectActivateRecord{
+			SideEffectRecord:  record.SideEffectRecord{Domain: domainRef, Request: head},
+			ObjectStateRecord: record.ObjectStateRecord{Image: prototype},
+			Parent:            parent,
+		})
+	}
+	amend := func(head, prototype core.RecordRef, prev *core.RecordID) *core.RecordID {
+		return update(head, &record.ObjectAmendRecord{
+			SideEffectRecord:  record.SideEffectRecord{Domain: domainRef, Request: *genRandomRef(0)},
+			ObjectStateRecord: record.ObjectStateRecord{Image: prototype},
+			PrevState:         *prev,
+		})
+	}
+	reject := func(head core.RecordRef, state *core.RecordID) {
+		_, err := handler.handleValidateRecord(ctx, pulse, &message.Parcel{
+			Msg: &message.ValidateRecord{Object: head, State: *state, IsValid: false},
+		})
+		require.NoError(t, err)
+	}
+	indexed := func(prototype core.RecordRef) []core.RecordRef {
+		page, err := db.GetObjectsByPrototype(ctx, core.ObjectsQuery{Prototype: prototype})
+		require.NoError(t, err)
+		return page.Heads
+	}
+
+	tests := []struct {
+		name string
+		// run changes object and returns prototypes which should and should not contain the object
+		run func(head core.RecordRef) (with, without []core.RecordRef)
+	}{
+		{
+			name: "missing previous state is treated as state without prototype",
+			run: func(head core.RecordRef) ([]core.RecordRef, []core.RecordRef) {
+				prototype := *genRandomRef(0)
+				missing := genRandomID(pulse)
+				err := db.SetObjectIndex(ctx, head.Record(), &index.ObjectLifeline{
+					LatestState: missing,
+					State:       record.StateAmend,
+				})
+				require.NoError(t, err)
+				amend(head, prototype, missing)
+				return []core.RecordRef{prototype}, nil
+			},
+		},
+		{
+			name: "rejected activation is removed",
+			run: func(head core.RecordRef) ([]core.RecordRef, []core.RecordRef) {
+				prototype := *genRandomRef(0)
+				reject(head, activate(head, prototype))
+				return nil, []core.RecordRef{prototype}
+			},
+		},
+		{
+			name: "rejected prototype change is rolled back",
+			run: func(head core.RecordRef) ([]core.RecordRef, []core.RecordRef) {
+				first, second := *genRandomRef(0), *genRandomRef(0)
+				activated := activate(head, first)
+				reject(head, amend(head, second, activated))
+				return []core.RecordRef{first}, []core.RecordRef{second}
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			head := *genRandomRef(0)
+			with, without := tt.run(head)
+			for _, prototype := range with {
+				assert.Contains(t, indexed(prototype), head)
+			}
+			for _, prototype := range without {
+				assert.NotContains(t, indexed(prototype), head)
+			}
+		})
+	}
+}
+
+// setObjectHistory creates activate and amend records for an object in provided pulses.
+func setObjectHistory(
+	ctx context.Context, t *testing.T, db *storage.DB, pulse1, pulse2 core.PulseNumber,
+) (*core.RecordID, *index.ObjectLifeline, []core.RecordID) {
+	memory1, err := db.SetBlob(ctx, pulse1, []byte{1})
+	require.NoError(t, err)
+	memory2, err := db.SetBlob(ctx, pulse2, []byte{2})
+	require.NoError(t, err)
+
+	activateID, err := db.SetRecord(ctx, pulse1, &record.ObjectActivateRecord{
+		SideEffectRecord:  record.SideEffectRecord{Domain: domainRef, Request: *genRandomRef(0)},
+		ObjectStateRecord: record.ObjectStateRecord{Memory: memory1},
+	})
+	require.NoError(t, err)
+	amendID, err := db.SetRecord(ctx, pulse2, &record.ObjectAmendRecord{
+		SideEffectRecord:  record.SideEffectRecord{Domain: domainRef, Request: *genRandomRef(0)},
+		ObjectStateRecord: record.ObjectStateRecord{Memory: memory2},
+		PrevState:         *activateID,
+	})
+	require.NoError(t, err)
+
+	idx := index.ObjectLifeline{
+		LatestState: amendID,
+		State:       record.StateAmend,
+	}
+	return activateID, &idx, []core.RecordID{*activateID, *amendID}
+}
+
+type testJetCoordinator struct {
+	executor core.RecordRef
+	heavy    core.RecordRef
+}
+
+func (jc *testJetCoordinator) IsAuthorized(
+	ctx context.Context, role core.JetRole, obj *core.RecordRef, pulse core.PulseNumber, node core.RecordRef,
+) (bool, error) {
+	return node == jc.executor, nil
+}
+
+func (jc *testJetCoordinator) QueryRole(
+	ctx context.Context, role core.JetRole, obj *core.RecordRef, pulse core.PulseNumber,
+) ([]core.RecordRef, error) {
+	if role == core.RoleHeavyExecutor {
+		return []core.RecordRef{jc.heavy}, nil
+	}
+	if jc.executor.IsEmpty() {
+		return nil, nil
+	}
+	return []core.RecordRef{jc.executor}, nil
+}
+
+func (jc *testJetCoordinator) GetActiveNodes(pulse core.PulseNumber) ([]core.Node, error) {
+	return nil, nil
+}
+
+func (jc *testJetCoordinator) UpdateJetTree(ctx context.Context, closed, next core.PulseNumber) error {
+	return nil
+}
+
+type testDelegationTokenFactory struct{}
+
+func (f *testDelegationTokenFactory) IssuePendingExecution(
+	msg core.Message, pulse core.PulseNumber,
+) (core.DelegationToken, error) {
+	return &delegationtoken.PendingExecution{}, nil
+}
+
+func (f *testDelegationTokenFactory) IssueGetObjectRedirect(
+	sender *core.RecordRef, redirectedMessage core.Message,
+) (core.DelegationToken, error) {
+	return &delegationtoken.GetObjectRedirect{}, nil
+}
+
+func (f *testDelegationTokenFactory) Verify(parcel core.Parcel) (bool, error) {
+	return true, nil
+}
+
 func TestLedgerArtifactManager_GetChildren(t *testing.T) {
 	t.Parallel()
 	ctx, db, am, cleaner := getTestData(t)
@@ -488,6 +1102,237 @@ func TestLedgerArtifactManager_GetChildren(t *testing.T) {
 	})
 }
 
+func TestLedgerArtifactManager_GetChildrenPage(t *testing.T) {
+	t.Parallel()
+	ctx, db, am, cleaner := getTestData(t)
+	defer cleaner()
+
+	parentID, err := db.SetRecord(ctx, core.GenesisPulse.PulseNumber, &record.ObjectActivateRecord{
+		SideEffectRecord: record.SideEffectRecord{Domain: *genRandomRef(0)},
+	})
+	require.NoError(t, err)
+	err = db.SetObjectIndex(ctx, parentID, &index.ObjectLifeline{LatestState: parentID})
+	require.NoError(t, err)
+	parent := *genRefWithID(parentID)
+
+	protoA, protoB := *genRandomRef(0), *genRandomRef(0)
+	var childrenA []core.ObjectDescriptor
+	for i := 0; i < 6; i++ {
+		prototype := protoA
+		if i%2 == 1 {
+			prototype = protoB
+		}
+		child, err := am.ActivateObject(ctx, domainRef, *genRandomRef(0), parent, prototype, false, nil)
+		require.NoError(t, err)
+		if prototype == protoA {
+			childrenA = append(childrenA, child)
+		}
+	}
+	_, err = am.DeactivateObject(ctx, domainRef, *genRandomRef(0), childrenA[0])
+	require.NoError(t, err)
+
+	t.Run("filters by prototype with cursor", func(t *testing.T) {
+		query := core.ChildrenQuery{Prototype: &protoA, Limit: 1}
+		var refs []core.RecordRef
+		for {
+			page, err := am.GetChildrenPage(ctx, parent, query)
+			require.NoError(t, err)
+			require.True(t, len(page.Refs) <= 1)
+			refs = append(refs, page.Refs...)
+			if page.Next == nil {
+				break
+			}
+			query.From = page.Next
+		}
+		// Latest children go first, deactivated child is skipped.
+		assert.Equal(t, []core.RecordRef{*childrenA[2].HeadRef(), *childrenA[1].HeadRef()}, refs)
+	})
+
+	t.Run("returns all children without filter", func(t *testing.T) {
+		page, err := am.GetChildrenPage(ctx, parent, core.ChildrenQuery{})
+		require.NoError(t, err)
+		assert.Len(t, page.Refs, 6)
+		assert.Nil(t, page.Next)
+	})
+
+	t.Run("counts children", func(t *testing.T) {
+		count, err := am.CountChildren(ctx, parent, core.ChildrenQuery{Prototype: &protoA, Limit: 1})
+		require.NoError(t, err)
+		assert.Equal(t, 2, count)
+
+		count, err = am.CountChildren(ctx, parent, core.ChildrenQuery{})
+		require.NoError(t, err)
+		assert.Equal(t, 6, count)
+
+		count, err = am.CountChildren(ctx, parent, core.ChildrenQuery{Prototype: genRandomRef(0)})
+		require.NoError(t, err)
+		assert.Equal(t, 0, count)
+	})
+}
+
+func TestMessageHandler_HandleGetChildren_NotLocalChildren(t *testing.T) {
+	t.Parallel()
+	ctx, db, _, cleaner := getTestData(t)
+	defer cleaner()
+
+	pulse := core.GenesisPulse.PulseNumber
+	prototype := *genRandomRef(0)
+	setState := func(rec record.Record) core.RecordRef {
+		id, err := db.SetRecord(ctx, pulse, rec)
+		require.NoError(t, err)
+		err = db.SetObjectIndex(ctx, id, &index.ObjectLifeline{LatestState: id})
+		require.NoError(t, err)
+		return *genRefWithID(id)
+	}
+	matching := setState(&record.ObjectActivateRecord{
+		SideEffectRecord:  record.SideEffectRecord{Domain: domainRef},
+		ObjectStateRecord: record.ObjectStateRecord{Image: prototype},
+	})
+	deactivated := setState(&record.DeactivationRecord{SideEffectRecord: record.SideEffectRecord{Domain: domainRef}})
+	changed := setState(&record.ObjectActivateRecord{
+		SideEffectRecord:  record.SideEffectRecord{Domain: domainRef},
+		ObjectStateRecord: record.ObjectStateRecord{Image: *genRandomRef(0)},
+	})
+	// Lifeline of the child is stored on another node.
+	remote := *genRandomRef(0)
+
+	setChildren := func(children ...core.RecordRef) *core.RecordID {
+		var childPointer *core.RecordID
+		for _, child := range children {
+			var err error
+			childPointer, err = db.SetRecord(ctx, pulse, &record.ChildRecord{
+				Ref: child, Prototype: &prototype, PrevChild: childPointer,
+			})
+			require.NoError(t, err)
+		}
+		parentID := genRandomID(pulse)
+		err := db.SetObjectIndex(ctx, parentID, &index.ObjectLifeline{LatestState: parentID, ChildPointer: childPointer})
+		require.NoError(t, err)
+		return parentID
+	}
+
+	heavy := *genRandomRef(0)
+	handler := MessageHandler{
+		db:             db,
+		recent:         storage.NewRecentStorage(1),
+		JetCoordinator: &testJetCoordinator{heavy: heavy},
+		NodeNet:        nodenetwork.NewNodeKeeper(nodenetwork.NewNode(*genRandomRef(0), nil, nil, 0, "", "")),
+	}
+
+	t.Run("local children are filtered by local states", func(t *testing.T) {
+		parentID := setChildren(matching, deactivated, changed)
+		msg := &message.GetChildren{Parent: *genRefWithID(parentID), Amount: 10, Prototype: &prototype}
+		rep, err := handler.handleGetChildren(ctx, pulse, &message.Parcel{Msg: msg})
+		require.NoError(t, err)
+		assert.Equal(t, []core.RecordRef{matching}, rep.(*reply.Children).Refs)
+
+		msg.Count = true
+		rep, err = handler.handleGetChildren(ctx, pulse, &message.Parcel{Msg: msg})
+		require.NoError(t, err)
+		assert.Equal(t, 1, rep.(*reply.Children).Count)
+	})
+
+	t.Run("page with not local child is redirected to heavy", func(t *testing.T) {
+		parentID := setChildren(remote, matching)
+		msg := &message.GetChildren{Parent: *genRefWithID(parentID), Amount: 10, Prototype: &prototype}
+		rep, err := handler.handleGetChildren(ctx, pulse, &message.Parcel{Msg: msg})
+		require.NoError(t, err)
+		redirect, ok := rep.(*reply.GetChildrenRedirect)
+		require.True(t, ok)
+		assert.Equal(t, heavy, *redirect.To)
+	})
+}
+
+func TestLedgerArtifactManager_GetHistory(t *testing.T) {
+	t.Parallel()
+	ctx, db, am, cleaner := getTestData(t)
+	defer cleaner()
+
+	request1 := *genRandomRef(0)
+	request2 := *genRandomRef(0)
+	request3 := *genRandomRef(0)
+
+	pulse1 := core.GenesisPulse.PulseNumber + 1
+	pulse2 := core.GenesisPulse.PulseNumber + 2
+	pulse3 := core.GenesisPulse.PulseNumber + 3
+
+	memory1, err := db.SetBlob(ctx, pulse1, []byte{1})
+	require.NoError(t, err)
+	memory2, err := db.SetBlob(ctx, pulse2, []byte{2})
+	require.NoError(t, err)
+
+	activateID, err := db.SetRecord(ctx, pulse1, &record.ObjectActivateRecord{
+		SideEffectRecord:  record.SideEffectRecord{Domain: domainRef, Request: request1},
+		ObjectStateRecord: record.ObjectStateRecord{Memory: memory1},
+	})
+	require.NoError(t, err)
+	amendID, err := db.SetRecord(ctx, pulse2, &record.ObjectAmendRecord{
+		SideEffectRecord:  record.SideEffectRecord{Domain: domainRef, Request: request2},
+		ObjectStateRecord: record.ObjectStateRecord{Memory: memory2},
+		PrevState:         *activateID,
+	})
+	require.NoError(t, err)
+	deactivateID, err := db.SetRecord(ctx, pulse3, &record.DeactivationRecord{
+		SideEffectRecord: record.SideEffectRecord{Domain: domainRef, Request: request3},
+		PrevState:        *amendID,
+	})
+	require.NoError(t, err)
+
+	err = db.SetObjectIndex(ctx, activateID, &index.ObjectLifeline{
+		LatestState: deactivateID,
+		State:       record.StateDeactivation,
+	})
+	require.NoError(t, err)
+
+	expected := []core.ObjectHistoryEntry{
+		{State: *deactivateID, Pulse: pulse3, Request: request3, Deactivated: true},
+		{State: *amendID, Pulse: pulse2, Request: request2, Memory: []byte{2}},
+		{State: *activateID, Pulse: pulse1, Request: request1, Memory: []byte{1}},
+	}
+	collect := func(t *testing.T, i core.ObjectHistoryIterator) []core.ObjectHistoryEntry {
+		var entries []core.ObjectHistoryEntry
+		for i.HasNext() {
+			entry, err := i.Next()
+			require.NoError(t, err)
+			entries = append(entries, *entry)
+		}
+		_, err := i.Next()
+		assert.Error(t, err)
+		return entries
+	}
+
+	t.Run("returns full history without pulse", func(t *testing.T) {
+		i, err := am.GetHistory(ctx, *genRefWithID(activateID), nil)
+		require.NoError(t, err)
+		assert.Equal(t, expected, collect(t, i))
+	})
+
+	t.Run("returns history before pulse", func(t *testing.T) {
+		i, err := am.GetHistory(ctx, *genRefWithID(activateID), &pulse2)
+		require.NoError(t, err)
+		assert.Equal(t, expected[1:], collect(t, i))
+	})
+
+	t.Run("returns history in many chunks", func(t *testing.T) {
+		am.getHistoryChunkSize = 1
+		i, err := am.GetHistory(ctx, *genRefWithID(activateID), nil)
+		require.NoError(t, err)
+		assert.Equal(t, expected, collect(t, i))
+	})
+
+	t.Run("returns history before pulse in many chunks", func(t *testing.T) {
+		am.getHistoryChunkSize = 1
+		i, err := am.GetHistory(ctx, *genRefWithID(activateID), &pulse2)
+		require.NoError(t, err)
+		assert.Equal(t, expected[1:], collect(t, i))
+	})
+
+	t.Run("returns error on non-positive amount", func(t *testing.T) {
+		_, err := am.DefaultBus.Send(ctx, &message.GetHistory{Object: *genRefWithID(activateID)})
+		assert.Error(t, err)
+	})
+}
+
 func TestLedgerArtifactManager_HandleJetDrop(t *testing.T) {
 	t.Parallel()
 	ctx, db, am, cleaner := getTestData(t)
@@ -506,12 +1351,14 @@ func TestLedgerArtifactManager_HandleJetDrop(t *testing.T) {
 	setRecordMessage := message.SetRecord{
 		Record: record.SerializeRecord(&codeRecord),
 	}
+	setRecordBytes, err := message.ToBytes(&setRecordMessage)
+	require.NoError(t, err)
 
 	rep, err := am.DefaultBus.Send(
 		ctx,
 		&message.JetDrop{
 			Messages: [][]byte{
-				message.ToBytes(&setRecordMessage),
+				setRecordBytes,
 			},
 			PulseNumber: core.GenesisPulse.PulseNumber,
 		},
@@ -545,11 +1392,11 @@ func TestLedgerArtifactManager_RegisterValidation(t *testing.T) {
 	assert.NoError(t, err)
 	stateID1 := desc.StateID()
 
-	desc, err = am.GetObject(ctx, *objRef, nil, false)
+	desc, err = am.GetObject(ctx, *objRef, nil, nil, false)
 	assert.NoError(t, err)
 	assert.Equal(t, *stateID1, *desc.StateID())
 
-	_, err = am.GetObject(ctx, *objRef, nil, true)
+	_, err = am.GetObject(ctx, *objRef, nil, nil, true)
 	assert.Equal(t, err, core.ErrStateNotAvailable)
 
 	desc, err = am.UpdateObject(
@@ -562,7 +1409,7 @@ func TestLedgerArtifactManager_RegisterValidation(t *testing.T) {
 	assert.NoError(t, err)
 	stateID2 := desc.StateID()
 
-	desc, err = am.GetObject(ctx, *objRef, nil, false)
+	desc, err = am.GetObject(ctx, *objRef, nil, nil, false)
 	assert.NoError(t, err)
 	desc, err = am.UpdateObject(
 		ctx,
@@ -576,14 +1423,78 @@ func TestLedgerArtifactManager_RegisterValidation(t *testing.T) {
 	err = am.RegisterValidation(ctx, *objRef, *stateID2, true, nil)
 	assert.NoError(t, err)
 
-	desc, err = am.GetObject(ctx, *objRef, nil, false)
+	desc, err = am.GetObject(ctx, *objRef, nil, nil, false)
 	assert.NoError(t, err)
 	assert.Equal(t, *stateID3, *desc.StateID())
-	desc, err = am.GetObject(ctx, *objRef, nil, true)
+	desc, err = am.GetObject(ctx, *objRef, nil, nil, true)
 	assert.NoError(t, err)
 	assert.Equal(t, *stateID2, *desc.StateID())
 }
 
+func TestLedgerArtifactManager_GetObjectStatus(t *testing.T) {
+	t.Parallel()
+	ctx, _, am, cleaner := getTestData(t)
+	defer cleaner()
+
+	objRef := genRandomRef(0)
+	activated, err := am.ActivateObject(ctx, domainRef, *objRef, *am.GenesisRef(), *genRandomRef(0), false, []byte{1})
+	require.NoError(t, err)
+
+	status, err := am.GetObjectStatus(ctx, *objRef)
+	require.NoError(t, err)
+	assert.Equal(t, activated.StateID(), status.LatestState)
+	assert.Nil(t, status.LatestStateApproved)
+	assert.True(t, status.Pending)
+	assert.Empty(t, status.Validations)
+
+	err = am.RegisterValidation(ctx, *objRef, *activated.StateID(), true, nil)
+	require.NoError(t, err)
+	status, err = am.GetObjectStatus(ctx, *objRef)
+	require.NoError(t, err)
+	assert.Equal(t, activated.StateID(), status.LatestStateApproved)
+	assert.False(t, status.Pending)
+
+	rejected, err := am.UpdateObject(ctx, domainRef, *genRandomRef(0), activated, []byte{2})
+	require.NoError(t, err)
+	err = am.RegisterValidation(ctx, *objRef, *rejected.StateID(), false, nil)
+	require.NoError(t, err)
+
+	status, err = am.GetObjectStatus(ctx, *objRef)
+	require.NoError(t, err)
+	assert.Equal(t, activated.StateID(), status.LatestState)
+	assert.False(t, status.Pending)
+	require.Len(t, status.Validations, 2)
+	assert.Equal(t, *activated.StateID(), status.Validations[0].State)
+	assert.True(t, status.Validations[0].IsValid)
+	assert.Equal(t, *rejected.StateID(), status.Validations[1].State)
+	assert.False(t, status.Validations[1].IsValid)
+
+	// New states can not be based on rejected one.
+	_, err = am.UpdateObject(ctx, domainRef, *genRandomRef(0), rejected, []byte{3})
+	assert.Equal(t, core.ErrStateRejected, err)
+
+	updated, err := am.UpdateObject(ctx, domainRef, *genRandomRef(0), activated, []byte{3})
+	require.NoError(t, err)
+	status, err = am.GetObjectStatus(ctx, *objRef)
+	require.NoError(t, err)
+	assert.Equal(t, updated.StateID(), status.LatestState)
+	assert.True(t, status.Pending)
+
+	// Rejected deactivation is rolled back, so the object is active again.
+	deactivated, err := am.DeactivateObject(ctx, domainRef, *genRandomRef(0), updated)
+	require.NoError(t, err)
+	err = am.RegisterValidation(ctx, *objRef, *deactivated, false, nil)
+	require.NoError(t, err)
+	desc, err := am.GetObject(ctx, *objRef, nil, nil, false)
+	require.NoError(t, err)
+	assert.Equal(t, updated.StateID(), desc.StateID())
+	_, err = am.UpdateObject(ctx, domainRef, *genRandomRef(0), desc, []byte{4})
+	require.NoError(t, err)
+
+	_, err = am.GetObjectStatus(ctx, *genRandomRef(0))
+	assert.Error(t, err)
+}
+
 func TestLedgerArtifactManager_RegisterResult(t *testing.T) {
 	t.Parallel()
 	ctx, db, am, cleaner := getTestData(t)
@@ -597,3 +1508,121 @@ func TestLedgerArtifactManager_RegisterResult(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, record.ResultRecord{Request: *request, Payload: []byte{1, 2, 3}}, *rec.(*record.ResultRecord))
 }
+
+func TestLedgerArtifactManager_GetRecordProof(t *testing.T) {
+	t.Parallel()
+	ctx, db, am, cleaner := getTestData(t)
+	defer cleaner()
+
+	pulse := core.GenesisPulse.PulseNumber + 1
+	var ids []core.RecordID
+	for i := 0; i < 5; i++ {
+		id, err := db.SetRecord(ctx, pulse, &record.CodeRecord{Code: genRandomID(pulse)})
+		require.NoError(t, err)
+		ids = append(ids, *id)
+	}
+
+	// Drop for the pulse is not created yet.
+	_, err := am.GetRecordProof(ctx, ids[0])
+	assert.Error(t, err)
+
+	drop, _, err := db.CreateDrop(ctx, pulse, []byte{1, 2, 3})
+	require.NoError(t, err)
+	err = db.SetDrop(ctx, drop)
+	require.NoError(t, err)
+
+	hasher := am.PlatformCryptographyScheme.ReferenceHasher()
+	for _, id := range ids {
+		proof, err := am.GetRecordProof(ctx, id)
+		require.NoError(t, err)
+		assert.Equal(t, id, proof.Record)
+		assert.Equal(t, drop.Hash, proof.DropHash)
+		assert.True(t, jetdrop.VerifyProof(hasher, proof))
+	}
+
+	_, err = am.GetRecordProof(ctx, *genRandomID(pulse))
+	assert.Error(t, err)
+}
+
+func TestLedgerArtifactManager_GetObjectsByPrototype(t *testing.T) {
+	t.Parallel()
+	ctx, db, am, cleaner := getTestData(t)
+	defer cleaner()
+
+	newParent := func() core.RecordRef {
+		parentID, err := db.SetRecord(ctx, core.GenesisPulse.PulseNumber, &record.ObjectActivateRecord{
+			SideEffectRecord: record.SideEffectRecord{Domain: *genRandomRef(0)},
+		})
+		require.NoError(t, err)
+		err = db.SetObjectIndex(ctx, parentID, &index.ObjectLifeline{LatestState: parentID})
+		require.NoError(t, err)
+		return *genRefWithID(parentID)
+	}
+	parentA, parentB := newParent(), newParent()
+	prototype := *genRandomRef(0)
+
+	var objects []core.ObjectDescriptor
+	for i := 0; i < 4; i++ {
+		parent := parentA
+		if i%2 == 1 {
+			parent = parentB
+		}
+		obj, err := am.ActivateObject(ctx, domainRef, *genRandomRef(0), parent, prototype, false, []byte{byte(i)})
+		require.NoError(t, err)
+		objects = append(objects, obj)
+	}
+	// Objects of other prototypes are not indexed together.
+	_, err := am.ActivateObject(ctx, domainRef, *genRandomRef(0), parentA, *genRandomRef(0), false, nil)
+	require.NoError(t, err)
+
+	activated := core.GenesisPulse.PulseNumber
+	deactivated := activated + 1
+	err = db.AddPulse(ctx, core.Pulse{PulseNumber: deactivated})
+	require.NoError(t, err)
+	_, err = am.DeactivateObject(ctx, domainRef, *genRandomRef(0), objects[0])
+	require.NoError(t, err)
+
+	collect := func(query core.ObjectsQuery) ([]core.RecordRef, int) {
+		var (
+			heads []core.RecordRef
+			pages int
+		)
+		for {
+			page, err := am.GetObjectsByPrototype(ctx, query)
+			require.NoError(t, err)
+			heads = append(heads, page.Heads...)
+			pages++
+			if page.Next == nil {
+				return heads, pages
+			}
+			query.After = page.Next
+		}
+	}
+	refs := func(objs ...core.ObjectDescriptor) []core.RecordRef {
+		var res []core.RecordRef
+		for _, obj := range objs {
+			res = append(res, *obj.HeadRef())
+		}
+		return res
+	}
+
+	t.Run("returns active objects in pages", func(t *testing.T) {
+		heads, pages := collect(core.ObjectsQuery{Prototype: prototype, Limit: 1})
+		assert.ElementsMatch(t, refs(objects[1:]...), heads)
+		assert.Equal(t, 3, pages)
+	})
+
+	t.Run("filters by pulse", func(t *testing.T) {
+		heads, _ := collect(core.ObjectsQuery{Prototype: prototype, Pulse: &activated})
+		assert.ElementsMatch(t, refs(objects...), heads)
+		heads, _ = collect(core.ObjectsQuery{Prototype: prototype, Pulse: &deactivated})
+		assert.ElementsMatch(t, refs(objects[1:]...), heads)
+	})
+
+	t.Run("filters by parent", func(t *testing.T) {
+		heads, _ := collect(core.ObjectsQuery{Prototype: prototype, Parent: &parentA, Pulse: &activated})
+		assert.ElementsMatch(t, refs(objects[0], objects[2]), heads)
+		heads, _ = collect(core.ObjectsQuery{Prototype: prototype, Parent: &parentB})
+		assert.ElementsMatch(t, refs(objects[1], objects[3]), heads)
+	})
+}
@@ -0,0 +1,264 @@
+/*
+ *    Copyright 2018 Insolar
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ */
+
+package artifactmanager
+
+import (
+	"container/list"
+	"context"
+	"sync"
+
+	"go.opencensus.io/stats"
+
+	"github.com/insolar/insolar/core"
+	"github.com/insolar/insolar/core/reply"
+	"github.com/insolar/insolar/instrumentation/insmetrics"
+)
+
+const (
+	cacheCode   = "code"
+	cacheObject = "object"
+)
+
+// descriptorCache keeps ledger replies for code and object requests on the client side.
+//
+// Code records never change, so code is kept until evicted by the size limit. Object states are valid only within
+// the pulse they were fetched in and are dropped on pulse change or when the object is changed through this client.
+// Replies fetched before pulse change or before object drop are not cached, as they could be already stale.
+//
+// Replies are copied when cached and when returned, so descriptors built from them don't share memory.
+type descriptorCache struct {
+	lock    sync.Mutex
+	code    *lru
+	objects *lru
+	// pulse is the current pulse, cached objects are valid only in it.
+	pulse core.PulseNumber
+	// drops counts object drops to detect replies fetched before a drop.
+	drops uint64
+}
+
+// objectFetch identifies cache state at the moment object request is sent.
+type objectFetch struct {
+	pulse core.PulseNumber
+	drops uint64
+}
+
+// cachedObject is an object reply with the pulse it was fetched in.
+type cachedObject struct {
+	pulse core.PulseNumber
+	obj   *reply.Object
+}
+
+// objectCacheKey identifies object request for the same object head.
+type objectCacheKey struct {
+	state    core.RecordID
+	pulse    core.PulseNumber
+	approved bool
+}
+
+func newDescriptorCache(codeSize, objectSize int) *descriptorCache {
+	return &descriptorCache{
+		code:    newLRU(codeSize),
+		objects: newLRU(objectSize),
+	}
+}
+
+func newObjectCacheKey(state *core.RecordID, pulse *core.PulseNumber, approved bool) objectCacheKey {
+	key := objectCacheKey{approved: approved}
+	if state != nil {
+		key.state = *state
+	}
+	if pulse != nil {
+		key.pulse = *pulse
+	}
+	return key
+}
+
+func (c *descriptorCache) getCode(ctx context.Context, ref core.RecordRef) *reply.Code {
+	if !c.code.enabled() {
+		return nil
+	}
+	c.lock.Lock()
+	value, ok := c.code.get(ref)
+	c.lock.Unlock()
+
+	recordCacheAccess(ctx, cacheCode, ok)
+	if !ok {
+		return nil
+	}
+	return copyCode(value.(*reply.Code))
+}
+
+func (c *descriptorCache) setCode(ref core.RecordRef, code *reply.Code) {
+	if !c.code.enabled() {
+		return
+	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.code.add(ref, copyCode(code))
+}
+
+func (c *descriptorCache) getObject(ctx context.Context, head core.RecordRef, key objectCacheKey) *reply.Object {
+	if !c.objects.enabled() {
+		return nil
+	}
+	var obj *reply.Object
+	c.lock.Lock()
+	if states, ok := c.objects.get(head); ok {
+		cached, ok := states.(map[objectCacheKey]cachedObject)[key]
+		if ok && cached.pulse == c.pulse {
+			obj = copyObject(cached.obj)
+		} else if ok {
+			delete(states.(map[objectCacheKey]cachedObject), key)
+		}
+	}
+	c.lock.Unlock()
+
+	recordCacheAccess(ctx, cacheObject, obj != nil)
+	return obj
+}
+
+// startFetch should be called before object request is sent. Its result is passed to setObject with the reply.
+func (c *descriptorCache) startFetch() objectFetch {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return objectFetch{pulse: c.pulse, drops: c.drops}
+}
+
+// setObject caches object reply if neither pulse was changed nor objects were dropped since fetch was started.
+func (c *descriptorCache) setObject(head core.RecordRef, key objectCacheKey, obj *reply.Object, fetch objectFetch) {
+	if !c.objects.enabled() {
+		return
+	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if fetch.pulse != c.pulse || fetch.drops != c.drops {
+		return
+	}
+	states, ok := c.objects.get(head)
+	if !ok {
+		states = map[objectCacheKey]cachedObject{}
+		c.objects.add(head, states)
+	}
+	states.(map[objectCacheKey]cachedObject)[key] = cachedObject{pulse: fetch.pulse, obj: copyObject(obj)}
+}
+
+// dropObject removes all cached states of the object. Called before and after the object is changed.
+func (c *descriptorCache) dropObject(head core.RecordRef) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.drops++
+	c.objects.remove(head)
+}
+
+// resetPulse removes all cached objects. Called on pulse change.
+func (c *descriptorCache) resetPulse(pulse core.PulseNumber) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.pulse = pulse
+	c.objects.reset()
+}
+
+func copyCode(code *reply.Code) *reply.Code {
+	cp := *code
+	cp.Code = append([]byte(nil), code.Code...)
+	return &cp
+}
+
+func copyObject(obj *reply.Object) *reply.Object {
+	cp := *obj
+	if obj.Prototype != nil {
+		prototype := *obj.Prototype
+		cp.Prototype = &prototype
+	}
+	if obj.ChildPointer != nil {
+		childPointer := *obj.ChildPointer
+		cp.ChildPointer = &childPointer
+	}
+	cp.Memory = append([]byte(nil), obj.Memory...)
+	return &cp
+}
+
+func recordCacheAccess(ctx context.Context, cache string, hit bool) {
+	ctx = insmetrics.InsertTag(ctx, tagCache, cache)
+	if hit {
+		stats.Record(ctx, statCacheHits.M(1))
+	} else {
+		stats.Record(ctx, statCacheMisses.M(1))
+	}
+}
+
+// lru is a size limited cache evicting least recently used items. It is not thread safe.
+type lru struct {
+	limit int
+	items map[interface{}]*list.Element
+	order *list.List
+}
+
+type lruItem struct {
+	key   interface{}
+	value interface{}
+}
+
+func newLRU(limit int) *lru {
+	return &lru{
+		limit: limit,
+		items: map[interface{}]*list.Element{},
+		order: list.New(),
+	}
+}
+
+func (c *lru) enabled() bool {
+	return c.limit > 0
+}
+
+func (c *lru) get(key interface{}) (interface{}, bool) {
+	el, ok := c.items[key]
+	if !ok {
+		return nil, false
+	}
+	c.order.MoveToFront(el)
+	return el.Value.(*lruItem).value, true
+}
+
+func (c *lru) add(key, value interface{}) {
+	if !c.enabled() {
+		return
+	}
+	if el, ok := c.items[key]; ok {
+		el.Value.(*lruItem).value = value
+		c.order.MoveToFront(el)
+		return
+	}
+	c.items[key] = c.order.PushFront(&lruItem{key: key, value: value})
+	for c.order.Len() > c.limit {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.items, oldest.Value.(*lruItem).key)
+	}
+}
+
+func (c *lru) remove(key interface{}) {
+	if el, ok := c.items[key]; ok {
+		c.order.Remove(el)
+		delete(c.items, key)
+	}
+}
+
+func (c *lru) reset() {
+	c.items = map[interface{}]*list.Element{}
+	c.order.Init()
+}
@@ -180,8 +180,8 @@ func (i *ChildIterator) HasNext() bool {
 
 // Next returns next element.
 func (i *ChildIterator) Next() (*core.RecordRef, error) {
-	// Get element from buffer.
-	if !i.hasInBuffer() && i.canFetch {
+	// Get element from buffer. Fetched chunk can be empty, so fetching until there is an element or nothing to fetch.
+	for !i.hasInBuffer() && i.canFetch {
 		err := i.fetch()
 		if err != nil {
 			return nil, err
@@ -209,22 +209,15 @@ func (i *ChildIterator) fetch() error {
 	if !i.canFetch {
 		return errors.New("failed to fetch record")
 	}
-	genericReply, err := i.messageBus.Send(
-		i.ctx,
-		&message.GetChildren{
-			Parent:    i.parent,
-			FromPulse: i.fromPulse,
-			FromChild: i.fromChild,
-			Amount:    i.chunkSize,
-		},
-	)
+	rep, err := fetchChildren(i.ctx, i.messageBus, &message.GetChildren{
+		Parent:    i.parent,
+		FromPulse: i.fromPulse,
+		FromChild: i.fromChild,
+		Amount:    i.chunkSize,
+	})
 	if err != nil {
 		return err
 	}
-	rep, ok := genericReply.(*reply.Children)
-	if !ok {
-		return errors.New("failed to fetch record")
-	}
 
 	if rep.NextFrom == nil {
 		i.canFetch = false
@@ -239,3 +232,115 @@ func (i *ChildIterator) fetch() error {
 func (i *ChildIterator) hasInBuffer() bool {
 	return i.buffIndex < len(i.buff)
 }
+
+// fetchChildren sends children request. Redirects to heavy node are followed by MessageBus.
+func fetchChildren(ctx context.Context, mb core.MessageBus, msg *message.GetChildren) (*reply.Children, error) {
+	genericReply, err := mb.Send(ctx, msg)
+	if err != nil {
+		return nil, err
+	}
+	rep, ok := genericReply.(*reply.Children)
+	if !ok {
+		return nil, errors.New("failed to fetch record")
+	}
+	return rep, nil
+}
+
+// HistoryIterator is used to iterate over object state history.
+//
+// During iteration states will be fetched from remote source (object's executor).
+type HistoryIterator struct {
+	ctx        context.Context
+	messageBus core.MessageBus
+	head       core.RecordRef
+	chunkSize  int
+	fromPulse  *core.PulseNumber
+	fromState  *core.RecordID
+	buff       []core.ObjectHistoryEntry
+	buffIndex  int
+	canFetch   bool
+}
+
+// NewHistoryIterator creates new object state history iterator.
+func NewHistoryIterator(
+	ctx context.Context,
+	mb core.MessageBus,
+	head core.RecordRef,
+	fromPulse *core.PulseNumber,
+	chunkSize int,
+) (*HistoryIterator, error) {
+	iter := HistoryIterator{
+		ctx:        ctx,
+		messageBus: mb,
+		head:       head,
+		fromPulse:  fromPulse,
+		chunkSize:  chunkSize,
+		canFetch:   true,
+	}
+	err := iter.fetch()
+	if err != nil {
+		return nil, err
+	}
+	return &iter, nil
+}
+
+// HasNext checks if any elements left in iterator.
+func (i *HistoryIterator) HasNext() bool {
+	return i.hasInBuffer() || i.canFetch
+}
+
+// Next returns next element.
+func (i *HistoryIterator) Next() (*core.ObjectHistoryEntry, error) {
+	// Get element from buffer.
+	if !i.hasInBuffer() && i.canFetch {
+		err := i.fetch()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if !i.hasInBuffer() {
+		return nil, errors.New("failed to fetch state")
+	}
+	entry := i.buff[i.buffIndex]
+	i.buffIndex++
+
+	return &entry, nil
+}
+
+func (i *HistoryIterator) fetch() error {
+	if !i.canFetch {
+		return errors.New("failed to fetch state")
+	}
+	genericReply, err := i.messageBus.Send(
+		i.ctx,
+		&message.GetHistory{
+			Object:    i.head,
+			FromPulse: i.fromPulse,
+			FromState: i.fromState,
+			Amount:    i.chunkSize,
+		},
+	)
+	if err != nil {
+		return err
+	}
+	switch rep := genericReply.(type) {
+	case *reply.History:
+		if rep.NextFrom == nil {
+			i.canFetch = false
+		}
+		i.buff = rep.States
+		i.buffIndex = 0
+		i.fromState = rep.NextFrom
+	case *reply.Error:
+		return rep.Error()
+	default:
+		return ErrUnexpectedReply
+	}
+
+	return nil
+}
+
+func (i *HistoryIterator) hasInBuffer() bool {
+	return i.buffIndex < len(i.buff)
+}
@@ -27,4 +27,5 @@ var (
 	ErrNotFound          = errors.New("object not found")
 	ErrUnexpectedReply   = errors.New("unexpected reply")
 	ErrStateNotAvailable = errors.New("object state is not available")
+	ErrStateRejected     = errors.New("object state is rejected by validation")
 )
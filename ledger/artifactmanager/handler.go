@@ -39,11 +39,13 @@ type MessageHandler struct {
 	db                         *storage.DB
 	jetDropHandlers            map[core.MessageType]internalHandler
 	recent                     *storage.RecentStorage
+	heavySync                  heavySyncLocks
 	Bus                        core.MessageBus                 `inject:""`
 	PlatformCryptographyScheme core.PlatformCryptographyScheme `inject:""`
 	JetCoordinator             core.JetCoordinator             `inject:""`
 	CryptographyService        core.CryptographyService        `inject:""`
 	DelegationTokenFactory     core.DelegationTokenFactory     `inject:""`
+	NodeNet                    core.NodeNetwork                `inject:""`
 }
 
 // NewMessageHandler creates new handler.
@@ -57,16 +59,39 @@ func NewMessageHandler(db *storage.DB, recentObjects *storage.RecentStorage) *Me
 
 // Init initializes handlers.
 func (h *MessageHandler) Init(ctx context.Context) error {
-	h.Bus.MustRegister(core.TypeGetCode, h.messagePersistingWrapper(h.handleGetCode))
-	h.Bus.MustRegister(core.TypeGetObject, h.messagePersistingWrapper(h.handleGetObject))
-	h.Bus.MustRegister(core.TypeGetDelegate, h.messagePersistingWrapper(h.handleGetDelegate))
-	h.Bus.MustRegister(core.TypeGetChildren, h.messagePersistingWrapper(h.handleGetChildren))
-	h.Bus.MustRegister(core.TypeUpdateObject, h.messagePersistingWrapper(h.handleUpdateObject))
-	h.Bus.MustRegister(core.TypeRegisterChild, h.messagePersistingWrapper(h.handleRegisterChild))
+	h.Bus.AddDeliverMiddleware(
+		h.messagePersistingMiddleware,
+		core.TypeGetCode,
+		core.TypeGetObject,
+		core.TypeGetDelegate,
+		core.TypeGetChildren,
+		core.TypeGetHistory,
+		core.TypeGetRecordProof,
+		core.TypeGetObjectsByPrototype,
+		core.TypeGetObjects,
+		core.TypeGetObjectStatus,
+		core.TypeUpdateObject,
+		core.TypeRegisterChild,
+		core.TypeSetRecord,
+		core.TypeSetBlob,
+		core.TypeValidateRecord,
+	)
+
+	h.Bus.MustRegister(core.TypeGetCode, h.latestPulseWrapper(h.handleGetCode))
+	h.Bus.MustRegister(core.TypeGetObject, h.latestPulseWrapper(h.handleGetObject))
+	h.Bus.MustRegister(core.TypeGetDelegate, h.latestPulseWrapper(h.handleGetDelegate))
+	h.Bus.MustRegister(core.TypeGetChildren, h.latestPulseWrapper(h.handleGetChildren))
+	h.Bus.MustRegister(core.TypeGetHistory, h.latestPulseWrapper(h.handleGetHistory))
+	h.Bus.MustRegister(core.TypeGetRecordProof, h.latestPulseWrapper(h.handleGetRecordProof))
+	h.Bus.MustRegister(core.TypeGetObjectsByPrototype, h.latestPulseWrapper(h.handleGetObjectsByPrototype))
+	h.Bus.MustRegister(core.TypeGetObjects, h.latestPulseWrapper(h.handleGetObjects))
+	h.Bus.MustRegister(core.TypeGetObjectStatus, h.latestPulseWrapper(h.handleGetObjectStatus))
+	h.Bus.MustRegister(core.TypeUpdateObject, h.latestPulseWrapper(h.handleUpdateObject))
+	h.Bus.MustRegister(core.TypeRegisterChild, h.latestPulseWrapper(h.handleRegisterChild))
 	h.Bus.MustRegister(core.TypeJetDrop, h.handleJetDrop)
-	h.Bus.MustRegister(core.TypeSetRecord, h.messagePersistingWrapper(h.handleSetRecord))
-	h.Bus.MustRegister(core.TypeSetBlob, h.messagePersistingWrapper(h.handleSetBlob))
-	h.Bus.MustRegister(core.TypeValidateRecord, h.messagePersistingWrapper(h.handleValidateRecord))
+	h.Bus.MustRegister(core.TypeSetRecord, h.latestPulseWrapper(h.handleSetRecord))
+	h.Bus.MustRegister(core.TypeSetBlob, h.latestPulseWrapper(h.handleSetBlob))
+	h.Bus.MustRegister(core.TypeValidateRecord, h.latestPulseWrapper(h.handleValidateRecord))
 
 	h.Bus.MustRegister(core.TypeHeavyStartStop, h.handleHeavyStartStop)
 	h.Bus.MustRegister(core.TypeHeavyPayload, h.handleHeavyPayload)
@@ -75,6 +100,11 @@ func (h *MessageHandler) Init(ctx context.Context) error {
 	h.jetDropHandlers[core.TypeGetObject] = h.handleGetObject
 	h.jetDropHandlers[core.TypeGetDelegate] = h.handleGetDelegate
 	h.jetDropHandlers[core.TypeGetChildren] = h.handleGetChildren
+	h.jetDropHandlers[core.TypeGetHistory] = h.handleGetHistory
+	h.jetDropHandlers[core.TypeGetRecordProof] = h.handleGetRecordProof
+	h.jetDropHandlers[core.TypeGetObjectsByPrototype] = h.handleGetObjectsByPrototype
+	h.jetDropHandlers[core.TypeGetObjects] = h.handleGetObjects
+	h.jetDropHandlers[core.TypeGetObjectStatus] = h.handleGetObjectStatus
 	h.jetDropHandlers[core.TypeUpdateObject] = h.handleUpdateObject
 	h.jetDropHandlers[core.TypeRegisterChild] = h.handleRegisterChild
 	h.jetDropHandlers[core.TypeSetRecord] = h.handleSetRecord
@@ -83,13 +113,20 @@ func (h *MessageHandler) Init(ctx context.Context) error {
 	return nil
 }
 
-func (h *MessageHandler) messagePersistingWrapper(handler internalHandler) core.MessageHandler {
+// messagePersistingMiddleware stores received messages, so they are included in the jet drop.
+func (h *MessageHandler) messagePersistingMiddleware(next core.MessageHandler) core.MessageHandler {
 	return func(ctx context.Context, genericMsg core.Parcel) (core.Reply, error) {
 		err := persistMessageToDb(ctx, h.db, genericMsg.Message())
 		if err != nil {
 			return nil, err
 		}
+		return next(ctx, genericMsg)
+	}
+}
 
+// latestPulseWrapper calls handler with the latest stored pulse number.
+func (h *MessageHandler) latestPulseWrapper(handler internalHandler) core.MessageHandler {
+	return func(ctx context.Context, genericMsg core.Parcel) (core.Reply, error) {
 		lastPulseNumber, err := h.db.GetLatestPulseNumber(ctx)
 		if err != nil {
 			return nil, err
@@ -144,10 +181,16 @@ func (h *MessageHandler) handleGetCode(ctx context.Context, pulseNumber core.Pul
 
 	codeRec, err := getCode(ctx, h.db, msg.Code.Record())
 	if err != nil {
+		if errors.Cause(err) == storage.ErrNotFound {
+			return h.codeRedirect(ctx, msg, msg.Code.Record().Pulse(), pulseNumber, err)
+		}
 		return nil, err
 	}
 	code, err := h.db.GetBlob(ctx, codeRec.Code)
 	if err != nil {
+		if err == storage.ErrNotFound {
+			return h.codeRedirect(ctx, msg, codeRec.Code.Pulse(), pulseNumber, err)
+		}
 		return nil, err
 	}
 
@@ -159,7 +202,56 @@ func (h *MessageHandler) handleGetCode(ctx context.Context, pulseNumber core.Pul
 	return &rep, nil
 }
 
-func (h *MessageHandler) createRedirect(ctx context.Context, genericMsg core.Parcel, msg *message.GetObject, definedState *core.RecordID) (*reply.GetObjectRedirectReply, error) {
+// heavyRedirect returns heavy material node if data of provided pulse is pruned on this node.
+// Returns nil if the data is not pruned.
+func (h *MessageHandler) heavyRedirect(
+	ctx context.Context, target *core.RecordRef, dataPulse, currentPulse core.PulseNumber,
+) (*core.RecordRef, error) {
+	pruned, err := h.db.IsPruned(ctx, dataPulse)
+	if err != nil || !pruned {
+		return nil, err
+	}
+	return h.heavyNode(ctx, target, currentPulse)
+}
+
+// heavyNode returns heavy material node of the current pulse.
+func (h *MessageHandler) heavyNode(
+	ctx context.Context, target *core.RecordRef, currentPulse core.PulseNumber,
+) (*core.RecordRef, error) {
+	nodes, err := h.JetCoordinator.QueryRole(ctx, core.RoleHeavyExecutor, target, currentPulse)
+	if err != nil {
+		return nil, err
+	}
+	if len(nodes) != 1 {
+		return nil, errors.New("failed to find heavy material node")
+	}
+	return &nodes[0], nil
+}
+
+// codeRedirect redirects code request to heavy material node if code is pruned, otherwise returns provided error.
+func (h *MessageHandler) codeRedirect(
+	ctx context.Context, msg *message.GetCode, dataPulse, currentPulse core.PulseNumber, notFound error,
+) (core.Reply, error) {
+	heavy, err := h.heavyRedirect(ctx, &msg.Code, dataPulse, currentPulse)
+	if err != nil {
+		return nil, err
+	}
+	if heavy == nil {
+		return nil, notFound
+	}
+	return reply.NewGetCodeRedirect(heavy), nil
+}
+
+func (h *MessageHandler) createRedirect(ctx context.Context, genericMsg core.Parcel, msg *message.GetObject, definedState *core.RecordID, currentPulse core.PulseNumber) (*reply.GetObjectRedirectReply, error) {
+	heavy, err := h.heavyRedirect(ctx, &msg.Head, definedState.Pulse(), currentPulse)
+	if err != nil {
+		return nil, err
+	}
+	if heavy != nil {
+		// Heavy material node serves pruned data without delegation.
+		return reply.NewGetObjectRedirectReply(heavy, definedState), nil
+	}
+
 	// here we need find node by pulse
 	redirect, err := h.prepareRedirect(ctx, msg, definedState, definedState.Pulse())
 	if err != nil {
@@ -183,6 +275,9 @@ func (h *MessageHandler) prepareRedirect(ctx context.Context, msg *message.GetOb
 	if err != nil {
 		return nil, err
 	}
+	if len(nodes) == 0 {
+		return nil, errors.New("failed to find executor")
+	}
 	if len(nodes) > 1 {
 		return nil, errors.New("found more than one executer")
 	}
@@ -191,24 +286,44 @@ func (h *MessageHandler) prepareRedirect(ctx context.Context, msg *message.GetOb
 }
 
 func (h *MessageHandler) handleGetObject(ctx context.Context, pulseNumber core.PulseNumber, parcel core.Parcel) (core.Reply, error) {
-	msg := parcel.Message().(*message.GetObject)
+	return h.getObject(ctx, pulseNumber, parcel, parcel.Message().(*message.GetObject))
+}
 
+func (h *MessageHandler) getObject(
+	ctx context.Context, pulseNumber core.PulseNumber, parcel core.Parcel, msg *message.GetObject,
+) (core.Reply, error) {
 	idx, err := h.db.GetObjectIndex(ctx, msg.Head.Record(), false)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to fetch object index")
 	}
-	stateID, state, err := getObjectState(ctx, h.db, idx, msg.State, msg.Approved)
+	requestedState := msg.State
+	if msg.Pulse != nil {
+		requestedState, err = getStateByPulse(ctx, h.db, idx, msg.State, *msg.Pulse, msg.Approved)
+		if err != nil {
+			switch err {
+			case ErrStateNotAvailable:
+				return &reply.Error{ErrType: reply.ErrStateNotAvailable}, nil
+			case storage.ErrNotFound:
+				// Older states are not stored on this node.
+				return h.createRedirect(ctx, parcel, msg, requestedState, pulseNumber)
+			default:
+				return nil, err
+			}
+		}
+	}
+
+	stateID, state, err := getObjectState(ctx, h.db, idx, requestedState, msg.Approved)
 	if err != nil {
 		switch err {
 		case ErrObjectDeactivated:
 			return &reply.Error{ErrType: reply.ErrDeactivated}, nil
 		case ErrStateNotAvailable:
 			return &reply.Error{ErrType: reply.ErrStateNotAvailable}, nil
-		case ErrNotFound:
+		case ErrNotFound, storage.ErrNotFound:
 			if stateID == nil {
 				return nil, err
 			}
-			return h.createRedirect(ctx, parcel, msg, stateID)
+			return h.createRedirect(ctx, parcel, msg, stateID, pulseNumber)
 		default:
 			return nil, err
 		}
@@ -230,6 +345,9 @@ func (h *MessageHandler) handleGetObject(ctx context.Context, pulseNumber core.P
 
 	if state.GetMemory() != nil {
 		rep.Memory, err = h.db.GetBlob(ctx, state.GetMemory())
+		if err == storage.ErrNotFound {
+			return h.createRedirect(ctx, parcel, msg, stateID, pulseNumber)
+		}
 		if err != nil {
 			return nil, err
 		}
@@ -238,6 +356,77 @@ func (h *MessageHandler) handleGetObject(ctx context.Context, pulseNumber core.P
 	return &rep, nil
 }
 
+func (h *MessageHandler) handleGetObjects(ctx context.Context, pulseNumber core.PulseNumber, parcel core.Parcel) (core.Reply, error) {
+	msg := parcel.Message().(*message.GetObjects)
+
+	replies := make([]core.Reply, len(msg.Objects))
+	// redirected items grouped by destination node
+	redirects := map[core.RecordRef][]int{}
+	for i, obj := range msg.Objects {
+		getObject := &message.GetObject{Head: obj.Head, State: obj.State, Approved: msg.Approved}
+		rep, err := h.getObject(ctx, pulseNumber, parcel, getObject)
+		if errors.Cause(err) == storage.ErrNotFound {
+			if msg.Redirected {
+				// Forwarded objects are not redirected again, so the object is missing only for this item.
+				replies[i] = &reply.Error{ErrType: reply.ErrObjectNotFound}
+				continue
+			}
+			// Object is stored on another node.
+			rep, err = h.prepareRedirect(ctx, getObject, obj.State, pulseNumber)
+		}
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to fetch object %v", obj.Head)
+		}
+		replies[i] = rep
+		if redirect, ok := rep.(*reply.GetObjectRedirectReply); ok && !msg.Redirected {
+			redirects[*redirect.To] = append(redirects[*redirect.To], i)
+		}
+	}
+
+	for node, items := range redirects {
+		h.forwardGetObjects(ctx, node, msg, items, replies)
+	}
+
+	return &reply.Objects{Objects: replies}, nil
+}
+
+// forwardGetObjects fetches redirected objects from the node in one request and puts the results into replies.
+// The request carries delegation token of this node as redirects do.
+//
+// Redirects are kept in replies if the request fails, so the sender could follow them one by one.
+func (h *MessageHandler) forwardGetObjects(
+	ctx context.Context, node core.RecordRef, msg *message.GetObjects, items []int, replies []core.Reply,
+) {
+	forwarded := &message.GetObjects{Approved: msg.Approved, Redirected: true}
+	for _, i := range items {
+		redirect := replies[i].(*reply.GetObjectRedirectReply)
+		forwarded.Objects = append(forwarded.Objects, core.ObjectHead{
+			Head:  msg.Objects[i].Head,
+			State: redirect.StateID,
+		})
+	}
+
+	origin := h.NodeNet.GetOrigin().ID()
+	token, err := h.DelegationTokenFactory.IssueGetObjectRedirect(&origin, forwarded)
+	if err != nil {
+		inslogger.FromContext(ctx).Warn(errors.Wrapf(err, "failed to issue token for objects forwarded to %v", node))
+		return
+	}
+	genericReply, err := h.Bus.Send(ctx, forwarded, core.SendOptionDestination(&node), core.SendOptionToken(token))
+	if err != nil {
+		inslogger.FromContext(ctx).Warn(errors.Wrapf(err, "failed to forward objects to %v", node))
+		return
+	}
+	rep, ok := genericReply.(*reply.Objects)
+	if !ok || len(rep.Objects) != len(items) {
+		inslogger.FromContext(ctx).Warnf("unexpected reply for objects forwarded to %v", node)
+		return
+	}
+	for j, i := range items {
+		replies[i] = rep.Objects[j]
+	}
+}
+
 func (h *MessageHandler) handleGetDelegate(ctx context.Context, pulseNumber core.PulseNumber, genericMsg core.Parcel) (core.Reply, error) {
 	msg := genericMsg.Message().(*message.GetDelegate)
 
@@ -278,6 +467,7 @@ func (h *MessageHandler) handleGetChildren(ctx context.Context, pulseNumber core
 
 	var (
 		refs         []core.RecordRef
+		count        int
 		currentChild *core.RecordID
 	)
 
@@ -288,15 +478,24 @@ func (h *MessageHandler) handleGetChildren(ctx context.Context, pulseNumber core
 		currentChild = idx.ChildPointer
 	}
 
-	counter := 0
+	scanned := 0
 	for currentChild != nil {
-		// We have enough results.
-		if counter >= msg.Amount {
-			return &reply.Children{Refs: refs, NextFrom: currentChild}, nil
+		// We have enough results or scanned too many records for one reply.
+		if scanned >= maxChildrenScan || (!msg.Count && len(refs) >= msg.Amount) {
+			return &reply.Children{Refs: refs, NextFrom: currentChild, Count: count}, nil
 		}
-		counter++
+		scanned++
 
 		rec, err := h.db.GetRecord(ctx, currentChild)
+		if err == storage.ErrNotFound {
+			heavy, err := h.heavyRedirect(ctx, &msg.Parent, currentChild.Pulse(), pulseNumber)
+			if err != nil {
+				return nil, err
+			}
+			if heavy != nil {
+				return reply.NewGetChildrenRedirect(heavy), nil
+			}
+		}
 		if err != nil {
 			return nil, errors.New("failed to retrieve children")
 		}
@@ -311,10 +510,182 @@ func (h *MessageHandler) handleGetChildren(ctx context.Context, pulseNumber core
 		if msg.FromPulse != nil && recPulse > *msg.FromPulse {
 			continue
 		}
+		if msg.Prototype != nil {
+			matches, err := h.childMatchesPrototype(ctx, childRec, *msg.Prototype)
+			if err == storage.ErrNotFound {
+				// Heavy material node stores all children, so the page is not split between nodes.
+				return h.childrenRedirect(ctx, &msg.Parent, pulseNumber)
+			}
+			if err != nil {
+				return nil, errors.Wrap(err, "failed to filter children")
+			}
+			if !matches {
+				continue
+			}
+		}
+		if msg.Count {
+			count++
+			continue
+		}
 		refs = append(refs, childRec.Ref)
 	}
 
-	return &reply.Children{Refs: refs, NextFrom: nil}, nil
+	return &reply.Children{Refs: refs, NextFrom: nil, Count: count}, nil
+}
+
+// childMatchesPrototype checks if the child is created from the prototype and is not deactivated.
+//
+// Child state is read from local storage. Returns storage.ErrNotFound if child lifeline or its latest state is not
+// stored on this node.
+func (h *MessageHandler) childMatchesPrototype(
+	ctx context.Context, child *record.ChildRecord, prototype core.RecordRef,
+) (bool, error) {
+	idx, err := h.db.GetObjectIndex(ctx, child.Ref.Record(), false)
+	if err != nil {
+		return false, err
+	}
+	_, state, err := getObjectState(ctx, h.db, idx, nil, false)
+	switch err {
+	case nil:
+	case ErrObjectDeactivated, ErrStateNotAvailable:
+		return false, nil
+	default:
+		return false, err
+	}
+	image := state.GetImage()
+	return !state.GetIsPrototype() && image != nil && *image == prototype, nil
+}
+
+// childrenRedirect redirects children request to heavy material node. Returns storage.ErrNotFound if this node is
+// heavy material node.
+func (h *MessageHandler) childrenRedirect(
+	ctx context.Context, parent *core.RecordRef, currentPulse core.PulseNumber,
+) (core.Reply, error) {
+	heavy, err := h.heavyNode(ctx, parent, currentPulse)
+	if err != nil {
+		return nil, err
+	}
+	if *heavy == h.NodeNet.GetOrigin().ID() {
+		return nil, errors.Wrap(storage.ErrNotFound, "failed to filter children")
+	}
+	return reply.NewGetChildrenRedirect(heavy), nil
+}
+
+func (h *MessageHandler) handleGetHistory(ctx context.Context, pulseNumber core.PulseNumber, genericMsg core.Parcel) (core.Reply, error) {
+	msg := genericMsg.Message().(*message.GetHistory)
+	if msg.Amount <= 0 {
+		return nil, errors.New("history amount should be positive")
+	}
+
+	idx, err := h.db.GetObjectIndex(ctx, msg.Object.Record(), false)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to fetch object index")
+	}
+	h.recent.AddObject(*msg.Object.Record())
+
+	var (
+		states       []core.ObjectHistoryEntry
+		currentState *core.RecordID
+	)
+
+	// Counting from specified state or the latest.
+	if msg.FromState != nil {
+		currentState = msg.FromState
+	} else {
+		currentState = idx.LatestState
+	}
+
+	for currentState != nil {
+		// We have enough results.
+		if len(states) >= msg.Amount {
+			return &reply.History{States: states, NextFrom: currentState}, nil
+		}
+
+		rec, err := h.db.GetRecord(ctx, currentState)
+		if err == storage.ErrNotFound {
+			return h.historyRedirect(ctx, &msg.Object, states, currentState, pulseNumber, err)
+		}
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to retrieve object state")
+		}
+		state, ok := rec.(record.ObjectState)
+		if !ok {
+			return nil, errors.New("invalid object record")
+		}
+		stateID := currentState
+		currentState = state.PrevStateID()
+
+		// Skip states later than specified pulse.
+		if msg.FromPulse != nil && stateID.Pulse() > *msg.FromPulse {
+			continue
+		}
+
+		entry := core.ObjectHistoryEntry{
+			State:       *stateID,
+			Pulse:       stateID.Pulse(),
+			Deactivated: state.State() == record.StateDeactivation,
+		}
+		if state.GetRequest() != nil {
+			entry.Request = *state.GetRequest()
+		}
+		if state.GetMemory() != nil {
+			entry.Memory, err = h.db.GetBlob(ctx, state.GetMemory())
+			if err == storage.ErrNotFound {
+				return h.historyRedirect(ctx, &msg.Object, states, stateID, pulseNumber, err)
+			}
+			if err != nil {
+				return nil, errors.Wrap(err, "failed to retrieve object memory")
+			}
+		}
+		states = append(states, entry)
+	}
+
+	return &reply.History{States: states, NextFrom: nil}, nil
+}
+
+// historyRedirect handles the state missing on this node. Collected states are returned with the missing one as the
+// next state to fetch. Request without collected states is redirected to heavy material node if the state is
+// pruned, otherwise provided error is returned.
+func (h *MessageHandler) historyRedirect(
+	ctx context.Context,
+	object *core.RecordRef,
+	states []core.ObjectHistoryEntry,
+	missing *core.RecordID,
+	currentPulse core.PulseNumber,
+	notFound error,
+) (core.Reply, error) {
+	if len(states) > 0 {
+		return &reply.History{States: states, NextFrom: missing}, nil
+	}
+	heavy, err := h.heavyRedirect(ctx, object, missing.Pulse(), currentPulse)
+	if err != nil {
+		return nil, err
+	}
+	if heavy == nil {
+		return nil, errors.Wrap(notFound, "failed to retrieve object state")
+	}
+	return reply.NewGetHistoryRedirect(heavy, missing), nil
+}
+
+func (h *MessageHandler) handleGetRecordProof(ctx context.Context, pulseNumber core.PulseNumber, genericMsg core.Parcel) (core.Reply, error) {
+	msg := genericMsg.Message().(*message.GetRecordProof)
+
+	proof, err := h.db.GetRecordProof(ctx, &msg.Record)
+	if errors.Cause(err) == storage.ErrPruned {
+		target := core.NewRecordRef(core.RecordID{}, msg.Record)
+		heavy, err := h.heavyRedirect(ctx, target, msg.Record.Pulse(), pulseNumber)
+		if err != nil {
+			return nil, err
+		}
+		if heavy != nil {
+			return reply.NewGetRecordProofRedirect(heavy), nil
+		}
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to build record proof")
+	}
+
+	return &reply.RecordProof{Proof: *proof}, nil
 }
 
 func (h *MessageHandler) handleUpdateObject(ctx context.Context, pulseNumber core.PulseNumber, genericMsg core.Parcel) (core.Reply, error) {
@@ -336,6 +707,16 @@ func (h *MessageHandler) handleUpdateObject(ctx context.Context, pulseNumber cor
 		if err = validateState(idx.State, state.State()); err != nil {
 			return err
 		}
+		// States rejected by validators are removed from the chain, but new states should not be based on them.
+		if prev := state.PrevStateID(); prev != nil && (idx.LatestState == nil || !prev.Equal(idx.LatestState)) {
+			rejected, err := isStateRejected(ctx, tx, idx, *prev)
+			if err != nil {
+				return err
+			}
+			if rejected {
+				return ErrStateRejected
+			}
+		}
 		// Index exists and latest record id does not match (preserving chain consistency).
 		if idx.LatestState != nil && !state.PrevStateID().Equal(idx.LatestState) {
 			return errors.New("invalid state record")
@@ -346,16 +727,23 @@ func (h *MessageHandler) handleUpdateObject(ctx context.Context, pulseNumber cor
 		if err != nil {
 			return err
 		}
-		idx.LatestState = id
-		idx.State = state.State()
 		if state.State() == record.StateActivation {
 			idx.Parent = state.(*record.ObjectActivateRecord).Parent
 		}
+		err = updatePrototypeIndex(ctx, tx, msg.Object, idx, state, pulseNumber)
+		if err != nil {
+			return errors.Wrap(err, "failed to update prototype index")
+		}
+		idx.LatestState = id
+		idx.State = state.State()
 		return tx.SetObjectIndex(ctx, msg.Object.Record(), idx)
 	})
 	if err != nil {
-		if err == ErrObjectDeactivated {
+		switch err {
+		case ErrObjectDeactivated:
 			return &reply.Error{ErrType: reply.ErrDeactivated}, nil
+		case ErrStateRejected:
+			return &reply.Error{ErrType: reply.ErrStateRejected}, nil
 		}
 		return nil, err
 	}
@@ -372,6 +760,146 @@ func (h *MessageHandler) handleUpdateObject(ctx context.Context, pulseNumber cor
 	return &rep, nil
 }
 
+func (h *MessageHandler) handleGetObjectsByPrototype(ctx context.Context, pulseNumber core.PulseNumber, genericMsg core.Parcel) (core.Reply, error) {
+	msg := genericMsg.Message().(*message.GetObjectsByPrototype)
+
+	query := msg.Query
+	if query.Limit <= 0 || query.Limit > maxObjectsPageSize {
+		query.Limit = maxObjectsPageSize
+	}
+	page, err := h.db.GetObjectsByPrototype(ctx, query)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to fetch objects by prototype")
+	}
+
+	return &reply.ObjectsPage{Page: *page}, nil
+}
+
+// updatePrototypeIndex keeps index of objects by prototype in sync with object state.
+// Must be called before object lifeline is updated with the new state.
+func updatePrototypeIndex(
+	ctx context.Context,
+	tx *storage.TransactionManager,
+	head core.RecordRef,
+	idx *index.ObjectLifeline,
+	state record.ObjectState,
+	pulseNumber core.PulseNumber,
+) error {
+	prev, err := latestObjectState(ctx, tx, idx)
+	if err != nil {
+		return err
+	}
+	return movePrototypeIndex(ctx, tx, head, idx.Parent, prev, state, pulseNumber)
+}
+
+// latestObjectState returns latest state record of the object.
+//
+// Returns nil if object has no state or latest state record is not stored on this node (e.g. pruned), such state
+// is treated as a state without prototype.
+func latestObjectState(
+	ctx context.Context, tx *storage.TransactionManager, idx *index.ObjectLifeline,
+) (record.ObjectState, error) {
+	if idx.LatestState == nil {
+		return nil, nil
+	}
+	rec, err := tx.GetRecord(ctx, idx.LatestState)
+	if err == storage.ErrNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	state, ok := rec.(record.ObjectState)
+	if !ok {
+		return nil, errors.New("invalid object state record")
+	}
+	return state, nil
+}
+
+// previousObjectState returns index state of the object rolled back to provided state after rejection.
+func previousObjectState(
+	ctx context.Context, tx *storage.TransactionManager, prev *core.RecordID,
+) (record.State, error) {
+	if prev == nil {
+		return record.StateUndefined, nil
+	}
+	rec, err := tx.GetRecord(ctx, prev)
+	if err == storage.ErrNotFound {
+		// Pruned state is not a deactivation, as the rejected state was based on it.
+		return record.StateAmend, nil
+	}
+	if err != nil {
+		return record.StateUndefined, err
+	}
+	state, ok := rec.(record.ObjectState)
+	if !ok {
+		return record.StateUndefined, errors.New("invalid object state record")
+	}
+	return state.State(), nil
+}
+
+// prototypeImage returns prototype of the object in provided state or nil if the object has no prototype.
+func prototypeImage(state record.ObjectState) *core.RecordRef {
+	if state == nil || state.State() == record.StateDeactivation || state.GetIsPrototype() {
+		return nil
+	}
+	return state.GetImage()
+}
+
+// movePrototypeIndex moves object in index of objects by prototype from prototype of 'from' state to prototype of
+// 'to' state.
+func movePrototypeIndex(
+	ctx context.Context,
+	tx *storage.TransactionManager,
+	head, parent core.RecordRef,
+	from, to record.ObjectState,
+	pulseNumber core.PulseNumber,
+) error {
+	prevImage, image := prototypeImage(from), prototypeImage(to)
+	if prevImage != nil && (image == nil || *image != *prevImage) {
+		err := tx.DeactivateObjectByPrototype(ctx, *prevImage, head, pulseNumber)
+		if err != nil && err != storage.ErrNotFound {
+			return err
+		}
+	}
+	if image != nil {
+		return tx.AddObjectByPrototype(ctx, *image, head, parent, pulseNumber)
+	}
+	return nil
+}
+
+// rejectPrototypeIndex updates index of objects by prototype when 'rejected' state and states after it are rejected.
+// If activation is rejected, the object is removed from the index.
+func rejectPrototypeIndex(
+	ctx context.Context,
+	tx *storage.TransactionManager,
+	head core.RecordRef,
+	idx *index.ObjectLifeline,
+	rejected record.ObjectState,
+	pulseNumber core.PulseNumber,
+) error {
+	latest, err := latestObjectState(ctx, tx, idx)
+	if err != nil {
+		return err
+	}
+	if rejected.PrevStateID() == nil {
+		for _, state := range []record.ObjectState{latest, rejected} {
+			if image := prototypeImage(state); image != nil {
+				if err := tx.RemoveObjectByPrototype(ctx, *image, head); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	}
+
+	valid, err := latestObjectState(ctx, tx, &index.ObjectLifeline{LatestState: rejected.PrevStateID()})
+	if err != nil {
+		return err
+	}
+	return movePrototypeIndex(ctx, tx, head, idx.Parent, latest, valid, pulseNumber)
+}
+
 func (h *MessageHandler) handleRegisterChild(ctx context.Context, pulseNumber core.PulseNumber, genericMsg core.Parcel) (core.Reply, error) {
 	msg := genericMsg.Message().(*message.RegisterChild)
 
@@ -476,9 +1004,28 @@ func (h *MessageHandler) handleValidateRecord(ctx context.Context, pulseNumber c
 				if msg.IsValid {
 					idx.LatestStateApproved = currentID
 				} else {
+					err = rejectPrototypeIndex(ctx, tx, msg.Object, idx, currentState, pulseNumber)
+					if err != nil {
+						return errors.Wrap(err, "failed to update prototype index")
+					}
 					idx.LatestState = currentState.PrevStateID()
+					idx.State, err = previousObjectState(ctx, tx, idx.LatestState)
+					if err != nil {
+						return errors.Wrap(err, "failed to roll back object state")
+					}
 				}
-				err := tx.SetObjectIndex(ctx, msg.Object.Record(), idx)
+				idx.LatestValidation, err = tx.SetRecord(ctx, pulseNumber, &record.ValidationRecord{
+					PrevValidation: idx.LatestValidation,
+					Object:         msg.Object,
+					State:          msg.State,
+					IsValid:        msg.IsValid,
+					Validator:      genericMsg.GetSender(),
+					Signature:      genericMsg.GetSign(),
+				})
+				if err != nil {
+					return errors.Wrap(err, "failed to store validation record")
+				}
+				err = tx.SetObjectIndex(ctx, msg.Object.Record(), idx)
 				if err != nil {
 					return err
 				}
@@ -499,6 +1046,87 @@ func (h *MessageHandler) handleValidateRecord(ctx context.Context, pulseNumber c
 	return &reply.OK{}, nil
 }
 
+func (h *MessageHandler) handleGetObjectStatus(ctx context.Context, pulseNumber core.PulseNumber, genericMsg core.Parcel) (core.Reply, error) {
+	msg := genericMsg.Message().(*message.GetObjectStatus)
+
+	var status core.ObjectStatus
+	err := h.db.View(ctx, func(tx *storage.TransactionManager) error {
+		idx, err := tx.GetObjectIndex(ctx, msg.Head.Record(), false)
+		if err != nil {
+			return errors.Wrap(err, "failed to fetch object index")
+		}
+
+		status.LatestState = idx.LatestState
+		status.LatestStateApproved = idx.LatestStateApproved
+		status.Pending = idx.LatestState != nil && !idx.LatestState.Equal(idx.LatestStateApproved)
+		err = iterateValidations(ctx, tx, idx, func(validation *record.ValidationRecord) (bool, error) {
+			status.Validations = append(status.Validations, core.ObjectValidation{
+				State:     validation.State,
+				IsValid:   validation.IsValid,
+				Validator: validation.Validator,
+				Signature: validation.Signature,
+			})
+			return true, nil
+		})
+		return errors.Wrap(err, "failed to fetch validation records")
+	})
+	if err != nil {
+		return nil, err
+	}
+	h.recent.AddObject(*msg.Head.Record())
+
+	// Validations are iterated from the latest one.
+	for i, j := 0, len(status.Validations)-1; i < j; i, j = i+1, j-1 {
+		status.Validations[i], status.Validations[j] = status.Validations[j], status.Validations[i]
+	}
+
+	return &reply.ObjectStatus{Status: status}, nil
+}
+
+// isStateRejected checks if validators rejected provided object state.
+func isStateRejected(
+	ctx context.Context, tx *storage.TransactionManager, idx *index.ObjectLifeline, state core.RecordID,
+) (bool, error) {
+	var rejected bool
+	err := iterateValidations(ctx, tx, idx, func(validation *record.ValidationRecord) (bool, error) {
+		if validation.State != state {
+			return true, nil
+		}
+		rejected = !validation.IsValid
+		return false, nil
+	})
+	return rejected, err
+}
+
+// iterateValidations calls provided function for object validation records starting from the latest one until it
+// returns false. Records pruned from the node are not iterated.
+func iterateValidations(
+	ctx context.Context,
+	tx *storage.TransactionManager,
+	idx *index.ObjectLifeline,
+	fn func(validation *record.ValidationRecord) (bool, error),
+) error {
+	for id := idx.LatestValidation; id != nil; {
+		rec, err := tx.GetRecord(ctx, id)
+		if err == storage.ErrNotFound {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		validation, ok := rec.(*record.ValidationRecord)
+		if !ok {
+			return errors.New("invalid validation record")
+		}
+		next, err := fn(validation)
+		if err != nil || !next {
+			return err
+		}
+		id = validation.PrevValidation
+	}
+	return nil
+}
+
 func persistMessageToDb(ctx context.Context, db *storage.DB, genericMsg core.Message) error {
 	lastPulse, err := db.GetLatestPulseNumber(ctx)
 	if err != nil {
@@ -561,6 +1189,43 @@ func getObjectState(
 	return stateID, stateRec, nil
 }
 
+// getStateByPulse walks object's lifeline from provided (or the latest) state and returns the newest state created
+// at or before the pulse. If a state record is not stored locally, its id is returned with storage.ErrNotFound.
+func getStateByPulse(
+	ctx context.Context,
+	s storage.Store,
+	idx *index.ObjectLifeline,
+	from *core.RecordID,
+	pulse core.PulseNumber,
+	approved bool,
+) (*core.RecordID, error) {
+	stateID := from
+	if stateID == nil {
+		if approved {
+			stateID = idx.LatestStateApproved
+		} else {
+			stateID = idx.LatestState
+		}
+	}
+
+	for stateID != nil && stateID.Pulse() > pulse {
+		rec, err := s.GetRecord(ctx, stateID)
+		if err != nil {
+			return stateID, err
+		}
+		stateRec, ok := rec.(record.ObjectState)
+		if !ok {
+			return nil, errors.New("invalid object record")
+		}
+		stateID = stateRec.PrevStateID()
+	}
+	if stateID == nil {
+		return nil, ErrStateNotAvailable
+	}
+
+	return stateID, nil
+}
+
 func getObjectIndexForUpdate(ctx context.Context, s storage.Store, head *core.RecordID) (*index.ObjectLifeline, error) {
 	idx, err := s.GetObjectIndex(ctx, head, true)
 	if err == storage.ErrNotFound {
@@ -589,7 +1254,12 @@ func (h *MessageHandler) handleHeavyPayload(ctx context.Context, genericMsg core
 	}
 	msg := genericMsg.Message().(*message.HeavyPayload)
 	inslog.Debugf("Heavy sync: get start payload message with %v records", len(msg.Records))
-	err := h.db.StoreKeyValues(ctx, msg.Records)
+
+	locked, ok := h.heavySync.get(genericMsg.GetSender())
+	if !ok {
+		return nil, errors.New("heavy sync is not started by sender")
+	}
+	err := h.db.StoreReplica(ctx, locked.begin, locked.end, msg.Records, locked.hashes)
 	if err != nil {
 		return nil, err
 	}
@@ -602,14 +1272,20 @@ func (h *MessageHandler) handleHeavyStartStop(ctx context.Context, genericMsg co
 		return &reply.OK{}, nil
 	}
 	msg := genericMsg.Message().(*message.HeavyStartStop)
+	sender := genericMsg.GetSender()
 	if msg.Finished {
-		// stop logic (unlock)
 		inslog.Debugf("Heavy sync: get stop message [%v,%v]", msg.Begin, msg.End)
+		h.heavySync.unlock(sender)
 		return &reply.OK{}, nil
 	}
 
-	// start logick (try to lock)
 	inslog.Debugf("Heavy sync: get start message [%v,%v]", msg.Begin, msg.End)
-	// TODO: handle start message (i.e. add lock)
+	if msg.Begin >= msg.End {
+		return nil, errors.Errorf("invalid heavy sync range [%v,%v]", msg.Begin, msg.End)
+	}
+	if !h.heavySync.lock(sender, msg.Begin, msg.End, msg.DropHashes) {
+		inslog.Debugf("Heavy sync: range [%v,%v] is locked by another node", msg.Begin, msg.End)
+		return &reply.Error{ErrType: reply.ErrHeavySyncInProgress}, nil
+	}
 	return &reply.OK{}, nil
 }
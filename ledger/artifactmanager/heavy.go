@@ -0,0 +1,113 @@
+/*
+ *    Copyright 2018 Insolar
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ */
+
+package artifactmanager
+
+import (
+	"sync"
+	"time"
+
+	"github.com/insolar/insolar/core"
+)
+
+// pulseRange is a half-open range of pulses [begin:end).
+type pulseRange struct {
+	begin core.PulseNumber
+	end   core.PulseNumber
+}
+
+func (r pulseRange) overlaps(other pulseRange) bool {
+	return r.begin < other.end && other.begin < r.end
+}
+
+// heavySyncLockTimeout is a default time after the last message from sender when its locked range expires.
+const heavySyncLockTimeout = 5 * time.Minute
+
+// heavySyncLock is a pulse range locked by sender with jet drop hashes provided in the start message.
+type heavySyncLock struct {
+	pulseRange
+	hashes  map[core.PulseNumber][]byte
+	expires time.Time
+}
+
+// heavySyncLocks holds pulse ranges currently replicated to heavy node by light nodes.
+//
+// Locks expire after timeout since the last message from sender, so a range locked by crashed light node is released.
+// Zero value is ready to use with heavySyncLockTimeout.
+type heavySyncLocks struct {
+	mu      sync.Mutex
+	timeout time.Duration
+	locks   map[core.RecordRef]*heavySyncLock
+}
+
+func (l *heavySyncLocks) expiration() time.Time {
+	timeout := l.timeout
+	if timeout == 0 {
+		timeout = heavySyncLockTimeout
+	}
+	return time.Now().Add(timeout)
+}
+
+// lock locks range for sender. Repeated lock by the same sender replaces its range (sync resume).
+// Returns false if range overlaps with the unexpired range locked by another sender.
+func (l *heavySyncLocks) lock(
+	sender core.RecordRef, begin, end core.PulseNumber, hashes map[core.PulseNumber][]byte,
+) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	r := pulseRange{begin: begin, end: end}
+	now := time.Now()
+	for s, locked := range l.locks {
+		if now.After(locked.expires) {
+			delete(l.locks, s)
+			continue
+		}
+		if s != sender && locked.overlaps(r) {
+			return false
+		}
+	}
+	if l.locks == nil {
+		l.locks = map[core.RecordRef]*heavySyncLock{}
+	}
+	l.locks[sender] = &heavySyncLock{pulseRange: r, hashes: hashes, expires: l.expiration()}
+	return true
+}
+
+// get returns unexpired lock of sender and prolongs it.
+func (l *heavySyncLocks) get(sender core.RecordRef) (*heavySyncLock, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	locked, ok := l.locks[sender]
+	if !ok {
+		return nil, false
+	}
+	if time.Now().After(locked.expires) {
+		delete(l.locks, sender)
+		return nil, false
+	}
+	locked.expires = l.expiration()
+	return locked, true
+}
+
+// unlock releases range locked by sender.
+func (l *heavySyncLocks) unlock(sender core.RecordRef) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	delete(l.locks, sender)
+}
@@ -17,17 +17,30 @@
 package artifactmanager
 
 import (
+	"context"
 	"testing"
+	"time"
 
 	"github.com/dgraph-io/badger"
+	"github.com/pkg/errors"
 
 	"github.com/insolar/insolar/core"
 	"github.com/insolar/insolar/core/message"
+	"github.com/insolar/insolar/core/reply"
+	"github.com/insolar/insolar/ledger/index"
+	"github.com/insolar/insolar/ledger/record"
 	"github.com/insolar/insolar/ledger/storage"
+	"github.com/insolar/insolar/ledger/storage/storagetest"
+	"github.com/insolar/insolar/testutils"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
+const (
+	scopeIDRecord = byte(2)
+	scopeIDBlob   = byte(7)
+)
+
 func TestLedgerArtifactManager_handleHeavy(t *testing.T) {
 	t.Parallel()
 	ctx, db, _, cleaner := getTestData(t)
@@ -35,23 +48,43 @@ func TestLedgerArtifactManager_handleHeavy(t *testing.T) {
 
 	mh := NewMessageHandler(db, storage.NewRecentStorage(0))
 
-	payload := []core.KV{
-		{K: []byte("ABC"), V: []byte("CDE")},
-		{K: []byte("ABC"), V: []byte("CDE")},
-		{K: []byte("CDE"), V: []byte("ABC")},
-	}
-
-	parcel := &message.Parcel{
-		Msg: &message.HeavyPayload{Records: payload},
-	}
+	pulse := core.GenesisPulse.PulseNumber + 1
+	payload, hashes := lightReplica(ctx, t, pulse)
+	sender := testutils.RandomRef()
 
 	var err error
-	_, err = mh.handleHeavyPayload(ctx, parcel)
+	_, err = mh.handleHeavyPayload(ctx, &message.Parcel{
+		Sender: sender,
+		Msg:    &message.HeavyPayload{Records: payload},
+	})
+	require.Error(t, err, "payload without start message")
+
+	rep, err := mh.handleHeavyStartStop(ctx, &message.Parcel{
+		Sender: sender,
+		Msg:    &message.HeavyStartStop{Begin: pulse, End: pulse + 1, DropHashes: hashes},
+	})
+	require.NoError(t, err)
+	require.Equal(t, &reply.OK{}, rep)
+
+	_, err = mh.handleHeavyPayload(ctx, &message.Parcel{
+		Sender: sender,
+		Msg:    &message.HeavyPayload{Records: payload},
+	})
 	require.NoError(t, err)
 
 	badgerdb := db.GetBadgerDB()
 	err = badgerdb.View(func(tx *badger.Txn) error {
 		for _, kv := range payload {
+			// blobs are replicated with content, but stored by content hash
+			if kv.K[0] == scopeIDBlob {
+				var id core.RecordID
+				copy(id[:], kv.K[1:])
+				blob, err := db.GetBlob(ctx, &id)
+				if assert.NoError(t, err) {
+					assert.Equal(t, kv.V, blob)
+				}
+				continue
+			}
 			item, err := tx.Get(kv.K)
 			if !assert.NoError(t, err) {
 				continue
@@ -67,3 +100,163 @@ func TestLedgerArtifactManager_handleHeavy(t *testing.T) {
 	})
 	require.NoError(t, err)
 }
+
+func TestLedgerArtifactManager_handleHeavy_Verify(t *testing.T) {
+	t.Parallel()
+	ctx, db, _, cleaner := getTestData(t)
+	defer cleaner()
+
+	mh := NewMessageHandler(db, storage.NewRecentStorage(0))
+
+	pulse := core.GenesisPulse.PulseNumber + 1
+	payload, hashes := lightReplica(ctx, t, pulse)
+	sender := testutils.RandomRef()
+
+	tamperedRecord := func(kvs []core.KV) []core.KV {
+		var tampered []core.KV
+		for _, kv := range kvs {
+			if kv.K[0] == scopeIDRecord {
+				k := append([]byte{}, kv.K...)
+				k[len(k)-1]++
+				kv.K = k
+			}
+			tampered = append(tampered, kv)
+		}
+		return tampered
+	}
+	tamperedValue := func(kvs []core.KV, scope byte, value []byte) []core.KV {
+		var tampered []core.KV
+		for _, kv := range kvs {
+			if kv.K[0] == scope {
+				kv.V = value
+			}
+			tampered = append(tampered, kv)
+		}
+		return tampered
+	}
+	tests := []struct {
+		name    string
+		records []core.KV
+		hashes  map[core.PulseNumber][]byte
+	}{
+		{"wrong hash", payload, map[core.PulseNumber][]byte{pulse: []byte("wrong checksum")}},
+		{"no hash", payload, nil},
+		{"tampered record", tamperedRecord(payload), hashes},
+		{
+			"tampered record value",
+			tamperedValue(payload, scopeIDRecord, record.SerializeRecord(&record.CodeRecord{})),
+			hashes,
+		},
+		{"broken record value", tamperedValue(payload, scopeIDRecord, []byte{1}), hashes},
+		{"tampered blob value", tamperedValue(payload, scopeIDBlob, []byte("100501")), hashes},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := mh.handleHeavyStartStop(ctx, &message.Parcel{
+				Sender: sender,
+				Msg:    &message.HeavyStartStop{Begin: pulse, End: pulse + 1, DropHashes: tt.hashes},
+			})
+			require.NoError(t, err)
+
+			_, err = mh.handleHeavyPayload(ctx, &message.Parcel{
+				Sender: sender,
+				Msg:    &message.HeavyPayload{Records: tt.records},
+			})
+			assert.Equal(t, storage.ErrReplicaChecksum, errors.Cause(err))
+
+			// nothing is stored on mismatch
+			_, err = db.GetDrop(ctx, pulse)
+			assert.Equal(t, storage.ErrNotFound, err)
+		})
+	}
+
+	_, err := mh.handleHeavyStartStop(ctx, &message.Parcel{
+		Sender: sender,
+		Msg:    &message.HeavyStartStop{Begin: pulse, End: pulse + 1, DropHashes: hashes},
+	})
+	require.NoError(t, err)
+
+	_, err = mh.handleHeavyPayload(ctx, &message.Parcel{
+		Sender: sender,
+		Msg: &message.HeavyPayload{
+			Records: []core.KV{{K: []byte("ABC"), V: []byte("CDE")}},
+		},
+	})
+	assert.Equal(t, storage.ErrReplicaOutOfRange, errors.Cause(err))
+}
+
+func TestLedgerArtifactManager_handleHeavy_Lock(t *testing.T) {
+	t.Parallel()
+	ctx, db, _, cleaner := getTestData(t)
+	defer cleaner()
+
+	mh := NewMessageHandler(db, storage.NewRecentStorage(0))
+
+	pulse := core.GenesisPulse.PulseNumber + 1
+	first := testutils.RandomRef()
+	second := testutils.RandomRef()
+	startstop := func(sender core.RecordRef, begin, end core.PulseNumber, finished bool) core.Reply {
+		t.Helper()
+		rep, err := mh.handleHeavyStartStop(ctx, &message.Parcel{
+			Sender: sender,
+			Msg:    &message.HeavyStartStop{Begin: begin, End: end, Finished: finished},
+		})
+		require.NoError(t, err)
+		return rep
+	}
+
+	assert.Equal(t, &reply.OK{}, startstop(first, pulse, pulse+10, false))
+	// resume by the same sender
+	assert.Equal(t, &reply.OK{}, startstop(first, pulse, pulse+10, false))
+	assert.Equal(t,
+		&reply.Error{ErrType: reply.ErrHeavySyncInProgress},
+		startstop(second, pulse+5, pulse+15, false))
+	assert.Equal(t, &reply.OK{}, startstop(second, pulse+10, pulse+15, false))
+
+	assert.Equal(t, &reply.OK{}, startstop(first, pulse, pulse+10, true))
+	assert.Equal(t, &reply.OK{}, startstop(second, pulse+5, pulse+15, false))
+
+	// range locked by crashed sender expires
+	mh.heavySync.timeout = time.Millisecond
+	assert.Equal(t, &reply.OK{}, startstop(first, pulse+20, pulse+30, false))
+	time.Sleep(10 * time.Millisecond)
+	assert.Equal(t, &reply.OK{}, startstop(second, pulse+25, pulse+35, false))
+	_, err := mh.handleHeavyPayload(ctx, &message.Parcel{
+		Sender: first,
+		Msg:    &message.HeavyPayload{},
+	})
+	assert.Error(t, err, "payload after lock expiration")
+}
+
+// lightReplica fills light node storage with single pulse and returns its replica with drop hashes.
+func lightReplica(
+	ctx context.Context,
+	t *testing.T,
+	pulse core.PulseNumber,
+) ([]core.KV, map[core.PulseNumber][]byte) {
+	db, cleaner := storagetest.TmpDB(ctx, t)
+	defer cleaner()
+
+	err := db.AddPulse(ctx, core.Pulse{PulseNumber: pulse})
+	require.NoError(t, err)
+	id, err := db.SetRecord(ctx, pulse, &record.ObjectActivateRecord{})
+	require.NoError(t, err)
+	err = db.SetObjectIndex(ctx, id, &index.ObjectLifeline{LatestState: id})
+	require.NoError(t, err)
+	_, err = db.SetBlob(ctx, pulse, []byte("100500"))
+	require.NoError(t, err)
+
+	prevDrop, err := db.GetDrop(ctx, 0)
+	require.NoError(t, err)
+	drop, _, err := db.CreateDrop(ctx, pulse, prevDrop.Hash)
+	require.NoError(t, err)
+	err = db.SetDrop(ctx, drop)
+	require.NoError(t, err)
+
+	kvs, err := storage.NewReplicaIter(ctx, db, pulse, pulse+1, 1<<20).NextRecords()
+	require.NoError(t, err)
+	hashes, err := db.DropHashes(ctx, pulse, pulse+1)
+	require.NoError(t, err)
+	require.Equal(t, map[core.PulseNumber][]byte{pulse: drop.Hash}, hashes)
+	return kvs, hashes
+}
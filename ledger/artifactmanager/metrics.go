@@ -27,11 +27,15 @@ import (
 var (
 	tagMethod = insmetrics.MustTagKey("method")
 	tagResult = insmetrics.MustTagKey("result")
+	tagCache  = insmetrics.MustTagKey("cache")
 )
 
 var (
 	statCalls   = stats.Int64("artifactmanager/calls", "The number of AM method calls", stats.UnitDimensionless)
 	statLatency = stats.Int64("artifactmanager/latency", "The latency in milliseconds per AM call", stats.UnitMilliseconds)
+
+	statCacheHits   = stats.Int64("artifactmanager/cache/hits", "The number of AM descriptor cache hits", stats.UnitDimensionless)
+	statCacheMisses = stats.Int64("artifactmanager/cache/misses", "The number of AM descriptor cache misses", stats.UnitDimensionless)
 )
 
 func init() {
@@ -51,6 +55,20 @@ func init() {
 			Aggregation: view.Distribution(0, 25, 50, 75, 100, 200, 400, 600, 800, 1000, 2000, 4000, 6000),
 			TagKeys:     commontags,
 		},
+		&view.View{
+			Name:        "artifactmanager_cache_hits",
+			Description: statCacheHits.Description(),
+			Measure:     statCacheHits,
+			Aggregation: view.Count(),
+			TagKeys:     []tag.Key{tagCache},
+		},
+		&view.View{
+			Name:        "artifactmanager_cache_misses",
+			Description: statCacheMisses.Description(),
+			Measure:     statCacheMisses,
+			Aggregation: view.Count(),
+			TagKeys:     []tag.Key{tagCache},
+		},
 	)
 	if err != nil {
 		panic(err)
@@ -23,6 +23,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"github.com/insolar/insolar/core"
 	"github.com/insolar/insolar/core/message"
 	"github.com/insolar/insolar/testutils/testmetrics"
 )
@@ -39,6 +40,14 @@ func TestLedgerArtifactManager_Metrics(t *testing.T) {
 	_, err := am.RegisterRequest(ctx, &message.Parcel{Msg: &msg})
 	require.NoError(t, err)
 
+	am.cache = newDescriptorCache(1, 1)
+	codeID, err := am.DeployCode(ctx, domainRef, *genRandomRef(0), []byte{1}, core.MachineTypeBuiltin)
+	require.NoError(t, err)
+	for i := 0; i < 3; i++ {
+		_, err = am.GetCode(ctx, *genRefWithID(codeID))
+		require.NoError(t, err)
+	}
+
 	time.Sleep(1500 * time.Millisecond)
 
 	_, _ = db, am
@@ -47,4 +56,6 @@ func TestLedgerArtifactManager_Metrics(t *testing.T) {
 
 	assert.Contains(t, content, `insolar_artifactmanager_latency_count{method="RegisterRequest",result="2xx"} 1`)
 	assert.Contains(t, content, `insolar_artifactmanager_calls{method="RegisterRequest",result="2xx"} 1`)
+	assert.Contains(t, content, `insolar_artifactmanager_cache_hits{cache="code"} 2`)
+	assert.Contains(t, content, `insolar_artifactmanager_cache_misses{cache="code"} 1`)
 }
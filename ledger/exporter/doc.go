@@ -14,5 +14,5 @@
  *    limitations under the License.
  */
 
-// Package exporter contains methods of extracting data from DB.
+// Package exporter contains methods of extracting data from DB and restoring DB from exported data.
 package exporter
@@ -22,6 +22,7 @@ import (
 	"math"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/insolar/insolar/core"
 	"github.com/insolar/insolar/core/message"
@@ -32,14 +33,20 @@ import (
 	"github.com/ugorji/go/codec"
 )
 
+const (
+	streamPollInterval = time.Second
+)
+
 // Exporter provides methods for fetching data view from storage.
 type Exporter struct {
 	db *storage.DB
+
+	streamPollInterval time.Duration
 }
 
 // NewExporter creates new StorageExporter instance.
 func NewExporter(db *storage.DB) *Exporter {
-	return &Exporter{db: db}
+	return &Exporter{db: db, streamPollInterval: streamPollInterval}
 }
 
 type payload = map[string]interface{}
@@ -17,8 +17,14 @@
 package exporter
 
 import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
 	"strconv"
 	"testing"
+	"time"
 
 	"github.com/insolar/insolar/core"
 	"github.com/insolar/insolar/core/message"
@@ -60,7 +66,9 @@ func TestExporter_Export(t *testing.T) {
 		},
 		IsDelegate: true,
 	})
-	payload := message.ParcelToBytes(&message.Parcel{LogTraceID: "callRequest"})
+	require.NoError(t, err)
+	payload, err := message.ParcelToBytes(&message.Parcel{LogTraceID: "callRequest"})
+	require.NoError(t, err)
 	requestID, err := db.SetRecord(ctx, core.FirstPulseNumber+1, &record.CallRequest{
 		Payload: payload,
 	})
@@ -97,3 +105,121 @@ func TestExporter_Export(t *testing.T) {
 	assert.Equal(t, payload, request.Data.(*record.CallRequest).Payload)
 	assert.Equal(t, "callRequest", request.Payload["Payload"].(*message.Parcel).LogTraceID)
 }
+
+func TestExporter_Stream(t *testing.T) {
+	ctx := inslogger.TestContext(t)
+	db, clean := storagetest.TmpDB(ctx, t)
+	defer clean()
+
+	exporter := NewExporter(db)
+	exporter.streamPollInterval = 10 * time.Millisecond
+
+	pulse1 := core.GenesisPulse.PulseNumber + 1
+	pulse2 := core.GenesisPulse.PulseNumber + 2
+	pulse3 := core.GenesisPulse.PulseNumber + 3
+	err := db.AddPulse(ctx, core.Pulse{PulseNumber: pulse1})
+	require.NoError(t, err)
+	err = db.AddPulse(ctx, core.Pulse{PulseNumber: pulse2})
+	require.NoError(t, err)
+
+	setRecords := func(pulse core.PulseNumber, count int) {
+		for i := 0; i < count; i++ {
+			code, err := db.SetBlob(ctx, pulse, []byte{byte(i)})
+			require.NoError(t, err)
+			_, err = db.SetRecord(ctx, pulse, &record.CodeRecord{Code: code})
+			require.NoError(t, err)
+		}
+	}
+	setRecords(pulse1, 3)
+	setRecords(pulse2, 2)
+
+	type line struct {
+		Pulse core.PulseNumber
+		ID    string
+		Type  string
+	}
+	decode := func(r io.Reader) []line {
+		var lines []line
+		scanner := bufio.NewScanner(r)
+		for scanner.Scan() {
+			var l line
+			require.NoError(t, json.Unmarshal(scanner.Bytes(), &l))
+			lines = append(lines, l)
+		}
+		return lines
+	}
+
+	// Latest pulse is not closed and is not exported.
+	var buf bytes.Buffer
+	err = exporter.Stream(ctx, &buf, core.ExportCursor{Pulse: pulse1}, false)
+	require.NoError(t, err)
+	lines := decode(&buf)
+	require.Equal(t, 3, len(lines))
+	for _, l := range lines {
+		assert.Equal(t, pulse1, l.Pulse)
+		assert.Equal(t, "TypeCode", l.Type)
+	}
+
+	// Export is resumed from the cursor.
+	after, err := core.NewRecordIDFromBase58(lines[0].ID)
+	require.NoError(t, err)
+	buf.Reset()
+	err = exporter.Stream(ctx, &buf, core.ExportCursor{Pulse: pulse1, After: after}, false)
+	require.NoError(t, err)
+	assert.Equal(t, lines[1:], decode(&buf))
+
+	// New pulses are exported as they close.
+	followCtx, cancel := context.WithCancel(ctx)
+	r, w := io.Pipe()
+	done := make(chan error)
+	go func() {
+		done <- exporter.Stream(followCtx, w, core.ExportCursor{Pulse: pulse2}, true)
+		w.Close()
+	}()
+	err = db.AddPulse(ctx, core.Pulse{PulseNumber: pulse3})
+	require.NoError(t, err)
+
+	scanner := bufio.NewScanner(r)
+	for i := 0; i < 2; i++ {
+		require.True(t, scanner.Scan())
+		var l line
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &l))
+		assert.Equal(t, pulse2, l.Pulse)
+	}
+	cancel()
+	assert.Equal(t, context.Canceled, <-done)
+}
+
+func TestExporter_Stream_FromMissingPulse(t *testing.T) {
+	ctx := inslogger.TestContext(t)
+	db, clean := storagetest.TmpDB(ctx, t)
+	defer clean()
+
+	exporter := NewExporter(db)
+
+	pulse1 := core.GenesisPulse.PulseNumber + 10
+	pulse2 := core.GenesisPulse.PulseNumber + 20
+	err := db.AddPulse(ctx, core.Pulse{PulseNumber: pulse1})
+	require.NoError(t, err)
+	err = db.AddPulse(ctx, core.Pulse{PulseNumber: pulse2})
+	require.NoError(t, err)
+	_, err = db.SetRecord(ctx, pulse1, &record.CodeRecord{})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name  string
+		from  core.ExportCursor
+		lines int
+	}{
+		{"from pulse before stored one", core.ExportCursor{Pulse: pulse1 - 5, After: &core.RecordID{0xFF}}, 1},
+		{"from pulse after all stored ones", core.ExportCursor{Pulse: pulse2 + 5}, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			err := exporter.Stream(ctx, &buf, tt.from, false)
+			require.NoError(t, err)
+			assert.Equal(t, tt.lines, bytes.Count(buf.Bytes(), []byte("\n")))
+		})
+	}
+}
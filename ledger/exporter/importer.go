@@ -0,0 +1,261 @@
+/*
+ *    Copyright 2018 Insolar
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ */
+
+package exporter
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/pkg/errors"
+
+	"github.com/insolar/insolar/core"
+	"github.com/insolar/insolar/ledger/index"
+	"github.com/insolar/insolar/ledger/record"
+	"github.com/insolar/insolar/ledger/storage"
+)
+
+// ErrIDMismatch is returned when record or blob id from export stream does not match its content.
+var ErrIDMismatch = errors.New("id does not match content")
+
+// Importer restores storage from export stream.
+type Importer struct {
+	db *storage.DB
+}
+
+// NewImporter creates new Importer instance.
+func NewImporter(db *storage.DB) *Importer {
+	return &Importer{db: db}
+}
+
+// importRecord is a line of export stream required for import.
+type importRecord struct {
+	Pulse core.PulseNumber
+	ID    string
+	Raw   []byte
+	Blob  []byte
+}
+
+// importState collects data required to rebuild lifelines.
+type importState struct {
+	records     map[core.RecordID]record.Record
+	activations []core.RecordID
+	children    []core.RecordID
+	// nextState maps object state to the following one.
+	nextState map[core.RecordID]core.RecordID
+}
+
+// Import reads export stream produced by Exporter.Stream and writes records, blobs and lifelines to storage.
+// Returns number of imported records.
+//
+// Record and blob ids are recalculated from their content and ErrIDMismatch is returned if they differ from exported
+// ones. Lifelines are rebuilt from imported records, so the whole stream should be imported at once. Pulses are
+// restored with pulse numbers only. Validation records are imported, but lifelines are not linked to them, so imported
+// states are not approved.
+func (i *Importer) Import(ctx context.Context, r io.Reader) (int, error) {
+	latest, err := i.db.GetLatestPulseNumber(ctx)
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to fetch latest pulse")
+	}
+
+	state := importState{
+		records:   map[core.RecordID]record.Record{},
+		nextState: map[core.RecordID]core.RecordID{},
+	}
+	dec := json.NewDecoder(r)
+	count := 0
+	for {
+		var line importRecord
+		err := dec.Decode(&line)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return count, errors.Wrapf(err, "failed to decode line %v", count+1)
+		}
+
+		if line.Pulse > latest {
+			err = i.db.AddPulse(ctx, core.Pulse{PulseNumber: line.Pulse})
+			if err != nil {
+				return count, errors.Wrapf(err, "failed to add pulse %v", line.Pulse)
+			}
+			latest = line.Pulse
+		}
+
+		id, err := i.importRecord(ctx, &line, &state)
+		if err != nil {
+			return count, errors.Wrapf(err, "failed to import record %v", line.ID)
+		}
+		count++
+
+		switch rec := state.records[*id].(type) {
+		case *record.ObjectActivateRecord:
+			state.activations = append(state.activations, *id)
+		case *record.ChildRecord:
+			state.children = append(state.children, *id)
+		case record.ObjectState:
+			prev := rec.PrevStateID()
+			if prev == nil {
+				break
+			}
+			// States rejected by validation are followed by a state with the same previous one.
+			if next, ok := state.nextState[*prev]; !ok || next.Pulse() < id.Pulse() {
+				state.nextState[*prev] = *id
+			}
+		}
+	}
+
+	err = i.setLifelines(ctx, &state)
+	if err != nil {
+		return count, errors.Wrap(err, "failed to rebuild lifelines")
+	}
+	return count, nil
+}
+
+func (i *Importer) importRecord(ctx context.Context, line *importRecord, state *importState) (*core.RecordID, error) {
+	id, err := core.NewRecordIDFromBase58(line.ID)
+	if err != nil {
+		return nil, err
+	}
+	rec, err := deserializeRecord(line.Raw)
+	if err != nil {
+		return nil, err
+	}
+
+	hasher := i.db.PlatformCryptographyScheme.ReferenceHasher()
+	_, err = rec.WriteHashData(hasher)
+	if err != nil {
+		return nil, err
+	}
+	if !core.NewRecordID(line.Pulse, hasher.Sum(nil)).Equal(id) {
+		return nil, ErrIDMismatch
+	}
+
+	if blob := recordBlob(rec); blob != nil {
+		blobID := record.CalculateIDForBlob(i.db.PlatformCryptographyScheme, blob.Pulse(), line.Blob)
+		if !blobID.Equal(blob) {
+			return nil, errors.Wrap(ErrIDMismatch, "blob")
+		}
+		_, err = i.db.SetBlob(ctx, blob.Pulse(), line.Blob)
+		if err != nil && err != storage.ErrOverride {
+			return nil, err
+		}
+	}
+
+	_, err = i.db.SetRecord(ctx, line.Pulse, rec)
+	if err != nil && err != storage.ErrOverride {
+		return nil, err
+	}
+	state.records[*id] = rec
+	return id, nil
+}
+
+func (i *Importer) setLifelines(ctx context.Context, state *importState) error {
+	lifelines := map[core.RecordID]*index.ObjectLifeline{}
+	getLifeline := func(head core.RecordID) (*index.ObjectLifeline, error) {
+		if idx, ok := lifelines[head]; ok {
+			return idx, nil
+		}
+		idx, err := i.db.GetObjectIndex(ctx, &head, false)
+		if err == storage.ErrNotFound {
+			idx = &index.ObjectLifeline{}
+		} else if err != nil {
+			return nil, err
+		}
+		if idx.Delegates == nil {
+			idx.Delegates = map[core.RecordRef]core.RecordRef{}
+		}
+		lifelines[head] = idx
+		return idx, nil
+	}
+
+	for _, head := range state.activations {
+		latest := head
+		for next, ok := state.nextState[latest]; ok; next, ok = state.nextState[latest] {
+			latest = next
+		}
+		idx, err := getLifeline(head)
+		if err != nil {
+			return err
+		}
+		latestState, ok := state.records[latest].(record.ObjectState)
+		if !ok {
+			return fmt.Errorf("record %v is not an object state", latest)
+		}
+		activation, ok := state.records[head].(*record.ObjectActivateRecord)
+		if !ok {
+			return fmt.Errorf("record %v is not an activation record", head)
+		}
+		idx.LatestState = &latest
+		idx.State = latestState.State()
+		idx.Parent = activation.Parent
+	}
+
+	// Latest child of a parent is the one which is not referenced by other children.
+	referenced := map[core.RecordID]bool{}
+	children := make(map[core.RecordID]*record.ChildRecord, len(state.children))
+	for _, id := range state.children {
+		child, ok := state.records[id].(*record.ChildRecord)
+		if !ok {
+			return fmt.Errorf("record %v is not a child record", id)
+		}
+		children[id] = child
+		if child.PrevChild != nil {
+			referenced[*child.PrevChild] = true
+		}
+	}
+	for _, id := range state.children {
+		child := children[id]
+		activation, ok := state.records[*child.Ref.Record()].(*record.ObjectActivateRecord)
+		if !ok {
+			return fmt.Errorf("no activation record for child %v", child.Ref.Record())
+		}
+		idx, err := getLifeline(*activation.Parent.Record())
+		if err != nil {
+			return err
+		}
+		if activation.IsDelegate && activation.GetImage() != nil {
+			idx.Delegates[*activation.GetImage()] = child.Ref
+		}
+		if !referenced[id] {
+			childID := id
+			idx.ChildPointer = &childID
+		}
+	}
+
+	for head, idx := range lifelines {
+		head := head
+		err := i.db.SetObjectIndex(ctx, &head, idx)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func deserializeRecord(buf []byte) (rec record.Record, err error) {
+	if len(buf) < record.TypeIDSize {
+		return nil, errors.New("record is too short")
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("failed to deserialize record: %v", r)
+		}
+	}()
+	return record.DeserializeRecord(buf), nil
+}
@@ -0,0 +1,164 @@
+/*
+ *    Copyright 2018 Insolar
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ */
+
+package exporter
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/insolar/insolar/core"
+	"github.com/insolar/insolar/instrumentation/inslogger"
+	"github.com/insolar/insolar/ledger/index"
+	"github.com/insolar/insolar/ledger/record"
+	"github.com/insolar/insolar/ledger/storage/storagetest"
+	"github.com/insolar/insolar/testutils"
+)
+
+func TestImporter_Import(t *testing.T) {
+	ctx := inslogger.TestContext(t)
+	db, clean := storagetest.TmpDB(ctx, t)
+	defer clean()
+
+	pulse1 := core.GenesisPulse.PulseNumber + 1
+	pulse2 := core.GenesisPulse.PulseNumber + 2
+	err := db.AddPulse(ctx, core.Pulse{PulseNumber: pulse1})
+	require.NoError(t, err)
+	err = db.AddPulse(ctx, core.Pulse{PulseNumber: pulse2})
+	require.NoError(t, err)
+	err = db.AddPulse(ctx, core.Pulse{PulseNumber: pulse2 + 1})
+	require.NoError(t, err)
+
+	domain := testutils.RandomID()
+	proto := testutils.RandomRef()
+
+	memory1, err := db.SetBlob(ctx, pulse1, []byte{1})
+	require.NoError(t, err)
+	parentID, err := db.SetRecord(ctx, pulse1, &record.ObjectActivateRecord{
+		ObjectStateRecord: record.ObjectStateRecord{Memory: memory1},
+	})
+	require.NoError(t, err)
+	parentRef := core.NewRecordRef(domain, *parentID)
+	memory2, err := db.SetBlob(ctx, pulse1, []byte{2})
+	require.NoError(t, err)
+	amendID, err := db.SetRecord(ctx, pulse2, &record.ObjectAmendRecord{
+		ObjectStateRecord: record.ObjectStateRecord{Memory: memory2},
+		PrevState:         *parentID,
+	})
+	require.NoError(t, err)
+
+	childID, err := db.SetRecord(ctx, pulse2, &record.ObjectActivateRecord{
+		ObjectStateRecord: record.ObjectStateRecord{Image: proto},
+		Parent:            *parentRef,
+		IsDelegate:        true,
+	})
+	require.NoError(t, err)
+	childRef := core.NewRecordRef(domain, *childID)
+	childRecID, err := db.SetRecord(ctx, pulse2, &record.ChildRecord{Ref: *childRef})
+	require.NoError(t, err)
+	code, err := db.SetBlob(ctx, pulse2, []byte{3})
+	require.NoError(t, err)
+	codeID, err := db.SetRecord(ctx, pulse2, &record.CodeRecord{Code: code})
+	require.NoError(t, err)
+
+	parentIdx := index.ObjectLifeline{
+		LatestState:  amendID,
+		ChildPointer: childRecID,
+		Delegates:    map[core.RecordRef]core.RecordRef{proto: *childRef},
+		State:        record.StateAmend,
+	}
+	err = db.SetObjectIndex(ctx, parentID, &parentIdx)
+	require.NoError(t, err)
+	childIdx := index.ObjectLifeline{
+		LatestState: childID,
+		Parent:      *parentRef,
+		Delegates:   map[core.RecordRef]core.RecordRef{},
+		State:       record.StateActivation,
+	}
+	err = db.SetObjectIndex(ctx, childID, &childIdx)
+	require.NoError(t, err)
+
+	var export bytes.Buffer
+	err = NewExporter(db).Stream(ctx, &export, core.ExportCursor{}, false)
+	require.NoError(t, err)
+
+	imported, importedClean := storagetest.TmpDB(ctx, t)
+	defer importedClean()
+	count, err := NewImporter(imported).Import(ctx, bytes.NewReader(export.Bytes()))
+	require.NoError(t, err)
+	assert.Equal(t, 6, count)
+
+	for _, id := range []*core.RecordID{parentID, amendID, childID, childRecID, codeID} {
+		expected, err := db.GetRecord(ctx, id)
+		require.NoError(t, err)
+		rec, err := imported.GetRecord(ctx, id)
+		require.NoError(t, err)
+		assert.Equal(t, expected, rec)
+	}
+	for _, id := range []*core.RecordID{memory1, memory2, code} {
+		expected, err := db.GetBlob(ctx, id)
+		require.NoError(t, err)
+		blob, err := imported.GetBlob(ctx, id)
+		require.NoError(t, err)
+		assert.Equal(t, expected, blob)
+	}
+
+	idx, err := imported.GetObjectIndex(ctx, parentID, false)
+	require.NoError(t, err)
+	assert.Equal(t, parentIdx, *idx)
+	idx, err = imported.GetObjectIndex(ctx, childID, false)
+	require.NoError(t, err)
+	assert.Equal(t, childIdx, *idx)
+
+	_, err = imported.GetPulse(ctx, pulse2)
+	assert.NoError(t, err)
+}
+
+func TestImporter_Import_IDMismatch(t *testing.T) {
+	ctx := inslogger.TestContext(t)
+	db, clean := storagetest.TmpDB(ctx, t)
+	defer clean()
+
+	pulse := core.GenesisPulse.PulseNumber + 1
+	err := db.AddPulse(ctx, core.Pulse{PulseNumber: pulse})
+	require.NoError(t, err)
+	err = db.AddPulse(ctx, core.Pulse{PulseNumber: pulse + 1})
+	require.NoError(t, err)
+	code, err := db.SetBlob(ctx, pulse, []byte{1})
+	require.NoError(t, err)
+	_, err = db.SetRecord(ctx, pulse, &record.CodeRecord{Code: code})
+	require.NoError(t, err)
+
+	var export bytes.Buffer
+	err = NewExporter(db).Stream(ctx, &export, core.ExportCursor{Pulse: pulse}, false)
+	require.NoError(t, err)
+
+	var line map[string]interface{}
+	require.NoError(t, json.Unmarshal(export.Bytes(), &line))
+	line["Raw"] = record.SerializeRecord(&record.CodeRecord{Code: &core.RecordID{2}})
+	tampered, err := json.Marshal(line)
+	require.NoError(t, err)
+
+	imported, importedClean := storagetest.TmpDB(ctx, t)
+	defer importedClean()
+	_, err = NewImporter(imported).Import(ctx, bytes.NewReader(tampered))
+	assert.Equal(t, ErrIDMismatch, errors.Cause(err))
+}
@@ -0,0 +1,140 @@
+/*
+ *    Copyright 2018 Insolar
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ */
+
+package exporter
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/insolar/insolar/core"
+	"github.com/insolar/insolar/ledger/record"
+	"github.com/insolar/insolar/ledger/storage"
+)
+
+// streamRecord is a single line of streaming export. Pulse and ID form a cursor to resume export from.
+type streamRecord struct {
+	Pulse   core.PulseNumber
+	ID      *core.RecordID
+	Type    string
+	Data    record.Record
+	Payload payload
+	// Raw is a serialized record. It is used to restore the record on import.
+	Raw []byte
+	// Blob is a raw blob referenced by the record: object memory of the state record or code of the code record.
+	Blob []byte `json:",omitempty"`
+}
+
+// recordBlob returns id of the blob referenced by the record or nil if record does not reference one.
+func recordBlob(rec record.Record) *core.RecordID {
+	switch r := rec.(type) {
+	case record.ObjectState:
+		return r.GetMemory()
+	case *record.CodeRecord:
+		return r.Code
+	}
+	return nil
+}
+
+// Stream writes records of closed pulses starting from cursor to w as newline delimited JSON.
+//
+// Records of a pulse are written in storage order, so the pulse and the id of the last written record can be used as
+// a cursor to resume export. If cursor pulse is not stored, export starts from the first stored pulse after it.
+// Records of the latest pulse are written after the pulse is closed. If follow is true, new pulses are written as
+// they close until context is cancelled.
+func (e *Exporter) Stream(ctx context.Context, w io.Writer, from core.ExportCursor, follow bool) error {
+	enc := json.NewEncoder(w)
+	start := core.PulseNumber(math.Max(float64(from.Pulse), float64(core.GenesisPulse.PulseNumber)))
+	current, err := e.db.FindPulse(ctx, start)
+	for err == storage.ErrNotFound && follow {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(e.streamPollInterval):
+		}
+		current, err = e.db.FindPulse(ctx, start)
+	}
+	if err == storage.ErrNotFound {
+		return nil
+	}
+	if err != nil {
+		return errors.Wrapf(err, "failed to find pulse %v", start)
+	}
+
+	// Cursor record belongs to the cursor pulse only.
+	after := from.After
+	if current != from.Pulse {
+		after = nil
+	}
+
+	for {
+		pulse, err := e.db.GetPulse(ctx, current)
+		if err != nil {
+			return errors.Wrapf(err, "failed to fetch pulse %v", current)
+		}
+
+		// Pulse is not closed yet.
+		if pulse.Next == nil {
+			if !follow {
+				return nil
+			}
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(e.streamPollInterval):
+			}
+			continue
+		}
+
+		err = e.db.IterateRecords(ctx, current, func(id core.RecordID, rec record.Record) error {
+			if after != nil && bytes.Compare(id[:], after[:]) <= 0 {
+				return nil
+			}
+			pl, err := e.getPayload(ctx, rec)
+			if err != nil {
+				return err
+			}
+			line := streamRecord{
+				Pulse:   current,
+				ID:      &id,
+				Type:    strings.Title(rec.Type().String()),
+				Data:    rec,
+				Payload: pl,
+				Raw:     record.SerializeRecord(rec),
+			}
+			if blobID := recordBlob(rec); blobID != nil {
+				line.Blob, err = e.db.GetBlob(ctx, blobID)
+				if err != nil {
+					return err
+				}
+			}
+			return enc.Encode(line)
+		})
+		if err != nil {
+			return errors.Wrapf(err, "failed to export pulse %v", current)
+		}
+
+		current = *pulse.Next
+		after = nil
+	}
+}
@@ -17,10 +17,13 @@
 package jetcoordinator
 
 import (
+	"bytes"
 	"context"
+	"sync"
 
 	"github.com/insolar/insolar/configuration"
 	"github.com/insolar/insolar/core"
+	"github.com/insolar/insolar/core/message"
 	"github.com/insolar/insolar/ledger/storage"
 	"github.com/pkg/errors"
 )
@@ -28,33 +31,38 @@ import (
 // JetCoordinator is responsible for all jet interactions
 type JetCoordinator struct {
 	db                         *storage.DB
-	rootJetNode                *JetNode
 	roleCounts                 map[core.JetRole]int
+	splitThreshold             int
+	mergeThreshold             int
+	Bus                        core.MessageBus                 `inject:""`
 	NodeNet                    core.NodeNetwork                `inject:""`
 	PlatformCryptographyScheme core.PlatformCryptographyScheme `inject:""`
+
+	// treeLock serializes jet tree decisions.
+	treeLock sync.Mutex
+	// loads are jet load reports received by jet tree authority.
+	loads     map[core.PulseNumber]*loadReports
+	loadsLock sync.Mutex
 }
 
 // NewJetCoordinator creates new coordinator instance.
 func NewJetCoordinator(db *storage.DB, conf configuration.JetCoordinator) *JetCoordinator {
 	jc := JetCoordinator{
-		db: db,
-		rootJetNode: &JetNode{
-			ref: core.RecordRef{},
-			left: &JetNode{
-				left:  &JetNode{ref: core.RecordRef{}},
-				right: &JetNode{ref: core.RecordRef{}},
-			},
-			right: &JetNode{
-				left:  &JetNode{ref: core.RecordRef{}},
-				right: &JetNode{ref: core.RecordRef{}},
-			},
-		},
+		db:    db,
+		loads: map[core.PulseNumber]*loadReports{},
 	}
 	jc.loadConfig(conf)
 
 	return &jc
 }
 
+// Init registers jet tree message handlers.
+func (jc *JetCoordinator) Init(ctx context.Context) error {
+	jc.Bus.MustRegister(core.TypeGetJetTree, jc.handleGetJetTree)
+	jc.Bus.MustRegister(core.TypeJetLoad, jc.handleJetLoad)
+	return nil
+}
+
 func (jc *JetCoordinator) loadConfig(conf configuration.JetCoordinator) {
 	jc.roleCounts = map[core.JetRole]int{}
 
@@ -62,6 +70,8 @@ func (jc *JetCoordinator) loadConfig(conf configuration.JetCoordinator) {
 		role := core.JetRole(intRole)
 		jc.roleCounts[role] = count
 	}
+	jc.splitThreshold = conf.SplitThreshold
+	jc.mergeThreshold = conf.MergeThreshold
 }
 
 // IsAuthorized checks for role on concrete pulse for the address.
@@ -85,6 +95,9 @@ func (jc *JetCoordinator) IsAuthorized(
 }
 
 // QueryRole returns node refs responsible for role bound operations for given object and pulse.
+//
+// Light material roles are selected for the jet of the object in the jet tree agreed for the pulse, so all objects
+// of a jet share light material nodes.
 func (jc *JetCoordinator) QueryRole(
 	ctx context.Context,
 	role core.JetRole,
@@ -95,7 +108,25 @@ func (jc *JetCoordinator) QueryRole(
 	if err != nil {
 		return nil, err
 	}
-	candidates := jc.NodeNet.GetActiveNodesByRole(role)
+	if jetRoleToNodeRole(role) != core.RoleLightMaterial {
+		return jc.selectNodes(ctx, role, pulseData, nil)
+	}
+
+	tree, err := jc.jetTree(ctx, pulse)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get jet tree")
+	}
+	return jc.selectNodes(ctx, role, pulseData, tree.Find(*obj.Record()))
+}
+
+// selectNodes selects nodes for the role on the pulse. If jet is provided, selection is different for every jet.
+func (jc *JetCoordinator) selectNodes(
+	ctx context.Context, role core.JetRole, pulseData *storage.Pulse, jetID *core.RecordID,
+) ([]core.RecordRef, error) {
+	candidates, err := jc.roleCandidates(ctx, role, pulseData.Pulse.PulseNumber)
+	if err != nil {
+		return nil, err
+	}
 	if len(candidates) == 0 {
 		return nil, errors.New("no candidates for this role")
 	}
@@ -104,16 +135,93 @@ func (jc *JetCoordinator) QueryRole(
 		return nil, errors.New("no candidate count for this role")
 	}
 
-	selected, err := selectByEntropy(jc.PlatformCryptographyScheme, pulseData.Pulse.Entropy, candidates, count)
+	var salt []byte
+	if jetID != nil {
+		salt = jetID[:]
+	}
+	return selectByEntropy(jc.PlatformCryptographyScheme, pulseData.Pulse.Entropy, salt, candidates, count)
+}
+
+// UpdateJetTree reports load of jets executed by this node in the closed pulse to jet tree authority of the next
+// pulse. The authority splits and merges jets of the closed pulse tree according to reported loads and saves the
+// result as the tree of the next pulse.
+//
+// Load of a jet is a number of messages creating records for objects of the jet.
+func (jc *JetCoordinator) UpdateJetTree(ctx context.Context, closed, next core.PulseNumber) error {
+	nextData, err := jc.db.GetPulse(ctx, next)
 	if err != nil {
-		return nil, err
+		return err
+	}
+	// Loads of other pulses are not used by the authority.
+	if nextData.Pulse.PrevPulseNumber == 0 || nextData.Pulse.PrevPulseNumber != closed {
+		return nil
 	}
 
-	return selected, nil
+	tree, err := jc.jetTree(ctx, closed)
+	if err != nil {
+		return errors.Wrap(err, "failed to get jet tree")
+	}
+	load := map[core.RecordID]int{}
+	err = jc.db.IterateMessages(ctx, closed, func(raw []byte) error {
+		parcel, err := message.Deserialize(bytes.NewBuffer(raw))
+		if err != nil {
+			return err
+		}
+		msg := parcel.Message()
+		if !isRecordCreating(msg) {
+			return nil
+		}
+		target := message.ExtractTarget(msg)
+		load[*tree.Find(*target.Record())]++
+		return nil
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed to calculate jets load")
+	}
+
+	authority, err := jc.treeAuthority(ctx, nextData)
+	if err != nil {
+		return err
+	}
+	origin := jc.NodeNet.GetOrigin().ID()
+	if authority == origin {
+		return jc.addLoad(ctx, nextData, origin, load)
+	}
+	_, err = jc.Bus.Send(ctx, &message.JetLoad{Pulse: next, Loads: load}, core.SendOptionDestination(&authority))
+	return err
 }
 
-func (jc *JetCoordinator) jetRef(objRef core.RecordRef) *core.RecordRef { // nolint: megacheck
-	return jc.rootJetNode.GetContaining(&objRef)
+// roleCandidates returns nodes which were active with the role on the pulse. Active nodes of the current
+// network state are used if the pulse is the latest one and its node history is not saved yet.
+func (jc *JetCoordinator) roleCandidates(
+	ctx context.Context, role core.JetRole, pulse core.PulseNumber,
+) ([]core.RecordRef, error) {
+	nodes, err := jc.db.GetActiveNodes(pulse)
+	if err == storage.ErrNotFound {
+		latest, latestErr := jc.db.GetLatestPulseNumber(ctx)
+		if latestErr != nil {
+			return nil, latestErr
+		}
+		if pulse != latest {
+			return nil, errors.Wrapf(err, "no active nodes history for pulse %v", pulse)
+		}
+		return jc.NodeNet.GetActiveNodesByRole(role), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	nodeRole := jetRoleToNodeRole(role)
+	var candidates []core.RecordRef
+	for _, n := range nodes {
+		for _, r := range n.Roles() {
+			if r == nodeRole {
+				candidates = append(candidates, n.ID())
+				break
+			}
+		}
+	}
+	return candidates, nil
 }
 
 // GetActiveNodes return active nodes for specified pulse.
@@ -18,16 +18,26 @@ package jetcoordinator_test
 
 import (
 	"bytes"
+	"context"
 	"sort"
 	"testing"
 
 	"github.com/insolar/insolar/configuration"
 	"github.com/insolar/insolar/core"
+	"github.com/insolar/insolar/core/message"
+	"github.com/insolar/insolar/core/reply"
 	"github.com/insolar/insolar/instrumentation/inslogger"
+	"github.com/insolar/insolar/ledger/jetcoordinator"
 	"github.com/insolar/insolar/ledger/ledgertestutils"
+	"github.com/insolar/insolar/ledger/storage"
+	"github.com/insolar/insolar/ledger/storage/jet"
+	"github.com/insolar/insolar/ledger/storage/storagetest"
 	"github.com/insolar/insolar/logicrunner"
 	"github.com/insolar/insolar/network/nodenetwork"
+	"github.com/insolar/insolar/platformpolicy"
+	"github.com/insolar/insolar/testutils"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func newActiveNode(ref core.RecordRef, role core.NodeRole) core.Node {
@@ -86,3 +96,311 @@ func TestJetCoordinator_QueryRole(t *testing.T) {
 	selected, err = jc.QueryRole(ctx, core.RoleHeavyExecutor, am.GenesisRef(), pulse.PulseNumber)
 	assert.Error(t, err)
 }
+
+func TestJetCoordinator_QueryRole_NodeHistory(t *testing.T) {
+	ctx := inslogger.TestContext(t)
+	db, cleaner := storagetest.TmpDB(ctx, t)
+	defer cleaner()
+
+	ref := func(r string) core.RecordRef { return core.NewRefFromBase58(r) }
+	oldVirtual := ref("53jNWvey7Nzyh4ZaLdJDf3SRgoD4GpWuwHgrgvVVGLbDkk3A7cwStSmBU2X7s4fm6cZtemEyJbce9dM9SwNxbsxf")
+	newVirtual := ref("4gU79K6woTZDvn4YUFHauNKfcHW69X42uyk8ZvRevCiMv3PLS24eM1vcA9mhKPv8b2jWj9J5RgGN9CB7PUzCtBsj")
+
+	pulse := core.PulseNumber(core.FirstPulseNumber + 1)
+	err := db.AddPulse(ctx, core.Pulse{PulseNumber: pulse})
+	require.NoError(t, err)
+	err = db.SetActiveNodes(pulse, []core.Node{newActiveNode(oldVirtual, core.RoleVirtual)})
+	require.NoError(t, err)
+
+	keeper := nodenetwork.NewNodeKeeper(nodenetwork.NewNode(core.RecordRef{}, nil, nil, 0, "", ""))
+	keeper.AddActiveNodes([]core.Node{newActiveNode(newVirtual, core.RoleVirtual)})
+
+	jc := jetcoordinator.NewJetCoordinator(db, configuration.NewLedger().JetCoordinator)
+	jc.NodeNet = keeper
+	jc.PlatformCryptographyScheme = platformpolicy.NewPlatformCryptographyScheme()
+
+	obj := testutils.RandomRef()
+	selected, err := jc.QueryRole(ctx, core.RoleVirtualExecutor, &obj, pulse)
+	require.NoError(t, err)
+	assert.Equal(t, []core.RecordRef{oldVirtual}, selected)
+
+	ok, err := jc.IsAuthorized(ctx, core.RoleVirtualExecutor, &obj, pulse, newVirtual)
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	// No history for a past pulse.
+	_, err = jc.QueryRole(ctx, core.RoleVirtualExecutor, &obj, core.FirstPulseNumber)
+	assert.Error(t, err)
+
+	// No history for the latest pulse yet, current active nodes are used.
+	latest := pulse + 1
+	err = db.AddPulse(ctx, core.Pulse{PulseNumber: latest})
+	require.NoError(t, err)
+	selected, err = jc.QueryRole(ctx, core.RoleVirtualExecutor, &obj, latest)
+	require.NoError(t, err)
+	assert.Equal(t, []core.RecordRef{newVirtual}, selected)
+}
+
+// Pulse without previous one has a single jet, so all objects share light executor.
+func TestJetCoordinator_QueryRole_SameLightExecutorForObjects(t *testing.T) {
+	ctx := inslogger.TestContext(t)
+	db, cleaner := storagetest.TmpDB(ctx, t)
+	defer cleaner()
+
+	pulse := core.PulseNumber(core.FirstPulseNumber + 1)
+	err := db.AddPulse(ctx, core.Pulse{PulseNumber: pulse, Entropy: core.Entropy{1, 2, 3}})
+	require.NoError(t, err)
+	var nodes []core.Node
+	for i := 0; i < 5; i++ {
+		nodes = append(nodes, newActiveNode(testutils.RandomRef(), core.RoleLightMaterial))
+	}
+	err = db.SetActiveNodes(pulse, nodes)
+	require.NoError(t, err)
+
+	jc := jetcoordinator.NewJetCoordinator(db, configuration.NewLedger().JetCoordinator)
+	jc.PlatformCryptographyScheme = platformpolicy.NewPlatformCryptographyScheme()
+
+	prototype := testutils.RandomRef()
+	expected, err := jc.QueryRole(ctx, core.RoleLightExecutor, &prototype, pulse)
+	require.NoError(t, err)
+	for i := 0; i < 10; i++ {
+		obj := testutils.RandomRef()
+		selected, err := jc.QueryRole(ctx, core.RoleLightExecutor, &obj, pulse)
+		require.NoError(t, err)
+		assert.Equal(t, expected, selected)
+	}
+}
+
+func TestJetCoordinator_QueryRole_Jets(t *testing.T) {
+	ctx := inslogger.TestContext(t)
+	db, cleaner := storagetest.TmpDB(ctx, t)
+	defer cleaner()
+
+	pulse := core.PulseNumber(core.FirstPulseNumber + 2)
+	err := db.AddPulse(ctx, core.Pulse{
+		PulseNumber:     pulse,
+		PrevPulseNumber: pulse - 1,
+		Entropy:         core.Entropy{1, 2, 3},
+	})
+	require.NoError(t, err)
+	var nodes []core.Node
+	for i := 0; i < 20; i++ {
+		nodes = append(nodes, newActiveNode(testutils.RandomRef(), core.RoleLightMaterial))
+	}
+	err = db.SetActiveNodes(pulse, nodes)
+	require.NoError(t, err)
+
+	tree := jet.NewTree()
+	_, _, err = tree.Split(*jet.NewID(0, nil))
+	require.NoError(t, err)
+	err = db.SetJetTree(ctx, pulse, tree)
+	require.NoError(t, err)
+
+	jc := jetcoordinator.NewJetCoordinator(db, configuration.NewLedger().JetCoordinator)
+	jc.PlatformCryptographyScheme = platformpolicy.NewPlatformCryptographyScheme()
+
+	// Objects which hashes start with 0 and 1 bits.
+	object := func(first byte) core.RecordRef {
+		obj := testutils.RandomRef()
+		obj[core.PulseNumberSize] = first
+		return obj
+	}
+	leftObj, rightObj := object(0x00), object(0x80)
+	left, err := jc.QueryRole(ctx, core.RoleLightExecutor, &leftObj, pulse)
+	require.NoError(t, err)
+	right, err := jc.QueryRole(ctx, core.RoleLightExecutor, &rightObj, pulse)
+	require.NoError(t, err)
+	assert.NotEqual(t, left, right)
+
+	for i := 0; i < 10; i++ {
+		obj := object(0x01)
+		selected, err := jc.QueryRole(ctx, core.RoleLightExecutor, &obj, pulse)
+		require.NoError(t, err)
+		assert.Equal(t, left, selected)
+	}
+}
+
+// newPulses saves pulses following each other with the only active node.
+func newPulses(ctx context.Context, t *testing.T, db *storage.DB, node core.Node, count int) []core.PulseNumber {
+	var (
+		pulses []core.PulseNumber
+		prev   core.PulseNumber
+	)
+	for i := 0; i < count; i++ {
+		pulse := core.PulseNumber(core.FirstPulseNumber + 1 + i)
+		err := db.AddPulse(ctx, core.Pulse{PulseNumber: pulse, PrevPulseNumber: prev})
+		require.NoError(t, err)
+		err = db.SetActiveNodes(pulse, []core.Node{node})
+		require.NoError(t, err)
+		pulses = append(pulses, pulse)
+		prev = pulse
+	}
+	return pulses
+}
+
+func TestJetCoordinator_UpdateJetTree(t *testing.T) {
+	ctx := inslogger.TestContext(t)
+	db, cleaner := storagetest.TmpDB(ctx, t)
+	defer cleaner()
+
+	origin := newActiveNode(testutils.RandomRef(), core.RoleLightMaterial)
+	jc := jetcoordinator.NewJetCoordinator(db, configuration.JetCoordinator{
+		RoleCounts:     configuration.NewLedger().JetCoordinator.RoleCounts,
+		SplitThreshold: 2,
+		MergeThreshold: 2,
+	})
+	jc.PlatformCryptographyScheme = platformpolicy.NewPlatformCryptographyScheme()
+	jc.NodeNet = nodenetwork.NewNodeKeeper(origin)
+
+	// Objects which hashes start with 0 and 1 bits.
+	var leftObj, rightObj core.RecordRef
+	leftObj[core.PulseNumberSize] = 0x00
+	rightObj[core.PulseNumberSize] = 0x80
+
+	setRecords := func(pulse core.PulseNumber, obj core.RecordRef, count int) {
+		for i := 0; i < count; i++ {
+			err := db.SetMessage(ctx, pulse, &message.SetRecord{
+				Record:    []byte{byte(i)},
+				TargetRef: obj,
+			})
+			require.NoError(t, err)
+		}
+	}
+	// The only light material node is jet tree authority, so the tree is decided on the first query.
+	agreedTree := func(pulse core.PulseNumber) *jet.Tree {
+		_, err := jc.QueryRole(ctx, core.RoleLightExecutor, &leftObj, pulse)
+		require.NoError(t, err)
+		tree, err := db.GetJetTree(ctx, pulse)
+		require.NoError(t, err)
+		return tree
+	}
+
+	pulses := newPulses(ctx, t, db, origin, 4)
+
+	// Root jet is overloaded.
+	setRecords(pulses[0], leftObj, 2)
+	setRecords(pulses[0], rightObj, 1)
+	err := jc.UpdateJetTree(ctx, pulses[0], pulses[1])
+	require.NoError(t, err)
+	tree := agreedTree(pulses[1])
+	assert.Equal(t, []core.RecordID{*jet.NewID(1, []byte{0x00}), *jet.NewID(1, []byte{0x80})}, tree.Leaves())
+
+	// Objects are located in different jets.
+	assert.Equal(t, *jet.NewID(1, []byte{0x00}), *tree.Find(*leftObj.Record()))
+	assert.Equal(t, *jet.NewID(1, []byte{0x80}), *tree.Find(*rightObj.Record()))
+
+	// Load is balanced, tree is not changed.
+	setRecords(pulses[1], leftObj, 1)
+	setRecords(pulses[1], rightObj, 1)
+	err = jc.UpdateJetTree(ctx, pulses[1], pulses[2])
+	require.NoError(t, err)
+	tree = agreedTree(pulses[2])
+	assert.Equal(t, []core.RecordID{*jet.NewID(1, []byte{0x00}), *jet.NewID(1, []byte{0x80})}, tree.Leaves())
+
+	// No load, jets are merged back.
+	err = jc.UpdateJetTree(ctx, pulses[2], pulses[3])
+	require.NoError(t, err)
+	tree = agreedTree(pulses[3])
+	assert.Equal(t, []core.RecordID{*jet.NewID(0, nil)}, tree.Leaves())
+
+	// Decided tree is not changed by late reports.
+	setRecords(pulses[2], leftObj, 5)
+	err = jc.UpdateJetTree(ctx, pulses[2], pulses[3])
+	require.NoError(t, err)
+	assert.Equal(t, []core.RecordID{*jet.NewID(0, nil)}, agreedTree(pulses[3]).Leaves())
+}
+
+func TestJetCoordinator_JetTreeAuthority(t *testing.T) {
+	ctx := inslogger.TestContext(t)
+	db, cleaner := storagetest.TmpDB(ctx, t)
+	defer cleaner()
+
+	authority := newActiveNode(testutils.RandomRef(), core.RoleLightMaterial)
+	pulses := newPulses(ctx, t, db, authority, 2)
+
+	split := jet.NewTree()
+	_, _, err := split.Split(*jet.NewID(0, nil))
+	require.NoError(t, err)
+
+	handlers := map[core.MessageType]core.MessageHandler{}
+	bus := testutils.NewMessageBusMock(t)
+	bus.MustRegisterFunc = func(mt core.MessageType, handler core.MessageHandler) {
+		handlers[mt] = handler
+	}
+	var requests int
+	bus.SendFunc = func(ctx context.Context, msg core.Message, options ...core.SendOption) (core.Reply, error) {
+		requests++
+		sendOptions := core.SendOptions{}
+		for _, option := range options {
+			option(&sendOptions)
+		}
+		require.NotNil(t, sendOptions.Receiver)
+		assert.Equal(t, authority.ID(), *sendOptions.Receiver)
+		assert.Equal(t, &message.GetJetTree{Pulse: pulses[1]}, msg)
+		return &reply.JetTree{Tree: split.Bytes()}, nil
+	}
+
+	jc := jetcoordinator.NewJetCoordinator(db, configuration.NewLedger().JetCoordinator)
+	jc.PlatformCryptographyScheme = platformpolicy.NewPlatformCryptographyScheme()
+	jc.NodeNet = nodenetwork.NewNodeKeeper(newActiveNode(testutils.RandomRef(), core.RoleVirtual))
+	jc.Bus = bus
+	err = jc.Init(ctx)
+	require.NoError(t, err)
+
+	// Tree is fetched from the authority once and saved.
+	obj := testutils.RandomRef()
+	for i := 0; i < 2; i++ {
+		_, err = jc.QueryRole(ctx, core.RoleLightExecutor, &obj, pulses[1])
+		require.NoError(t, err)
+	}
+	assert.Equal(t, 1, requests)
+	tree, err := db.GetJetTree(ctx, pulses[1])
+	require.NoError(t, err)
+	assert.Equal(t, split.Leaves(), tree.Leaves())
+
+	// Only the authority answers jet tree messages.
+	_, err = handlers[core.TypeGetJetTree](ctx, &message.Parcel{Msg: &message.GetJetTree{Pulse: pulses[1]}})
+	assert.Error(t, err)
+	_, err = handlers[core.TypeJetLoad](ctx, &message.Parcel{
+		Sender: authority.ID(),
+		Msg:    &message.JetLoad{Pulse: pulses[1]},
+	})
+	assert.Error(t, err)
+}
+
+func TestJetCoordinator_JetLoad_UnknownSender(t *testing.T) {
+	ctx := inslogger.TestContext(t)
+	db, cleaner := storagetest.TmpDB(ctx, t)
+	defer cleaner()
+
+	origin := newActiveNode(testutils.RandomRef(), core.RoleLightMaterial)
+	pulses := newPulses(ctx, t, db, origin, 2)
+
+	handlers := map[core.MessageType]core.MessageHandler{}
+	bus := testutils.NewMessageBusMock(t)
+	bus.MustRegisterFunc = func(mt core.MessageType, handler core.MessageHandler) {
+		handlers[mt] = handler
+	}
+
+	jc := jetcoordinator.NewJetCoordinator(db, configuration.NewLedger().JetCoordinator)
+	jc.PlatformCryptographyScheme = platformpolicy.NewPlatformCryptographyScheme()
+	jc.NodeNet = nodenetwork.NewNodeKeeper(origin)
+	jc.Bus = bus
+	err := jc.Init(ctx)
+	require.NoError(t, err)
+
+	_, err = handlers[core.TypeJetLoad](ctx, &message.Parcel{
+		Sender: testutils.RandomRef(),
+		Msg:    &message.JetLoad{Pulse: pulses[1], Loads: map[core.RecordID]int{*jet.NewID(0, nil): 100}},
+	})
+	assert.Error(t, err)
+
+	_, err = handlers[core.TypeJetLoad](ctx, &message.Parcel{
+		Sender: origin.ID(),
+		Msg:    &message.JetLoad{Pulse: pulses[1]},
+	})
+	assert.NoError(t, err)
+	rep, err := handlers[core.TypeGetJetTree](ctx, &message.Parcel{Msg: &message.GetJetTree{Pulse: pulses[1]}})
+	require.NoError(t, err)
+	assert.Equal(t, jet.NewTree().Bytes(), rep.(*reply.JetTree).Tree)
+}
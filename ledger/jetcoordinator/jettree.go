@@ -0,0 +1,341 @@
+/*
+ *    Copyright 2018 Insolar
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ */
+
+package jetcoordinator
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/insolar/insolar/core"
+	"github.com/insolar/insolar/core/message"
+	"github.com/insolar/insolar/core/reply"
+	"github.com/insolar/insolar/instrumentation/inslogger"
+	"github.com/insolar/insolar/ledger/storage"
+	"github.com/insolar/insolar/ledger/storage/jet"
+)
+
+// jetLoadTimeout is a time jet tree authority waits for load reports of all light material nodes.
+const jetLoadTimeout = 2 * time.Second
+
+// loadReports are jet loads of the previous pulse reported to jet tree authority of the pulse.
+type loadReports struct {
+	expected map[core.RecordRef]bool
+	reported map[core.RecordRef]bool
+	load     map[core.RecordID]int
+	// done is closed when all expected nodes reported.
+	done chan struct{}
+}
+
+// jetTree returns jet tree agreed for the pulse.
+//
+// The tree is decided by jet tree authority of the pulse, a light material node selected by entropy of the pulse.
+// Other nodes fetch the tree from the authority. Trees are saved in the ledger, so the decision is not changed.
+func (jc *JetCoordinator) jetTree(ctx context.Context, pulse core.PulseNumber) (*jet.Tree, error) {
+	tree, err := jc.db.GetJetTree(ctx, pulse)
+	if err != storage.ErrNotFound {
+		return tree, err
+	}
+	pulseData, err := jc.db.GetPulse(ctx, pulse)
+	if err != nil {
+		return nil, err
+	}
+	// There is no load to split jets by before the first pulse with a previous one.
+	if pulseData.Pulse.PrevPulseNumber == 0 {
+		return jet.NewTree(), nil
+	}
+
+	authority, err := jc.treeAuthority(ctx, pulseData)
+	if err != nil {
+		return nil, err
+	}
+	if authority == jc.NodeNet.GetOrigin().ID() {
+		return jc.decideJetTree(ctx, pulseData)
+	}
+	return jc.fetchJetTree(ctx, pulse, authority)
+}
+
+// treeAuthority returns the node which decides jet tree of the pulse.
+func (jc *JetCoordinator) treeAuthority(ctx context.Context, pulseData *storage.Pulse) (core.RecordRef, error) {
+	candidates, err := jc.roleCandidates(ctx, core.RoleLightExecutor, pulseData.Pulse.PulseNumber)
+	if err != nil {
+		return core.RecordRef{}, err
+	}
+	if len(candidates) == 0 {
+		return core.RecordRef{}, errors.New("no light material nodes to decide jet tree")
+	}
+	selected, err := selectByEntropy(jc.PlatformCryptographyScheme, pulseData.Pulse.Entropy, nil, candidates, 1)
+	if err != nil {
+		return core.RecordRef{}, err
+	}
+	return selected[0], nil
+}
+
+// fetchJetTree requests jet tree of the pulse from jet tree authority and saves it.
+func (jc *JetCoordinator) fetchJetTree(
+	ctx context.Context, pulse core.PulseNumber, authority core.RecordRef,
+) (*jet.Tree, error) {
+	genericReply, err := jc.Bus.Send(ctx, &message.GetJetTree{Pulse: pulse}, core.SendOptionDestination(&authority))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to fetch jet tree")
+	}
+	rep, ok := genericReply.(*reply.JetTree)
+	if !ok {
+		return nil, errors.Errorf("unexpected reply %T on jet tree request", genericReply)
+	}
+	tree, err := jet.Decode(rep.Tree)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to decode jet tree")
+	}
+	if err = jc.db.SetJetTree(ctx, pulse, tree); err != nil {
+		return nil, err
+	}
+	return tree, nil
+}
+
+// decideJetTree splits and merges jets of the previous pulse tree according to reported loads and saves the result
+// as the tree of the pulse. Should be called on jet tree authority of the pulse only.
+//
+// Jets with load over split threshold are split in two. Sibling jets with total load under merge threshold are
+// merged.
+func (jc *JetCoordinator) decideJetTree(ctx context.Context, pulseData *storage.Pulse) (*jet.Tree, error) {
+	pulse := pulseData.Pulse.PulseNumber
+	tree, err := jc.db.GetJetTree(ctx, pulse)
+	if err != storage.ErrNotFound {
+		return tree, err
+	}
+
+	base, err := jc.jetTree(ctx, pulseData.Pulse.PrevPulseNumber)
+	if err != nil {
+		// Other nodes take the tree from the authority, so the tree stays agreed.
+		inslogger.FromContext(ctx).Warnf("failed to get jet tree of pulse %v, single jet is used: %v",
+			pulseData.Pulse.PrevPulseNumber, err)
+		base = jet.NewTree()
+	}
+
+	reports, err := jc.loadReports(ctx, pulseData)
+	if err != nil {
+		return nil, err
+	}
+	select {
+	case <-reports.done:
+	case <-time.After(jetLoadTimeout):
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	jc.treeLock.Lock()
+	defer jc.treeLock.Unlock()
+
+	tree, err = jc.db.GetJetTree(ctx, pulse)
+	if err != storage.ErrNotFound {
+		return tree, err
+	}
+
+	jc.loadsLock.Lock()
+	load := make(map[core.RecordID]int, len(reports.load))
+	for id, n := range reports.load {
+		load[id] = n
+	}
+	jc.loadsLock.Unlock()
+
+	tree = base.Clone()
+	if err = jc.balance(tree, load); err != nil {
+		return nil, err
+	}
+	if err = jc.db.SetJetTree(ctx, pulse, tree); err != nil {
+		return nil, err
+	}
+
+	jc.loadsLock.Lock()
+	delete(jc.loads, pulse)
+	jc.loadsLock.Unlock()
+	return tree, nil
+}
+
+// balance splits overloaded jets and merges underloaded siblings.
+func (jc *JetCoordinator) balance(tree *jet.Tree, load map[core.RecordID]int) error {
+	leaves := tree.Leaves()
+	isLeaf := make(map[core.RecordID]bool, len(leaves))
+	for _, id := range leaves {
+		isLeaf[id] = true
+	}
+
+	merged := map[core.RecordID]bool{}
+	for _, id := range leaves {
+		if merged[id] {
+			continue
+		}
+		if jc.splitThreshold > 0 && load[id] > jc.splitThreshold {
+			if _, _, err := tree.Split(id); err != nil {
+				return err
+			}
+			continue
+		}
+		sibling, isLeft := siblingJet(id)
+		// Each pair is checked once from its left jet.
+		if sibling == nil || !isLeft || !isLeaf[*sibling] {
+			continue
+		}
+		if load[id]+load[*sibling] < jc.mergeThreshold {
+			if _, err := tree.Merge(id); err != nil {
+				return err
+			}
+			merged[*sibling] = true
+		}
+	}
+	return nil
+}
+
+// loadReports returns load reports for the pulse. Reports of older pulses are dropped.
+func (jc *JetCoordinator) loadReports(ctx context.Context, pulseData *storage.Pulse) (*loadReports, error) {
+	pulse := pulseData.Pulse.PulseNumber
+	jc.loadsLock.Lock()
+	reports, ok := jc.loads[pulse]
+	jc.loadsLock.Unlock()
+	if ok {
+		return reports, nil
+	}
+
+	candidates, err := jc.roleCandidates(ctx, core.RoleLightExecutor, pulse)
+	if err != nil {
+		return nil, err
+	}
+	reports = &loadReports{
+		expected: make(map[core.RecordRef]bool, len(candidates)),
+		reported: map[core.RecordRef]bool{},
+		load:     map[core.RecordID]int{},
+		done:     make(chan struct{}),
+	}
+	for _, node := range candidates {
+		reports.expected[node] = true
+	}
+
+	jc.loadsLock.Lock()
+	defer jc.loadsLock.Unlock()
+	if existing, ok := jc.loads[pulse]; ok {
+		return existing, nil
+	}
+	for pn := range jc.loads {
+		if pn < pulse {
+			delete(jc.loads, pn)
+		}
+	}
+	jc.loads[pulse] = reports
+	return reports, nil
+}
+
+// addLoad saves jet loads reported by the node to jet tree authority. Loads of jets the node was not executor of in
+// the previous pulse are ignored. Reports after the tree is decided are ignored.
+func (jc *JetCoordinator) addLoad(
+	ctx context.Context, pulseData *storage.Pulse, node core.RecordRef, load map[core.RecordID]int,
+) error {
+	pulse := pulseData.Pulse.PulseNumber
+	if _, err := jc.db.GetJetTree(ctx, pulse); err != storage.ErrNotFound {
+		return err
+	}
+	reports, err := jc.loadReports(ctx, pulseData)
+	if err != nil {
+		return err
+	}
+	if !reports.expected[node] {
+		return errors.Errorf("node %v is not light material node of pulse %v", node, pulse)
+	}
+
+	accepted := map[core.RecordID]int{}
+	prevData, err := jc.db.GetPulse(ctx, pulseData.Pulse.PrevPulseNumber)
+	if err != nil && err != storage.ErrNotFound {
+		return err
+	}
+	// Loads can't be checked if the previous pulse is unknown.
+	if err == nil {
+		for id, n := range load {
+			jetID := id
+			executors, err := jc.selectNodes(ctx, core.RoleLightExecutor, prevData, &jetID)
+			if err != nil {
+				return err
+			}
+			for _, executor := range executors {
+				if executor == node {
+					accepted[id] = n
+					break
+				}
+			}
+		}
+	}
+
+	jc.loadsLock.Lock()
+	defer jc.loadsLock.Unlock()
+	if reports.reported[node] {
+		return nil
+	}
+	reports.reported[node] = true
+	for id, n := range accepted {
+		reports.load[id] += n
+	}
+	if len(reports.reported) == len(reports.expected) {
+		close(reports.done)
+	}
+	return nil
+}
+
+// handleGetJetTree returns jet tree of the pulse if this node is jet tree authority of the pulse.
+func (jc *JetCoordinator) handleGetJetTree(ctx context.Context, parcel core.Parcel) (core.Reply, error) {
+	msg := parcel.Message().(*message.GetJetTree)
+	pulseData, err := jc.authorityPulse(ctx, msg.Pulse)
+	if err != nil {
+		return nil, err
+	}
+	tree, err := jc.decideJetTree(ctx, pulseData)
+	if err != nil {
+		return nil, err
+	}
+	return &reply.JetTree{Tree: tree.Bytes()}, nil
+}
+
+// handleJetLoad saves jet loads reported by the sender if this node is jet tree authority of the pulse.
+func (jc *JetCoordinator) handleJetLoad(ctx context.Context, parcel core.Parcel) (core.Reply, error) {
+	msg := parcel.Message().(*message.JetLoad)
+	pulseData, err := jc.authorityPulse(ctx, msg.Pulse)
+	if err != nil {
+		return nil, err
+	}
+	if err = jc.addLoad(ctx, pulseData, parcel.GetSender(), msg.Loads); err != nil {
+		return nil, err
+	}
+	return &reply.OK{}, nil
+}
+
+// authorityPulse returns the pulse if this node is jet tree authority of the pulse.
+func (jc *JetCoordinator) authorityPulse(ctx context.Context, pulse core.PulseNumber) (*storage.Pulse, error) {
+	pulseData, err := jc.db.GetPulse(ctx, pulse)
+	if err != nil {
+		return nil, err
+	}
+	if pulseData.Pulse.PrevPulseNumber == 0 {
+		return nil, errors.Errorf("jet tree of pulse %v is not decided by authority", pulse)
+	}
+	authority, err := jc.treeAuthority(ctx, pulseData)
+	if err != nil {
+		return nil, err
+	}
+	if authority != jc.NodeNet.GetOrigin().ID() {
+		return nil, errors.Errorf("node is not jet tree authority of pulse %v", pulse)
+	}
+	return pulseData, nil
+}
@@ -22,9 +22,17 @@ import (
 	"sort"
 
 	"github.com/insolar/insolar/core"
+	"github.com/insolar/insolar/core/message"
+	"github.com/insolar/insolar/ledger/storage/jet"
 )
 
-func selectByEntropy(scheme core.PlatformCryptographyScheme, entropy core.Entropy, values []core.RecordRef, count int) ([]core.RecordRef, error) { // nolint: megacheck
+func selectByEntropy(
+	scheme core.PlatformCryptographyScheme,
+	entropy core.Entropy,
+	salt []byte,
+	values []core.RecordRef,
+	count int,
+) ([]core.RecordRef, error) {
 	type idxHash struct {
 		idx  int
 		hash []byte
@@ -41,6 +49,10 @@ func selectByEntropy(scheme core.PlatformCryptographyScheme, entropy core.Entrop
 		if err != nil {
 			return nil, err
 		}
+		_, err = h.Write(salt)
+		if err != nil {
+			return nil, err
+		}
 		_, err = h.Write(value[:])
 		if err != nil {
 			return nil, err
@@ -59,3 +71,40 @@ func selectByEntropy(scheme core.PlatformCryptographyScheme, entropy core.Entrop
 	}
 	return selected, nil
 }
+
+func jetRoleToNodeRole(role core.JetRole) core.NodeRole {
+	switch role {
+	case core.RoleVirtualExecutor, core.RoleVirtualValidator:
+		return core.RoleVirtual
+	case core.RoleLightExecutor, core.RoleLightValidator:
+		return core.RoleLightMaterial
+	case core.RoleHeavyExecutor:
+		return core.RoleHeavyMaterial
+	default:
+		return core.RoleUnknown
+	}
+}
+
+// isRecordCreating checks if message creates records for its target object.
+func isRecordCreating(msg core.Message) bool {
+	switch msg.(type) {
+	case *message.SetRecord, *message.SetBlob, *message.UpdateObject, *message.RegisterChild:
+		return true
+	default:
+		return false
+	}
+}
+
+// siblingJet returns sibling of provided jet and whether provided jet is the left one. Root jet has no sibling.
+func siblingJet(id core.RecordID) (*core.RecordID, bool) {
+	depth, prefix := jet.Jet(id)
+	if depth == 0 {
+		return nil, false
+	}
+	sibling := append([]byte(nil), prefix...)
+	index := depth - 1
+	mask := byte(0x80 >> (index % 8))
+	isLeft := sibling[index/8]&mask == 0
+	sibling[index/8] ^= mask
+	return jet.NewID(depth, sibling), isLeft
+}
@@ -17,9 +17,29 @@
 package jetdrop
 
 import (
+	"github.com/pkg/errors"
+
 	"github.com/insolar/insolar/core"
 )
 
+// HashVersion is a version of jet drop hash scheme. Every drop keeps the version it was created with, so drops created
+// before the scheme change can still be verified.
+type HashVersion uint8
+
+const (
+	// HashVersionPrev is a hash of the previous drop hash. Drops created before hash versioning have this version.
+	HashVersionPrev HashVersion = iota
+	// HashVersionRecords is a hash of the previous drop hash followed by record hashes of the pulse.
+	HashVersionRecords
+	// HashVersionMerkle is a root of Merkle tree of the previous drop hash and record hashes of the pulse.
+	HashVersionMerkle
+	// HashVersionMerkleSeparated is a root of Merkle tree with different prefixes for leaf and inner node hashes.
+	HashVersionMerkleSeparated
+
+	// HashVersionLatest is a version used for new drops.
+	HashVersionLatest = HashVersionMerkleSeparated
+)
+
 // JetDrop is a blockchain block.
 // It contains hashes of the current block and the previous one.
 type JetDrop struct {
@@ -31,4 +51,34 @@ type JetDrop struct {
 
 	// Hash is a hash of all record hashes belongs to one pulse and previous drop hash.
 	Hash []byte
+
+	// HashVersion is a version of the scheme Hash was calculated with.
+	HashVersion HashVersion
+}
+
+// VersionedHash calculates jet drop hash with provided scheme version.
+func VersionedHash(hasher core.Hasher, version HashVersion, prevHash []byte, records [][]byte) ([]byte, error) {
+	switch version {
+	case HashVersionPrev:
+		records = nil
+	case HashVersionRecords:
+	case HashVersionMerkle:
+		return legacyMerkleHash(hasher, prevHash, records)
+	case HashVersionMerkleSeparated:
+		return Hash(hasher, prevHash, records)
+	default:
+		return nil, errors.Errorf("unknown jet drop hash version %d", version)
+	}
+
+	_, err := hasher.Write(prevHash)
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range records {
+		_, err = hasher.Write(r)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return hasher.Sum(nil), nil
 }
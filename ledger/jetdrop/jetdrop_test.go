@@ -20,6 +20,9 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/insolar/insolar/platformpolicy"
 )
 
 func TestJetDrop_Hash(t *testing.T) {
@@ -51,3 +54,42 @@ func TestJetDrop_Hash(t *testing.T) {
 	assert.NotEqual(t, drop1got, drop2got)
 	assert.NotEqual(t, b1, b2)
 }
+
+func TestVersionedHash(t *testing.T) {
+	scheme := platformpolicy.NewPlatformCryptographyScheme()
+	prevHash := []byte{1, 2, 3}
+	records := [][]byte{{4}, {5}}
+	hash := func(data ...[]byte) []byte {
+		h := scheme.ReferenceHasher()
+		for _, d := range data {
+			_, err := h.Write(d)
+			require.NoError(t, err)
+		}
+		return h.Sum(nil)
+	}
+	merkle, err := legacyMerkleHash(scheme.ReferenceHasher(), prevHash, records)
+	require.NoError(t, err)
+	separated, err := Hash(scheme.ReferenceHasher(), prevHash, records)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name    string
+		version HashVersion
+		hash    []byte
+	}{
+		{"prev", HashVersionPrev, hash(prevHash)},
+		{"records", HashVersionRecords, hash(prevHash, records[0], records[1])},
+		{"merkle", HashVersionMerkle, merkle},
+		{"merkle separated", HashVersionMerkleSeparated, separated},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := VersionedHash(scheme.ReferenceHasher(), tt.version, prevHash, records)
+			require.NoError(t, err)
+			assert.Equal(t, tt.hash, got)
+		})
+	}
+
+	_, err = VersionedHash(scheme.ReferenceHasher(), HashVersionLatest+1, prevHash, records)
+	assert.Error(t, err)
+}
@@ -0,0 +1,130 @@
+/*
+ *    Copyright 2018 Insolar
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ */
+
+package jetdrop
+
+import (
+	"bytes"
+
+	"github.com/onrik/gomerkle"
+	"github.com/pkg/errors"
+
+	"github.com/insolar/insolar/core"
+)
+
+// Prefixes of Merkle tree hashes. Leaves and inner nodes are hashed with different prefixes, so an inner node can't
+// be presented as a record in a proof.
+const (
+	leafPrefix = 0x00
+	nodePrefix = 0x01
+)
+
+// Hash calculates jet drop hash.
+//
+// Drop hash is a root of Merkle tree. The first leaf of the tree is the previous drop hash, the rest are record
+// hashes of the pulse. A level with odd number of nodes moves its last node to the next level as is.
+func Hash(hasher core.Hasher, prevHash []byte, records [][]byte) ([]byte, error) {
+	levels := merkleLevels(hasher, prevHash, records)
+	return levels[len(levels)-1][0], nil
+}
+
+// Proof returns Merkle path from the record with provided hash to the drop hash.
+func Proof(hasher core.Hasher, prevHash []byte, records [][]byte, record []byte) ([]core.RecordProofStep, error) {
+	index := -1
+	for i, r := range records {
+		if bytes.Equal(r, record) {
+			index = i
+			break
+		}
+	}
+	if index < 0 {
+		return nil, errors.New("record is not included in the drop")
+	}
+
+	levels := merkleLevels(hasher, prevHash, records)
+	// The first leaf is reserved for the previous drop hash.
+	index++
+	var path []core.RecordProofStep
+	for _, level := range levels[:len(levels)-1] {
+		sibling := index ^ 1
+		if sibling < len(level) {
+			path = append(path, core.RecordProofStep{Hash: level[sibling], Left: sibling < index})
+		}
+		index /= 2
+	}
+	return path, nil
+}
+
+// VerifyProof checks that the proof leads from the record to the drop hash.
+func VerifyProof(hasher core.Hasher, proof *core.RecordProof) bool {
+	hash := leafHash(hasher, proof.Record.Hash())
+	for _, step := range proof.Path {
+		if step.Left {
+			hash = nodeHash(hasher, step.Hash, hash)
+		} else {
+			hash = nodeHash(hasher, hash, step.Hash)
+		}
+	}
+	return bytes.Equal(hash, proof.DropHash)
+}
+
+// merkleLevels returns levels of Merkle tree from leaves to the root.
+func merkleLevels(hasher core.Hasher, prevHash []byte, records [][]byte) [][][]byte {
+	level := make([][]byte, 0, len(records)+1)
+	level = append(level, leafHash(hasher, prevHash))
+	for _, r := range records {
+		level = append(level, leafHash(hasher, r))
+	}
+
+	levels := [][][]byte{level}
+	for len(level) > 1 {
+		next := make([][]byte, 0, (len(level)+1)/2)
+		for i := 0; i < len(level); i += 2 {
+			if i+1 == len(level) {
+				next = append(next, level[i])
+				continue
+			}
+			next = append(next, nodeHash(hasher, level[i], level[i+1]))
+		}
+		levels = append(levels, next)
+		level = next
+	}
+	return levels
+}
+
+func leafHash(hasher core.Hasher, data []byte) []byte {
+	return hasher.Hash(append([]byte{leafPrefix}, data...))
+}
+
+func nodeHash(hasher core.Hasher, left, right []byte) []byte {
+	buf := make([]byte, 0, len(left)+len(right)+1)
+	buf = append(buf, nodePrefix)
+	buf = append(buf, left...)
+	buf = append(buf, right...)
+	return hasher.Hash(buf)
+}
+
+// legacyMerkleHash calculates drop hash of HashVersionMerkle. Leaves and inner nodes are hashed the same way, so
+// record proofs are not issued for such drops.
+func legacyMerkleHash(hasher core.Hasher, prevHash []byte, records [][]byte) ([]byte, error) {
+	tree := gomerkle.NewTree(hasher)
+	tree.AddHash(hasher.Hash(prevHash))
+	tree.AddHash(records...)
+	if err := tree.Generate(); err != nil {
+		return nil, errors.Wrap(err, "failed to generate merkle tree")
+	}
+	return tree.Root(), nil
+}
@@ -0,0 +1,113 @@
+/*
+ *    Copyright 2018 Insolar
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ */
+
+package jetdrop
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/insolar/insolar/core"
+	"github.com/insolar/insolar/platformpolicy"
+	"github.com/insolar/insolar/testutils"
+)
+
+func TestProof(t *testing.T) {
+	hasher := platformpolicy.NewPlatformCryptographyScheme().ReferenceHasher()
+	prevHash := []byte{1, 2, 3}
+
+	for count := 1; count <= 9; count++ {
+		var (
+			ids     []core.RecordID
+			records [][]byte
+		)
+		for i := 0; i < count; i++ {
+			id := testutils.RandomID()
+			ids = append(ids, id)
+			records = append(records, id.Hash())
+		}
+
+		dropHash, err := Hash(hasher, prevHash, records)
+		require.NoError(t, err)
+
+		for _, id := range ids {
+			path, err := Proof(hasher, prevHash, records, id.Hash())
+			require.NoError(t, err)
+			proof := core.RecordProof{Record: id, DropHash: dropHash, Path: path}
+			assert.True(t, VerifyProof(hasher, &proof), "records: %v", count)
+
+			// Proof is not valid for another drop.
+			proof.DropHash = hasher.Hash(dropHash)
+			assert.False(t, VerifyProof(hasher, &proof))
+		}
+
+		missing := testutils.RandomID()
+		_, err = Proof(hasher, prevHash, records, missing.Hash())
+		assert.Error(t, err)
+	}
+}
+
+func TestHash(t *testing.T) {
+	hasher := platformpolicy.NewPlatformCryptographyScheme().ReferenceHasher()
+	id1, id2 := testutils.RandomID(), testutils.RandomID()
+
+	hash, err := Hash(hasher, []byte{1}, [][]byte{id1.Hash(), id2.Hash()})
+	require.NoError(t, err)
+
+	// Drop hash depends on the previous drop hash.
+	other, err := Hash(hasher, []byte{2}, [][]byte{id1.Hash(), id2.Hash()})
+	require.NoError(t, err)
+	assert.NotEqual(t, hash, other)
+
+	// Drop hash depends on records order.
+	other, err = Hash(hasher, []byte{1}, [][]byte{id2.Hash(), id1.Hash()})
+	require.NoError(t, err)
+	assert.NotEqual(t, hash, other)
+}
+
+func TestVerifyProof_InnerNode(t *testing.T) {
+	hasher := platformpolicy.NewPlatformCryptographyScheme().ReferenceHasher()
+	prevHash := []byte{1, 2, 3}
+	var records [][]byte
+	for i := 0; i < 4; i++ {
+		id := testutils.RandomID()
+		records = append(records, id.Hash())
+	}
+	dropHash, err := Hash(hasher, prevHash, records)
+	require.NoError(t, err)
+
+	// Inner node of leaves 0 and 1 is presented as a record with the rest of the path.
+	levels := merkleLevels(hasher, prevHash, records)
+	inner := levels[1][0]
+	require.Len(t, inner, len(records[0]))
+	var path []core.RecordProofStep
+	index := 0
+	for _, level := range levels[1 : len(levels)-1] {
+		sibling := index ^ 1
+		if sibling < len(level) {
+			path = append(path, core.RecordProofStep{Hash: level[sibling], Left: sibling < index})
+		}
+		index /= 2
+	}
+	forged := core.RecordProof{
+		Record:   *core.NewRecordID(core.FirstPulseNumber, inner),
+		DropHash: dropHash,
+		Path:     path,
+	}
+	assert.False(t, VerifyProof(hasher, &forged))
+}
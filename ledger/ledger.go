@@ -26,6 +26,7 @@ import (
 	"github.com/insolar/insolar/ledger/jetcoordinator"
 	"github.com/insolar/insolar/ledger/localstorage"
 	"github.com/insolar/insolar/ledger/pulsemanager"
+	"github.com/insolar/insolar/ledger/retention"
 	"github.com/insolar/insolar/ledger/storage"
 	"github.com/insolar/insolar/log"
 	"github.com/pkg/errors"
@@ -83,16 +84,23 @@ func NewTestLedger(
 }
 
 // GetLedgerComponents returns ledger components.
-func GetLedgerComponents(conf configuration.Ledger) []interface{} {
-	db, err := storage.NewDB(conf, nil)
+//
+// Key store is used to derive storage encryption key if encryption is enabled without key file.
+func GetLedgerComponents(conf configuration.Ledger, keyStore core.KeyStore) []interface{} {
+	db, err := storage.NewDB(conf, nil, keyStore)
 	if err != nil {
 		panic(errors.Wrap(err, "failed to initialize DB"))
 	}
 	return []interface{}{
 		db,
-		artifactmanager.NewArtifactManger(db),
+		artifactmanager.NewArtifactManger(
+			db,
+			artifactmanager.CodeCacheSize(conf.ArtifactManager.CodeCacheSize),
+			artifactmanager.ObjectCacheSize(conf.ArtifactManager.ObjectCacheSize),
+		),
 		jetcoordinator.NewJetCoordinator(db, conf.JetCoordinator),
 		pulsemanager.NewPulseManager(db),
+		retention.NewPolicy(db, conf.LightChainLimit),
 		artifactmanager.NewMessageHandler(db, storage.NewRecentStorage(1)),
 		localstorage.NewLocalStorage(db),
 		exporter.NewExporter(db),
@@ -27,6 +27,7 @@ import (
 	"github.com/insolar/insolar/ledger/jetcoordinator"
 	"github.com/insolar/insolar/ledger/localstorage"
 	"github.com/insolar/insolar/ledger/pulsemanager"
+	"github.com/insolar/insolar/ledger/retention"
 	"github.com/insolar/insolar/ledger/storage"
 	"github.com/insolar/insolar/ledger/storage/storagetest"
 	"github.com/insolar/insolar/log"
@@ -51,7 +52,11 @@ func TmpLedger(t *testing.T, dir string, c core.Components) (*ledger.Ledger, fun
 	handler := artifactmanager.NewMessageHandler(db, storage.NewRecentStorage(0))
 	handler.PlatformCryptographyScheme = pcs
 
-	am := artifactmanager.NewArtifactManger(db)
+	am := artifactmanager.NewArtifactManger(
+		db,
+		artifactmanager.CodeCacheSize(conf.ArtifactManager.CodeCacheSize),
+		artifactmanager.ObjectCacheSize(conf.ArtifactManager.ObjectCacheSize),
+	)
 	am.PlatformCryptographyScheme = pcs
 	jc := jetcoordinator.NewJetCoordinator(db, conf.JetCoordinator)
 	jc.PlatformCryptographyScheme = pcs
@@ -67,11 +72,15 @@ func TmpLedger(t *testing.T, dir string, c core.Components) (*ledger.Ledger, fun
 	}
 
 	handler.Bus = c.MessageBus
+	handler.NodeNet = c.NodeNetwork
 	am.DefaultBus = c.MessageBus
 	jc.NodeNet = c.NodeNetwork
 	pm.NodeNet = c.NodeNetwork
 	pm.Bus = c.MessageBus
 	pm.LR = c.LogicRunner
+	pm.JetCoordinator = jc
+	pm.Retention = retention.NewPolicy(db, conf.LightChainLimit)
+	pm.Cache = am
 
 	err := handler.Init(ctx)
 	if err != nil {
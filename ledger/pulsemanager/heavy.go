@@ -19,8 +19,11 @@ package pulsemanager
 import (
 	"context"
 
+	"github.com/pkg/errors"
+
 	"github.com/insolar/insolar/core"
 	"github.com/insolar/insolar/core/message"
+	"github.com/insolar/insolar/core/reply"
 	"github.com/insolar/insolar/instrumentation/inslogger"
 	"github.com/insolar/insolar/ledger/storage"
 )
@@ -28,6 +31,8 @@ import (
 // HeavySync syncs records from light to heavy node, returns last synced pulse and error.
 //
 // It syncs records from start to end of provided pulse numbers.
+// Progress is saved after every batch acknowledged by heavy node,
+// so interrupted sync of the same range continues from the last acknowledged batch.
 func (m *PulseManager) HeavySync(
 	ctx context.Context,
 	start core.PulseNumber,
@@ -35,38 +40,57 @@ func (m *PulseManager) HeavySync(
 ) (core.PulseNumber, error) {
 	inslog := inslogger.FromContext(ctx)
 
-	// MAYBE we should check Message Bus replies?
-	signalMsg := &message.HeavyStartStop{Begin: start, End: end}
-	_, starterr := m.Bus.Send(ctx, signalMsg)
-	// TODO: check if locked
-	if starterr != nil {
-		return 0, starterr
+	hashes, err := m.db.DropHashes(ctx, start, end)
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to fetch jet drop hashes for heavy sync")
+	}
+	signalMsg := &message.HeavyStartStop{Begin: start, End: end, DropHashes: hashes}
+	if err := m.sendToHeavy(ctx, signalMsg); err != nil {
+		return 0, errors.Wrap(err, "heavy sync start failed")
 	}
 	inslog.Debugf("synchronize, sucessfully send start message for range [%v:%v]", start, end)
 
 	replicator := storage.NewReplicaIter(
 		ctx, m.db, start, end, m.options.syncmessagelimit)
+
+	state, err := m.db.GetHeavySyncState(ctx)
+	if err != nil && err != storage.ErrNotFound {
+		return 0, err
+	}
+	if state != nil && state.Begin == start && state.End == end {
+		if err := replicator.Restore(state.Iter); err != nil {
+			return 0, err
+		}
+		inslog.Debugf("synchronize, resume range [%v:%v] from last acknowledged batch", start, end)
+	}
+
 	for {
 		recs, err := replicator.NextRecords()
 		if err == storage.ErrReplicatorDone {
 			break
 		}
 		if err != nil {
-			panic(err)
+			return 0, errors.Wrap(err, "failed to fetch records for heavy sync")
 		}
 		msg := &message.HeavyPayload{Records: recs}
-		_, senderr := m.Bus.Send(ctx, msg)
-		if senderr != nil {
-			return 0, senderr
+		if err := m.sendToHeavy(ctx, msg); err != nil {
+			return 0, errors.Wrap(err, "heavy sync payload failed")
+		}
+		err = m.db.SetHeavySyncState(ctx, &storage.HeavySyncState{
+			Begin: start,
+			End:   end,
+			Iter:  replicator.State(),
+		})
+		if err != nil {
+			return 0, err
 		}
 	}
 
-	signalMsg.Finished = true
-	_, stoperr := m.Bus.Send(ctx, signalMsg)
-	if stoperr != nil {
-		return 0, stoperr
+	signalMsg = &message.HeavyStartStop{Begin: start, End: end, Finished: true}
+	if err := m.sendToHeavy(ctx, signalMsg); err != nil {
+		return 0, errors.Wrap(err, "heavy sync stop failed")
 	}
-	inslog.Debugf("synchronize, sucessfully send start message for range [%v:%v]", start, end)
+	inslog.Debugf("synchronize, sucessfully send stop message for range [%v:%v]", start, end)
 
 	lastmeetpulse := replicator.LastPulse()
 	inslog.Debugf("synchronize on [%v:%v] finised (maximum record pulse is %v)",
@@ -74,6 +98,18 @@ func (m *PulseManager) HeavySync(
 	return lastmeetpulse, nil
 }
 
+// sendToHeavy sends message to heavy node and converts error reply to error.
+func (m *PulseManager) sendToHeavy(ctx context.Context, msg core.Message) error {
+	rep, err := m.Bus.Send(ctx, msg)
+	if err != nil {
+		return err
+	}
+	if errrep, ok := rep.(*reply.Error); ok {
+		return errrep.Error()
+	}
+	return nil
+}
+
 // NextSyncPulses returns pulse numbers range for syncing to heavy node.
 // If nothing to sync it returns 0, 0, nil.
 func (m *PulseManager) NextSyncPulses(ctx context.Context) (start, end core.PulseNumber, err error) {
@@ -25,15 +25,21 @@ import (
 	"testing"
 
 	"github.com/dgraph-io/badger"
+	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"github.com/insolar/insolar/configuration"
 	"github.com/insolar/insolar/core"
 	"github.com/insolar/insolar/core/message"
+	"github.com/insolar/insolar/core/reply"
 	"github.com/insolar/insolar/instrumentation/inslogger"
+	"github.com/insolar/insolar/ledger/artifactmanager"
 	"github.com/insolar/insolar/ledger/index"
+	"github.com/insolar/insolar/ledger/jetcoordinator"
 	"github.com/insolar/insolar/ledger/pulsemanager"
 	"github.com/insolar/insolar/ledger/record"
+	"github.com/insolar/insolar/ledger/retention"
 	"github.com/insolar/insolar/ledger/storage"
 	"github.com/insolar/insolar/ledger/storage/storagetest"
 	"github.com/insolar/insolar/testutils"
@@ -104,6 +110,9 @@ func TestPulseManager_SendToHeavy(t *testing.T) {
 	pm.LR = lrMock
 	pm.NodeNet = nodenetMock
 	pm.Bus = busMock
+	pm.JetCoordinator = jetcoordinator.NewJetCoordinator(db, configuration.NewLedger().JetCoordinator)
+	pm.Retention = retention.NewPolicy(db, 0)
+	pm.Cache = artifactmanager.NewArtifactManger(db)
 
 	// start PulseManager
 	err := pm.Start(ctx)
@@ -139,10 +148,113 @@ func TestPulseManager_SendToHeavy(t *testing.T) {
 
 	synckeys = uniqkeys(sortkeys(synckeys))
 
-	recs := getallkeys(db.GetBadgerDB())
+	// data of the current pulse is not replicated until the pulse is closed
+	var recs []key
+	for _, k := range getallkeys(db.GetBadgerDB()) {
+		if k.pulse() < core.PulseNumber(lastpulse) {
+			recs = append(recs, k)
+		}
+	}
 	assert.Equal(t, recs, synckeys, "synced keys count are the same as records in storage")
 }
 
+func TestPulseManager_HeavySyncResume(t *testing.T) {
+	ctx := inslogger.TestContext(t)
+	db, cleaner := storagetest.TmpDB(ctx, t)
+	defer cleaner()
+
+	lrMock := testutils.NewLogicRunnerMock(t)
+	lrMock.OnPulseMock.Return(nil)
+	nodeMock := network.NewNodeMock(t)
+	nodeMock.RoleMock.Return(core.RoleLightMaterial)
+	nodenetMock := network.NewNodeNetworkMock(t)
+	nodenetMock.GetActiveNodesMock.Return(nil)
+	nodenetMock.GetOriginMock.Return(nodeMock)
+
+	var (
+		synckeys  []key
+		payloads  int
+		failAfter = 2
+		startrep  core.Reply
+		hashes    map[core.PulseNumber][]byte
+	)
+	busMock := testutils.NewMessageBusMock(t)
+	busMock.SendFunc = func(ctx context.Context, msg core.Message, op ...core.SendOption) (core.Reply, error) {
+		switch m := msg.(type) {
+		case *message.HeavyStartStop:
+			if !m.Finished {
+				hashes = m.DropHashes
+				return startrep, nil
+			}
+		case *message.HeavyPayload:
+			payloads++
+			if payloads == failAfter {
+				return nil, errors.New("connection lost")
+			}
+			for _, rec := range m.Records {
+				synckeys = append(synckeys, rec.K)
+			}
+		}
+		return &reply.OK{}, nil
+	}
+
+	kb := 1 << 10
+	pm := pulsemanager.NewPulseManager(
+		db,
+		pulsemanager.EnableSync(false),
+		pulsemanager.SyncMessageLimit(kb/4),
+	)
+	pm.LR = lrMock
+	pm.NodeNet = nodenetMock
+	pm.Bus = busMock
+	pm.JetCoordinator = jetcoordinator.NewJetCoordinator(db, configuration.NewLedger().JetCoordinator)
+	pm.Retention = retention.NewPolicy(db, 0)
+	pm.Cache = artifactmanager.NewArtifactManger(db)
+
+	start := core.GenesisPulse.PulseNumber + 1
+	lastpulse := start
+	for i := 0; i < 3; i++ {
+		err := setpulse(ctx, pm, int(lastpulse))
+		require.NoError(t, err)
+		addRecords(ctx, t, db, lastpulse)
+		lastpulse++
+	}
+	err := setpulse(ctx, pm, int(lastpulse))
+	require.NoError(t, err)
+
+	// heavy node is locked by another light node
+	startrep = &reply.Error{ErrType: reply.ErrHeavySyncInProgress}
+	_, err = pm.HeavySync(ctx, start, lastpulse)
+	require.Error(t, err)
+	assert.Equal(t, core.ErrHeavySyncInProgress, errors.Cause(err))
+	assert.Equal(t, 0, payloads)
+
+	startrep = &reply.OK{}
+	_, err = pm.HeavySync(ctx, start, lastpulse)
+	require.Error(t, err)
+	assert.Equal(t, failAfter, payloads)
+
+	// resumed sync should not resend acknowledged batches
+	synced, err := pm.HeavySync(ctx, start, lastpulse)
+	require.NoError(t, err)
+	assert.Equal(t, lastpulse-1, synced)
+
+	// lifelines are synced from the first pulse
+	var expected []key
+	for _, k := range getallkeys(db.GetBadgerDB()) {
+		if k.pulse() < lastpulse && (k[0] == scopeIDLifeline || k.pulse() >= start) {
+			expected = append(expected, k)
+		}
+	}
+	assert.Equal(t, sortkeys(expected), sortkeys(synckeys))
+
+	for pn := start; pn < lastpulse; pn++ {
+		drop, err := db.GetDrop(ctx, pn)
+		require.NoError(t, err)
+		assert.Equal(t, drop.Hash, hashes[pn], "drop hash for pulse %v", pn)
+	}
+}
+
 func setpulse(ctx context.Context, pm core.PulseManager, pulsenum int) error {
 	return pm.Set(ctx, core.Pulse{PulseNumber: core.PulseNumber(pulsenum)})
 }
@@ -181,6 +293,7 @@ var (
 	scopeIDRecord   = byte(2)
 	scopeIDJetDrop  = byte(3)
 	scopeIDBlob     = byte(7)
+	scopeIDJetTree  = byte(10)
 )
 
 type key []byte
@@ -200,7 +313,8 @@ func getallkeys(db *badger.DB) (records []key) {
 			scopeIDRecord,
 			scopeIDJetDrop,
 			scopeIDLifeline,
-			scopeIDBlob:
+			scopeIDBlob,
+			scopeIDJetTree:
 			if !bytes.HasPrefix(k[1:], emptypulse.Bytes()) {
 				records = append(records, k)
 			}
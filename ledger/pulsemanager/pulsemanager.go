@@ -19,6 +19,7 @@ package pulsemanager
 import (
 	"context"
 	"sync"
+	"time"
 
 	"github.com/pkg/errors"
 
@@ -31,10 +32,13 @@ import (
 
 // PulseManager implements core.PulseManager.
 type PulseManager struct {
-	db      *storage.DB
-	LR      core.LogicRunner `inject:""`
-	Bus     core.MessageBus  `inject:""`
-	NodeNet core.NodeNetwork `inject:""`
+	db             *storage.DB
+	LR             core.LogicRunner     `inject:""`
+	Bus            core.MessageBus      `inject:""`
+	NodeNet        core.NodeNetwork     `inject:""`
+	JetCoordinator core.JetCoordinator  `inject:""`
+	Retention      core.RetentionPolicy `inject:""`
+	Cache          core.DescriptorCache `inject:""`
 	// setLock locks Set method call.
 	setLock sync.Mutex
 	stopped bool
@@ -46,9 +50,13 @@ type PulseManager struct {
 	options pmOptions
 }
 
+// defaultSyncRetryDelay is a delay before retry of failed sync to heavy node.
+const defaultSyncRetryDelay = 5 * time.Second
+
 type pmOptions struct {
 	enablesync       bool
 	syncmessagelimit int
+	syncretrydelay   time.Duration
 }
 
 // Option provides functional option for TmpDB.
@@ -69,9 +77,18 @@ func SyncMessageLimit(size int) Option {
 	}
 }
 
+// SyncRetryDelay sets delay before retry of failed sync to heavy node.
+func SyncRetryDelay(delay time.Duration) Option {
+	return func(opts *pmOptions) {
+		opts.syncretrydelay = delay
+	}
+}
+
 // NewPulseManager creates PulseManager instance.
 func NewPulseManager(db *storage.DB, options ...Option) *PulseManager {
-	opts := &pmOptions{}
+	opts := &pmOptions{
+		syncretrydelay: defaultSyncRetryDelay,
+	}
 	for _, o := range options {
 		o(opts)
 	}
@@ -144,14 +161,18 @@ func (m *PulseManager) Set(ctx context.Context, pulse core.Pulse) error {
 	}
 
 	// Run only on material executor.
-	var err error
+	var (
+		err                error
+		latestPulseAsLight core.PulseNumber
+	)
+	isLight := m.NodeNet.GetOrigin().Role() == core.RoleLightMaterial
 	// execute only on material executor
-	if m.NodeNet.GetOrigin().Role() == core.RoleLightMaterial {
+	if isLight {
 		if err = m.processDrop(ctx); err != nil {
 			return errors.Wrap(err, "processDrop failed")
 		}
 
-		latestPulseAsLight, err := m.db.GetLatestPulseNumber(ctx)
+		latestPulseAsLight, err = m.db.GetLatestPulseNumber(ctx)
 		if err != nil {
 			return errors.Wrap(err, "call of GetLatestPulseNumber failed")
 		}
@@ -170,6 +191,16 @@ func (m *PulseManager) Set(ctx context.Context, pulse core.Pulse) error {
 		return errors.Wrap(err, "call of SetActiveNodes failed")
 	}
 
+	// Jet tree authority of the new pulse is selected from its active nodes. The authority agrees the tree without
+	// the report if it is not delivered, so the failure doesn't stop pulse change.
+	if isLight {
+		if err = m.JetCoordinator.UpdateJetTree(ctx, latestPulseAsLight, pulse.PulseNumber); err != nil {
+			inslogger.FromContext(ctx).Error(errors.Wrap(err, "call of UpdateJetTree failed"))
+		}
+	}
+
+	m.Cache.ResetPulse(ctx, pulse.PulseNumber)
+
 	return m.LR.OnPulse(ctx, pulse)
 }
 
@@ -235,7 +266,7 @@ func (m *PulseManager) syncloop(ctx context.Context, start, end core.PulseNumber
 				err = errors.Wrap(err,
 					"PulseManager syncloop failed on NextSyncPulseNumber call")
 				inslog.Error(err)
-				panic(err)
+				start = 0
 			}
 		}
 		inslog.Debugf("syncronization sync pulses: [%v:%v]", start, end)
@@ -244,7 +275,9 @@ func (m *PulseManager) syncloop(ctx context.Context, start, end core.PulseNumber
 		if syncerr != nil {
 			syncerr = errors.Wrap(syncerr, "HeavySync failed")
 			inslog.Error(syncerr.Error())
-			// TODO: add sleep and some retry logic here?
+			if !m.waitSyncRetry() {
+				return
+			}
 			continue
 		}
 		err = m.db.SetReplicatedPulse(ctx, lastprocessed)
@@ -252,8 +285,39 @@ func (m *PulseManager) syncloop(ctx context.Context, start, end core.PulseNumber
 			err = errors.Wrap(err,
 				"SetReplicatedPulse failed after success HeavySync in Pulsemanager")
 			inslog.Error(err)
-			panic(err)
+			if !m.waitSyncRetry() {
+				return
+			}
+			continue
 		}
+		m.applyRetention(ctx)
 		start = 0
 	}
 }
+
+// applyRetention removes data replicated to heavy material node. Failed pruning is continued after the next sync.
+func (m *PulseManager) applyRetention(ctx context.Context) {
+	pulse, err := m.db.GetLastPulseAsLightMaterial(ctx)
+	if err == nil {
+		err = m.Retention.Apply(ctx, pulse)
+	}
+	if err != nil {
+		inslogger.FromContext(ctx).Error(errors.Wrap(err, "call of retention policy Apply failed"))
+	}
+}
+
+// waitSyncRetry waits retry delay, returns false if Stop was called.
+func (m *PulseManager) waitSyncRetry() bool {
+	timer := time.NewTimer(m.options.syncretrydelay)
+	defer timer.Stop()
+	for {
+		select {
+		case <-timer.C:
+			return true
+		case _, ok := <-m.gotpulse:
+			if !ok {
+				return false
+			}
+		}
+	}
+}
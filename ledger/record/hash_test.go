@@ -38,6 +38,7 @@ var emptyRecordsGens = []recordgen{
 	func() Record { return &ObjectAmendRecord{} },
 	func() Record { return &TypeRecord{} },
 	func() Record { return &ChildRecord{} },
+	func() Record { return &ValidationRecord{} },
 	func() Record { return &GenesisRecord{} },
 }
 
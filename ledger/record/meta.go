@@ -59,11 +59,18 @@ func (*GenesisRecord) GetIsPrototype() bool {
 	return false
 }
 
+// GetRequest returns request reference. Genesis record has no request.
+func (*GenesisRecord) GetRequest() *core.RecordRef {
+	return nil
+}
+
 // ChildRecord is a child activation record. Its used for children iterating.
 type ChildRecord struct {
 	PrevChild *core.RecordID
 
 	Ref core.RecordRef // Reference to the child's head.
+	// Prototype of the child. Omitted for prototypes and for children registered before it was introduced.
+	Prototype *core.RecordRef `codec:",omitempty"`
 }
 
 // Type implementation of Record interface.
@@ -73,3 +80,22 @@ func (r *ChildRecord) Type() TypeID { return typeChild }
 func (r *ChildRecord) WriteHashData(w io.Writer) (int, error) {
 	return w.Write(SerializeRecord(r))
 }
+
+// ValidationRecord is a result of object state validation. Validation records of an object are chained.
+type ValidationRecord struct {
+	PrevValidation *core.RecordID
+
+	Object    core.RecordRef // Reference to the object's head.
+	State     core.RecordID  // Validated object state.
+	IsValid   bool
+	Validator core.RecordRef // Node which sent validation result.
+	Signature []byte         // Validator's signature of validation message.
+}
+
+// Type implementation of Record interface.
+func (r *ValidationRecord) Type() TypeID { return typeValidation }
+
+// WriteHashData writes record data to provided writer. This data is used to calculate record's hash.
+func (r *ValidationRecord) WriteHashData(w io.Writer) (int, error) {
+	return w.Write(SerializeRecord(r))
+}
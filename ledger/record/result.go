@@ -48,6 +48,8 @@ type ObjectState interface {
 	GetMemory() *core.RecordID
 	// PrevStateID returns previous state id.
 	PrevStateID() *core.RecordID
+	// GetRequest returns request reference which produced the state.
+	GetRequest() *core.RecordRef
 }
 
 // ResultRecord represents result of a VM method.
@@ -72,6 +74,11 @@ type SideEffectRecord struct {
 	Request core.RecordRef
 }
 
+// GetRequest returns request reference which produced the record.
+func (r *SideEffectRecord) GetRequest() *core.RecordRef {
+	return &r.Request
+}
+
 // TypeRecord is a code interface declaration.
 type TypeRecord struct {
 	SideEffectRecord
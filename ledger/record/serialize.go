@@ -32,8 +32,9 @@ import (
 //go:generate stringer -type=TypeID
 const (
 	// meta
-	typeGenesis TypeID = 10
-	typeChild   TypeID = 11
+	typeGenesis    TypeID = 10
+	typeChild      TypeID = 11
+	typeValidation TypeID = 12
 
 	// request
 	typeCallRequest TypeID = 20
@@ -66,6 +67,8 @@ func getRecordByTypeID(id TypeID) Record { // nolint: gocyclo
 		return &TypeRecord{}
 	case typeChild:
 		return &ChildRecord{}
+	case typeValidation:
+		return &ValidationRecord{}
 	case typeGenesis:
 		return &GenesisRecord{}
 	case typeResult:
@@ -44,6 +44,7 @@ var type2idTests = []struct {
 	{"ObjectAmendRecord", &ObjectAmendRecord{}, typeAmend},
 	{"TypeRecord", &TypeRecord{}, typeType},
 	{"ChildRecord", &ChildRecord{}, typeChild},
+	{"ValidationRecord", &ValidationRecord{}, typeValidation},
 	{"GenesisRecord", &GenesisRecord{}, typeGenesis},
 }
 
@@ -0,0 +1,132 @@
+/*
+ *    Copyright 2018 Insolar
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ */
+
+package retention
+
+import (
+	"context"
+	"sync"
+
+	"github.com/pkg/errors"
+
+	"github.com/insolar/insolar/core"
+	"github.com/insolar/insolar/ledger/storage"
+)
+
+// Policy implements core.RetentionPolicy for light material node.
+type Policy struct {
+	db    *storage.DB
+	depth int
+
+	// cutoff is a pulse 'depth' pulses before the last applied pulse, it is advanced with new pulses.
+	lock    sync.Mutex
+	applied core.PulseNumber
+	cutoff  core.PulseNumber
+}
+
+// NewPolicy creates retention policy which keeps replicated data for 'depth' pulses.
+// Non-positive depth disables pruning.
+func NewPolicy(db *storage.DB, depth int) *Policy {
+	return &Policy{db: db, depth: depth}
+}
+
+// Apply removes records, blobs and local data replicated to heavy material node
+// and older than configured number of pulses before provided pulse.
+func (p *Policy) Apply(ctx context.Context, pulse core.PulseNumber) error {
+	if p.depth <= 0 {
+		return nil
+	}
+	replicated, err := p.db.GetReplicatedPulse(ctx)
+	if err != nil {
+		return errors.Wrap(err, "failed to fetch replicated pulse")
+	}
+	if replicated == 0 {
+		return nil
+	}
+
+	until, err := p.cutoffFor(ctx, pulse)
+	if err != nil {
+		return err
+	}
+	// genesis pulse data is never pruned
+	if until <= core.FirstPulseNumber {
+		return nil
+	}
+	if replicated < until {
+		until = replicated
+	}
+
+	return p.db.Prune(ctx, until)
+}
+
+// cutoffFor returns pulse 'depth' pulses before provided one or zero if the chain is shorter.
+//
+// The cutoff is calculated once and then moved forward by the number of pulses added since the last call.
+func (p *Policy) cutoffFor(ctx context.Context, pulse core.PulseNumber) (core.PulseNumber, error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	if p.cutoff != 0 && pulse >= p.applied {
+		applied, cutoff := p.applied, p.cutoff
+		for applied < pulse {
+			next, err := p.next(ctx, applied)
+			if err != nil {
+				return 0, err
+			}
+			if next == nil {
+				break
+			}
+			applied = *next
+			next, err = p.next(ctx, cutoff)
+			if err != nil {
+				return 0, err
+			}
+			if next == nil {
+				break
+			}
+			cutoff = *next
+		}
+		if applied == pulse {
+			p.applied, p.cutoff = applied, cutoff
+			return cutoff, nil
+		}
+	}
+
+	cutoff := pulse
+	for i := 0; i < p.depth; i++ {
+		if cutoff <= core.FirstPulseNumber {
+			return 0, nil
+		}
+		stored, err := p.db.GetPulse(ctx, cutoff)
+		if err != nil {
+			return 0, errors.Wrapf(err, "failed to fetch pulse %v", cutoff)
+		}
+		if stored.Prev == nil {
+			return 0, nil
+		}
+		cutoff = *stored.Prev
+	}
+	p.applied, p.cutoff = pulse, cutoff
+	return cutoff, nil
+}
+
+func (p *Policy) next(ctx context.Context, pulse core.PulseNumber) (*core.PulseNumber, error) {
+	stored, err := p.db.GetPulse(ctx, pulse)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to fetch pulse %v", pulse)
+	}
+	return stored.Next, nil
+}
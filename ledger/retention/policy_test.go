@@ -0,0 +1,90 @@
+/*
+ *    Copyright 2018 Insolar
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ */
+
+package retention
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/insolar/insolar/core"
+	"github.com/insolar/insolar/instrumentation/inslogger"
+	"github.com/insolar/insolar/ledger/storage/storagetest"
+)
+
+func TestPolicy_Apply(t *testing.T) {
+	t.Parallel()
+	ctx := inslogger.TestContext(t)
+	db, cleaner := storagetest.TmpDB(ctx, t)
+	defer cleaner()
+
+	genesis := core.GenesisPulse.PulseNumber
+	latest := genesis + 5
+	for pn := genesis + 1; pn <= latest; pn++ {
+		err := db.AddPulse(ctx, core.Pulse{PulseNumber: pn})
+		require.NoError(t, err)
+	}
+	prunedPulse := func() core.PulseNumber {
+		pn, err := db.GetPrunedPulse(ctx)
+		require.NoError(t, err)
+		return pn
+	}
+
+	// nothing is replicated yet
+	err := NewPolicy(db, 2).Apply(ctx, latest)
+	require.NoError(t, err)
+	assert.Equal(t, core.PulseNumber(0), prunedPulse())
+
+	// pruning is limited by replicated pulse
+	err = db.SetReplicatedPulse(ctx, genesis+2)
+	require.NoError(t, err)
+	err = NewPolicy(db, 2).Apply(ctx, latest)
+	require.NoError(t, err)
+	assert.Equal(t, genesis+2, prunedPulse())
+
+	// pruning is limited by depth
+	err = db.SetReplicatedPulse(ctx, genesis+5)
+	require.NoError(t, err)
+	err = NewPolicy(db, 2).Apply(ctx, latest)
+	require.NoError(t, err)
+	assert.Equal(t, genesis+3, prunedPulse())
+
+	// disabled policy does nothing
+	err = NewPolicy(db, 0).Apply(ctx, latest+1)
+	require.NoError(t, err)
+	assert.Equal(t, genesis+3, prunedPulse())
+
+	// chain is shorter than depth
+	err = NewPolicy(db, 100).Apply(ctx, latest)
+	require.NoError(t, err)
+	assert.Equal(t, genesis+3, prunedPulse())
+
+	// cutoff is moved forward with new pulses
+	policy := NewPolicy(db, 2)
+	err = policy.Apply(ctx, latest)
+	require.NoError(t, err)
+	for pn := latest + 1; pn <= latest+3; pn++ {
+		err = db.AddPulse(ctx, core.Pulse{PulseNumber: pn})
+		require.NoError(t, err)
+		err = db.SetReplicatedPulse(ctx, pn)
+		require.NoError(t, err)
+		err = policy.Apply(ctx, pn)
+		require.NoError(t, err)
+		assert.Equal(t, pn-2, prunedPulse())
+	}
+}
@@ -0,0 +1,299 @@
+/*
+ *    Copyright 2018 Insolar
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ */
+
+package storage
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io/ioutil"
+
+	"github.com/pkg/errors"
+	"github.com/ugorji/go/codec"
+
+	"github.com/insolar/insolar/core"
+)
+
+// Blob content is stored once per content hash in scopeIDBlobContent with a number of blob ids referencing it.
+// Keys in scopeIDBlob keep only content hash, so equal blobs saved in different pulses share the content.
+// Blobs saved before deduplication keep raw content in scopeIDBlob and are read as is.
+
+// Blob compression names used in configuration.
+const (
+	BlobCompressionNone = "none"
+	BlobCompressionGzip = "gzip"
+)
+
+const (
+	blobCodecNone byte = 0
+	blobCodecGzip byte = 1
+)
+
+// ErrUnknownBlobCompression is returned if blob compression is not supported.
+var ErrUnknownBlobCompression = errors.New("unknown blob compression")
+
+func blobCodecByName(name string) (byte, error) {
+	switch name {
+	case "", BlobCompressionNone:
+		return blobCodecNone, nil
+	case BlobCompressionGzip:
+		return blobCodecGzip, nil
+	}
+	return 0, errors.Wrapf(ErrUnknownBlobCompression, "%q", name)
+}
+
+// blobContent is a value of blob content key.
+type blobContent struct {
+	Refs  uint64
+	Codec byte
+	Data  []byte
+}
+
+func encodeBlobContent(content *blobContent) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := codec.NewEncoder(&buf, &codec.CborHandle{})
+	if err := enc.Encode(content); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func decodeBlobContent(buf []byte) (*blobContent, error) {
+	var content blobContent
+	dec := codec.NewDecoder(bytes.NewReader(buf), &codec.CborHandle{})
+	if err := dec.Decode(&content); err != nil {
+		return nil, err
+	}
+	return &content, nil
+}
+
+// compressBlob returns blob packed with provided codec.
+// Blob is kept as is if compression does not reduce its size.
+func compressBlob(blobCodec byte, blob []byte) (byte, []byte, error) {
+	if blobCodec != blobCodecGzip {
+		return blobCodecNone, blob, nil
+	}
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	if _, err := w.Write(blob); err != nil {
+		return 0, nil, err
+	}
+	if err := w.Close(); err != nil {
+		return 0, nil, err
+	}
+	if buf.Len() >= len(blob) {
+		return blobCodecNone, blob, nil
+	}
+	return blobCodecGzip, buf.Bytes(), nil
+}
+
+// data returns unpacked blob.
+func (c *blobContent) data() ([]byte, error) {
+	switch c.Codec {
+	case blobCodecNone:
+		return c.Data, nil
+	case blobCodecGzip:
+		r, err := gzip.NewReader(bytes.NewReader(c.Data))
+		if err != nil {
+			return nil, err
+		}
+		defer r.Close()
+		return ioutil.ReadAll(r)
+	}
+	return nil, errors.Errorf("unknown blob codec %v", c.Codec)
+}
+
+func blobContentKey(hash []byte) []byte {
+	return prefixkey(scopeIDBlobContent, hash)
+}
+
+// blobRef returns content hash referenced by value of blob key or nil if value is a legacy raw blob.
+func blobRef(k, v []byte) []byte {
+	hash := k[1+core.PulseNumberSize:]
+	if !bytes.Equal(v, hash) {
+		return nil
+	}
+	return hash
+}
+
+// readBlob returns unpacked blob by blob key and its value.
+func readBlob(get func(k []byte) ([]byte, error), k, v []byte) ([]byte, error) {
+	hash := blobRef(k, v)
+	if hash == nil {
+		return v, nil
+	}
+	buf, err := get(blobContentKey(hash))
+	if err != nil {
+		if err == ErrNotFound {
+			return nil, errors.Wrapf(err, "blob content %v", bytes2hex(hash))
+		}
+		return nil, err
+	}
+	content, err := decodeBlobContent(buf)
+	if err != nil {
+		return nil, err
+	}
+	return content.data()
+}
+
+// lockOnContent serializes reference counter updates of the same content.
+func (m *TransactionManager) lockOnContent(hash []byte) {
+	id := core.NewRecordID(0, hash)
+	for _, locked := range m.locks {
+		if locked.Equal(id) {
+			return
+		}
+	}
+	m.lockOnID(id)
+}
+
+// storeBlob saves blob by id and references its content. Existing blob is not changed.
+func (m *TransactionManager) storeBlob(ctx context.Context, id *core.RecordID, blob []byte) error {
+	k := prefixkey(scopeIDBlob, id[:])
+	_, err := m.get(ctx, k)
+	if err == nil {
+		return nil
+	}
+	if err != ErrNotFound {
+		return err
+	}
+
+	hash := id.Hash()
+	m.lockOnContent(hash)
+	ck := blobContentKey(hash)
+	var content *blobContent
+	buf, err := m.get(ctx, ck)
+	switch err {
+	case nil:
+		content, err = decodeBlobContent(buf)
+		if err != nil {
+			return err
+		}
+	case ErrNotFound:
+		content = &blobContent{}
+		content.Codec, content.Data, err = compressBlob(m.db.blobCodec, blob)
+		if err != nil {
+			return errors.Wrap(err, "failed to compress blob")
+		}
+	default:
+		return err
+	}
+	content.Refs++
+
+	encoded, err := encodeBlobContent(content)
+	if err != nil {
+		return err
+	}
+	if err = m.set(ctx, ck, encoded); err != nil {
+		return err
+	}
+	return m.set(ctx, k, hash)
+}
+
+// removeBlob removes blob by key and releases its content. Content without references is removed.
+func (m *TransactionManager) removeBlob(ctx context.Context, k []byte) error {
+	v, err := m.get(ctx, k)
+	if err != nil {
+		return err
+	}
+	if err = m.remove(ctx, k); err != nil {
+		return err
+	}
+	hash := blobRef(k, v)
+	if hash == nil {
+		return nil
+	}
+
+	m.lockOnContent(hash)
+	ck := blobContentKey(hash)
+	buf, err := m.get(ctx, ck)
+	if err != nil {
+		if err == ErrNotFound {
+			return nil
+		}
+		return err
+	}
+	content, err := decodeBlobContent(buf)
+	if err != nil {
+		return err
+	}
+	if content.Refs <= 1 {
+		return m.remove(ctx, ck)
+	}
+	content.Refs--
+	encoded, err := encodeBlobContent(content)
+	if err != nil {
+		return err
+	}
+	return m.set(ctx, ck, encoded)
+}
+
+// storeReplicaBlob saves blob replicated with provided key. Blob hash should be checked by the caller.
+func (m *TransactionManager) storeReplicaBlob(ctx context.Context, k, blob []byte) error {
+	var id core.RecordID
+	copy(id[:], k[1:])
+	return m.storeBlob(ctx, &id, blob)
+}
+
+// BlobStats describes blob storage usage.
+type BlobStats struct {
+	// Blobs is a number of stored blob ids.
+	Blobs int
+	// Contents is a number of unique blob contents.
+	Contents int
+	// Size is a total size of blobs as they were saved.
+	Size int64
+	// StoredSize is a total size of unique blob contents after compression.
+	StoredSize int64
+}
+
+// GetBlobStats calculates blob storage usage. Legacy raw blobs are counted as separate uncompressed contents.
+func (db *DB) GetBlobStats(ctx context.Context) (*BlobStats, error) {
+	stats := BlobStats{}
+	err := db.iterate(ctx, []byte{scopeIDBlobContent}, func(k, v []byte) error {
+		content, err := decodeBlobContent(v)
+		if err != nil {
+			return err
+		}
+		data, err := content.data()
+		if err != nil {
+			return err
+		}
+		stats.Blobs += int(content.Refs)
+		stats.Contents++
+		stats.Size += int64(content.Refs) * int64(len(data))
+		stats.StoredSize += int64(len(content.Data))
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	err = db.iterate(ctx, []byte{scopeIDBlob}, func(k, v []byte) error {
+		if blobRef(prefixkey(scopeIDBlob, k), v) != nil {
+			return nil
+		}
+		stats.Blobs++
+		stats.Contents++
+		stats.Size += int64(len(v))
+		stats.StoredSize += int64(len(v))
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &stats, nil
+}
@@ -0,0 +1,54 @@
+/*
+ *    Copyright 2018 Insolar
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ */
+
+package storage
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/insolar/insolar/configuration"
+	"github.com/insolar/insolar/instrumentation/inslogger"
+	"github.com/insolar/insolar/testutils"
+)
+
+func TestDB_Blob_LegacyRawValue(t *testing.T) {
+	ctx := inslogger.TestContext(t)
+	db := NewDBWithStore(configuration.Ledger{}, NewMemoryStore())
+
+	// Blobs saved before deduplication keep raw content as value.
+	legacy := testutils.RandomID()
+	raw := []byte("legacy blob")
+	k := prefixkey(scopeIDBlob, legacy[:])
+	require.NoError(t, db.set(ctx, k, raw))
+
+	blob, err := db.GetBlob(ctx, &legacy)
+	require.NoError(t, err)
+	assert.Equal(t, raw, blob)
+
+	stats, err := db.GetBlobStats(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, &BlobStats{Blobs: 1, Contents: 1, Size: int64(len(raw)), StoredSize: int64(len(raw))}, stats)
+
+	err = db.Update(ctx, func(tx *TransactionManager) error {
+		return tx.removeBlob(ctx, k)
+	})
+	require.NoError(t, err)
+	_, err = db.GetBlob(ctx, &legacy)
+	assert.Equal(t, ErrNotFound, err)
+}
@@ -0,0 +1,100 @@
+/*
+ *    Copyright 2018 Insolar
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ */
+
+package storage_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/insolar/insolar/core"
+	"github.com/insolar/insolar/instrumentation/inslogger"
+	"github.com/insolar/insolar/ledger/storage"
+	"github.com/insolar/insolar/ledger/storage/storagetest"
+)
+
+func TestDB_SetBlob_Deduplicates(t *testing.T) {
+	t.Parallel()
+	ctx := inslogger.TestContext(t)
+	db, cleaner := storagetest.TmpDB(ctx, t)
+	defer cleaner()
+
+	genesis := core.GenesisPulse.PulseNumber
+	var pulses []core.PulseNumber
+	for i := 1; i <= 3; i++ {
+		pn := genesis + core.PulseNumber(i)
+		require.NoError(t, db.AddPulse(ctx, core.Pulse{PulseNumber: pn}))
+		pulses = append(pulses, pn)
+	}
+
+	memory := []byte("shared memory")
+	first, err := db.SetBlob(ctx, pulses[0], memory)
+	require.NoError(t, err)
+	second, err := db.SetBlob(ctx, pulses[1], memory)
+	require.NoError(t, err)
+	assert.NotEqual(t, *first, *second)
+	_, err = db.SetBlob(ctx, pulses[1], memory)
+	assert.Equal(t, storage.ErrOverride, err)
+
+	stats, err := db.GetBlobStats(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 2, stats.Blobs)
+	assert.Equal(t, 1, stats.Contents)
+	assert.Equal(t, int64(2*len(memory)), stats.Size)
+	assert.Equal(t, int64(len(memory)), stats.StoredSize)
+
+	// Pruned blob releases shared content.
+	require.NoError(t, db.Prune(ctx, pulses[1]))
+	_, err = db.GetBlob(ctx, first)
+	assert.Equal(t, storage.ErrNotFound, err)
+	blob, err := db.GetBlob(ctx, second)
+	require.NoError(t, err)
+	assert.Equal(t, memory, blob)
+
+	require.NoError(t, db.Prune(ctx, pulses[2]))
+	stats, err = db.GetBlobStats(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, storage.BlobStats{}, *stats)
+}
+
+func TestDB_SetBlob_Compression(t *testing.T) {
+	t.Parallel()
+	ctx := inslogger.TestContext(t)
+	db, cleaner := storagetest.TmpDB(ctx, t, storagetest.BlobCompression(storage.BlobCompressionGzip))
+	defer cleaner()
+
+	pulse := core.GenesisPulse.PulseNumber
+	memory := bytes.Repeat([]byte("compressible memory "), 100)
+	id, err := db.SetBlob(ctx, pulse, memory)
+	require.NoError(t, err)
+	small, err := db.SetBlob(ctx, pulse, []byte{1})
+	require.NoError(t, err)
+
+	blob, err := db.GetBlob(ctx, id)
+	require.NoError(t, err)
+	assert.Equal(t, memory, blob)
+	blob, err = db.GetBlob(ctx, small)
+	require.NoError(t, err)
+	assert.Equal(t, []byte{1}, blob)
+
+	stats, err := db.GetBlobStats(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(len(memory)+1), stats.Size)
+	assert.True(t, stats.StoredSize < stats.Size/2)
+}
@@ -28,50 +28,80 @@ import (
 	"github.com/insolar/insolar/configuration"
 	"github.com/insolar/insolar/core"
 	"github.com/insolar/insolar/core/message"
+	"github.com/insolar/insolar/encryption"
 	"github.com/insolar/insolar/instrumentation/inslogger"
 	"github.com/insolar/insolar/ledger/index"
 	"github.com/insolar/insolar/ledger/jetdrop"
 	"github.com/insolar/insolar/ledger/record"
+	"github.com/insolar/insolar/ledger/storage/jet"
 )
 
 const (
-	scopeIDLifeline byte = 1
-	scopeIDRecord   byte = 2
-	scopeIDJetDrop  byte = 3
-	scopeIDPulse    byte = 4
-	scopeIDSystem   byte = 5
-	scopeIDMessage  byte = 6
-	scopeIDBlob     byte = 7
-	scopeIDLocal    byte = 8
+	scopeIDLifeline    byte = 1
+	scopeIDRecord      byte = 2
+	scopeIDJetDrop     byte = 3
+	scopeIDPulse       byte = 4
+	scopeIDSystem      byte = 5
+	scopeIDMessage     byte = 6
+	scopeIDBlob        byte = 7
+	scopeIDLocal       byte = 8
+	scopeIDNodes       byte = 9
+	scopeIDJetTree     byte = 10
+	scopeIDPrototype   byte = 11
+	scopeIDBlobContent byte = 12
+	// scopeIDStateHead keys are pulses of object states followed by object heads. They are pruning candidates.
+	scopeIDStateHead byte = 13
 
 	sysGenesis                  byte = 1
 	sysLatestPulse              byte = 2
 	sysReplicatedPulse          byte = 3
 	sysLastPulseAsLightMaterial byte = 4
+	sysRestoreInProgress        byte = 5
+	sysHeavySyncState           byte = 6
+	sysPrunedPulse              byte = 7
+	sysEncryptionState          byte = 8
 )
 
-// DB represents BadgerDB storage implementation.
+// DB represents ledger storage implementation on top of key-value backend (BadgerDB by default).
 type DB struct {
 	PlatformCryptographyScheme core.PlatformCryptographyScheme `inject:""`
 
-	db         *badger.DB
+	store      KVStore
 	genesisRef *core.RecordRef
 
 	// dropWG guards inflight updates before jet drop calculated.
 	dropWG sync.WaitGroup
 
-	// for BadgerDB it is normal to have transaction conflicts
-	// and these conflicts we should resolve by ourself
+	// for BadgerDB (and other optimistic backends) it is normal to have
+	// transaction conflicts and these conflicts we should resolve by ourself
 	// so txretiries is our knob to tune up retry logic.
 	txretiries int
 
 	idlocker *IDLocker
 
-	// NodeHistory is an in-memory active node storage for each pulse. It's required to calculate node roles
-	// for past pulses to locate data.
-	// It should only contain previous N pulses. It should be stored on disk.
-	nodeHistory     map[core.PulseNumber][]core.Node
-	nodeHistoryLock sync.Mutex
+	// nodeHistory is an in-memory cache of active nodes of the latest nodeHistoryCacheSize pulses. It's required to
+	// calculate node roles for past pulses to locate data. Active nodes are stored on disk for previous
+	// nodeHistoryDepth pulses.
+	nodeHistory      map[core.PulseNumber][]core.Node
+	nodeHistoryLock  sync.Mutex
+	nodeHistoryDepth int
+
+	// jetTrees is an in-memory cache of decoded jet trees. Generation is increased on every reset, so trees loaded
+	// before the reset are not cached.
+	jetTrees           map[core.PulseNumber]*jet.Tree
+	jetTreesGeneration uint64
+	jetTreesLock       sync.Mutex
+
+	// blobCodec is a compression applied to new blob contents.
+	blobCodec byte
+
+	// readOnly storage is not re-encrypted.
+	readOnly bool
+
+	// stop is closed on Close to terminate background jobs, jobs are tracked by jobsWG.
+	stop     chan struct{}
+	stopOnce sync.Once
+	jobsWG   sync.WaitGroup
 }
 
 // SetTxRetiries sets number of retries on conflict in Update
@@ -91,7 +121,18 @@ func setOptions(o *badger.Options) *badger.Options {
 
 // NewDB returns storage.DB with BadgerDB instance initialized by opts.
 // Creates database in provided dir or in current directory if dir parameter is empty.
-func NewDB(conf configuration.Ledger, opts *badger.Options) (*DB, error) {
+//
+// If encryption is enabled in configuration without key file, encryption key is derived from keyStore.
+// Plain values of existing storage are encrypted before DB is returned. Storage opened read-only is not
+// encrypted or re-encrypted, ErrEncryptionMigrationPending is returned if it has plain values.
+func NewDB(conf configuration.Ledger, opts *badger.Options, keyStore core.KeyStore) (*DB, error) {
+	if _, err := blobCodecByName(conf.Storage.BlobCompression); err != nil {
+		return nil, err
+	}
+	ring, err := encryption.NewKeyRingFromConfig(conf.Storage.Encryption, keyStore)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to load storage encryption keys")
+	}
 	opts = setOptions(opts)
 	dir, err := filepath.Abs(conf.Storage.DataDirectory)
 	if err != nil {
@@ -101,24 +142,72 @@ func NewDB(conf configuration.Ledger, opts *badger.Options) (*DB, error) {
 	opts.Dir = dir
 	opts.ValueDir = dir
 
-	bdb, err := badger.Open(*opts)
+	store, err := NewBadgerStore(*opts)
 	if err != nil {
 		return nil, errors.Wrap(err, "local database open failed")
 	}
-
-	db := &DB{
-		db:          bdb,
-		txretiries:  conf.Storage.TxRetriesOnConflict,
-		idlocker:    NewIDLocker(),
-		nodeHistory: map[core.PulseNumber][]core.Node{},
+	if ring != nil {
+		newStore := NewEncryptedStore
+		if opts.ReadOnly {
+			newStore = NewReadOnlyEncryptedStore
+		}
+		encrypted, err := newStore(store, ring)
+		if err != nil {
+			store.Close()
+			return nil, err
+		}
+		store = encrypted
+	} else {
+		encrypted, err := isEncrypted(store)
+		if err == nil && encrypted {
+			err = errors.New("storage is encrypted, encryption should be enabled")
+		}
+		if err != nil {
+			store.Close()
+			return nil, err
+		}
 	}
+	db := NewDBWithStore(conf, store)
+	db.readOnly = opts.ReadOnly
 	return db, nil
 }
 
+// NewMemoryDB returns storage.DB which keeps all data in memory.
+//
+// It is intended for tests and local simulations.
+func NewMemoryDB(conf configuration.Ledger) *DB {
+	return NewDBWithStore(conf, NewMemoryStore())
+}
+
+// NewDBWithStore returns storage.DB on top of provided key-value backend.
+//
+// Unknown blob compression in configuration is treated as no compression.
+func NewDBWithStore(conf configuration.Ledger, store KVStore) *DB {
+	blobCodec, _ := blobCodecByName(conf.Storage.BlobCompression)
+	return &DB{
+		store:            store,
+		txretiries:       conf.Storage.TxRetriesOnConflict,
+		idlocker:         NewIDLocker(),
+		nodeHistory:      map[core.PulseNumber][]core.Node{},
+		nodeHistoryDepth: conf.NodeHistoryDepth,
+		jetTrees:         map[core.PulseNumber]*jet.Tree{},
+		blobCodec:        blobCodec,
+		stop:             make(chan struct{}),
+	}
+}
+
 // Init creates initial records in storage.
 func (db *DB) Init(ctx context.Context) error {
 	inslog := inslogger.FromContext(ctx)
 	inslog.Debug("start storage bootstrap")
+
+	_, err := db.get(ctx, prefixkey(scopeIDSystem, []byte{sysRestoreInProgress}))
+	if err == nil {
+		return ErrRestoreInProgress
+	}
+	if err != ErrNotFound {
+		return errors.Wrap(err, "bootstrap failed")
+	}
 	getGenesisRef := func() (*core.RecordRef, error) {
 		buff, err := db.get(ctx, prefixkey(scopeIDSystem, []byte{sysGenesis}))
 		if err != nil {
@@ -166,7 +255,6 @@ func (db *DB) Init(ctx context.Context) error {
 		return genesisRef, db.set(ctx, prefixkey(scopeIDSystem, []byte{sysGenesis}), genesisRef[:])
 	}
 
-	var err error
 	db.genesisRef, err = getGenesisRef()
 	if err == ErrNotFound {
 		db.genesisRef, err = createGenesisRecord()
@@ -175,6 +263,14 @@ func (db *DB) Init(ctx context.Context) error {
 		return errors.Wrap(err, "bootstrap failed")
 	}
 
+	if _, ok := db.store.(*encryptedStore); ok && !db.readOnly {
+		db.jobsWG.Add(1)
+		go func() {
+			defer db.jobsWG.Done()
+			db.reencryptInBackground(ctx)
+		}()
+	}
+
 	return nil
 }
 
@@ -185,14 +281,16 @@ func (db *DB) GenesisRef() *core.RecordRef {
 	return db.genesisRef
 }
 
-// Close wraps BadgerDB Close method.
+// Close wraps backend Close method.
 //
 // From https://godoc.org/github.com/dgraph-io/badger#DB.Close:
 // «It's crucial to call it to ensure all the pending updates make their way to disk.
 // Calling DB.Close() multiple times is not safe and wouldcause panic.»
 func (db *DB) Close() error {
 	// TODO: add close flag and mutex guard on Close method
-	return db.db.Close()
+	db.stopOnce.Do(func() { close(db.stop) })
+	db.jobsWG.Wait()
+	return db.store.Close()
 }
 
 // Stop stops DB component.
@@ -322,28 +420,14 @@ func (db *DB) CreateDrop(ctx context.Context, pulse core.PulseNumber, prevHash [
 	var err error
 	db.waitinflight()
 
-	hw := db.PlatformCryptographyScheme.ReferenceHasher()
-	_, err = hw.Write(prevHash)
+	hash, err := db.dropHash(ctx, pulse, prevHash, jetdrop.HashVersionLatest)
 	if err != nil {
 		return nil, nil, err
 	}
 
-	prefix := make([]byte, core.PulseNumberSize+1)
-	prefix[0] = scopeIDMessage
-	copy(prefix[1:], pulse.Bytes())
-
 	var messages [][]byte
-	err = db.db.View(func(txn *badger.Txn) error {
-		it := txn.NewIterator(badger.DefaultIteratorOptions)
-		defer it.Close()
-
-		for it.Seek(prefix); it.ValidForPrefix(prefix); it.Next() {
-			val, err := it.Item().ValueCopy(nil)
-			if err != nil {
-				return err
-			}
-			messages = append(messages, val)
-		}
+	err = db.IterateMessages(ctx, pulse, func(msg []byte) error {
+		messages = append(messages, msg)
 		return nil
 	})
 	if err != nil {
@@ -351,23 +435,158 @@ func (db *DB) CreateDrop(ctx context.Context, pulse core.PulseNumber, prevHash [
 	}
 
 	drop := jetdrop.JetDrop{
-		Pulse:    pulse,
-		PrevHash: prevHash,
-		Hash:     hw.Sum(nil),
+		Pulse:       pulse,
+		PrevHash:    prevHash,
+		Hash:        hash,
+		HashVersion: jetdrop.HashVersionLatest,
 	}
 	return &drop, messages, nil
 }
 
+// dropHash calculates jet drop hash of provided scheme version from previous drop hash and hashes of all records of
+// the pulse.
+func (db *DB) dropHash(
+	ctx context.Context, pulse core.PulseNumber, prevHash []byte, version jetdrop.HashVersion,
+) ([]byte, error) {
+	records, err := db.dropRecords(ctx, pulse)
+	if err != nil {
+		return nil, err
+	}
+	return jetdrop.VersionedHash(db.PlatformCryptographyScheme.ReferenceHasher(), version, prevHash, records)
+}
+
+// dropRecords returns hashes of all records of the pulse in storage order.
+func (db *DB) dropRecords(ctx context.Context, pulse core.PulseNumber) ([][]byte, error) {
+	var records [][]byte
+	prefix := bytes.Join([][]byte{{scopeIDRecord}, pulse.Bytes()}, nil)
+	err := db.iterate(ctx, prefix, func(k, v []byte) error {
+		records = append(records, k)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return records, nil
+}
+
+// GetRecordProof returns Merkle inclusion proof of the record in the jet drop of its pulse.
+//
+// Returns ErrNotFound if the record does not exist or the drop for its pulse is not created yet. Returns
+// ErrProofNotSupported if the drop was created before Merkle drop hashes with separated leaf and node hashes and
+// ErrPruned if records of the pulse are pruned.
+func (db *DB) GetRecordProof(ctx context.Context, id *core.RecordID) (*core.RecordProof, error) {
+	pruned, err := db.IsPruned(ctx, id.Pulse())
+	if err != nil {
+		return nil, err
+	}
+	if pruned {
+		return nil, errors.Wrapf(ErrPruned, "pulse %v", id.Pulse())
+	}
+	drop, err := db.GetDrop(ctx, id.Pulse())
+	if err != nil {
+		return nil, err
+	}
+	if drop.HashVersion != jetdrop.HashVersionMerkleSeparated {
+		return nil, errors.Wrapf(ErrProofNotSupported, "pulse %v", drop.Pulse)
+	}
+	records, err := db.dropRecords(ctx, id.Pulse())
+	if err != nil {
+		return nil, err
+	}
+	var found bool
+	for _, r := range records {
+		if bytes.Equal(r, id.Hash()) {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return nil, ErrNotFound
+	}
+
+	path, err := jetdrop.Proof(db.PlatformCryptographyScheme.ReferenceHasher(), drop.PrevHash, records, id.Hash())
+	if err != nil {
+		return nil, err
+	}
+	return &core.RecordProof{Record: *id, DropHash: drop.Hash, Path: path}, nil
+}
+
+// VerifyDrops recomputes hashes of all stored jet drops and checks that every drop
+// references hash of the drop from the previous pulse.
+func (db *DB) VerifyDrops(ctx context.Context) error {
+	return db.iterate(ctx, []byte{scopeIDJetDrop}, func(k, v []byte) error {
+		drop, err := jetdrop.Decode(v)
+		if err != nil {
+			return errors.Wrap(err, "failed to decode jet drop")
+		}
+		return db.checkDrop(ctx, drop)
+	})
+}
+
+// checkDrop recomputes jet drop hash and checks link to the drop from the previous pulse.
+// Hashes of pruned pulses drops are not recomputed.
+//
+// Returns ErrDropHashMismatch or ErrDropChainBroken (wrapped) if drop is invalid.
+func (db *DB) checkDrop(ctx context.Context, drop *jetdrop.JetDrop) error {
+	// Genesis drop has no hashes.
+	if drop.Pulse == 0 {
+		return nil
+	}
+
+	// Records of pruned pulses are removed partially, so the hash can not be recomputed.
+	pruned, err := db.IsPruned(ctx, drop.Pulse)
+	if err != nil {
+		return err
+	}
+	if !pruned {
+		hash, err := db.dropHash(ctx, drop.Pulse, drop.PrevHash, drop.HashVersion)
+		if err != nil {
+			return err
+		}
+		if !bytes.Equal(hash, drop.Hash) {
+			return errors.Wrapf(ErrDropHashMismatch, "pulse %v", drop.Pulse)
+		}
+	}
+
+	pulse, err := db.GetPulse(ctx, drop.Pulse)
+	if err != nil {
+		return errors.Wrapf(err, "failed to fetch pulse %v", drop.Pulse)
+	}
+	if pulse.Prev == nil {
+		return nil
+	}
+	prevDrop, err := db.GetDrop(ctx, *pulse.Prev)
+	if err == ErrNotFound {
+		return errors.Wrapf(ErrDropChainBroken, "pulse %v: no drop for previous pulse %v", drop.Pulse, *pulse.Prev)
+	}
+	if err != nil {
+		return errors.Wrapf(err, "failed to fetch previous jet drop for pulse %v", drop.Pulse)
+	}
+	if !bytes.Equal(prevDrop.Hash, drop.PrevHash) {
+		return errors.Wrapf(ErrDropChainBroken, "pulse %v", drop.Pulse)
+	}
+	return nil
+}
+
 // SetDrop saves provided JetDrop in db.
+//
+// Saving the same drop again is allowed, so pulse change can be retried after a failure. Returns ErrOverride if
+// another drop is saved for the pulse.
 func (db *DB) SetDrop(ctx context.Context, drop *jetdrop.JetDrop) error {
 	k := prefixkey(scopeIDJetDrop, drop.Pulse.Bytes())
-	_, err := db.get(ctx, k)
+	encoded, err := jetdrop.Encode(drop)
+	if err != nil {
+		return err
+	}
+
+	saved, err := db.get(ctx, k)
 	if err == nil {
+		if bytes.Equal(saved, encoded) {
+			return nil
+		}
 		return ErrOverride
 	}
-
-	encoded, err := jetdrop.Encode(drop)
-	if err != nil {
+	if err != ErrNotFound {
 		return err
 	}
 	return db.set(ctx, k, encoded)
@@ -410,7 +629,7 @@ func (db *DB) Update(ctx context.Context, fn func(*TransactionManager) error) er
 		if err == nil {
 			break
 		}
-		if err != badger.ErrConflict {
+		if err != ErrConflict {
 			break
 		}
 		if tries < 1 {
@@ -432,16 +651,34 @@ func (db *DB) Update(ctx context.Context, fn func(*TransactionManager) error) er
 	return err
 }
 
-// GetBadgerDB return badger.DB instance (for internal usage, like tests)
+// GetBadgerDB return badger.DB instance (for internal usage, like tests).
+//
+// Returns nil if DB works on top of another backend.
 func (db *DB) GetBadgerDB() *badger.DB {
-	return db.db
+	store := db.store
+	// values of encrypted store are returned as is
+	if es, ok := store.(*encryptedStore); ok {
+		store = es.store
+	}
+	if bs, ok := store.(*badgerStore); ok {
+		return bs.db
+	}
+	return nil
+}
+
+// GetStore returns key-value backend of DB (for internal usage, like tests).
+func (db *DB) GetStore() KVStore {
+	return db.store
 }
 
 // SetMessage persists message to the database
 func (db *DB) SetMessage(ctx context.Context, pulseNumber core.PulseNumber, genericMessage core.Message) error {
-	messageBytes := message.ToBytes(genericMessage)
+	messageBytes, err := message.ToBytes(genericMessage)
+	if err != nil {
+		return err
+	}
 	hw := db.PlatformCryptographyScheme.ReferenceHasher()
-	_, err := hw.Write(messageBytes)
+	_, err = hw.Write(messageBytes)
 	if err != nil {
 		return err
 	}
@@ -454,6 +691,17 @@ func (db *DB) SetMessage(ctx context.Context, pulseNumber core.PulseNumber, gene
 	)
 }
 
+// IterateMessages calls handler for every serialized message saved in provided pulse.
+func (db *DB) IterateMessages(ctx context.Context, pulse core.PulseNumber, handler func(msg []byte) error) error {
+	prefix := make([]byte, core.PulseNumberSize+1)
+	prefix[0] = scopeIDMessage
+	copy(prefix[1:], pulse.Bytes())
+
+	return db.iterate(ctx, prefix, func(k, v []byte) error {
+		return handler(v)
+	})
+}
+
 // SetLocalData saves provided data to storage.
 func (db *DB) SetLocalData(ctx context.Context, pulse core.PulseNumber, key []byte, data []byte) error {
 	return db.set(
@@ -503,32 +751,9 @@ func (db *DB) IterateRecords(
 	})
 }
 
-// SetActiveNodes saves active nodes for pulse in memory.
-func (db *DB) SetActiveNodes(pulse core.PulseNumber, nodes []core.Node) error {
-	db.nodeHistoryLock.Lock()
-	defer db.nodeHistoryLock.Unlock()
-
-	if _, ok := db.nodeHistory[pulse]; ok {
-		return errors.New("node history override is forbidden")
-	}
-
-	db.nodeHistory[pulse] = nodes
-
-	return nil
-}
-
-// GetActiveNodes return active nodes for specified pulse.
-func (db *DB) GetActiveNodes(pulse core.PulseNumber) ([]core.Node, error) {
-	nodes, ok := db.nodeHistory[pulse]
-	if !ok {
-		return nil, errors.New("no nodes for this pulse")
-	}
-
-	return nodes, nil
-}
-
 // StoreKeyValues stores provided key/value pairs.
 func (db *DB) StoreKeyValues(ctx context.Context, kvs []core.KV) error {
+	defer db.resetJetTrees()
 	return db.Update(ctx, func(tx *TransactionManager) error {
 		for _, rec := range kvs {
 			err := tx.set(ctx, rec.K, rec.V)
@@ -559,21 +784,5 @@ func (db *DB) iterate(
 	prefix []byte,
 	handler func(k, v []byte) error,
 ) error {
-	return db.db.View(func(txn *badger.Txn) error {
-		it := txn.NewIterator(badger.DefaultIteratorOptions)
-		defer it.Close()
-
-		for it.Seek(prefix); it.ValidForPrefix(prefix); it.Next() {
-			key := it.Item().KeyCopy(nil)[len(prefix):]
-			value, err := it.Item().ValueCopy(nil)
-			if err != nil {
-				return err
-			}
-			err = handler(key, value)
-			if err != nil {
-				return err
-			}
-		}
-		return nil
-	})
+	return kvIterate(db.store, prefix, handler)
 }
@@ -14,5 +14,7 @@
  *    limitations under the License.
  */
 
-// Package storage contains ledger storage implementation on top of BadgerDB engine.
+// Package storage contains ledger storage implementation on top of key-value backend.
+//
+// BadgerDB backend is used for nodes and in-memory backend could be used for tests and local simulations.
 package storage
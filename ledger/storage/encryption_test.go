@@ -0,0 +1,242 @@
+/*
+ *    Copyright 2018 Insolar
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ */
+
+package storage_test
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/dgraph-io/badger"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/insolar/insolar/configuration"
+	"github.com/insolar/insolar/core"
+	"github.com/insolar/insolar/encryption"
+	"github.com/insolar/insolar/instrumentation/inslogger"
+	"github.com/insolar/insolar/ledger/storage"
+	"github.com/insolar/insolar/ledger/storage/storagetest"
+	"github.com/insolar/insolar/platformpolicy"
+)
+
+func randomKey(t *testing.T) []byte {
+	key := make([]byte, encryption.KeySize)
+	_, err := rand.Read(key)
+	require.NoError(t, err)
+	return key
+}
+
+// encryptionStateKey is a key of storage encryption state which is kept unencrypted.
+var encryptionStateKey = append([]byte{5, 8}, make([]byte, core.RecordIDSize-1)...)
+
+func rawValues(t *testing.T, store storage.KVStore) [][]byte {
+	txn := store.NewTransaction(false)
+	defer txn.Discard()
+	it := txn.NewIterator()
+	defer it.Close()
+
+	var values [][]byte
+	for it.Seek(nil); it.ValidForPrefix(nil); it.Next() {
+		if bytes.Equal(it.Key(), encryptionStateKey) {
+			continue
+		}
+		value, err := it.Value()
+		require.NoError(t, err)
+		values = append(values, value)
+	}
+	return values
+}
+
+func encryptedDB(t *testing.T, store storage.KVStore, ring *encryption.KeyRing) *storage.DB {
+	encrypted, err := storage.NewEncryptedStore(store, ring)
+	require.NoError(t, err)
+	db := storage.NewDBWithStore(configuration.NewLedger(), encrypted)
+	db.PlatformCryptographyScheme = platformpolicy.NewPlatformCryptographyScheme()
+	return db
+}
+
+func TestDB_Encryption_Rotation(t *testing.T) {
+	t.Parallel()
+	ctx := inslogger.TestContext(t)
+	raw := storage.NewMemoryStore()
+
+	// Storage written before encryption was enabled.
+	plain := storage.NewDBWithStore(configuration.NewLedger(), raw)
+	plain.PlatformCryptographyScheme = platformpolicy.NewPlatformCryptographyScheme()
+	require.NoError(t, plain.Init(ctx))
+	memory := []byte("secret contract memory")
+	blobID, err := plain.SetBlob(ctx, core.GenesisPulse.PulseNumber, memory)
+	require.NoError(t, err)
+
+	// Plain values are encrypted on open.
+	oldKey, newKey := randomKey(t), randomKey(t)
+	oldRing, err := encryption.NewKeyRing(oldKey)
+	require.NoError(t, err)
+	db := encryptedDB(t, raw, oldRing)
+	for _, value := range rawValues(t, raw) {
+		assert.True(t, oldRing.IsActual(value))
+		assert.False(t, bytes.Contains(value, memory))
+	}
+	blob, err := db.GetBlob(ctx, blobID)
+	require.NoError(t, err)
+	assert.Equal(t, memory, blob)
+	count, err := db.Reencrypt(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, count)
+
+	// Key rotation: the new key is active, the old one is kept until data is re-encrypted.
+	rotated, err := encryption.NewKeyRing(oldKey, newKey)
+	require.NoError(t, err)
+	db = encryptedDB(t, raw, rotated)
+	count, err = db.Reencrypt(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, len(rawValues(t, raw)), count)
+	count, err = db.Reencrypt(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, count)
+
+	newRing, err := encryption.NewKeyRing(newKey)
+	require.NoError(t, err)
+	db = encryptedDB(t, raw, newRing)
+	blob, err = db.GetBlob(ctx, blobID)
+	require.NoError(t, err)
+	assert.Equal(t, memory, blob)
+
+	db = encryptedDB(t, raw, oldRing)
+	_, err = db.GetBlob(ctx, blobID)
+	assert.Equal(t, encryption.ErrUnknownKey, err)
+}
+
+func TestNewReadOnlyEncryptedStore(t *testing.T) {
+	t.Parallel()
+	ctx := inslogger.TestContext(t)
+	raw := storage.NewMemoryStore()
+	plain := storage.NewDBWithStore(configuration.NewLedger(), raw)
+	plain.PlatformCryptographyScheme = platformpolicy.NewPlatformCryptographyScheme()
+	require.NoError(t, plain.Init(ctx))
+	memory := []byte("secret contract memory")
+	blobID, err := plain.SetBlob(ctx, core.GenesisPulse.PulseNumber, memory)
+	require.NoError(t, err)
+	ring, err := encryption.NewKeyRing(randomKey(t))
+	require.NoError(t, err)
+
+	// Plain values are not encrypted by read-only store.
+	before := rawValues(t, raw)
+	_, err = storage.NewReadOnlyEncryptedStore(raw, ring)
+	assert.Equal(t, storage.ErrEncryptionMigrationPending, err)
+	assert.Equal(t, before, rawValues(t, raw))
+
+	encryptedDB(t, raw, ring)
+	store, err := storage.NewReadOnlyEncryptedStore(raw, ring)
+	require.NoError(t, err)
+	db := storage.NewDBWithStore(configuration.NewLedger(), store)
+	blob, err := db.GetBlob(ctx, blobID)
+	require.NoError(t, err)
+	assert.Equal(t, memory, blob)
+}
+
+func TestDB_Encryption_Snapshot(t *testing.T) {
+	t.Parallel()
+	ctx := inslogger.TestContext(t)
+	ring, err := encryption.NewKeyRing(randomKey(t))
+	require.NoError(t, err)
+	db := encryptedDB(t, storage.NewMemoryStore(), ring)
+	require.NoError(t, db.Init(ctx))
+	memory := []byte("secret contract memory")
+	blobID, err := db.SetBlob(ctx, core.GenesisPulse.PulseNumber, memory)
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	count, err := db.Snapshot(ctx, &buf)
+	require.NoError(t, err)
+	assert.False(t, bytes.Contains(buf.Bytes(), memory))
+
+	plain, cleaner := storagetest.TmpDB(ctx, t, storagetest.InMemory(), storagetest.DisableBootstrap())
+	defer cleaner()
+	_, err = plain.Restore(ctx, bytes.NewReader(buf.Bytes()))
+	assert.Error(t, err)
+
+	restored := encryptedDB(t, storage.NewMemoryStore(), ring)
+	rcount, err := restored.Restore(ctx, &buf)
+	require.NoError(t, err)
+	assert.Equal(t, count, rcount)
+	blob, err := restored.GetBlob(ctx, blobID)
+	require.NoError(t, err)
+	assert.Equal(t, memory, blob)
+}
+
+func TestDB_Encryption_KeyFile(t *testing.T) {
+	t.Parallel()
+	ctx := inslogger.TestContext(t)
+	dir, err := ioutil.TempDir("", "keys")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "storage.keys")
+	require.NoError(t, ioutil.WriteFile(path, []byte(hex.EncodeToString(randomKey(t))), 0600))
+
+	db, cleaner := storagetest.TmpDB(ctx, t, storagetest.EncryptionKeyFile(path))
+	defer cleaner()
+
+	memory := []byte("secret contract memory")
+	blobID, err := db.SetBlob(ctx, core.GenesisPulse.PulseNumber, memory)
+	require.NoError(t, err)
+	blob, err := db.GetBlob(ctx, blobID)
+	require.NoError(t, err)
+	assert.Equal(t, memory, blob)
+
+	err = db.GetBadgerDB().View(func(txn *badger.Txn) error {
+		it := txn.NewIterator(badger.DefaultIteratorOptions)
+		defer it.Close()
+		for it.Rewind(); it.Valid(); it.Next() {
+			if bytes.Equal(it.Item().Key(), encryptionStateKey) {
+				continue
+			}
+			value, err := it.Item().ValueCopy(nil)
+			require.NoError(t, err)
+			assert.True(t, encryption.IsEncrypted(value))
+			assert.False(t, bytes.Contains(value, memory))
+		}
+		return nil
+	})
+	require.NoError(t, err)
+}
+
+func TestNewDB_EncryptedStorageRequiresKeys(t *testing.T) {
+	t.Parallel()
+	dir, err := ioutil.TempDir("", "encrypted")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "storage.keys")
+	require.NoError(t, ioutil.WriteFile(path, []byte(hex.EncodeToString(randomKey(t))), 0600))
+
+	conf := configuration.NewLedger()
+	conf.Storage.DataDirectory = filepath.Join(dir, "data")
+	conf.Storage.Encryption = configuration.StorageEncryption{Enabled: true, KeyFile: path}
+	db, err := storage.NewDB(conf, nil, nil)
+	require.NoError(t, err)
+	require.NoError(t, db.Close())
+
+	conf.Storage.Encryption.Enabled = false
+	_, err = storage.NewDB(conf, nil, nil)
+	assert.Error(t, err)
+}
@@ -26,6 +26,12 @@ var (
 	// ErrNotFound returns if record/index not found in storage.
 	ErrNotFound = errors.New("storage object not found")
 
+	// ErrReplicaOutOfRange is returned if replicated key is out of the locked pulse range.
+	ErrReplicaOutOfRange = errors.New("replicated key is out of sync range")
+
+	// ErrReplicaChecksum is returned if replicated jet drop does not match provided checksum.
+	ErrReplicaChecksum = errors.New("replicated jet drop checksum mismatch")
+
 	// ErrConflictRetriesOver is returned if Update transaction fails on all retry attempts.
 	ErrConflictRetriesOver = errors.New("transaction conflict retries limit exceeded")
 
@@ -34,4 +40,26 @@ var (
 
 	// ErrOverride is returned if SetRecord tries update existing record
 	ErrOverride = errors.New("records override is forbidden")
+
+	// ErrRestoreInProgress is returned on Init if storage restore from snapshot was not finished.
+	ErrRestoreInProgress = errors.New("storage restore from snapshot is not finished")
+
+	// ErrDropHashMismatch is returned if recomputed jet drop hash differs from the stored one.
+	ErrDropHashMismatch = errors.New("jet drop hash mismatch")
+
+	// ErrDropChainBroken is returned if jet drop previous hash differs from the previous drop hash.
+	ErrDropChainBroken = errors.New("jet drop hash chain is broken")
+
+	// ErrProofNotSupported is returned if record proof is requested for a drop created before Merkle hashes with
+	// separated leaf and node hashes.
+	ErrProofNotSupported = errors.New("jet drop hash version does not support record proofs")
+
+	// ErrPruned is returned if requested data of the pulse is pruned.
+	ErrPruned = errors.New("pulse data is pruned")
+
+	// ErrEncryptionMigrationPending is returned if read-only storage has plain values which should be encrypted.
+	ErrEncryptionMigrationPending = errors.New("storage encryption migration is pending, open storage for writing")
+
+	// ErrReadOnlyTxn is returned if write is called on read-only backend transaction.
+	ErrReadOnlyTxn = errors.New("no sets or deletes are allowed in a read-only transaction")
 )
@@ -0,0 +1,211 @@
+/*
+ *    Copyright 2018 Insolar
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ */
+
+package jet
+
+import (
+	"bytes"
+
+	"github.com/pkg/errors"
+	"github.com/ugorji/go/codec"
+
+	"github.com/insolar/insolar/core"
+)
+
+// MaxDepth is the maximum depth of jet tree.
+const MaxDepth = core.RecordHashSize * 8
+
+// NewID creates jet id from depth and prefix. Only first depth bits of the prefix are used.
+//
+// Depth is saved in the pulse part of the id, so jet ids never intersect with record ids.
+func NewID(depth uint8, prefix []byte) *core.RecordID {
+	hash := make([]byte, core.RecordHashSize)
+	copy(hash, prefix)
+	resetBits(hash, depth)
+	return core.NewRecordID(core.PulseNumber(depth), hash)
+}
+
+// Jet returns depth and prefix of provided jet id.
+func Jet(id core.RecordID) (uint8, []byte) {
+	return uint8(id.Pulse()), id.Hash()
+}
+
+// Tree stores jets in a binary tree format.
+type Tree struct {
+	Head *jet
+}
+
+type jet struct {
+	Left  *jet
+	Right *jet
+}
+
+func (j *jet) isLeaf() bool {
+	return j.Left == nil && j.Right == nil
+}
+
+// NewTree creates tree with a single root jet.
+func NewTree() *Tree {
+	return &Tree{Head: &jet{}}
+}
+
+// Find returns id of the jet containing provided object.
+func (t *Tree) Find(obj core.RecordID) *core.RecordID {
+	hash := obj.Hash()
+	j, depth := t.Head, uint8(0)
+	for !j.isLeaf() {
+		if getBit(hash, depth) {
+			j = j.Right
+		} else {
+			j = j.Left
+		}
+		depth++
+	}
+	return NewID(depth, hash)
+}
+
+// Leaves returns ids of all jets in the tree ordered from left to right.
+func (t *Tree) Leaves() []core.RecordID {
+	var leaves []core.RecordID
+	var walk func(j *jet, depth uint8, prefix []byte)
+	walk = func(j *jet, depth uint8, prefix []byte) {
+		if j.isLeaf() {
+			leaves = append(leaves, *NewID(depth, prefix))
+			return
+		}
+		walk(j.Left, depth+1, prefix)
+		right := append([]byte(nil), prefix...)
+		setBit(right, depth)
+		walk(j.Right, depth+1, right)
+	}
+	walk(t.Head, 0, make([]byte, core.RecordHashSize))
+	return leaves
+}
+
+// Split splits provided jet into two jets. Returns ids of new left and right jets.
+func (t *Tree) Split(id core.RecordID) (*core.RecordID, *core.RecordID, error) {
+	depth, prefix := Jet(id)
+	if depth >= MaxDepth {
+		return nil, nil, errors.New("jet has maximum depth")
+	}
+	j, err := t.get(depth, prefix)
+	if err != nil {
+		return nil, nil, err
+	}
+	j.Left, j.Right = &jet{}, &jet{}
+
+	right := append([]byte(nil), prefix...)
+	setBit(right, depth)
+	return NewID(depth+1, prefix), NewID(depth+1, right), nil
+}
+
+// Merge merges provided jet with its sibling. Both jets should be leaves. Returns id of the merged jet.
+func (t *Tree) Merge(id core.RecordID) (*core.RecordID, error) {
+	depth, prefix := Jet(id)
+	if depth == 0 {
+		return nil, errors.New("root jet can't be merged")
+	}
+	if _, err := t.get(depth, prefix); err != nil {
+		return nil, err
+	}
+	parent, err := t.node(depth-1, prefix)
+	if err != nil {
+		return nil, err
+	}
+	if !parent.Left.isLeaf() || !parent.Right.isLeaf() {
+		return nil, errors.New("sibling jet is split")
+	}
+	parent.Left, parent.Right = nil, nil
+
+	return NewID(depth-1, prefix), nil
+}
+
+// Clone returns a deep copy of the tree.
+func (t *Tree) Clone() *Tree {
+	return &Tree{Head: t.Head.clone()}
+}
+
+func (j *jet) clone() *jet {
+	if j == nil {
+		return nil
+	}
+	return &jet{Left: j.Left.clone(), Right: j.Right.clone()}
+}
+
+// Bytes serializes tree.
+func (t *Tree) Bytes() []byte {
+	var buf bytes.Buffer
+	enc := codec.NewEncoder(&buf, &codec.CborHandle{})
+	enc.MustEncode(t)
+	return buf.Bytes()
+}
+
+// Decode deserializes tree.
+func Decode(buf []byte) (*Tree, error) {
+	var t Tree
+	dec := codec.NewDecoder(bytes.NewReader(buf), &codec.CborHandle{})
+	err := dec.Decode(&t)
+	if err != nil {
+		return nil, err
+	}
+	if t.Head == nil {
+		t.Head = &jet{}
+	}
+	return &t, nil
+}
+
+// get returns leaf jet with provided depth and prefix.
+func (t *Tree) get(depth uint8, prefix []byte) (*jet, error) {
+	j, err := t.node(depth, prefix)
+	if err != nil {
+		return nil, err
+	}
+	if !j.isLeaf() {
+		return nil, errors.New("jet is split")
+	}
+	return j, nil
+}
+
+// node returns tree node with provided depth and prefix.
+func (t *Tree) node(depth uint8, prefix []byte) (*jet, error) {
+	j := t.Head
+	for i := uint8(0); i < depth; i++ {
+		if j.isLeaf() {
+			return nil, errors.New("jet not found")
+		}
+		if getBit(prefix, i) {
+			j = j.Right
+		} else {
+			j = j.Left
+		}
+	}
+	return j, nil
+}
+
+func getBit(value []byte, index uint8) bool {
+	return value[index/8]&(0x80>>(index%8)) != 0
+}
+
+func setBit(value []byte, index uint8) {
+	value[index/8] |= 0x80 >> (index % 8)
+}
+
+// resetBits resets all bits starting from provided index.
+func resetBits(value []byte, from uint8) {
+	for i := int(from); i < len(value)*8; i++ {
+		value[i/8] &^= 0x80 >> uint(i%8)
+	}
+}
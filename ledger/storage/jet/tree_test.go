@@ -0,0 +1,119 @@
+/*
+ *    Copyright 2018 Insolar
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ */
+
+package jet_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/insolar/insolar/core"
+	"github.com/insolar/insolar/ledger/storage/jet"
+)
+
+func objectID(prefix byte) core.RecordID {
+	hash := make([]byte, core.RecordHashSize)
+	hash[0] = prefix
+	return *core.NewRecordID(core.FirstPulseNumber, hash)
+}
+
+func TestNewID(t *testing.T) {
+	prefix := []byte{0xFF, 0xFF}
+	id := jet.NewID(4, prefix)
+	depth, jetPrefix := jet.Jet(*id)
+	assert.Equal(t, uint8(4), depth)
+	assert.Equal(t, byte(0xF0), jetPrefix[0])
+	assert.Equal(t, byte(0), jetPrefix[1])
+	// Provided prefix is not modified.
+	assert.Equal(t, []byte{0xFF, 0xFF}, prefix)
+}
+
+func TestTree_SplitAndFind(t *testing.T) {
+	tree := jet.NewTree()
+	root := *jet.NewID(0, nil)
+	assert.Equal(t, root, *tree.Find(objectID(0xFF)))
+	assert.Equal(t, []core.RecordID{root}, tree.Leaves())
+
+	left, right, err := tree.Split(root)
+	require.NoError(t, err)
+	assert.Equal(t, *jet.NewID(1, []byte{0x00}), *left)
+	assert.Equal(t, *jet.NewID(1, []byte{0x80}), *right)
+	assert.Equal(t, *left, *tree.Find(objectID(0x7F)))
+	assert.Equal(t, *right, *tree.Find(objectID(0x80)))
+
+	// Split jets can't be split again.
+	_, _, err = tree.Split(root)
+	assert.Error(t, err)
+
+	rightLeft, rightRight, err := tree.Split(*right)
+	require.NoError(t, err)
+	assert.Equal(t, *rightLeft, *tree.Find(objectID(0xA0)))
+	assert.Equal(t, *rightRight, *tree.Find(objectID(0xC0)))
+	assert.Equal(t, []core.RecordID{*left, *rightLeft, *rightRight}, tree.Leaves())
+}
+
+func TestTree_Merge(t *testing.T) {
+	tree := jet.NewTree()
+	root := *jet.NewID(0, nil)
+	_, err := tree.Merge(root)
+	assert.Error(t, err)
+
+	left, right, err := tree.Split(root)
+	require.NoError(t, err)
+	_, _, err = tree.Split(*right)
+	require.NoError(t, err)
+
+	// Sibling is split.
+	_, err = tree.Merge(*left)
+	assert.Error(t, err)
+
+	rightLeft := tree.Find(objectID(0xA0))
+	merged, err := tree.Merge(*rightLeft)
+	require.NoError(t, err)
+	assert.Equal(t, *right, *merged)
+	assert.Equal(t, []core.RecordID{*left, *right}, tree.Leaves())
+
+	merged, err = tree.Merge(*right)
+	require.NoError(t, err)
+	assert.Equal(t, root, *merged)
+	assert.Equal(t, []core.RecordID{root}, tree.Leaves())
+}
+
+func TestTree_Serialization(t *testing.T) {
+	tree := jet.NewTree()
+	_, right, err := tree.Split(*jet.NewID(0, nil))
+	require.NoError(t, err)
+	_, _, err = tree.Split(*right)
+	require.NoError(t, err)
+
+	decoded, err := jet.Decode(tree.Bytes())
+	require.NoError(t, err)
+	assert.Equal(t, tree.Leaves(), decoded.Leaves())
+	assert.Equal(t, tree, decoded)
+}
+
+func TestTree_Clone(t *testing.T) {
+	tree := jet.NewTree()
+	root := *jet.NewID(0, nil)
+	clone := tree.Clone()
+
+	_, _, err := clone.Split(root)
+	require.NoError(t, err)
+	assert.Equal(t, []core.RecordID{root}, tree.Leaves())
+	assert.Len(t, clone.Leaves(), 2)
+}
@@ -0,0 +1,92 @@
+/*
+ *    Copyright 2018 Insolar
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ */
+
+package storage
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+
+	"github.com/insolar/insolar/core"
+	"github.com/insolar/insolar/ledger/storage/jet"
+)
+
+// jetTreeCacheSize is a number of decoded jet trees cached in memory.
+const jetTreeCacheSize = 16
+
+// SetJetTree saves jet tree agreed at the end of provided pulse. The tree is used for role selection in the next
+// pulse.
+func (db *DB) SetJetTree(ctx context.Context, pulse core.PulseNumber, tree *jet.Tree) error {
+	err := db.set(ctx, prefixkey(scopeIDJetTree, pulse.Bytes()), tree.Bytes())
+	if err != nil {
+		return err
+	}
+	db.resetJetTrees()
+	return nil
+}
+
+// GetJetTree returns jet tree agreed at the end of provided pulse.
+//
+// Returns ErrNotFound if the tree is not saved yet. Returned tree is cached and shared between callers, it should be
+// cloned before modification.
+func (db *DB) GetJetTree(ctx context.Context, pulse core.PulseNumber) (*jet.Tree, error) {
+	db.jetTreesLock.Lock()
+	tree, ok := db.jetTrees[pulse]
+	generation := db.jetTreesGeneration
+	db.jetTreesLock.Unlock()
+	if ok {
+		return tree, nil
+	}
+
+	buf, err := db.get(ctx, prefixkey(scopeIDJetTree, pulse.Bytes()))
+	if err != nil {
+		return nil, err
+	}
+	tree, err = jet.Decode(buf)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to decode jet tree")
+	}
+
+	db.jetTreesLock.Lock()
+	defer db.jetTreesLock.Unlock()
+	// Trees were changed while the tree was loading.
+	if generation != db.jetTreesGeneration {
+		return tree, nil
+	}
+	for len(db.jetTrees) >= jetTreeCacheSize {
+		oldest := pulse
+		for pn := range db.jetTrees {
+			if pn < oldest {
+				oldest = pn
+			}
+		}
+		if oldest == pulse {
+			return tree, nil
+		}
+		delete(db.jetTrees, oldest)
+	}
+	db.jetTrees[pulse] = tree
+	return tree, nil
+}
+
+// resetJetTrees drops cached jet trees.
+func (db *DB) resetJetTrees() {
+	db.jetTreesLock.Lock()
+	db.jetTrees = map[core.PulseNumber]*jet.Tree{}
+	db.jetTreesGeneration++
+	db.jetTreesLock.Unlock()
+}
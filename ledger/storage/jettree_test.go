@@ -0,0 +1,61 @@
+/*
+ *    Copyright 2018 Insolar
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ */
+
+package storage_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/insolar/insolar/instrumentation/inslogger"
+	"github.com/insolar/insolar/ledger/storage"
+	"github.com/insolar/insolar/ledger/storage/jet"
+	"github.com/insolar/insolar/ledger/storage/storagetest"
+)
+
+func TestDB_GetJetTree(t *testing.T) {
+	t.Parallel()
+	ctx := inslogger.TestContext(t)
+	db, cleaner := storagetest.TmpDB(ctx, t, storagetest.InMemory())
+	defer cleaner()
+
+	root := *jet.NewID(0, nil)
+	splitTree := jet.NewTree()
+	_, _, err := splitTree.Split(root)
+	require.NoError(t, err)
+
+	// Tree is not agreed yet.
+	_, err = db.GetJetTree(ctx, pulseDelta(2))
+	assert.Equal(t, storage.ErrNotFound, err)
+
+	err = db.SetJetTree(ctx, pulseDelta(2), splitTree)
+	require.NoError(t, err)
+
+	tree, err := db.GetJetTree(ctx, pulseDelta(2))
+	require.NoError(t, err)
+	assert.Len(t, tree.Leaves(), 2)
+
+	// Cached tree is returned on the second call.
+	cached, err := db.GetJetTree(ctx, pulseDelta(2))
+	require.NoError(t, err)
+	assert.True(t, tree == cached)
+
+	// Trees of other pulses are not inherited.
+	_, err = db.GetJetTree(ctx, pulseDelta(5))
+	assert.Equal(t, storage.ErrNotFound, err)
+}
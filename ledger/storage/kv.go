@@ -0,0 +1,91 @@
+/*
+ *    Copyright 2018 Insolar
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ */
+
+package storage
+
+// KVStore is a transactional key-value backend used by DB.
+//
+// Keys are ordered lexicographically, so all data with the same scope prefix (scopeIDRecord, scopeIDLifeline, etc.)
+// could be fetched by prefix iteration. Update transactions are optimistic: Commit returns ErrConflict if keys
+// read by transaction were changed by another committed transaction. Such errors are retried by DB.Update.
+type KVStore interface {
+	// NewTransaction opens new transaction. Read-only transactions return ErrReadOnlyTxn on writes.
+	NewTransaction(update bool) KVTxn
+	// Close releases backend resources.
+	Close() error
+}
+
+// KVTxn is a transaction over KVStore.
+type KVTxn interface {
+	// Get returns value by key or ErrNotFound.
+	Get(key []byte) ([]byte, error)
+	// Set stores value by key.
+	Set(key, value []byte) error
+	// Delete removes key.
+	Delete(key []byte) error
+	// NewIterator creates iterator over transaction data.
+	NewIterator() KVIterator
+	// Commit applies transaction changes.
+	Commit() error
+	// Discard terminates transaction. It is safe to call Discard after Commit.
+	Discard()
+}
+
+// KVIterator iterates over keys in lexicographical order.
+type KVIterator interface {
+	// Seek moves iterator to the smallest key greater or equal to provided one.
+	Seek(key []byte)
+	// ValidForPrefix returns false when iterator reaches the end or the key without provided prefix.
+	ValidForPrefix(prefix []byte) bool
+	// Next moves iterator to the next key.
+	Next()
+	// Key returns copy of current key.
+	Key() []byte
+	// Value returns copy of current value.
+	Value() ([]byte, error)
+	// Close releases iterator.
+	Close()
+}
+
+// kvView runs fn in read-only transaction.
+func kvView(store KVStore, fn func(KVTxn) error) error {
+	txn := store.NewTransaction(false)
+	defer txn.Discard()
+	return fn(txn)
+}
+
+// kvIterate calls handler for all key/value pairs with provided prefix.
+//
+// Handler receives keys without prefix.
+func kvIterate(store KVStore, prefix []byte, handler func(k, v []byte) error) error {
+	return kvView(store, func(txn KVTxn) error {
+		it := txn.NewIterator()
+		defer it.Close()
+
+		for it.Seek(prefix); it.ValidForPrefix(prefix); it.Next() {
+			key := it.Key()[len(prefix):]
+			value, err := it.Value()
+			if err != nil {
+				return err
+			}
+			err = handler(key, value)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
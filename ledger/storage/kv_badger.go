@@ -0,0 +1,112 @@
+/*
+ *    Copyright 2018 Insolar
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ */
+
+package storage
+
+import (
+	"github.com/dgraph-io/badger"
+)
+
+type badgerStore struct {
+	db *badger.DB
+}
+
+// NewBadgerStore opens BadgerDB backend with provided options.
+func NewBadgerStore(opts badger.Options) (KVStore, error) {
+	bdb, err := badger.Open(opts)
+	if err != nil {
+		return nil, err
+	}
+	return &badgerStore{db: bdb}, nil
+}
+
+func (s *badgerStore) NewTransaction(update bool) KVTxn {
+	return &badgerTxn{txn: s.db.NewTransaction(update)}
+}
+
+func (s *badgerStore) Close() error {
+	return s.db.Close()
+}
+
+type badgerTxn struct {
+	txn *badger.Txn
+}
+
+func (t *badgerTxn) Get(key []byte) ([]byte, error) {
+	item, err := t.txn.Get(key)
+	if err != nil {
+		if err == badger.ErrKeyNotFound {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return item.ValueCopy(nil)
+}
+
+func (t *badgerTxn) Set(key, value []byte) error {
+	return badgerErr(t.txn.Set(key, value))
+}
+
+func (t *badgerTxn) Delete(key []byte) error {
+	return badgerErr(t.txn.Delete(key))
+}
+
+func (t *badgerTxn) NewIterator() KVIterator {
+	return &badgerIterator{it: t.txn.NewIterator(badger.DefaultIteratorOptions)}
+}
+
+func (t *badgerTxn) Commit() error {
+	return t.txn.Commit(nil)
+}
+
+func (t *badgerTxn) Discard() {
+	t.txn.Discard()
+}
+
+type badgerIterator struct {
+	it *badger.Iterator
+}
+
+func (i *badgerIterator) Seek(key []byte) {
+	i.it.Seek(key)
+}
+
+func (i *badgerIterator) ValidForPrefix(prefix []byte) bool {
+	return i.it.ValidForPrefix(prefix)
+}
+
+func (i *badgerIterator) Next() {
+	i.it.Next()
+}
+
+func (i *badgerIterator) Key() []byte {
+	return i.it.Item().KeyCopy(nil)
+}
+
+func (i *badgerIterator) Value() ([]byte, error) {
+	return i.it.Item().ValueCopy(nil)
+}
+
+func (i *badgerIterator) Close() {
+	i.it.Close()
+}
+
+func badgerErr(err error) error {
+	if err == badger.ErrReadOnlyTxn {
+		return ErrReadOnlyTxn
+	}
+	return err
+}
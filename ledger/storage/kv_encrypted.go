@@ -0,0 +1,316 @@
+/*
+ *    Copyright 2018 Insolar
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ */
+
+package storage
+
+import (
+	"bytes"
+
+	"github.com/pkg/errors"
+
+	"github.com/insolar/insolar/core"
+	"github.com/insolar/insolar/encryption"
+)
+
+// encryptionStateKey keeps state of values encryption. Its value is stored as is.
+var encryptionStateKey = prefixkey(scopeIDSystem, []byte{sysEncryptionState})
+
+const (
+	// encryptionMigrating state is followed by the key to continue encryption of plain values from.
+	encryptionMigrating byte = 1
+	// encryptionDone state means that all values are encrypted.
+	encryptionDone byte = 2
+)
+
+// encryptedStore encrypts values of underlying KVStore. Keys are kept as is to preserve their ordering.
+type encryptedStore struct {
+	store KVStore
+	ring  *encryption.KeyRing
+}
+
+// NewEncryptedStore wraps KVStore with encryption of values by provided key ring.
+//
+// Plain values written before encryption was enabled are encrypted by explicit migration first. Migration is done
+// in batches and continues from the last batch if it was interrupted. After migration all values should be encrypted.
+func NewEncryptedStore(store KVStore, ring *encryption.KeyRing) (KVStore, error) {
+	s := &encryptedStore{store: store, ring: ring}
+	if err := s.migrate(reencryptBatchSize); err != nil {
+		return nil, errors.Wrap(err, "failed to encrypt plain values")
+	}
+	return s, nil
+}
+
+// NewReadOnlyEncryptedStore wraps read-only KVStore with encryption of values by provided key ring.
+//
+// Returns ErrEncryptionMigrationPending if plain values of the store were not encrypted yet.
+func NewReadOnlyEncryptedStore(store KVStore, ring *encryption.KeyRing) (KVStore, error) {
+	s := &encryptedStore{store: store, ring: ring}
+	_, done, err := s.migrationState()
+	if err != nil {
+		return nil, err
+	}
+	if !done {
+		return nil, ErrEncryptionMigrationPending
+	}
+	return s, nil
+}
+
+// isEncrypted checks if values of store were encrypted.
+func isEncrypted(store KVStore) (bool, error) {
+	err := kvView(store, func(txn KVTxn) error {
+		_, err := txn.Get(encryptionStateKey)
+		return err
+	})
+	if err == ErrNotFound {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// migrate encrypts plain values, up to 'limit' values in one transaction.
+func (s *encryptedStore) migrate(limit int) error {
+	for {
+		from, done, err := s.migrationState()
+		if err != nil || done {
+			return err
+		}
+		if err = s.encryptPlain(from, limit); err != nil {
+			return err
+		}
+	}
+}
+
+// migrationState returns the key to continue migration from or true if migration is done.
+func (s *encryptedStore) migrationState() ([]byte, bool, error) {
+	var state []byte
+	err := kvView(s.store, func(txn KVTxn) error {
+		var err error
+		state, err = txn.Get(encryptionStateKey)
+		return err
+	})
+	if err == ErrNotFound {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	switch {
+	case len(state) == 1 && state[0] == encryptionDone:
+		return nil, true, nil
+	case len(state) > 1 && state[0] == encryptionMigrating:
+		return state[1:], false, nil
+	}
+	return nil, false, errors.Errorf("unknown encryption state %v", bytes2hex(state))
+}
+
+// encryptPlain encrypts up to 'limit' plain values starting from key 'from' and saves migration state.
+func (s *encryptedStore) encryptPlain(from []byte, limit int) error {
+	var (
+		plain []core.KV
+		next  []byte
+	)
+	err := kvView(s.store, func(txn KVTxn) error {
+		it := txn.NewIterator()
+		defer it.Close()
+
+		for it.Seek(from); it.ValidForPrefix(nil); it.Next() {
+			key := it.Key()
+			if bytes.Equal(key, encryptionStateKey) {
+				continue
+			}
+			if len(plain) >= limit {
+				next = key
+				return nil
+			}
+			value, err := it.Value()
+			if err != nil {
+				return err
+			}
+			plain = append(plain, core.KV{K: key, V: value})
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	txn := s.store.NewTransaction(true)
+	defer txn.Discard()
+	for _, kv := range plain {
+		encrypted, err := s.ring.Encrypt(kv.K, kv.V)
+		if err != nil {
+			return err
+		}
+		if err = txn.Set(kv.K, encrypted); err != nil {
+			return err
+		}
+	}
+	state := []byte{encryptionDone}
+	if next != nil {
+		state = append([]byte{encryptionMigrating}, next...)
+	}
+	if err = txn.Set(encryptionStateKey, state); err != nil {
+		return err
+	}
+	return txn.Commit()
+}
+
+// setEncrypted saves already encrypted values as is.
+func (s *encryptedStore) setEncrypted(kvs []core.KV) error {
+	txn := s.store.NewTransaction(true)
+	defer txn.Discard()
+	for _, kv := range kvs {
+		if err := txn.Set(kv.K, kv.V); err != nil {
+			return err
+		}
+	}
+	return txn.Commit()
+}
+
+func (s *encryptedStore) NewTransaction(update bool) KVTxn {
+	return &encryptedTxn{txn: s.store.NewTransaction(update), ring: s.ring}
+}
+
+func (s *encryptedStore) Close() error {
+	return s.store.Close()
+}
+
+// reencrypt re-encrypts with active key up to 'limit' values starting from key 'from'.
+//
+// Returns the key to continue from, or nil if there are no more keys, and a number of re-encrypted values.
+func (s *encryptedStore) reencrypt(from []byte, limit int) ([]byte, int, error) {
+	var (
+		stale [][]byte
+		next  []byte
+	)
+	err := kvView(s.store, func(txn KVTxn) error {
+		it := txn.NewIterator()
+		defer it.Close()
+
+		scanned := 0
+		for it.Seek(from); it.ValidForPrefix(nil); it.Next() {
+			key := it.Key()
+			if bytes.Equal(key, encryptionStateKey) {
+				continue
+			}
+			if scanned >= limit {
+				next = key
+				return nil
+			}
+			scanned++
+			value, err := it.Value()
+			if err != nil {
+				return err
+			}
+			if !s.ring.IsActual(value) {
+				stale = append(stale, key)
+			}
+		}
+		return nil
+	})
+	if err != nil || len(stale) == 0 {
+		return next, 0, err
+	}
+
+	txn := s.store.NewTransaction(true)
+	defer txn.Discard()
+	count := 0
+	for _, k := range stale {
+		value, err := txn.Get(k)
+		if err == ErrNotFound {
+			continue
+		}
+		if err != nil {
+			return nil, 0, err
+		}
+		if s.ring.IsActual(value) {
+			continue
+		}
+		plain, err := s.ring.Decrypt(k, value)
+		if err != nil {
+			return nil, 0, err
+		}
+		encrypted, err := s.ring.Encrypt(k, plain)
+		if err != nil {
+			return nil, 0, err
+		}
+		if err = txn.Set(k, encrypted); err != nil {
+			return nil, 0, err
+		}
+		count++
+	}
+	if err := txn.Commit(); err != nil {
+		return nil, 0, err
+	}
+	return next, count, nil
+}
+
+type encryptedTxn struct {
+	txn  KVTxn
+	ring *encryption.KeyRing
+}
+
+func (t *encryptedTxn) Get(key []byte) ([]byte, error) {
+	value, err := t.txn.Get(key)
+	if err != nil {
+		return nil, err
+	}
+	if bytes.Equal(key, encryptionStateKey) {
+		return value, nil
+	}
+	return t.ring.Decrypt(key, value)
+}
+
+func (t *encryptedTxn) Set(key, value []byte) error {
+	encrypted, err := t.ring.Encrypt(key, value)
+	if err != nil {
+		return err
+	}
+	return t.txn.Set(key, encrypted)
+}
+
+func (t *encryptedTxn) Delete(key []byte) error {
+	return t.txn.Delete(key)
+}
+
+func (t *encryptedTxn) NewIterator() KVIterator {
+	return &encryptedIterator{KVIterator: t.txn.NewIterator(), ring: t.ring}
+}
+
+func (t *encryptedTxn) Commit() error {
+	return t.txn.Commit()
+}
+
+func (t *encryptedTxn) Discard() {
+	t.txn.Discard()
+}
+
+type encryptedIterator struct {
+	KVIterator
+	ring *encryption.KeyRing
+}
+
+func (i *encryptedIterator) Value() ([]byte, error) {
+	value, err := i.KVIterator.Value()
+	if err != nil {
+		return nil, err
+	}
+	key := i.KVIterator.Key()
+	if bytes.Equal(key, encryptionStateKey) {
+		return value, nil
+	}
+	return i.ring.Decrypt(key, value)
+}
@@ -0,0 +1,229 @@
+/*
+ *    Copyright 2018 Insolar
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ */
+
+package storage
+
+import (
+	"sort"
+	"strings"
+	"sync"
+)
+
+type memoryItem struct {
+	value   []byte
+	version uint64
+}
+
+type memoryStore struct {
+	lock    sync.RWMutex
+	items   map[string]memoryItem
+	sorted  []string
+	version uint64
+}
+
+// NewMemoryStore creates in-memory backend. All data is lost after Close.
+func NewMemoryStore() KVStore {
+	return &memoryStore{
+		items: map[string]memoryItem{},
+	}
+}
+
+func (s *memoryStore) NewTransaction(update bool) KVTxn {
+	return &memoryTxn{
+		store:  s,
+		update: update,
+		reads:  map[string]uint64{},
+		writes: map[string][]byte{},
+	}
+}
+
+func (s *memoryStore) Close() error {
+	s.lock.Lock()
+	s.items = map[string]memoryItem{}
+	s.sorted = nil
+	s.lock.Unlock()
+	return nil
+}
+
+// sortedKeys returns sorted keys snapshot. Should be called under lock.
+func (s *memoryStore) sortedKeys() []string {
+	if s.sorted == nil {
+		s.sorted = make([]string, 0, len(s.items))
+		for k := range s.items {
+			s.sorted = append(s.sorted, k)
+		}
+		sort.Strings(s.sorted)
+	}
+	return s.sorted
+}
+
+type memoryTxn struct {
+	store  *memoryStore
+	update bool
+	// reads stores versions of keys read by update transaction for conflict detection.
+	reads map[string]uint64
+	// writes stores pending changes. Nil value means deleted key.
+	writes map[string][]byte
+}
+
+func (t *memoryTxn) Get(key []byte) ([]byte, error) {
+	k := string(key)
+	if v, ok := t.writes[k]; ok {
+		if v == nil {
+			return nil, ErrNotFound
+		}
+		return copyBytes(v), nil
+	}
+
+	t.store.lock.RLock()
+	item, ok := t.store.items[k]
+	t.store.lock.RUnlock()
+	if t.update {
+		t.reads[k] = item.version
+	}
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return copyBytes(item.value), nil
+}
+
+func (t *memoryTxn) Set(key, value []byte) error {
+	if !t.update {
+		return ErrReadOnlyTxn
+	}
+	if value == nil {
+		value = []byte{}
+	}
+	t.writes[string(key)] = copyBytes(value)
+	return nil
+}
+
+func (t *memoryTxn) Delete(key []byte) error {
+	if !t.update {
+		return ErrReadOnlyTxn
+	}
+	t.writes[string(key)] = nil
+	return nil
+}
+
+func (t *memoryTxn) NewIterator() KVIterator {
+	t.store.lock.RLock()
+	defer t.store.lock.RUnlock()
+	keys := t.store.sortedKeys()
+
+	if len(t.writes) > 0 {
+		merged := make([]string, 0, len(keys)+len(t.writes))
+		for _, k := range keys {
+			if _, ok := t.writes[k]; !ok {
+				merged = append(merged, k)
+			}
+		}
+		for k, v := range t.writes {
+			if v != nil {
+				merged = append(merged, k)
+			}
+		}
+		sort.Strings(merged)
+		keys = merged
+	}
+
+	// Values are taken with keys, so commits made during iteration are not visible like in Badger backend. Stored
+	// values are never modified in place, so the snapshot shares them.
+	values := make([][]byte, len(keys))
+	for i, k := range keys {
+		if v, ok := t.writes[k]; ok {
+			values[i] = v
+			continue
+		}
+		values[i] = t.store.items[k].value
+	}
+	return &memoryIterator{keys: keys, values: values}
+}
+
+func (t *memoryTxn) Commit() error {
+	if len(t.writes) == 0 {
+		return nil
+	}
+	if !t.update {
+		return ErrReadOnlyTxn
+	}
+
+	t.store.lock.Lock()
+	defer t.store.lock.Unlock()
+
+	for k, version := range t.reads {
+		if t.store.items[k].version != version {
+			return ErrConflict
+		}
+	}
+	for k, v := range t.writes {
+		if v == nil {
+			delete(t.store.items, k)
+			continue
+		}
+		t.store.version++
+		t.store.items[k] = memoryItem{value: v, version: t.store.version}
+	}
+	t.store.sorted = nil
+	t.writes = map[string][]byte{}
+	return nil
+}
+
+func (t *memoryTxn) Discard() {
+	t.reads = map[string]uint64{}
+	t.writes = map[string][]byte{}
+}
+
+// memoryIterator iterates over keys and values snapshot taken on iterator creation.
+type memoryIterator struct {
+	keys   []string
+	values [][]byte
+	pos    int
+}
+
+func (i *memoryIterator) Seek(key []byte) {
+	i.pos = sort.SearchStrings(i.keys, string(key))
+}
+
+func (i *memoryIterator) ValidForPrefix(prefix []byte) bool {
+	if i.pos >= len(i.keys) {
+		return false
+	}
+	return strings.HasPrefix(i.keys[i.pos], string(prefix))
+}
+
+func (i *memoryIterator) Next() {
+	i.pos++
+}
+
+func (i *memoryIterator) Key() []byte {
+	return []byte(i.keys[i.pos])
+}
+
+func (i *memoryIterator) Value() ([]byte, error) {
+	return copyBytes(i.values[i.pos]), nil
+}
+
+func (i *memoryIterator) Close() {
+	i.keys = nil
+	i.values = nil
+}
+
+func copyBytes(b []byte) []byte {
+	c := make([]byte, len(b))
+	copy(c, b)
+	return c
+}
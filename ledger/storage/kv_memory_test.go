@@ -0,0 +1,188 @@
+/*
+ *    Copyright 2018 Insolar
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ */
+
+package storage_test
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/dgraph-io/badger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/insolar/insolar/core"
+	"github.com/insolar/insolar/instrumentation/inslogger"
+	"github.com/insolar/insolar/ledger/record"
+	"github.com/insolar/insolar/ledger/storage"
+	"github.com/insolar/insolar/ledger/storage/storagetest"
+)
+
+func TestMemoryStore_GetSetDelete(t *testing.T) {
+	t.Parallel()
+	store := storage.NewMemoryStore()
+	defer store.Close()
+
+	rtx := store.NewTransaction(false)
+	err := rtx.Set([]byte{1}, []byte{1})
+	assert.Equal(t, storage.ErrReadOnlyTxn, err)
+	_, err = rtx.Get([]byte{1})
+	assert.Equal(t, storage.ErrNotFound, err)
+	rtx.Discard()
+
+	tx := store.NewTransaction(true)
+	require.NoError(t, tx.Set([]byte{1}, []byte{42}))
+	v, err := tx.Get([]byte{1})
+	require.NoError(t, err)
+	assert.Equal(t, []byte{42}, v)
+	require.NoError(t, tx.Commit())
+	tx.Discard()
+
+	tx = store.NewTransaction(true)
+	require.NoError(t, tx.Delete([]byte{1}))
+	_, err = tx.Get([]byte{1})
+	assert.Equal(t, storage.ErrNotFound, err)
+	require.NoError(t, tx.Commit())
+	tx.Discard()
+
+	rtx = store.NewTransaction(false)
+	defer rtx.Discard()
+	_, err = rtx.Get([]byte{1})
+	assert.Equal(t, storage.ErrNotFound, err)
+}
+
+func TestMemoryStore_Conflict(t *testing.T) {
+	t.Parallel()
+	store := storage.NewMemoryStore()
+	defer store.Close()
+
+	tx1 := store.NewTransaction(true)
+	tx2 := store.NewTransaction(true)
+	defer tx1.Discard()
+	defer tx2.Discard()
+
+	_, err := tx1.Get([]byte{1})
+	assert.Equal(t, storage.ErrNotFound, err)
+	require.NoError(t, tx1.Set([]byte{1}, []byte{1}))
+
+	require.NoError(t, tx2.Set([]byte{1}, []byte{2}))
+	require.NoError(t, tx2.Commit())
+
+	assert.Equal(t, storage.ErrConflict, tx1.Commit())
+}
+
+func TestMemoryStore_IteratePrefix(t *testing.T) {
+	t.Parallel()
+	store := storage.NewMemoryStore()
+	defer store.Close()
+
+	tx := store.NewTransaction(true)
+	defer tx.Discard()
+	for _, k := range [][]byte{{2, 3}, {1, 2}, {2, 1}, {3, 1}, {2, 2}} {
+		require.NoError(t, tx.Set(k, k))
+	}
+	require.NoError(t, tx.Commit())
+
+	tx = store.NewTransaction(true)
+	defer tx.Discard()
+	require.NoError(t, tx.Delete([]byte{2, 2}))
+	require.NoError(t, tx.Set([]byte{2, 0}, []byte{2, 0}))
+
+	var keys [][]byte
+	it := tx.NewIterator()
+	for it.Seek([]byte{2}); it.ValidForPrefix([]byte{2}); it.Next() {
+		v, err := it.Value()
+		require.NoError(t, err)
+		assert.Equal(t, it.Key(), v)
+		keys = append(keys, it.Key())
+	}
+	it.Close()
+	assert.Equal(t, [][]byte{{2, 0}, {2, 1}, {2, 3}}, keys)
+}
+
+func TestKVStore_IteratorSnapshot(t *testing.T) {
+	t.Parallel()
+	dir, err := ioutil.TempDir("", "kv-snapshot")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	opts := badger.DefaultOptions
+	opts.Dir = dir
+	opts.ValueDir = dir
+	badgerStore, err := storage.NewBadgerStore(opts)
+	require.NoError(t, err)
+
+	for name, store := range map[string]storage.KVStore{
+		"memory": storage.NewMemoryStore(),
+		"badger": badgerStore,
+	} {
+		store := store
+		t.Run(name, func(t *testing.T) {
+			defer store.Close()
+
+			tx := store.NewTransaction(true)
+			require.NoError(t, tx.Set([]byte{1, 1}, []byte{1}))
+			require.NoError(t, tx.Set([]byte{1, 2}, []byte{1}))
+			require.NoError(t, tx.Commit())
+			tx.Discard()
+
+			rtx := store.NewTransaction(false)
+			defer rtx.Discard()
+			it := rtx.NewIterator()
+			defer it.Close()
+
+			// Written during iteration.
+			tx = store.NewTransaction(true)
+			require.NoError(t, tx.Set([]byte{1, 2}, []byte{2}))
+			require.NoError(t, tx.Set([]byte{1, 3}, []byte{2}))
+			require.NoError(t, tx.Commit())
+			tx.Discard()
+
+			var values [][]byte
+			for it.Seek([]byte{1}); it.ValidForPrefix([]byte{1}); it.Next() {
+				v, err := it.Value()
+				require.NoError(t, err)
+				values = append(values, v)
+			}
+			assert.Equal(t, [][]byte{{1}, {1}}, values)
+		})
+	}
+}
+
+func TestDB_InMemory(t *testing.T) {
+	t.Parallel()
+	ctx := inslogger.TestContext(t)
+	db, cleaner := storagetest.TmpDB(ctx, t, storagetest.InMemory())
+	defer cleaner()
+
+	assert.Nil(t, db.GetBadgerDB())
+	assert.NotNil(t, db.GenesisRef())
+
+	pulse := core.PulseNumber(core.FirstPulseNumber + 10)
+	rec := &record.CallRequest{}
+	id, err := db.SetRecord(ctx, pulse, rec)
+	require.NoError(t, err)
+	_, err = db.SetRecord(ctx, pulse, rec)
+	assert.Equal(t, storage.ErrOverride, err)
+
+	var ids []core.RecordID
+	err = db.IterateRecords(ctx, pulse, func(id core.RecordID, rec record.Record) error {
+		ids = append(ids, id)
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []core.RecordID{*id}, ids)
+}
@@ -0,0 +1,235 @@
+/*
+ *    Copyright 2018 Insolar
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ */
+
+package storage
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+
+	"github.com/pkg/errors"
+	"github.com/ugorji/go/codec"
+
+	"github.com/insolar/insolar/core"
+	"github.com/insolar/insolar/platformpolicy"
+)
+
+// nodeHistoryCacheSize is a number of the latest pulses which active nodes are cached in memory.
+const nodeHistoryCacheSize = 100
+
+// historyNode is a core.Node implementation for nodes restored from node history.
+type historyNode struct {
+	NodeID              core.RecordRef
+	NodeShortID         core.ShortNodeID
+	NodeRoles           []core.NodeRole
+	NodePublicKey       []byte
+	NodePulseNum        core.PulseNumber
+	NodePhysicalAddress string
+	NodeVersion         string
+
+	publicKey crypto.PublicKey
+}
+
+func (n *historyNode) ID() core.RecordRef {
+	return n.NodeID
+}
+
+func (n *historyNode) ShortID() core.ShortNodeID {
+	return n.NodeShortID
+}
+
+func (n *historyNode) Pulse() core.PulseNumber {
+	return n.NodePulseNum
+}
+
+func (n *historyNode) Roles() []core.NodeRole {
+	return n.NodeRoles
+}
+
+func (n *historyNode) Role() core.NodeRole {
+	if len(n.NodeRoles) == 0 {
+		return core.RoleUnknown
+	}
+	return n.NodeRoles[0]
+}
+
+func (n *historyNode) PublicKey() crypto.PublicKey {
+	return n.publicKey
+}
+
+func (n *historyNode) PhysicalAddress() string {
+	return n.NodePhysicalAddress
+}
+
+func (n *historyNode) Version() string {
+	return n.NodeVersion
+}
+
+func encodeNodes(nodes []core.Node) ([]byte, error) {
+	kp := platformpolicy.NewKeyProcessor()
+	hnodes := make([]historyNode, 0, len(nodes))
+	for _, n := range nodes {
+		hn := historyNode{
+			NodeID:              n.ID(),
+			NodeShortID:         n.ShortID(),
+			NodeRoles:           n.Roles(),
+			NodePulseNum:        n.Pulse(),
+			NodePhysicalAddress: n.PhysicalAddress(),
+			NodeVersion:         n.Version(),
+		}
+		if n.PublicKey() != nil {
+			key, err := kp.ExportPublicKey(n.PublicKey())
+			if err != nil {
+				return nil, errors.Wrap(err, "failed to export node public key")
+			}
+			hn.NodePublicKey = key
+		}
+		hnodes = append(hnodes, hn)
+	}
+
+	var buf bytes.Buffer
+	enc := codec.NewEncoder(&buf, &codec.CborHandle{})
+	err := enc.Encode(hnodes)
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func decodeNodes(buf []byte) ([]core.Node, error) {
+	var hnodes []historyNode
+	dec := codec.NewDecoder(bytes.NewReader(buf), &codec.CborHandle{})
+	err := dec.Decode(&hnodes)
+	if err != nil {
+		return nil, err
+	}
+
+	kp := platformpolicy.NewKeyProcessor()
+	nodes := make([]core.Node, 0, len(hnodes))
+	for i := range hnodes {
+		hn := &hnodes[i]
+		if len(hn.NodePublicKey) > 0 {
+			hn.publicKey, err = kp.ImportPublicKey(hn.NodePublicKey)
+			if err != nil {
+				return nil, errors.Wrap(err, "failed to import node public key")
+			}
+		}
+		nodes = append(nodes, hn)
+	}
+	return nodes, nil
+}
+
+// SetActiveNodes saves active nodes for pulse.
+//
+// Only active nodes of the latest nodeHistoryDepth pulses are kept, older entries are removed.
+func (db *DB) SetActiveNodes(pulse core.PulseNumber, nodes []core.Node) error {
+	ctx := context.Background()
+	db.nodeHistoryLock.Lock()
+	defer db.nodeHistoryLock.Unlock()
+
+	k := prefixkey(scopeIDNodes, pulse.Bytes())
+	_, err := db.get(ctx, k)
+	if err == nil {
+		return errors.New("node history override is forbidden")
+	}
+	if err != ErrNotFound {
+		return err
+	}
+
+	encoded, err := encodeNodes(nodes)
+	if err != nil {
+		return err
+	}
+
+	var stale [][]byte
+	if db.nodeHistoryDepth > 0 {
+		var pulses [][]byte
+		err = db.iterate(ctx, []byte{scopeIDNodes}, func(k, v []byte) error {
+			pulses = append(pulses, append([]byte(nil), k...))
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		// The new entry is counted too.
+		if extra := len(pulses) + 1 - db.nodeHistoryDepth; extra > 0 {
+			stale = pulses[:extra]
+		}
+	}
+
+	err = db.Update(ctx, func(tx *TransactionManager) error {
+		for _, pn := range stale {
+			if err := tx.remove(ctx, prefixkey(scopeIDNodes, pn)); err != nil {
+				return err
+			}
+		}
+		return tx.set(ctx, k, encoded)
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, pn := range stale {
+		delete(db.nodeHistory, core.NewPulseNumber(pn[:core.PulseNumberSize]))
+	}
+	db.cacheActiveNodes(pulse, nodes)
+	return nil
+}
+
+// GetActiveNodes return active nodes for specified pulse.
+//
+// Returns ErrNotFound if there are no nodes for the pulse (e.g. they were removed from history).
+func (db *DB) GetActiveNodes(pulse core.PulseNumber) ([]core.Node, error) {
+	db.nodeHistoryLock.Lock()
+	defer db.nodeHistoryLock.Unlock()
+
+	if nodes, ok := db.nodeHistory[pulse]; ok {
+		return nodes, nil
+	}
+
+	buf, err := db.get(context.Background(), prefixkey(scopeIDNodes, pulse.Bytes()))
+	if err != nil {
+		return nil, err
+	}
+	nodes, err := decodeNodes(buf)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to decode active nodes")
+	}
+	db.cacheActiveNodes(pulse, nodes)
+	return nodes, nil
+}
+
+// cacheActiveNodes saves active nodes in memory cache. Nodes of the oldest pulses are evicted if cache is full.
+//
+// Should be called under nodeHistoryLock.
+func (db *DB) cacheActiveNodes(pulse core.PulseNumber, nodes []core.Node) {
+	db.nodeHistory[pulse] = nodes
+	for len(db.nodeHistory) > nodeHistoryCacheSize {
+		oldest := pulse
+		for pn := range db.nodeHistory {
+			if pn < oldest {
+				oldest = pn
+			}
+		}
+		if oldest == pulse {
+			// Requested pulse is the oldest one, it's not cached.
+			delete(db.nodeHistory, pulse)
+			return
+		}
+		delete(db.nodeHistory, oldest)
+	}
+}
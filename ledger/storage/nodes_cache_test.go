@@ -0,0 +1,51 @@
+/*
+ *    Copyright 2018 Insolar
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ */
+
+package storage
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/insolar/insolar/configuration"
+	"github.com/insolar/insolar/core"
+	"github.com/insolar/insolar/network/nodenetwork"
+	"github.com/insolar/insolar/testutils"
+)
+
+func TestDB_ActiveNodes_CacheEviction(t *testing.T) {
+	db := NewDBWithStore(configuration.Ledger{}, NewMemoryStore())
+
+	nodes := []core.Node{
+		nodenetwork.NewNode(testutils.RandomRef(), []core.NodeRole{core.RoleVirtual}, nil, 0, "", ""),
+	}
+	total := 2 * nodeHistoryCacheSize
+	for i := 0; i < total; i++ {
+		err := db.SetActiveNodes(core.FirstPulseNumber+core.PulseNumber(i), nodes)
+		require.NoError(t, err)
+	}
+	assert.Len(t, db.nodeHistory, nodeHistoryCacheSize)
+	assert.NotContains(t, db.nodeHistory, core.PulseNumber(core.FirstPulseNumber))
+
+	// Evicted pulses are read from disk and don't displace the latest pulses.
+	restored, err := db.GetActiveNodes(core.FirstPulseNumber)
+	require.NoError(t, err)
+	assert.Equal(t, nodes[0].ID(), restored[0].ID())
+	assert.Len(t, db.nodeHistory, nodeHistoryCacheSize)
+	assert.Contains(t, db.nodeHistory, core.PulseNumber(core.FirstPulseNumber+total-1))
+}
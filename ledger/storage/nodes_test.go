@@ -0,0 +1,97 @@
+/*
+ *    Copyright 2018 Insolar
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ */
+
+package storage_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/insolar/insolar/configuration"
+	"github.com/insolar/insolar/core"
+	"github.com/insolar/insolar/ledger/storage"
+	"github.com/insolar/insolar/network/nodenetwork"
+	"github.com/insolar/insolar/platformpolicy"
+	"github.com/insolar/insolar/testutils"
+)
+
+func TestDB_ActiveNodes_Persisted(t *testing.T) {
+	store := storage.NewMemoryStore()
+	conf := configuration.Ledger{NodeHistoryDepth: 10}
+
+	key, err := platformpolicy.NewKeyProcessor().GeneratePrivateKey()
+	require.NoError(t, err)
+	pubKey := platformpolicy.NewKeyProcessor().ExtractPublicKey(key)
+
+	nodes := []core.Node{
+		nodenetwork.NewNode(testutils.RandomRef(), []core.NodeRole{core.RoleVirtual}, pubKey, 10, "127.0.0.1:1", "v1"),
+		nodenetwork.NewNode(testutils.RandomRef(), []core.NodeRole{core.RoleLightMaterial}, nil, 11, "127.0.0.1:2", "v1"),
+	}
+	db := storage.NewDBWithStore(conf, store)
+	err = db.SetActiveNodes(core.FirstPulseNumber, nodes)
+	require.NoError(t, err)
+	err = db.SetActiveNodes(core.FirstPulseNumber, nodes)
+	assert.Error(t, err)
+
+	// New instance on the same storage emulates node restart.
+	db = storage.NewDBWithStore(conf, store)
+	restored, err := db.GetActiveNodes(core.FirstPulseNumber)
+	require.NoError(t, err)
+	require.Len(t, restored, len(nodes))
+	for i, n := range nodes {
+		r := restored[i]
+		assert.Equal(t, n.ID(), r.ID())
+		assert.Equal(t, n.ShortID(), r.ShortID())
+		assert.Equal(t, n.Roles(), r.Roles())
+		assert.Equal(t, n.Role(), r.Role())
+		assert.Equal(t, n.Pulse(), r.Pulse())
+		assert.Equal(t, n.PhysicalAddress(), r.PhysicalAddress())
+		assert.Equal(t, n.Version(), r.Version())
+	}
+	assert.Equal(t, pubKey, restored[0].PublicKey())
+	assert.Nil(t, restored[1].PublicKey())
+
+	_, err = db.GetActiveNodes(core.FirstPulseNumber + 1)
+	assert.Equal(t, storage.ErrNotFound, err)
+}
+
+func TestDB_ActiveNodes_Retention(t *testing.T) {
+	store := storage.NewMemoryStore()
+	conf := configuration.Ledger{NodeHistoryDepth: 3}
+	db := storage.NewDBWithStore(conf, store)
+
+	nodes := []core.Node{
+		nodenetwork.NewNode(testutils.RandomRef(), []core.NodeRole{core.RoleVirtual}, nil, 0, "", ""),
+	}
+	for i := 0; i < 5; i++ {
+		err := db.SetActiveNodes(core.FirstPulseNumber+core.PulseNumber(i), nodes)
+		require.NoError(t, err)
+	}
+
+	for _, db := range []*storage.DB{db, storage.NewDBWithStore(conf, store)} {
+		for i := 0; i < 2; i++ {
+			_, err := db.GetActiveNodes(core.FirstPulseNumber + core.PulseNumber(i))
+			assert.Equal(t, storage.ErrNotFound, err)
+		}
+		for i := 2; i < 5; i++ {
+			restored, err := db.GetActiveNodes(core.FirstPulseNumber + core.PulseNumber(i))
+			require.NoError(t, err)
+			assert.Equal(t, nodes[0].ID(), restored[0].ID())
+		}
+	}
+}
@@ -0,0 +1,169 @@
+/*
+ *    Copyright 2018 Insolar
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ */
+
+package storage
+
+import (
+	"bytes"
+	"context"
+	"errors"
+
+	"github.com/ugorji/go/codec"
+
+	"github.com/insolar/insolar/core"
+)
+
+// prototypeEntry is a value of objects by prototype index.
+type prototypeEntry struct {
+	Head        core.RecordRef
+	Parent      core.RecordRef
+	Activated   core.PulseNumber
+	Deactivated core.PulseNumber
+}
+
+// activeOn checks if object is active on the pulse.
+func (e *prototypeEntry) activeOn(pulse core.PulseNumber) bool {
+	return e.Activated <= pulse && (e.Deactivated == 0 || e.Deactivated > pulse)
+}
+
+func prototypePrefix(prototype *core.RecordRef) []byte {
+	return bytes.Join([][]byte{{scopeIDPrototype}, prototype.Record()[:]}, nil)
+}
+
+func prototypeKey(prototype *core.RecordRef, head *core.RecordRef) []byte {
+	return bytes.Join([][]byte{prototypePrefix(prototype), head.Record()[:]}, nil)
+}
+
+func encodePrototypeEntry(entry *prototypeEntry) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := codec.NewEncoder(&buf, &codec.CborHandle{})
+	if err := enc.Encode(entry); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func decodePrototypeEntry(buf []byte) (*prototypeEntry, error) {
+	var entry prototypeEntry
+	dec := codec.NewDecoder(bytes.NewReader(buf), &codec.CborHandle{})
+	if err := dec.Decode(&entry); err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
+
+// AddObjectByPrototype adds activated object to index of objects by prototype.
+//
+// Active entry of the object is kept as is, so its activation pulse is not moved forward.
+func (m *TransactionManager) AddObjectByPrototype(
+	ctx context.Context,
+	prototype, head, parent core.RecordRef,
+	pulse core.PulseNumber,
+) error {
+	k := prototypeKey(&prototype, &head)
+	buf, err := m.get(ctx, k)
+	if err == nil {
+		entry, err := decodePrototypeEntry(buf)
+		if err != nil {
+			return err
+		}
+		if entry.Deactivated == 0 {
+			return nil
+		}
+	} else if err != ErrNotFound {
+		return err
+	}
+
+	encoded, err := encodePrototypeEntry(&prototypeEntry{
+		Head:      head,
+		Parent:    parent,
+		Activated: pulse,
+	})
+	if err != nil {
+		return err
+	}
+	return m.set(ctx, k, encoded)
+}
+
+// DeactivateObjectByPrototype marks object as deactivated in index of objects by prototype.
+//
+// Returns ErrNotFound if object is not indexed with provided prototype.
+func (m *TransactionManager) DeactivateObjectByPrototype(
+	ctx context.Context,
+	prototype, head core.RecordRef,
+	pulse core.PulseNumber,
+) error {
+	k := prototypeKey(&prototype, &head)
+	buf, err := m.get(ctx, k)
+	if err != nil {
+		return err
+	}
+	entry, err := decodePrototypeEntry(buf)
+	if err != nil {
+		return err
+	}
+	entry.Deactivated = pulse
+	encoded, err := encodePrototypeEntry(entry)
+	if err != nil {
+		return err
+	}
+	return m.set(ctx, k, encoded)
+}
+
+// RemoveObjectByPrototype removes object from index of objects by prototype.
+// It is used when object activation is rejected, so the object never existed.
+func (m *TransactionManager) RemoveObjectByPrototype(ctx context.Context, prototype, head core.RecordRef) error {
+	return m.remove(ctx, prototypeKey(&prototype, &head))
+}
+
+// errPageIsFull stops index iteration.
+var errPageIsFull = errors.New("page is full")
+
+// GetObjectsByPrototype returns a page of objects from index of objects by prototype.
+//
+// Objects are ordered by head record id. Without pulse filter only currently active objects are returned.
+func (db *DB) GetObjectsByPrototype(ctx context.Context, query core.ObjectsQuery) (*core.ObjectsPage, error) {
+	page := core.ObjectsPage{}
+	err := db.iterate(ctx, prototypePrefix(&query.Prototype), func(k, v []byte) error {
+		if query.After != nil && bytes.Compare(k, query.After[:]) <= 0 {
+			return nil
+		}
+		entry, err := decodePrototypeEntry(v)
+		if err != nil {
+			return err
+		}
+		if query.Pulse != nil {
+			if !entry.activeOn(*query.Pulse) {
+				return nil
+			}
+		} else if entry.Deactivated != 0 {
+			return nil
+		}
+		if query.Parent != nil && entry.Parent != *query.Parent {
+			return nil
+		}
+		if query.Limit > 0 && len(page.Heads) >= query.Limit {
+			page.Next = page.Heads[len(page.Heads)-1].Record()
+			return errPageIsFull
+		}
+		page.Heads = append(page.Heads, entry.Head)
+		return nil
+	})
+	if err != nil && err != errPageIsFull {
+		return nil, err
+	}
+	return &page, nil
+}
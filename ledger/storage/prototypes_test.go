@@ -0,0 +1,91 @@
+/*
+ *    Copyright 2018 Insolar
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ */
+
+package storage_test
+
+import (
+	"bytes"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/insolar/insolar/core"
+	"github.com/insolar/insolar/instrumentation/inslogger"
+	"github.com/insolar/insolar/ledger/storage"
+	"github.com/insolar/insolar/ledger/storage/storagetest"
+	"github.com/insolar/insolar/testutils"
+)
+
+func TestDB_GetObjectsByPrototype(t *testing.T) {
+	t.Parallel()
+	ctx := inslogger.TestContext(t)
+	db, cleaner := storagetest.TmpDB(ctx, t)
+	defer cleaner()
+
+	prototype := testutils.RandomRef()
+	parent := testutils.RandomRef()
+	pulse := core.GenesisPulse.PulseNumber + 1
+
+	var heads []core.RecordRef
+	for i := 0; i < 5; i++ {
+		heads = append(heads, testutils.RandomRef())
+	}
+	sort.Slice(heads, func(i, j int) bool {
+		return bytes.Compare(heads[i].Record()[:], heads[j].Record()[:]) < 0
+	})
+
+	err := db.Update(ctx, func(tx *storage.TransactionManager) error {
+		for _, head := range heads {
+			if err := tx.AddObjectByPrototype(ctx, prototype, head, parent, pulse); err != nil {
+				return err
+			}
+		}
+		return tx.DeactivateObjectByPrototype(ctx, prototype, heads[1], pulse+1)
+	})
+	require.NoError(t, err)
+
+	err = db.Update(ctx, func(tx *storage.TransactionManager) error {
+		return tx.DeactivateObjectByPrototype(ctx, prototype, testutils.RandomRef(), pulse+1)
+	})
+	assert.Equal(t, storage.ErrNotFound, err)
+
+	page, err := db.GetObjectsByPrototype(ctx, core.ObjectsQuery{Prototype: prototype, Limit: 2})
+	require.NoError(t, err)
+	assert.Equal(t, []core.RecordRef{heads[0], heads[2]}, page.Heads)
+	require.NotNil(t, page.Next)
+	assert.Equal(t, *heads[2].Record(), *page.Next)
+
+	page, err = db.GetObjectsByPrototype(ctx, core.ObjectsQuery{Prototype: prototype, Limit: 2, After: page.Next})
+	require.NoError(t, err)
+	assert.Equal(t, []core.RecordRef{heads[3], heads[4]}, page.Heads)
+	assert.Nil(t, page.Next)
+
+	page, err = db.GetObjectsByPrototype(ctx, core.ObjectsQuery{Prototype: prototype, Pulse: &pulse})
+	require.NoError(t, err)
+	assert.Equal(t, heads, page.Heads)
+
+	before := pulse - 1
+	page, err = db.GetObjectsByPrototype(ctx, core.ObjectsQuery{Prototype: prototype, Pulse: &before})
+	require.NoError(t, err)
+	assert.Empty(t, page.Heads)
+
+	other := testutils.RandomRef()
+	page, err = db.GetObjectsByPrototype(ctx, core.ObjectsQuery{Prototype: prototype, Parent: &other})
+	require.NoError(t, err)
+	assert.Empty(t, page.Heads)
+}
@@ -0,0 +1,208 @@
+/*
+ *    Copyright 2018 Insolar
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ */
+
+package storage
+
+import (
+	"bytes"
+	"context"
+
+	"github.com/insolar/insolar/core"
+	"github.com/insolar/insolar/ledger/record"
+)
+
+// GetPrunedPulse returns pulse number before which records, blobs and local data are removed from storage.
+func (db *DB) GetPrunedPulse(ctx context.Context) (core.PulseNumber, error) {
+	buf, err := db.get(ctx, prefixkey(scopeIDSystem, []byte{sysPrunedPulse}))
+	if err != nil {
+		if err == ErrNotFound {
+			err = nil
+		}
+		return 0, err
+	}
+	return core.NewPulseNumber(buf), nil
+}
+
+// pruneBatchSize is a maximum number of keys removed in a single transaction.
+const pruneBatchSize = 1000
+
+// IsPruned checks if records, blobs and local data of provided pulse are removed from storage.
+//
+// Latest object states and their memory are kept in pruned pulses, so drops of pruned pulses can not be verified
+// against stored records.
+func (db *DB) IsPruned(ctx context.Context, pulse core.PulseNumber) (bool, error) {
+	pruned, err := db.GetPrunedPulse(ctx)
+	if err != nil {
+		return false, err
+	}
+	return pulse > core.FirstPulseNumber && pulse < pruned, nil
+}
+
+// Prune removes records, blobs and local data of all pulses before 'until'. Provided pulse should exist in storage.
+//
+// Data of genesis pulse is never removed. Latest states of objects and their memory are kept, so idle objects are
+// served without redirects. Pulse is marked as pruned before its data is removed, so interrupted call is continued
+// by the next one from the last marked pulse.
+func (db *DB) Prune(ctx context.Context, until core.PulseNumber) error {
+	pruned, err := db.GetPrunedPulse(ctx)
+	if err != nil {
+		return err
+	}
+	if until <= pruned {
+		return nil
+	}
+
+	current := core.PulseNumber(core.FirstPulseNumber)
+	if pruned > core.FirstPulseNumber {
+		// The last marked pulse could be pruned partially.
+		pulse, err := db.GetPulse(ctx, pruned)
+		if err != nil {
+			return err
+		}
+		current = pruned
+		if pulse.Prev != nil && *pulse.Prev > core.FirstPulseNumber {
+			current = *pulse.Prev
+		}
+	}
+	if current == core.FirstPulseNumber {
+		pulse, err := db.GetPulse(ctx, current)
+		if err != nil {
+			return err
+		}
+		if pulse.Next == nil {
+			return nil
+		}
+		current = *pulse.Next
+	}
+
+	for current < until {
+		pulse, err := db.GetPulse(ctx, current)
+		if err != nil {
+			return err
+		}
+		if pulse.Next == nil {
+			break
+		}
+		if err = db.setPrunedPulse(ctx, *pulse.Next); err != nil {
+			return err
+		}
+		if err = db.prunePulse(ctx, current); err != nil {
+			return err
+		}
+		current = *pulse.Next
+	}
+
+	return db.setPrunedPulse(ctx, until)
+}
+
+func (db *DB) setPrunedPulse(ctx context.Context, pulse core.PulseNumber) error {
+	return db.Update(ctx, func(tx *TransactionManager) error {
+		return tx.set(ctx, prefixkey(scopeIDSystem, []byte{sysPrunedPulse}), pulse.Bytes())
+	})
+}
+
+// latestStates returns keys of object states created in the pulse which are still latest and keys of their memory
+// blobs. Only objects marked with the pulse on index update are checked.
+func (db *DB) latestStates(ctx context.Context, pulse core.PulseNumber) (map[string]bool, error) {
+	keep := map[string]bool{}
+	var heads []core.RecordID
+	err := db.iterate(ctx, bytes.Join([][]byte{{scopeIDStateHead}, pulse.Bytes()}, nil), func(k, v []byte) error {
+		var head core.RecordID
+		copy(head[:], k)
+		heads = append(heads, head)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range heads {
+		idx, err := db.GetObjectIndex(ctx, &heads[i], false)
+		if err == ErrNotFound {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		for _, id := range []*core.RecordID{idx.LatestState, idx.LatestStateApproved} {
+			if id == nil || id.Pulse() != pulse {
+				continue
+			}
+			keep[string(prefixkey(scopeIDRecord, id[:]))] = true
+			rec, err := db.GetRecord(ctx, id)
+			if err == ErrNotFound {
+				continue
+			}
+			if err != nil {
+				return nil, err
+			}
+			if state, ok := rec.(record.ObjectState); ok && state.GetMemory() != nil {
+				keep[string(prefixkey(scopeIDBlob, state.GetMemory()[:]))] = true
+			}
+		}
+	}
+	return keep, nil
+}
+
+// prunePulse removes data of the pulse except latest states in transactions of pruneBatchSize keys.
+func (db *DB) prunePulse(ctx context.Context, pulse core.PulseNumber) error {
+	keep, err := db.latestStates(ctx, pulse)
+	if err != nil {
+		return err
+	}
+	var keys [][]byte
+	// State marks are removed too, states kept in pruned pulse are not pruned again.
+	for _, scope := range []byte{scopeIDRecord, scopeIDBlob, scopeIDLocal, scopeIDStateHead} {
+		prefix := bytes.Join([][]byte{{scope}, pulse.Bytes()}, nil)
+		err := db.iterate(ctx, prefix, func(k, v []byte) error {
+			key := bytes.Join([][]byte{prefix, k}, nil)
+			if !keep[string(key)] {
+				keys = append(keys, key)
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	for len(keys) > 0 {
+		batch := keys
+		if len(batch) > pruneBatchSize {
+			batch = batch[:pruneBatchSize]
+		}
+		keys = keys[len(batch):]
+
+		err := db.Update(ctx, func(tx *TransactionManager) error {
+			for _, k := range batch {
+				var err error
+				if k[0] == scopeIDBlob {
+					err = tx.removeBlob(ctx, k)
+				} else {
+					err = tx.remove(ctx, k)
+				}
+				if err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,197 @@
+/*
+ *    Copyright 2018 Insolar
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ */
+
+package storage_test
+
+import (
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/insolar/insolar/core"
+	"github.com/insolar/insolar/instrumentation/inslogger"
+	"github.com/insolar/insolar/ledger/index"
+	"github.com/insolar/insolar/ledger/record"
+	"github.com/insolar/insolar/ledger/storage"
+	"github.com/insolar/insolar/ledger/storage/storagetest"
+	"github.com/insolar/insolar/testutils"
+)
+
+func TestDB_Prune(t *testing.T) {
+	t.Parallel()
+	ctx := inslogger.TestContext(t)
+	db, cleaner := storagetest.TmpDB(ctx, t)
+	defer cleaner()
+
+	genesis := core.GenesisPulse.PulseNumber
+	var (
+		pulses  []core.PulseNumber
+		records []*core.RecordID
+		blobs   []*core.RecordID
+	)
+	for i := 1; i <= 3; i++ {
+		pn := genesis + core.PulseNumber(i)
+		err := db.AddPulse(ctx, core.Pulse{PulseNumber: pn})
+		require.NoError(t, err)
+		recID, err := db.SetRecord(ctx, pn, &record.ObjectActivateRecord{
+			SideEffectRecord: record.SideEffectRecord{Domain: testutils.RandomRef()},
+		})
+		require.NoError(t, err)
+		blobID, err := db.SetBlob(ctx, pn, []byte{byte(i)})
+		require.NoError(t, err)
+		err = db.SetLocalData(ctx, pn, []byte("key"), []byte{byte(i)})
+		require.NoError(t, err)
+
+		pulses = append(pulses, pn)
+		records = append(records, recID)
+		blobs = append(blobs, blobID)
+	}
+
+	err := db.Prune(ctx, pulses[2])
+	require.NoError(t, err)
+
+	pruned, err := db.GetPrunedPulse(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, pulses[2], pruned)
+
+	for i, pn := range pulses {
+		isPruned, err := db.IsPruned(ctx, pn)
+		require.NoError(t, err)
+
+		_, recErr := db.GetRecord(ctx, records[i])
+		_, blobErr := db.GetBlob(ctx, blobs[i])
+		_, localErr := db.GetLocalData(ctx, pn, []byte("key"))
+		if i < 2 {
+			assert.True(t, isPruned)
+			assert.Equal(t, storage.ErrNotFound, recErr)
+			assert.Equal(t, storage.ErrNotFound, blobErr)
+			assert.Equal(t, storage.ErrNotFound, localErr)
+		} else {
+			assert.False(t, isPruned)
+			assert.NoError(t, recErr)
+			assert.NoError(t, blobErr)
+			assert.NoError(t, localErr)
+		}
+	}
+
+	// genesis data is kept
+	_, err = db.GetRecord(ctx, db.GenesisRef().Record())
+	assert.NoError(t, err)
+	isPruned, err := db.IsPruned(ctx, genesis)
+	require.NoError(t, err)
+	assert.False(t, isPruned)
+
+	// repeated call continues from the last pruned pulse
+	err = db.Prune(ctx, pulses[1])
+	require.NoError(t, err)
+	err = db.Prune(ctx, pulses[2])
+	require.NoError(t, err)
+}
+
+func TestDB_Prune_KeepsLatestStates(t *testing.T) {
+	t.Parallel()
+	ctx := inslogger.TestContext(t)
+	db, cleaner := storagetest.TmpDB(ctx, t, storagetest.InMemory())
+	defer cleaner()
+
+	pulse1 := pulseDelta(1)
+	pulse2 := pulseDelta(2)
+	pulse3 := pulseDelta(3)
+
+	memory1, err := db.SetBlob(ctx, core.FirstPulseNumber, []byte{1})
+	require.NoError(t, err)
+	activateID, err := db.SetRecord(ctx, core.FirstPulseNumber, &record.ObjectActivateRecord{
+		SideEffectRecord:  record.SideEffectRecord{Domain: testutils.RandomRef()},
+		ObjectStateRecord: record.ObjectStateRecord{Memory: memory1},
+	})
+	require.NoError(t, err)
+	closePulse(ctx, t, db, pulse1)
+
+	memory2, err := db.SetBlob(ctx, pulse1, []byte{2})
+	require.NoError(t, err)
+	amendID, err := db.SetRecord(ctx, pulse1, &record.ObjectAmendRecord{
+		SideEffectRecord:  record.SideEffectRecord{Domain: testutils.RandomRef()},
+		ObjectStateRecord: record.ObjectStateRecord{Memory: memory2},
+		PrevState:         *activateID,
+	})
+	require.NoError(t, err)
+	idleID, err := db.SetRecord(ctx, pulse1, &record.ObjectActivateRecord{
+		SideEffectRecord: record.SideEffectRecord{Domain: testutils.RandomRef()},
+	})
+	require.NoError(t, err)
+	err = db.SetObjectIndex(ctx, idleID, &index.ObjectLifeline{LatestState: idleID, State: record.StateActivation})
+	require.NoError(t, err)
+	closePulse(ctx, t, db, pulse2)
+
+	memory3, err := db.SetBlob(ctx, pulse2, []byte{3})
+	require.NoError(t, err)
+	supersededID, err := db.SetRecord(ctx, pulse2, &record.ObjectAmendRecord{
+		SideEffectRecord:  record.SideEffectRecord{Domain: testutils.RandomRef()},
+		ObjectStateRecord: record.ObjectStateRecord{Memory: memory3},
+		PrevState:         *amendID,
+	})
+	require.NoError(t, err)
+	latestID, err := db.SetRecord(ctx, pulse2, &record.ObjectAmendRecord{
+		SideEffectRecord:  record.SideEffectRecord{Domain: testutils.RandomRef()},
+		ObjectStateRecord: record.ObjectStateRecord{Memory: memory3},
+		PrevState:         *supersededID,
+	})
+	require.NoError(t, err)
+	err = db.SetObjectIndex(ctx, activateID, &index.ObjectLifeline{LatestState: latestID, State: record.StateAmend})
+	require.NoError(t, err)
+	closePulse(ctx, t, db, pulse3)
+
+	err = db.Prune(ctx, pulse3)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name string
+		id   *core.RecordID
+		kept bool
+	}{
+		{"genesis state", activateID, true},
+		{"superseded state", amendID, false},
+		{"idle object state", idleID, true},
+		{"superseded state in the latest state pulse", supersededID, false},
+		{"latest state", latestID, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := db.GetRecord(ctx, tt.id)
+			if tt.kept {
+				assert.NoError(t, err)
+			} else {
+				assert.Equal(t, storage.ErrNotFound, err)
+			}
+		})
+	}
+	_, err = db.GetBlob(ctx, memory2)
+	assert.Equal(t, storage.ErrNotFound, err)
+	blob, err := db.GetBlob(ctx, memory3)
+	require.NoError(t, err)
+	assert.Equal(t, []byte{3}, blob)
+
+	// drops of pruned pulses are not verified against partially removed records
+	report, err := db.Verify(ctx)
+	require.NoError(t, err)
+	assert.True(t, report.OK(), "unexpected issues: %v", report.Issues)
+	assert.Equal(t, 2, report.Pruned)
+
+	_, err = db.GetRecordProof(ctx, latestID)
+	assert.Equal(t, storage.ErrPruned, errors.Cause(err))
+}
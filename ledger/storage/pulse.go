@@ -54,10 +54,13 @@ func (m *TransactionManager) GetPulse(ctx context.Context, num core.PulseNumber)
 	if err != nil {
 		return nil, err
 	}
+	return decodePulse(buf)
+}
 
+func decodePulse(buf []byte) (*Pulse, error) {
 	dec := codec.NewDecoder(bytes.NewReader(buf), &codec.CborHandle{})
 	var rec Pulse
-	err = dec.Decode(&rec)
+	err := dec.Decode(&rec)
 	if err != nil {
 		return nil, err
 	}
@@ -116,6 +119,34 @@ func (db *DB) GetPulse(ctx context.Context, num core.PulseNumber) (*Pulse, error
 	return pulse, nil
 }
 
+// FindPulse returns the first stored pulse number greater or equal to provided one.
+//
+// Returns ErrNotFound if there is no such pulse.
+func (db *DB) FindPulse(ctx context.Context, from core.PulseNumber) (core.PulseNumber, error) {
+	var (
+		found core.PulseNumber
+		ok    bool
+	)
+	err := kvView(db.store, func(txn KVTxn) error {
+		it := txn.NewIterator()
+		defer it.Close()
+
+		it.Seek(prefixkey(scopeIDPulse, from.Bytes()))
+		if it.ValidForPrefix([]byte{scopeIDPulse}) {
+			found = core.NewPulseNumber(it.Key()[1:])
+			ok = true
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	if !ok {
+		return 0, ErrNotFound
+	}
+	return found, nil
+}
+
 // GetLatestPulseNumber returns current pulse number.
 func (db *DB) GetLatestPulseNumber(ctx context.Context) (core.PulseNumber, error) {
 	tx := db.BeginTransaction(false)
@@ -0,0 +1,87 @@
+/*
+ *    Copyright 2018 Insolar
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ */
+
+package storage
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/insolar/insolar/instrumentation/inslogger"
+)
+
+const (
+	// reencryptBatchSize is a number of keys checked in one re-encryption transaction.
+	reencryptBatchSize = 1000
+	// reencryptRetryDelay is a pause before retrying of failed re-encryption.
+	reencryptRetryDelay = 5 * time.Second
+)
+
+// Reencrypt re-encrypts all stored values with active encryption key after key rotation.
+// Returns number of re-encrypted values.
+//
+// Does nothing if storage is not encrypted. Stops on DB close.
+func (db *DB) Reencrypt(ctx context.Context) (int, error) {
+	store, ok := db.store.(*encryptedStore)
+	if !ok {
+		return 0, nil
+	}
+
+	var (
+		from  []byte
+		total int
+	)
+	for {
+		select {
+		case <-db.stop:
+			return total, nil
+		default:
+		}
+
+		next, count, err := store.reencrypt(from, reencryptBatchSize)
+		if err != nil {
+			return total, err
+		}
+		total += count
+		if next == nil {
+			return total, nil
+		}
+		from = next
+	}
+}
+
+// reencryptInBackground runs Reencrypt until it succeeds or DB is closed.
+func (db *DB) reencryptInBackground(ctx context.Context) {
+	inslog := inslogger.FromContext(ctx)
+	for {
+		count, err := db.Reencrypt(ctx)
+		if err == nil {
+			if count > 0 {
+				inslog.Infof("storage re-encryption is finished, %v values re-encrypted", count)
+			}
+			return
+		}
+		inslog.Error(errors.Wrap(err, "storage re-encryption failed"))
+
+		select {
+		case <-db.stop:
+			return
+		case <-time.After(reencryptRetryDelay):
+		}
+	}
+}
@@ -17,18 +17,224 @@
 package storage
 
 import (
+	"bytes"
 	"context"
+	"sort"
+
+	"github.com/pkg/errors"
+	"github.com/ugorji/go/codec"
 
 	"github.com/insolar/insolar/core"
+	"github.com/insolar/insolar/ledger/jetdrop"
+	"github.com/insolar/insolar/ledger/record"
 )
 
-// SetReplicatedPulse saves last pulse succesfully replicated to 'heavy material' node.
+// HeavySyncState is a progress of replication to 'heavy material' node.
+// It is saved after every batch acknowledged by heavy node, so interrupted sync could be resumed.
+type HeavySyncState struct {
+	Begin core.PulseNumber
+	End   core.PulseNumber
+	Iter  ReplicaIterState
+}
+
+// SetReplicatedPulse saves last pulse succesfully replicated to 'heavy material' node
+// and drops saved replication progress.
 func (db *DB) SetReplicatedPulse(ctx context.Context, pulsenum core.PulseNumber) error {
 	return db.Update(ctx, func(tx *TransactionManager) error {
+		err := tx.remove(ctx, prefixkey(scopeIDSystem, []byte{sysHeavySyncState}))
+		if err != nil {
+			return err
+		}
 		return tx.set(ctx, prefixkey(scopeIDSystem, []byte{sysReplicatedPulse}), pulsenum.Bytes())
 	})
 }
 
+// SetHeavySyncState saves progress of replication to 'heavy material' node.
+func (db *DB) SetHeavySyncState(ctx context.Context, state *HeavySyncState) error {
+	var buf bytes.Buffer
+	enc := codec.NewEncoder(&buf, &codec.CborHandle{})
+	if err := enc.Encode(state); err != nil {
+		return err
+	}
+	return db.Update(ctx, func(tx *TransactionManager) error {
+		return tx.set(ctx, prefixkey(scopeIDSystem, []byte{sysHeavySyncState}), buf.Bytes())
+	})
+}
+
+// GetHeavySyncState returns saved progress of replication to 'heavy material' node.
+//
+// Returns ErrNotFound if there is no unfinished replication.
+func (db *DB) GetHeavySyncState(ctx context.Context) (*HeavySyncState, error) {
+	buf, err := db.get(ctx, prefixkey(scopeIDSystem, []byte{sysHeavySyncState}))
+	if err != nil {
+		return nil, err
+	}
+	var state HeavySyncState
+	dec := codec.NewDecoder(bytes.NewReader(buf), &codec.CborHandle{})
+	if err := dec.Decode(&state); err != nil {
+		return nil, err
+	}
+	return &state, nil
+}
+
+// DropHashes returns jet drop hashes by pulse for all jet drops in pulses range [begin:end).
+//
+// Light node sends them to heavy node before replication, so replicated drops are verified against hashes provided by
+// the sender rather than against the replicated data itself.
+func (db *DB) DropHashes(ctx context.Context, begin, end core.PulseNumber) (map[core.PulseNumber][]byte, error) {
+	hashes := map[core.PulseNumber][]byte{}
+	err := db.iterate(ctx, []byte{scopeIDJetDrop}, func(k, v []byte) error {
+		pulse := core.NewPulseNumber(k[:core.PulseNumberSize])
+		if pulse < begin || pulse >= end {
+			return nil
+		}
+		drop, err := jetdrop.Decode(v)
+		if err != nil {
+			return err
+		}
+		hashes[pulse] = drop.Hash
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return hashes, nil
+}
+
+// StoreReplica stores key/value pairs replicated from 'light material' node for pulses range [begin:end).
+//
+// Indexes (lifelines and objects by prototype) are accepted from any pulse before the end of the range, all other
+// keys should be in the range.
+// Blob values are blob contents, they are deduplicated on store.
+//
+// Record and blob ids are recalculated from replicated values and replicated jet drops are verified before anything is stored: drop hash should be equal to the hash provided by the
+// sender in checksums and to the hash calculated from records of the drop pulse. Records are replicated before drops,
+// so records stored by previous calls are taken into account.
+func (db *DB) StoreReplica(
+	ctx context.Context, begin, end core.PulseNumber, kvs []core.KV, checksums map[core.PulseNumber][]byte,
+) error {
+	for _, kv := range kvs {
+		if len(kv.K) < 1+core.PulseNumberSize {
+			return errors.Wrapf(ErrReplicaOutOfRange, "malformed key %v", bytes2hex(kv.K))
+		}
+		pulse := core.NewPulseNumber(kv.K[1 : 1+core.PulseNumberSize])
+		var ok bool
+		switch kv.K[0] {
+		case scopeIDLifeline, scopeIDPrototype:
+			ok = pulse < end
+		case scopeIDRecord, scopeIDBlob, scopeIDJetDrop, scopeIDJetTree:
+			ok = pulse >= begin && pulse < end
+		}
+		if !ok {
+			return errors.Wrapf(ErrReplicaOutOfRange, "key %v for range [%v:%v)", bytes2hex(kv.K), begin, end)
+		}
+		if err := db.checkReplicaValue(kv); err != nil {
+			return err
+		}
+	}
+	for _, kv := range kvs {
+		if kv.K[0] != scopeIDJetDrop {
+			continue
+		}
+		pulse := core.NewPulseNumber(kv.K[1 : 1+core.PulseNumberSize])
+		err := db.checkReplicaDrop(ctx, pulse, kv.V, kvs, checksums)
+		if err != nil {
+			return err
+		}
+	}
+
+	defer db.resetJetTrees()
+	return db.Update(ctx, func(tx *TransactionManager) error {
+		for _, kv := range kvs {
+			var err error
+			if kv.K[0] == scopeIDBlob {
+				err = tx.storeReplicaBlob(ctx, kv.K, kv.V)
+			} else {
+				err = tx.set(ctx, kv.K, kv.V)
+			}
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// checkReplicaValue checks that id of replicated record or blob is calculated from its value.
+func (db *DB) checkReplicaValue(kv core.KV) error {
+	var expected *core.RecordID
+	switch kv.K[0] {
+	case scopeIDRecord:
+		rec, err := decodeRecord(kv.V)
+		if err != nil {
+			return errors.Wrapf(ErrReplicaChecksum, "record %v: %v", bytes2hex(kv.K), err)
+		}
+		hasher := db.PlatformCryptographyScheme.ReferenceHasher()
+		if _, err = rec.WriteHashData(hasher); err != nil {
+			return err
+		}
+		expected = core.NewRecordID(core.NewPulseNumber(kv.K[1:1+core.PulseNumberSize]), hasher.Sum(nil))
+	case scopeIDBlob:
+		expected = record.CalculateIDForBlob(
+			db.PlatformCryptographyScheme, core.NewPulseNumber(kv.K[1:1+core.PulseNumberSize]), kv.V,
+		)
+	default:
+		return nil
+	}
+	if !bytes.Equal(kv.K[1:], expected[:]) {
+		return errors.Wrapf(ErrReplicaChecksum, "value of %v", bytes2hex(kv.K))
+	}
+	return nil
+}
+
+// checkReplicaDrop verifies replicated jet drop against checksums and records from storage and replicated batch.
+func (db *DB) checkReplicaDrop(
+	ctx context.Context, pulse core.PulseNumber, buf []byte, kvs []core.KV, checksums map[core.PulseNumber][]byte,
+) error {
+	drop, err := jetdrop.Decode(buf)
+	if err != nil {
+		return err
+	}
+	if drop.Pulse != pulse {
+		return errors.Wrapf(ErrReplicaChecksum, "jet drop of pulse %v stored with pulse %v", drop.Pulse, pulse)
+	}
+	checksum, ok := checksums[pulse]
+	if !ok || !bytes.Equal(drop.Hash, checksum) {
+		return errors.Wrapf(ErrReplicaChecksum, "jet drop hash for pulse %v", drop.Pulse)
+	}
+
+	records, err := db.dropRecords(ctx, drop.Pulse)
+	if err != nil {
+		return err
+	}
+	prefix := bytes.Join([][]byte{{scopeIDRecord}, drop.Pulse.Bytes()}, nil)
+	for _, kv := range kvs {
+		if bytes.HasPrefix(kv.K, prefix) {
+			records = append(records, kv.K[len(prefix):])
+		}
+	}
+	sort.Slice(records, func(i, j int) bool {
+		return bytes.Compare(records[i], records[j]) < 0
+	})
+	unique := records[:0]
+	for i, rec := range records {
+		if i == 0 || !bytes.Equal(rec, records[i-1]) {
+			unique = append(unique, rec)
+		}
+	}
+
+	hash, err := jetdrop.VersionedHash(
+		db.PlatformCryptographyScheme.ReferenceHasher(), drop.HashVersion, drop.PrevHash, unique,
+	)
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(hash, checksum) {
+		return errors.Wrapf(ErrReplicaChecksum, "records hash for pulse %v", drop.Pulse)
+	}
+	return nil
+}
+
 // GetReplicatedPulse returns last pulse succesfully replicated to 'heavy material' node.
 func (db *DB) GetReplicatedPulse(ctx context.Context) (core.PulseNumber, error) {
 	buf, err := db.get(ctx, prefixkey(scopeIDSystem, []byte{sysReplicatedPulse}))
@@ -21,8 +21,6 @@ import (
 	"context"
 	"errors"
 
-	"github.com/dgraph-io/badger"
-
 	"github.com/insolar/insolar/core"
 )
 
@@ -33,11 +31,12 @@ type iterstate struct {
 	end    []byte
 }
 
-// ReplicaIter provides partial iterator over BadgerDB key/value pairs
+// ReplicaIter provides partial iterator over storage key/value pairs
 // required for replication to Heavy Material node in provided pulses range.
 //
-// "Required KV pairs" are all keys with namespace 'scopeIDRecord' (TODO: 'add scopeIDBlob')
-// in provided pulses range and all indexes from zero pulse to the end of provided range.
+// "Required KV pairs" are all keys with namespaces 'scopeIDRecord', 'scopeIDBlob' (with blob content as value),
+// 'scopeIDJetDrop' and 'scopeIDJetTree' in provided pulses range and all indexes (lifelines and objects by prototype)
+// from zero pulse to the end of provided range.
 //
 // "Partial" means it fetches data in chunks of the specified size.
 // After a chunk has been fetched, an iterator saves current position.
@@ -85,7 +84,9 @@ func NewReplicaIter(
 			newit(scopeIDRecord, start, end),
 			newit(scopeIDBlob, start, end),
 			newit(scopeIDLifeline, core.FirstPulseNumber, end),
+			newit(scopeIDPrototype, core.FirstPulseNumber, end),
 			newit(scopeIDJetDrop, start, end),
+			newit(scopeIDJetTree, start, end),
 		},
 	}
 }
@@ -96,7 +97,7 @@ func (r *ReplicaIter) NextRecords() ([]core.KV, error) {
 		return nil, ErrReplicatorDone
 	}
 	fc := &fetchchunk{
-		db:    r.db.db,
+		store: r.db.store,
 		limit: r.limitBytes,
 	}
 	for _, is := range r.istates {
@@ -121,6 +122,48 @@ func (r *ReplicaIter) LastPulse() core.PulseNumber {
 	return r.lastpulse
 }
 
+// ReplicaIterState is a position of ReplicaIter.
+type ReplicaIterState struct {
+	// Positions holds next key of every internal iterator, nil if iterator is done.
+	Positions [][]byte
+	LastPulse core.PulseNumber
+}
+
+// State returns current position of the iterator.
+func (r *ReplicaIter) State() ReplicaIterState {
+	state := ReplicaIterState{
+		Positions: make([][]byte, 0, len(r.istates)),
+		LastPulse: r.lastpulse,
+	}
+	for _, is := range r.istates {
+		state.Positions = append(state.Positions, is.start)
+	}
+	return state
+}
+
+// Restore moves the iterator to the position returned by State method.
+func (r *ReplicaIter) Restore(state ReplicaIterState) error {
+	if len(state.Positions) != len(r.istates) {
+		return ErrReplicaIterState
+	}
+	for i, is := range r.istates {
+		start := state.Positions[i]
+		if len(start) == 0 {
+			is.start = nil
+			continue
+		}
+		if !bytes.HasPrefix(start, is.prefix) {
+			return ErrReplicaIterState
+		}
+		is.start = start
+	}
+	r.lastpulse = state.LastPulse
+	return nil
+}
+
+// ErrReplicaIterState is returned by Restore method if provided state does not fit the iterator.
+var ErrReplicaIterState = errors.New("invalid replica iterator state")
+
 // ErrReplicatorDone is returned by an Replicator NextRecords method when the iteration is complete.
 var ErrReplicatorDone = errors.New("no more items in iterator")
 
@@ -134,7 +177,7 @@ func (r *ReplicaIter) isDone() bool {
 }
 
 type fetchchunk struct {
-	db      *badger.DB
+	store   KVStore
 	records []core.KV
 	size    int
 	limit   int
@@ -152,21 +195,17 @@ func (fc *fetchchunk) fetch(
 
 	var nextstart []byte
 	var lastpulse core.PulseNumber
-	err := fc.db.View(func(txn *badger.Txn) error {
-		it := txn.NewIterator(badger.DefaultIteratorOptions)
+	err := kvView(fc.store, func(txn KVTxn) error {
+		it := txn.NewIterator()
 		defer it.Close()
 
 		for it.Seek(start); it.ValidForPrefix(prefix); it.Next() {
-			item := it.Item()
-			if item == nil {
-				break
-			}
+			key := it.Key()
 			// key prefix < end
-			if bytes.Compare(item.Key()[:len(end)], end) != -1 {
+			if bytes.Compare(key[:len(end)], end) != -1 {
 				break
 			}
 
-			key := item.KeyCopy(nil)
 			if fc.size > fc.limit {
 				nextstart = key
 				// inslogger.FromContext(ctx).Warnf("size > r.limit: %v > %v (nextstart=%v)",
@@ -177,10 +216,17 @@ func (fc *fetchchunk) fetch(
 			lastpulse = core.NewPulseNumber(key[1 : 1+core.PulseNumberSize])
 			// fmt.Printf("key: %v (pulse=%v)\n", hex.EncodeToString(key), lastpulse)
 
-			value, err := it.Item().ValueCopy(nil)
+			value, err := it.Value()
 			if err != nil {
 				return err
 			}
+			// blobs are replicated with content
+			if prefix[0] == scopeIDBlob {
+				value, err = readBlob(txn.Get, key, value)
+				if err != nil {
+					return err
+				}
+			}
 			fc.records = append(fc.records, core.KV{K: key, V: value})
 			fc.size += len(key) + len(value)
 		}
@@ -205,6 +205,79 @@ func Test_ReplicaIter(t *testing.T) {
 	}
 }
 
+func Test_ReplicaIter_Restore(t *testing.T) {
+	t.Parallel()
+	ctx := inslogger.TestContext(t)
+	db, cleaner := storagetest.TmpDB(ctx, t, storagetest.DisableBootstrap())
+	defer cleaner()
+
+	pulsescount := 3
+	for i := 0; i < pulsescount; i++ {
+		addRecords(ctx, t, db, pulseDelta(i))
+		setDrop(ctx, t, db, pulseDelta(i))
+	}
+	start, end := pulseDelta(0), pulseDelta(pulsescount)
+	maxsize := 100
+
+	var expected []key
+	replicator := storage.NewReplicaIter(ctx, db, start, end, maxsize)
+	for {
+		recs, err := replicator.NextRecords()
+		if err == storage.ErrReplicatorDone {
+			break
+		}
+		require.NoError(t, err)
+		for _, rec := range recs {
+			expected = append(expected, rec.K)
+		}
+	}
+
+	// interrupt after the first chunk and continue with a new iterator from saved state
+	var got []key
+	interrupted := storage.NewReplicaIter(ctx, db, start, end, maxsize)
+	recs, err := interrupted.NextRecords()
+	require.NoError(t, err)
+	for _, rec := range recs {
+		got = append(got, rec.K)
+	}
+	err = db.SetHeavySyncState(ctx, &storage.HeavySyncState{
+		Begin: start,
+		End:   end,
+		Iter:  interrupted.State(),
+	})
+	require.NoError(t, err)
+
+	state, err := db.GetHeavySyncState(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, start, state.Begin)
+	assert.Equal(t, end, state.End)
+
+	resumed := storage.NewReplicaIter(ctx, db, start, end, maxsize)
+	err = resumed.Restore(state.Iter)
+	require.NoError(t, err)
+	for {
+		recs, err := resumed.NextRecords()
+		if err == storage.ErrReplicatorDone {
+			break
+		}
+		require.NoError(t, err)
+		for _, rec := range recs {
+			got = append(got, rec.K)
+		}
+	}
+	assert.Equal(t, expected, got)
+	assert.Equal(t, replicator.LastPulse(), resumed.LastPulse())
+
+	err = resumed.Restore(storage.ReplicaIterState{})
+	assert.Equal(t, storage.ErrReplicaIterState, err)
+
+	// replicated pulse drops saved state
+	err = db.SetReplicatedPulse(ctx, end-1)
+	require.NoError(t, err)
+	_, err = db.GetHeavySyncState(ctx)
+	assert.Equal(t, storage.ErrNotFound, err)
+}
+
 func setDrop(
 	ctx context.Context,
 	t *testing.T,
@@ -0,0 +1,305 @@
+/*
+ *    Copyright 2018 Insolar
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ */
+
+package storage
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"io"
+
+	"github.com/pkg/errors"
+
+	"github.com/insolar/insolar/core"
+	"github.com/insolar/insolar/instrumentation/inslogger"
+)
+
+const (
+	snapshotMagic   = "INSLSNAP"
+	snapshotVersion = uint32(2)
+
+	// snapshotEncrypted flag means that snapshot values are encrypted as they are in storage.
+	snapshotEncrypted byte = 1
+
+	snapshotEnd   byte = 0
+	snapshotEntry byte = 1
+
+	// snapshotMaxChunk limits size of key or value read from snapshot.
+	snapshotMaxChunk = 1 << 30
+	// restoreBatchSize is number of key/value pairs written in one transaction on restore.
+	restoreBatchSize = 1000
+)
+
+// snapshotScopes defines key scopes saved in snapshot.
+var snapshotScopes = []byte{
+	scopeIDSystem,
+	scopeIDPulse,
+	scopeIDRecord,
+	scopeIDBlob,
+	scopeIDBlobContent,
+	scopeIDLifeline,
+	scopeIDJetDrop,
+	scopeIDNodes,
+	scopeIDJetTree,
+	scopeIDPrototype,
+	scopeIDStateHead,
+}
+
+// Snapshot writes records, blobs, lifelines, objects by prototype index, pulses of object states, pulses, jet drops,
+// jet trees and node history into w.
+//
+// Snapshot format is a header (magic string, format version and flags) followed by key/value entries,
+// entries counter and the checksum of all previous data. Returns number of written entries.
+//
+// Values of encrypted storage are written as they are stored, so snapshot stays encrypted.
+func (db *DB) Snapshot(ctx context.Context, w io.Writer) (int, error) {
+	bw := bufio.NewWriter(w)
+	hw := db.PlatformCryptographyScheme.ReferenceHasher()
+	sw := &snapshotWriter{w: io.MultiWriter(bw, hw)}
+
+	store, flags := db.store, byte(0)
+	if encrypted, ok := db.store.(*encryptedStore); ok {
+		store, flags = encrypted.store, snapshotEncrypted
+	}
+	sw.write([]byte(snapshotMagic))
+	sw.writeUint(uint64(snapshotVersion))
+	sw.write([]byte{flags})
+
+	var count int
+	restoreKey := prefixkey(scopeIDSystem, []byte{sysRestoreInProgress})
+	err := kvView(store, func(txn KVTxn) error {
+		it := txn.NewIterator()
+		defer it.Close()
+
+		for _, scope := range snapshotScopes {
+			prefix := []byte{scope}
+			for it.Seek(prefix); it.ValidForPrefix(prefix); it.Next() {
+				key := it.Key()
+				if bytes.Equal(key, restoreKey) || bytes.Equal(key, encryptionStateKey) {
+					continue
+				}
+				value, err := it.Value()
+				if err != nil {
+					return err
+				}
+				sw.write([]byte{snapshotEntry})
+				sw.writeChunk(key)
+				sw.writeChunk(value)
+				if sw.err != nil {
+					return sw.err
+				}
+				count++
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to write snapshot")
+	}
+
+	sw.write([]byte{snapshotEnd})
+	sw.writeUint(uint64(count))
+	// Checksum itself is not hashed.
+	sw.w = bw
+	sw.writeChunk(hw.Sum(nil))
+	if sw.err != nil {
+		return 0, errors.Wrap(sw.err, "failed to write snapshot")
+	}
+
+	inslogger.FromContext(ctx).Debugf("snapshot is written, %v entries", count)
+	return count, bw.Flush()
+}
+
+// Restore reads snapshot written by Snapshot method from r and saves it to storage.
+//
+// Storage should be empty (not initialized by Init) or should contain unfinished restore.
+// Encrypted snapshot could be restored only to storage encrypted with the same keys.
+// After all data is written, jet drops hash chain is verified. Until successful verification
+// storage Init returns ErrRestoreInProgress, so node could not be started on partially restored data.
+// Returns number of restored entries.
+func (db *DB) Restore(ctx context.Context, r io.Reader) (int, error) {
+	restoreKey := prefixkey(scopeIDSystem, []byte{sysRestoreInProgress})
+	_, err := db.get(ctx, restoreKey)
+	if err == ErrNotFound {
+		_, err = db.get(ctx, prefixkey(scopeIDSystem, []byte{sysGenesis}))
+		if err == nil {
+			return 0, errors.New("restore to non-empty storage is forbidden")
+		}
+		if err != ErrNotFound {
+			return 0, err
+		}
+		err = db.set(ctx, restoreKey, []byte{1})
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	hw := db.PlatformCryptographyScheme.ReferenceHasher()
+	br := bufio.NewReader(r)
+	sr := &snapshotReader{r: io.TeeReader(br, hw)}
+
+	magic := sr.read(len(snapshotMagic))
+	if sr.err == nil && string(magic) != snapshotMagic {
+		return 0, errors.New("invalid snapshot format")
+	}
+	version := sr.readUint()
+	if sr.err == nil && (version == 0 || version > uint64(snapshotVersion)) {
+		return 0, errors.Errorf("unsupported snapshot version %v", version)
+	}
+	// Snapshots of the first version have no flags.
+	var flags byte
+	if version > 1 {
+		if b := sr.read(1); sr.err == nil {
+			flags = b[0]
+		}
+	}
+	storeBatch := db.StoreKeyValues
+	if flags&snapshotEncrypted != 0 {
+		encrypted, ok := db.store.(*encryptedStore)
+		if !ok {
+			return 0, errors.New("encrypted snapshot could not be restored to storage without encryption")
+		}
+		storeBatch = func(ctx context.Context, kvs []core.KV) error {
+			defer db.resetJetTrees()
+			return encrypted.setEncrypted(kvs)
+		}
+	}
+
+	var (
+		count int
+		batch []core.KV
+	)
+	for sr.err == nil {
+		kind := sr.read(1)
+		if sr.err != nil || kind[0] == snapshotEnd {
+			break
+		}
+		if kind[0] != snapshotEntry {
+			return 0, errors.New("invalid snapshot entry")
+		}
+		key := sr.readChunk()
+		value := sr.readChunk()
+		if sr.err != nil {
+			break
+		}
+		batch = append(batch, core.KV{K: key, V: value})
+		count++
+		if len(batch) >= restoreBatchSize {
+			if err = storeBatch(ctx, batch); err != nil {
+				return 0, err
+			}
+			batch = nil
+		}
+	}
+	expectedCount := sr.readUint()
+	sum := hw.Sum(nil)
+	sr.r = br
+	expectedSum := sr.readChunk()
+	if sr.err != nil {
+		return 0, errors.Wrap(sr.err, "failed to read snapshot")
+	}
+	if expectedCount != uint64(count) {
+		return 0, errors.Errorf("snapshot entries count mismatch: expected %v, got %v", expectedCount, count)
+	}
+	if !bytes.Equal(expectedSum, sum) {
+		return 0, errors.New("snapshot checksum mismatch")
+	}
+	if len(batch) > 0 {
+		if err = storeBatch(ctx, batch); err != nil {
+			return 0, err
+		}
+	}
+
+	err = db.VerifyDrops(ctx)
+	if err != nil {
+		return 0, errors.Wrap(err, "jet drops verification failed")
+	}
+
+	err = db.Update(ctx, func(tx *TransactionManager) error {
+		return tx.remove(ctx, restoreKey)
+	})
+	if err != nil {
+		return 0, err
+	}
+	inslogger.FromContext(ctx).Debugf("snapshot is restored, %v entries", count)
+	return count, nil
+}
+
+// snapshotWriter writes snapshot primitives and remembers the first error.
+type snapshotWriter struct {
+	w   io.Writer
+	err error
+}
+
+func (sw *snapshotWriter) write(b []byte) {
+	if sw.err != nil {
+		return
+	}
+	_, sw.err = sw.w.Write(b)
+}
+
+func (sw *snapshotWriter) writeUint(n uint64) {
+	buf := make([]byte, binary.MaxVarintLen64)
+	sw.write(buf[:binary.PutUvarint(buf, n)])
+}
+
+func (sw *snapshotWriter) writeChunk(b []byte) {
+	sw.writeUint(uint64(len(b)))
+	sw.write(b)
+}
+
+// snapshotReader reads snapshot primitives and remembers the first error.
+type snapshotReader struct {
+	r   io.Reader
+	err error
+}
+
+// ReadByte implements io.ByteReader for binary.ReadUvarint.
+func (sr *snapshotReader) ReadByte() (byte, error) {
+	b := sr.read(1)
+	if sr.err != nil {
+		return 0, sr.err
+	}
+	return b[0], nil
+}
+
+func (sr *snapshotReader) read(n int) []byte {
+	if sr.err != nil {
+		return nil
+	}
+	buf := make([]byte, n)
+	_, sr.err = io.ReadFull(sr.r, buf)
+	return buf
+}
+
+func (sr *snapshotReader) readUint() uint64 {
+	if sr.err != nil {
+		return 0
+	}
+	var n uint64
+	n, sr.err = binary.ReadUvarint(sr)
+	return n
+}
+
+func (sr *snapshotReader) readChunk() []byte {
+	n := sr.readUint()
+	if sr.err == nil && n > snapshotMaxChunk {
+		sr.err = errors.New("snapshot chunk is too large")
+	}
+	return sr.read(int(n))
+}
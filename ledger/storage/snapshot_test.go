@@ -0,0 +1,139 @@
+/*
+ *    Copyright 2018 Insolar
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ */
+
+package storage_test
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/insolar/insolar/core"
+	"github.com/insolar/insolar/instrumentation/inslogger"
+	"github.com/insolar/insolar/ledger/jetdrop"
+	"github.com/insolar/insolar/ledger/storage"
+	"github.com/insolar/insolar/ledger/storage/storagetest"
+)
+
+func TestDB_SnapshotRestore(t *testing.T) {
+	t.Parallel()
+	ctx := inslogger.TestContext(t)
+	db, cleaner := storagetest.TmpDB(ctx, t, storagetest.InMemory())
+	defer cleaner()
+
+	for n := 1; n < 4; n++ {
+		addRecords(ctx, t, db, pulseDelta(n-1))
+		closePulse(ctx, t, db, pulseDelta(n))
+	}
+
+	var buf bytes.Buffer
+	count, err := db.Snapshot(ctx, &buf)
+	require.NoError(t, err)
+	assert.NotZero(t, count)
+
+	restored, rcleaner := storagetest.TmpDB(ctx, t, storagetest.InMemory(), storagetest.DisableBootstrap())
+	defer rcleaner()
+	rcount, err := restored.Restore(ctx, &buf)
+	require.NoError(t, err)
+	assert.Equal(t, count, rcount)
+
+	require.NoError(t, restored.Init(ctx))
+	assert.Equal(t, db.GenesisRef(), restored.GenesisRef())
+	assert.Equal(t, allKVs(t, db.GetStore()), allKVs(t, restored.GetStore()))
+}
+
+func TestDB_Restore_BrokenDropChain(t *testing.T) {
+	t.Parallel()
+	ctx := inslogger.TestContext(t)
+	db, cleaner := storagetest.TmpDB(ctx, t, storagetest.InMemory())
+	defer cleaner()
+
+	for n := 1; n < 3; n++ {
+		closePulse(ctx, t, db, pulseDelta(n))
+	}
+	drop, err := db.GetDrop(ctx, pulseDelta(1))
+	require.NoError(t, err)
+	drop.PrevHash = []byte{1, 2, 3}
+	encoded, err := jetdrop.Encode(drop)
+	require.NoError(t, err)
+	dropKey := make([]byte, core.RecordIDSize+1)
+	dropKey[0] = scopeIDJetDrop
+	copy(dropKey[1:], drop.Pulse.Bytes())
+	require.NoError(t, db.StoreKeyValues(ctx, []core.KV{{K: dropKey, V: encoded}}))
+
+	var buf bytes.Buffer
+	_, err = db.Snapshot(ctx, &buf)
+	require.NoError(t, err)
+
+	restored, rcleaner := storagetest.TmpDB(ctx, t, storagetest.InMemory(), storagetest.DisableBootstrap())
+	defer rcleaner()
+	_, err = restored.Restore(ctx, &buf)
+	require.Error(t, err)
+	assert.Equal(t, storage.ErrRestoreInProgress, restored.Init(ctx))
+}
+
+func TestDB_Restore_Corrupted(t *testing.T) {
+	t.Parallel()
+	ctx := inslogger.TestContext(t)
+	db, cleaner := storagetest.TmpDB(ctx, t, storagetest.InMemory())
+	defer cleaner()
+
+	var buf bytes.Buffer
+	_, err := db.Snapshot(ctx, &buf)
+	require.NoError(t, err)
+	data := buf.Bytes()
+	data[len(data)/2] ^= 0xFF
+
+	restored, rcleaner := storagetest.TmpDB(ctx, t, storagetest.InMemory(), storagetest.DisableBootstrap())
+	defer rcleaner()
+	_, err = restored.Restore(ctx, bytes.NewReader(data))
+	require.Error(t, err)
+
+	_, err = db.Restore(ctx, bytes.NewReader(buf.Bytes()))
+	require.Error(t, err, "restore to non-empty storage should fail")
+}
+
+// closePulse creates jet drop for the latest pulse and adds the new one (as PulseManager does).
+func closePulse(ctx context.Context, t *testing.T, db *storage.DB, pn core.PulseNumber) {
+	latest, err := db.GetLatestPulseNumber(ctx)
+	require.NoError(t, err)
+	latestPulse, err := db.GetPulse(ctx, latest)
+	require.NoError(t, err)
+	prevDrop, err := db.GetDrop(ctx, *latestPulse.Prev)
+	require.NoError(t, err)
+	drop, _, err := db.CreateDrop(ctx, latest, prevDrop.Hash)
+	require.NoError(t, err)
+	require.NoError(t, db.SetDrop(ctx, drop))
+	require.NoError(t, db.AddPulse(ctx, core.Pulse{PulseNumber: pn}))
+}
+
+func allKVs(t *testing.T, store storage.KVStore) []core.KV {
+	txn := store.NewTransaction(false)
+	defer txn.Discard()
+	it := txn.NewIterator()
+	defer it.Close()
+
+	var kvs []core.KV
+	for it.Seek(nil); it.ValidForPrefix(nil); it.Next() {
+		v, err := it.Value()
+		require.NoError(t, err)
+		kvs = append(kvs, core.KV{K: it.Key(), V: v})
+	}
+	return kvs
+}
@@ -134,7 +134,7 @@ func TestDB_CreateDrop(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, 3, len(messages))
 	assert.Equal(t, pulse, drop.Pulse)
-	assert.Equal(t, "2aCdao6DhZSWQNTrtrxJW7QQZRb6UJ1ssRi9cg", base58.Encode(drop.Hash))
+	assert.Equal(t, "LAYtd2YFehGX3U1dyWCfXnCYGX6By8ATw7yU3b", base58.Encode(drop.Hash))
 
 	for _, rawMessage := range messages {
 		formatedMessage, err := message.Deserialize(bytes.NewBuffer(rawMessage))
@@ -159,6 +159,13 @@ func TestDB_SetDrop(t *testing.T) {
 	got, err := db.GetDrop(ctx, 42)
 	assert.NoError(t, err)
 	assert.Equal(t, *got, drop42)
+
+	// The same drop can be saved again.
+	err = db.SetDrop(ctx, &drop42)
+	assert.NoError(t, err)
+
+	err = db.SetDrop(ctx, &jetdrop.JetDrop{Pulse: 42, Hash: []byte{0xEE}})
+	assert.Equal(t, storage.ErrOverride, err)
 }
 
 func TestDB_AddPulse(t *testing.T) {
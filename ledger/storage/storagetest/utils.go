@@ -31,6 +31,9 @@ import (
 type tmpDBOptions struct {
 	dir         string
 	nobootstrap bool
+	inmemory    bool
+	compression string
+	keyFile     string
 }
 
 // Option provides functional option for TmpDB.
@@ -50,23 +53,53 @@ func DisableBootstrap() Option {
 	}
 }
 
+// InMemory makes TmpDB to use in-memory backend instead of BadgerDB.
+func InMemory() Option {
+	return func(opts *tmpDBOptions) {
+		opts.inmemory = true
+	}
+}
+
+// BlobCompression defines compression of blobs in database.
+func BlobCompression(name string) Option {
+	return func(opts *tmpDBOptions) {
+		opts.compression = name
+	}
+}
+
+// EncryptionKeyFile enables storage encryption with keys from provided file.
+func EncryptionKeyFile(path string) Option {
+	return func(opts *tmpDBOptions) {
+		opts.keyFile = path
+	}
+}
+
 // TmpDB returns BadgerDB's storage implementation and cleanup function.
 //
-// Creates BadgerDB in temporary directory and uses t for errors reporting.
+// Creates BadgerDB in temporary directory (or in memory if InMemory option provided)
+// and uses t for errors reporting.
 func TmpDB(ctx context.Context, t testing.TB, options ...Option) (*storage.DB, func()) {
 	opts := &tmpDBOptions{}
 	for _, o := range options {
 		o(opts)
 	}
+	if opts.inmemory {
+		return memoryDB(ctx, t, opts)
+	}
 	tmpdir, err := ioutil.TempDir(opts.dir, "bdb-test-")
 	if err != nil {
 		t.Fatal(err)
 	}
 	db, err := storage.NewDB(configuration.Ledger{
 		Storage: configuration.Storage{
-			DataDirectory: tmpdir,
+			DataDirectory:   tmpdir,
+			BlobCompression: opts.compression,
+			Encryption: configuration.StorageEncryption{
+				Enabled: opts.keyFile != "",
+				KeyFile: opts.keyFile,
+			},
 		},
-	}, nil)
+	}, nil, nil)
 	db.PlatformCryptographyScheme = platformpolicy.NewPlatformCryptographyScheme()
 	if err != nil {
 		t.Fatal(err)
@@ -88,3 +121,21 @@ func TmpDB(ctx context.Context, t testing.TB, options ...Option) (*storage.DB, f
 		}
 	}
 }
+
+func memoryDB(ctx context.Context, t testing.TB, opts *tmpDBOptions) (*storage.DB, func()) {
+	db := storage.NewMemoryDB(configuration.Ledger{
+		Storage: configuration.Storage{BlobCompression: opts.compression},
+	})
+	db.PlatformCryptographyScheme = platformpolicy.NewPlatformCryptographyScheme()
+	if !opts.nobootstrap {
+		err := db.Init(ctx)
+		assert.NoError(t, err)
+	}
+
+	return db, func() {
+		err := db.Close()
+		if err != nil {
+			t.Error("in-memory db close failed", err)
+		}
+	}
+}
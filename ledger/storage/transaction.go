@@ -17,19 +17,19 @@
 package storage
 
 import (
+	"bytes"
 	"context"
 	"encoding/hex"
 
-	"github.com/dgraph-io/badger"
-
 	"github.com/insolar/insolar/core"
 	"github.com/insolar/insolar/ledger/index"
 	"github.com/insolar/insolar/ledger/record"
 )
 
 type keyval struct {
-	k []byte
-	v []byte
+	k       []byte
+	v       []byte
+	deleted bool
 }
 
 // TransactionManager is used to ensure persistent writes to disk.
@@ -76,10 +76,14 @@ func (m *TransactionManager) Commit() error {
 		return nil
 	}
 	var err error
-	tx := m.db.db.NewTransaction(m.update)
+	tx := m.db.store.NewTransaction(m.update)
 	defer tx.Discard()
 	for _, rec := range m.txupdates {
-		err = tx.Set(rec.k, rec.v)
+		if rec.deleted {
+			err = tx.Delete(rec.k)
+		} else {
+			err = tx.Set(rec.k, rec.v)
+		}
 		if err != nil {
 			break
 		}
@@ -87,7 +91,7 @@ func (m *TransactionManager) Commit() error {
 	if err != nil {
 		return err
 	}
-	return tx.Commit(nil)
+	return tx.Commit()
 }
 
 // Discard terminates transaction without disk writes.
@@ -99,7 +103,7 @@ func (m *TransactionManager) Discard() {
 	}
 }
 
-// GetRequest returns request record from BadgerDB by *record.Reference.
+// GetRequest returns request record from storage by *record.Reference.
 //
 // It returns ErrNotFound if the DB does not contain the key.
 func (m *TransactionManager) GetRequest(ctx context.Context, id *core.RecordID) (record.Request, error) {
@@ -115,32 +119,40 @@ func (m *TransactionManager) GetRequest(ctx context.Context, id *core.RecordID)
 // GetBlob returns binary value stored by record ID.
 func (m *TransactionManager) GetBlob(ctx context.Context, id *core.RecordID) ([]byte, error) {
 	k := prefixkey(scopeIDBlob, id[:])
-	return m.get(ctx, k)
+	v, err := m.get(ctx, k)
+	if err != nil {
+		return nil, err
+	}
+	return readBlob(func(k []byte) ([]byte, error) {
+		return m.get(ctx, k)
+	}, k, v)
 }
 
 // SetBlob saves binary value for provided pulse.
+//
+// Content of equal blobs is stored once and compressed according to storage configuration.
 func (m *TransactionManager) SetBlob(ctx context.Context, pulseNumber core.PulseNumber, blob []byte) (*core.RecordID, error) {
 	id := record.CalculateIDForBlob(m.db.PlatformCryptographyScheme, pulseNumber, blob)
 	k := prefixkey(scopeIDBlob, id[:])
-	geterr := m.db.db.View(func(tx *badger.Txn) error {
+	geterr := kvView(m.db.store, func(tx KVTxn) error {
 		_, err := tx.Get(k)
 		return err
 	})
 	if geterr == nil {
 		return id, ErrOverride
 	}
-	if geterr != badger.ErrKeyNotFound {
+	if geterr != ErrNotFound {
 		return nil, ErrNotFound
 	}
 
-	err := m.set(ctx, k, blob)
+	err := m.storeBlob(ctx, id, blob)
 	if err != nil {
 		return nil, err
 	}
 	return id, nil
 }
 
-// GetRecord returns record from BadgerDB by *record.Reference.
+// GetRecord returns record from storage by *record.Reference.
 //
 // It returns ErrNotFound if the DB does not contain the key.
 func (m *TransactionManager) GetRecord(ctx context.Context, id *core.RecordID) (record.Record, error) {
@@ -152,7 +164,7 @@ func (m *TransactionManager) GetRecord(ctx context.Context, id *core.RecordID) (
 	return record.DeserializeRecord(buf), nil
 }
 
-// SetRecord stores record in BadgerDB and returns *record.ID of new record.
+// SetRecord stores record in storage and returns *record.ID of new record.
 //
 // If record exists returns both *record.ID and ErrOverride error.
 // If record not found returns nil and ErrNotFound error
@@ -164,14 +176,14 @@ func (m *TransactionManager) SetRecord(ctx context.Context, pulseNumber core.Pul
 	}
 	id := core.NewRecordID(pulseNumber, recHash.Sum(nil))
 	k := prefixkey(scopeIDRecord, id[:])
-	geterr := m.db.db.View(func(tx *badger.Txn) error {
+	geterr := kvView(m.db.store, func(tx KVTxn) error {
 		_, err := tx.Get(k)
 		return err
 	})
 	if geterr == nil {
 		return id, ErrOverride
 	}
-	if geterr != badger.ErrKeyNotFound {
+	if geterr != ErrNotFound {
 		return nil, ErrNotFound
 	}
 
@@ -213,6 +225,16 @@ func (m *TransactionManager) SetObjectIndex(
 	if err != nil {
 		return err
 	}
+	// Pulses of latest states are marked, so pruning checks only objects with states in the pruned pulse.
+	for _, state := range []*core.RecordID{idx.LatestState, idx.LatestStateApproved} {
+		if state == nil {
+			continue
+		}
+		err = m.set(ctx, bytes.Join([][]byte{{scopeIDStateHead}, state.Pulse().Bytes(), id[:]}, nil), []byte{})
+		if err != nil {
+			return err
+		}
+	}
 	return m.set(ctx, k, encoded)
 }
 
@@ -224,22 +246,26 @@ func (m *TransactionManager) set(ctx context.Context, key, value []byte) error {
 	return nil
 }
 
+// remove deletes value by key.
+func (m *TransactionManager) remove(ctx context.Context, key []byte) error {
+	debugf(ctx, "remove key %v", bytes2hex(key))
+
+	m.txupdates[string(key)] = keyval{k: key, deleted: true}
+	return nil
+}
+
 // get returns value by key.
 func (m *TransactionManager) get(ctx context.Context, key []byte) ([]byte, error) {
 	debugf(ctx, "get key %v", bytes2hex(key))
 
 	if kv, ok := m.txupdates[string(key)]; ok {
+		if kv.deleted {
+			return nil, ErrNotFound
+		}
 		return kv.v, nil
 	}
 
-	txn := m.db.db.NewTransaction(false)
+	txn := m.db.store.NewTransaction(false)
 	defer txn.Discard()
-	item, err := txn.Get(key)
-	if err != nil {
-		if err == badger.ErrKeyNotFound {
-			return nil, ErrNotFound
-		}
-		return nil, err
-	}
-	return item.ValueCopy(nil)
+	return txn.Get(key)
 }
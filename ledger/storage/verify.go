@@ -0,0 +1,266 @@
+/*
+ *    Copyright 2018 Insolar
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ */
+
+package storage
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+
+	"github.com/pkg/errors"
+
+	"github.com/insolar/insolar/core"
+	"github.com/insolar/insolar/ledger/index"
+	"github.com/insolar/insolar/ledger/jetdrop"
+	"github.com/insolar/insolar/ledger/record"
+)
+
+// Integrity issue kinds reported by Verify.
+const (
+	IssueBrokenPulse        = "broken_pulse"
+	IssueBrokenDrop         = "broken_drop"
+	IssueDropHashMismatch   = "drop_hash_mismatch"
+	IssueDropChainBroken    = "drop_chain_broken"
+	IssueBrokenRecord       = "broken_record"
+	IssueRecordHashMismatch = "record_hash_mismatch"
+	IssueBrokenIndex        = "broken_index"
+	IssueMissingRecord      = "missing_record"
+	IssueMissingBlob        = "missing_blob"
+)
+
+// VerifyIssue describes single integrity problem found by Verify.
+type VerifyIssue struct {
+	// Kind is one of Issue* constants.
+	Kind string `json:"kind"`
+	// Pulse is a pulse of broken drop or source record.
+	Pulse core.PulseNumber `json:"pulse,omitempty"`
+	// Source is an ID of record or lifeline which contains broken link.
+	Source *core.RecordID `json:"source,omitempty"`
+	// Field is a name of the field with broken link.
+	Field string `json:"field,omitempty"`
+	// Target is an ID of missing record or blob.
+	Target *core.RecordID `json:"target,omitempty"`
+	// Error contains additional error description.
+	Error string `json:"error,omitempty"`
+}
+
+// VerifyReport is a result of storage integrity check.
+type VerifyReport struct {
+	Pulses    int           `json:"pulses"`
+	Drops     int           `json:"drops"`
+	Pruned    int           `json:"pruned"`
+	Records   int           `json:"records"`
+	Lifelines int           `json:"lifelines"`
+	Issues    []VerifyIssue `json:"issues"`
+}
+
+// OK returns true if no issues were found.
+func (r *VerifyReport) OK() bool {
+	return len(r.Issues) == 0
+}
+
+func (r *VerifyReport) add(issue VerifyIssue) {
+	r.Issues = append(r.Issues, issue)
+}
+
+// Verify checks storage integrity.
+//
+// It recomputes jet drop hashes for every pulse, checks records hashes, checks that lifelines and object
+// state records point to existing records and blobs. Drop hashes of pruned pulses and links to their records and
+// blobs are not checked, such pulses are counted in the report. Found problems are collected in the report, returned
+// error means storage could not be read at all.
+func (db *DB) Verify(ctx context.Context) (*VerifyReport, error) {
+	report := &VerifyReport{Issues: []VerifyIssue{}}
+
+	err := db.verifyPulses(ctx, report)
+	if err != nil {
+		return nil, err
+	}
+	err = db.verifyRecords(ctx, report)
+	if err != nil {
+		return nil, err
+	}
+	err = db.verifyLifelines(ctx, report)
+	if err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+func (db *DB) verifyPulses(ctx context.Context, report *VerifyReport) error {
+	return db.iterate(ctx, []byte{scopeIDPulse}, func(k, v []byte) error {
+		pn := core.NewPulseNumber(k[:core.PulseNumberSize])
+		report.Pulses++
+		if _, err := decodePulse(v); err != nil {
+			report.add(VerifyIssue{Kind: IssueBrokenPulse, Pulse: pn, Error: err.Error()})
+			return nil
+		}
+
+		buf, err := db.get(ctx, prefixkey(scopeIDJetDrop, pn.Bytes()))
+		if err == ErrNotFound {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		report.Drops++
+		pruned, err := db.IsPruned(ctx, pn)
+		if err != nil {
+			return err
+		}
+		if pruned {
+			report.Pruned++
+		}
+		drop, err := jetdrop.Decode(buf)
+		if err != nil {
+			report.add(VerifyIssue{Kind: IssueBrokenDrop, Pulse: pn, Error: err.Error()})
+			return nil
+		}
+
+		err = db.checkDrop(ctx, drop)
+		switch errors.Cause(err) {
+		case nil:
+		case ErrDropHashMismatch:
+			report.add(VerifyIssue{Kind: IssueDropHashMismatch, Pulse: pn, Error: err.Error()})
+		case ErrDropChainBroken:
+			report.add(VerifyIssue{Kind: IssueDropChainBroken, Pulse: pn, Error: err.Error()})
+		default:
+			report.add(VerifyIssue{Kind: IssueBrokenDrop, Pulse: pn, Error: err.Error()})
+		}
+		return nil
+	})
+}
+
+func (db *DB) verifyRecords(ctx context.Context, report *VerifyReport) error {
+	return db.iterate(ctx, []byte{scopeIDRecord}, func(k, v []byte) error {
+		var id core.RecordID
+		copy(id[:], k)
+		report.Records++
+
+		rec, err := decodeRecord(v)
+		if err != nil {
+			report.add(VerifyIssue{Kind: IssueBrokenRecord, Pulse: id.Pulse(), Source: &id, Error: err.Error()})
+			return nil
+		}
+
+		hw := db.PlatformCryptographyScheme.ReferenceHasher()
+		_, err = rec.WriteHashData(hw)
+		if err != nil {
+			return err
+		}
+		if !bytes.Equal(hw.Sum(nil), id.Hash()) {
+			report.add(VerifyIssue{Kind: IssueRecordHashMismatch, Pulse: id.Pulse(), Source: &id})
+		}
+
+		switch r := rec.(type) {
+		case record.ObjectState:
+			if r.GetMemory() != nil {
+				db.checkBlobLink(ctx, report, &id, "Memory", r.GetMemory())
+			}
+			if r.PrevStateID() != nil {
+				db.checkRecordLink(ctx, report, &id, "PrevState", r.PrevStateID())
+			}
+		case *record.ChildRecord:
+			if r.PrevChild != nil {
+				db.checkRecordLink(ctx, report, &id, "PrevChild", r.PrevChild)
+			}
+		case *record.ValidationRecord:
+			if r.PrevValidation != nil {
+				db.checkRecordLink(ctx, report, &id, "PrevValidation", r.PrevValidation)
+			}
+		}
+		return nil
+	})
+}
+
+func (db *DB) verifyLifelines(ctx context.Context, report *VerifyReport) error {
+	return db.iterate(ctx, []byte{scopeIDLifeline}, func(k, v []byte) error {
+		var id core.RecordID
+		copy(id[:], k)
+		report.Lifelines++
+
+		idx, err := index.DecodeObjectLifeline(v)
+		if err != nil {
+			report.add(VerifyIssue{Kind: IssueBrokenIndex, Pulse: id.Pulse(), Source: &id, Error: err.Error()})
+			return nil
+		}
+		if idx.LatestState != nil {
+			db.checkRecordLink(ctx, report, &id, "LatestState", idx.LatestState)
+		}
+		if idx.LatestStateApproved != nil {
+			db.checkRecordLink(ctx, report, &id, "LatestStateApproved", idx.LatestStateApproved)
+		}
+		if idx.ChildPointer != nil {
+			db.checkRecordLink(ctx, report, &id, "ChildPointer", idx.ChildPointer)
+		}
+		if idx.LatestValidation != nil {
+			db.checkRecordLink(ctx, report, &id, "LatestValidation", idx.LatestValidation)
+		}
+		return nil
+	})
+}
+
+func (db *DB) checkRecordLink(
+	ctx context.Context, report *VerifyReport, source *core.RecordID, field string, target *core.RecordID,
+) {
+	db.checkLink(ctx, report, IssueMissingRecord, prefixkey(scopeIDRecord, target[:]), source, field, target)
+}
+
+func (db *DB) checkBlobLink(
+	ctx context.Context, report *VerifyReport, source *core.RecordID, field string, target *core.RecordID,
+) {
+	db.checkLink(ctx, report, IssueMissingBlob, prefixkey(scopeIDBlob, target[:]), source, field, target)
+}
+
+func (db *DB) checkLink(
+	ctx context.Context,
+	report *VerifyReport,
+	kind string,
+	key []byte,
+	source *core.RecordID,
+	field string,
+	target *core.RecordID,
+) {
+	_, err := db.get(ctx, key)
+	if err == nil {
+		return
+	}
+	if err == ErrNotFound {
+		pruned, pruneErr := db.IsPruned(ctx, target.Pulse())
+		if pruneErr == nil && pruned {
+			return
+		}
+	}
+	issue := VerifyIssue{Kind: kind, Pulse: source.Pulse(), Source: source, Field: field, Target: target}
+	if err != ErrNotFound {
+		issue.Error = err.Error()
+	}
+	report.add(issue)
+}
+
+// decodeRecord deserializes record, returns error instead of panic on broken data.
+func decodeRecord(buf []byte) (rec record.Record, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("failed to decode record: %v", r)
+		}
+	}()
+	if len(buf) < record.TypeIDSize {
+		return nil, errors.New("record is too short")
+	}
+	return record.DeserializeRecord(buf), nil
+}
@@ -0,0 +1,153 @@
+/*
+ *    Copyright 2018 Insolar
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ */
+
+package storage_test
+
+import (
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/insolar/insolar/core"
+	"github.com/insolar/insolar/instrumentation/inslogger"
+	"github.com/insolar/insolar/ledger/index"
+	"github.com/insolar/insolar/ledger/jetdrop"
+	"github.com/insolar/insolar/ledger/record"
+	"github.com/insolar/insolar/ledger/storage"
+	"github.com/insolar/insolar/ledger/storage/storagetest"
+	"github.com/insolar/insolar/platformpolicy"
+)
+
+func TestDB_Verify(t *testing.T) {
+	t.Parallel()
+	ctx := inslogger.TestContext(t)
+	db, cleaner := storagetest.TmpDB(ctx, t, storagetest.InMemory())
+	defer cleaner()
+
+	for n := 1; n < 4; n++ {
+		addRecords(ctx, t, db, pulseDelta(n-1))
+		closePulse(ctx, t, db, pulseDelta(n))
+	}
+
+	report, err := db.Verify(ctx)
+	require.NoError(t, err)
+	assert.True(t, report.OK(), "unexpected issues: %v", report.Issues)
+	assert.Equal(t, 4, report.Pulses)
+	assert.Equal(t, 3, report.Drops)
+	assert.NotZero(t, report.Records)
+	assert.NotZero(t, report.Lifelines)
+}
+
+func TestDB_Verify_BrokenLinks(t *testing.T) {
+	t.Parallel()
+	ctx := inslogger.TestContext(t)
+	db, cleaner := storagetest.TmpDB(ctx, t, storagetest.InMemory())
+	defer cleaner()
+
+	pulse := core.PulseNumber(core.FirstPulseNumber)
+	missingBlob := core.NewRecordID(pulse, hexhash("b10b"))
+	missingRecord := core.NewRecordID(pulse, hexhash("5e7"))
+
+	stateID, err := db.SetRecord(ctx, pulse, &record.ObjectActivateRecord{
+		ObjectStateRecord: record.ObjectStateRecord{
+			Memory: missingBlob,
+		},
+	})
+	require.NoError(t, err)
+	err = db.SetObjectIndex(ctx, stateID, &index.ObjectLifeline{
+		LatestState:  stateID,
+		ChildPointer: missingRecord,
+	})
+	require.NoError(t, err)
+
+	// Drop is created before the record is added to the pulse, so the drop hash is broken.
+	closePulse(ctx, t, db, pulseDelta(1))
+	_, err = db.SetRecord(ctx, pulse, &record.CodeRecord{})
+	require.NoError(t, err)
+
+	// Break chain of the next drop (pulse without records, so drop hash depends on the previous one only).
+	closePulse(ctx, t, db, pulseDelta(2))
+	drop, err := db.GetDrop(ctx, pulseDelta(1))
+	require.NoError(t, err)
+	drop.PrevHash = []byte{1, 2, 3}
+	hasher := platformpolicy.NewPlatformCryptographyScheme().ReferenceHasher()
+	drop.Hash, err = jetdrop.Hash(hasher, drop.PrevHash, nil)
+	require.NoError(t, err)
+	encoded, err := jetdrop.Encode(drop)
+	require.NoError(t, err)
+	dropKey := make([]byte, core.RecordIDSize+1)
+	dropKey[0] = scopeIDJetDrop
+	copy(dropKey[1:], drop.Pulse.Bytes())
+	require.NoError(t, db.StoreKeyValues(ctx, []core.KV{{K: dropKey, V: encoded}}))
+
+	report, err := db.Verify(ctx)
+	require.NoError(t, err)
+
+	kinds := map[string]storage.VerifyIssue{}
+	for _, issue := range report.Issues {
+		kinds[issue.Kind] = issue
+	}
+	require.Len(t, kinds, 4, "unexpected issues: %v", report.Issues)
+
+	assert.Equal(t, pulse, kinds[storage.IssueDropHashMismatch].Pulse)
+	assert.Equal(t, pulseDelta(1), kinds[storage.IssueDropChainBroken].Pulse)
+
+	blobIssue := kinds[storage.IssueMissingBlob]
+	assert.Equal(t, stateID, blobIssue.Source)
+	assert.Equal(t, "Memory", blobIssue.Field)
+	assert.Equal(t, missingBlob, blobIssue.Target)
+
+	recIssue := kinds[storage.IssueMissingRecord]
+	assert.Equal(t, stateID, recIssue.Source)
+	assert.Equal(t, "ChildPointer", recIssue.Field)
+	assert.Equal(t, missingRecord, recIssue.Target)
+}
+
+func TestDB_Verify_LegacyDropHash(t *testing.T) {
+	t.Parallel()
+	ctx := inslogger.TestContext(t)
+	db, cleaner := storagetest.TmpDB(ctx, t, storagetest.InMemory())
+	defer cleaner()
+
+	pulse := core.PulseNumber(core.FirstPulseNumber)
+	recID, err := db.SetRecord(ctx, pulse, &record.CodeRecord{})
+	require.NoError(t, err)
+	closePulse(ctx, t, db, pulseDelta(1))
+
+	// Drops created before hash versioning have a hash of the previous drop hash only.
+	drop, err := db.GetDrop(ctx, pulse)
+	require.NoError(t, err)
+	drop.HashVersion = jetdrop.HashVersionPrev
+	hasher := platformpolicy.NewPlatformCryptographyScheme().ReferenceHasher()
+	_, err = hasher.Write(drop.PrevHash)
+	require.NoError(t, err)
+	drop.Hash = hasher.Sum(nil)
+	encoded, err := jetdrop.Encode(drop)
+	require.NoError(t, err)
+	dropKey := make([]byte, core.RecordIDSize+1)
+	dropKey[0] = scopeIDJetDrop
+	copy(dropKey[1:], drop.Pulse.Bytes())
+	require.NoError(t, db.StoreKeyValues(ctx, []core.KV{{K: dropKey, V: encoded}}))
+
+	report, err := db.Verify(ctx)
+	require.NoError(t, err)
+	assert.True(t, report.OK(), "unexpected issues: %v", report.Issues)
+
+	_, err = db.GetRecordProof(ctx, recID)
+	assert.Equal(t, storage.ErrProofNotSupported, errors.Cause(err))
+}
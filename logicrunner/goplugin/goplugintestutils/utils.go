@@ -158,6 +158,36 @@ func (t *TestArtifactManager) GetChildren(ctx context.Context, parent core.Recor
 	panic("implement me")
 }
 
+// GetChildrenPage implementation for tests
+func (t *TestArtifactManager) GetChildrenPage(ctx context.Context, parent core.RecordRef, query core.ChildrenQuery) (*core.ChildrenPage, error) {
+	panic("implement me")
+}
+
+// CountChildren implementation for tests
+func (t *TestArtifactManager) CountChildren(ctx context.Context, parent core.RecordRef, query core.ChildrenQuery) (int, error) {
+	panic("implement me")
+}
+
+// GetHistory implementation for tests
+func (t *TestArtifactManager) GetHistory(ctx context.Context, head core.RecordRef, pulse *core.PulseNumber) (core.ObjectHistoryIterator, error) {
+	panic("implement me")
+}
+
+// GetRecordProof implementation for tests
+func (t *TestArtifactManager) GetRecordProof(ctx context.Context, id core.RecordID) (*core.RecordProof, error) {
+	panic("implement me")
+}
+
+// GetObjects implementation for tests
+func (t *TestArtifactManager) GetObjects(ctx context.Context, heads []core.ObjectHead, approved bool) ([]core.ObjectDescriptor, error) {
+	panic("implement me")
+}
+
+// GetObjectsByPrototype implementation for tests
+func (t *TestArtifactManager) GetObjectsByPrototype(ctx context.Context, query core.ObjectsQuery) (*core.ObjectsPage, error) {
+	panic("implement me")
+}
+
 // NewTestArtifactManager implementation for tests
 func NewTestArtifactManager() *TestArtifactManager {
 	return &TestArtifactManager{
@@ -184,7 +214,7 @@ func (t *TestArtifactManager) RegisterResult(
 }
 
 // GetObject implementation for tests
-func (t *TestArtifactManager) GetObject(ctx context.Context, object core.RecordRef, state *core.RecordID, approved bool) (core.ObjectDescriptor, error) {
+func (t *TestArtifactManager) GetObject(ctx context.Context, object core.RecordRef, state *core.RecordID, pulse *core.PulseNumber, approved bool) (core.ObjectDescriptor, error) {
 	res, ok := t.Objects[object]
 	if !ok {
 		return nil, errors.New("No object")
@@ -341,6 +371,11 @@ func (t *TestArtifactManager) RegisterValidation(
 	panic("implement me")
 }
 
+// GetObjectStatus implementation for tests
+func (t *TestArtifactManager) GetObjectStatus(ctx context.Context, head core.RecordRef) (*core.ObjectStatus, error) {
+	panic("implement me")
+}
+
 // CBORMarshal - testing serialize helper
 func CBORMarshal(t testing.TB, o interface{}) []byte {
 	data, err := core.Serialize(o)
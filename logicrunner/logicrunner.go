@@ -401,7 +401,7 @@ func (lr *LogicRunner) getObjectMessage(es *ExecutionState, objref Ref) error {
 		return nil
 	}
 
-	objDesc, err := lr.ArtifactManager.GetObject(ctx, objref, nil, false)
+	objDesc, err := lr.ArtifactManager.GetObject(ctx, objref, nil, nil, false)
 	if err != nil {
 		return errors.Wrap(err, "couldn't get object")
 	}
@@ -409,7 +409,7 @@ func (lr *LogicRunner) getObjectMessage(es *ExecutionState, objref Ref) error {
 	if err != nil {
 		return errors.Wrap(err, "couldn't get prototype reference")
 	}
-	protoDesc, err := lr.ArtifactManager.GetObject(ctx, *protoRef, nil, false)
+	protoDesc, err := lr.ArtifactManager.GetObject(ctx, *protoRef, nil, nil, false)
 	if err != nil {
 		return errors.Wrap(err, "couldn't get object's class")
 	}
@@ -537,7 +537,7 @@ func (lr *LogicRunner) executeConstructorCall(es *ExecutionState, m *message.Cal
 		return nil, es.ErrorWrap(nil, "Call constructor from nowhere")
 	}
 
-	protoDesc, err := lr.ArtifactManager.GetObject(ctx, m.PrototypeRef, nil, false)
+	protoDesc, err := lr.ArtifactManager.GetObject(ctx, m.PrototypeRef, nil, nil, false)
 	if err != nil {
 		return nil, errors.Wrap(err, "couldn't get prototype")
 	}
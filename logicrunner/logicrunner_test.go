@@ -1894,7 +1894,7 @@ func (r *One) EmptyMethod() (error) {
 
 	//prototype = *cb.Prototypes["one"]
 	prototypeRef, err := object.Prototype()
-	prototype, err := am.GetObject(ctx, *prototypeRef, nil, false)
+	prototype, err := am.GetObject(ctx, *prototypeRef, nil, nil, false)
 	codeRef, err := prototype.Code()
 
 	assert.NoError(t, err, "get contract code")
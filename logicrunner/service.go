@@ -209,30 +209,17 @@ func (gpr *RPC) GetObjChildren(req rpctypes.UpGetObjChildrenReq, rep *rpctypes.U
 	}
 
 	am := gpr.lr.ArtifactManager
-	i, err := am.GetChildren(ctx, req.Obj, nil)
-	if err != nil {
-		return errors.Wrap(err, "[ GetObjChildren ] Can't get children")
-	}
-	for i.HasNext() {
-		r, err := i.Next()
-		if err != nil {
-			return errors.Wrap(err, "[ GetObjChildren ] Can't get Next")
-		}
-		o, err := am.GetObject(ctx, *r, nil, false)
-		if err != nil {
-			if err == core.ErrDeactivated {
-				continue
-			}
-			return errors.Wrap(err, "[ GetObjChildren ] Can't get Next")
-		}
-		protoRef, err := o.Prototype()
+	query := core.ChildrenQuery{Prototype: &req.Prototype}
+	for {
+		page, err := am.GetChildrenPage(ctx, req.Obj, query)
 		if err != nil {
-			return errors.Wrap(err, "[ GetObjChildren ] Can't get prototype reference")
+			return errors.Wrap(err, "[ GetObjChildren ] Can't get children")
 		}
-
-		if protoRef.Equal(req.Prototype) {
-			rep.Children = append(rep.Children, *r)
+		rep.Children = append(rep.Children, page.Refs...)
+		if page.Next == nil {
+			break
 		}
+		query.From = page.Next
 	}
 	gpr.lr.addObjectCaseRecord(req.Callee, core.CaseRecord{ // bad idea, we can store gadzillion of children
 		Type:   core.CaseRecordTypeGetObjChildren,
@@ -0,0 +1,73 @@
+/*
+ *    Copyright 2018 Insolar
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ */
+
+package messagebus
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/insolar/insolar/core"
+)
+
+// contextWithDeadline returns context that is cancelled when provided deadline passes. Zero deadline means no deadline.
+func contextWithDeadline(ctx context.Context, deadline time.Time) (context.Context, context.CancelFunc) {
+	if deadline.IsZero() {
+		return context.WithCancel(ctx)
+	}
+	return context.WithDeadline(ctx, deadline)
+}
+
+// awaitReply calls provided function and waits for its result until the context is done.
+//
+// The function receives the context and should stop when it is done, its result is discarded if the context is done
+// first.
+func awaitReply(
+	ctx context.Context, parcel core.Parcel, call func(context.Context) (core.Reply, error),
+) (core.Reply, error) {
+	if ctx.Err() != nil {
+		return nil, deadlineError(ctx, parcel)
+	}
+	if ctx.Done() == nil {
+		return call(ctx)
+	}
+
+	type result struct {
+		rep core.Reply
+		err error
+	}
+	done := make(chan result, 1)
+	go func() {
+		rep, err := call(ctx)
+		done <- result{rep: rep, err: err}
+	}()
+
+	select {
+	case res := <-done:
+		return res.rep, res.err
+	case <-ctx.Done():
+		return nil, deadlineError(ctx, parcel)
+	}
+}
+
+func deadlineError(ctx context.Context, parcel core.Parcel) error {
+	if ctx.Err() == context.DeadlineExceeded {
+		return errors.Wrapf(core.ErrDeadlineExceeded, "no reply for message %s", parcel.Type())
+	}
+	return ctx.Err()
+}
@@ -0,0 +1,131 @@
+/*
+ *    Copyright 2018 Insolar
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ */
+
+package messagebus
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/gojuno/minimock"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/insolar/insolar/configuration"
+	"github.com/insolar/insolar/core"
+	"github.com/insolar/insolar/core/message"
+	"github.com/insolar/insolar/core/reply"
+	"github.com/insolar/insolar/instrumentation/inslogger"
+	"github.com/insolar/insolar/testutils"
+)
+
+type testBlockingNetwork struct {
+	core.Network
+	nodeID  core.RecordRef
+	release chan struct{}
+}
+
+func (n *testBlockingNetwork) GetNodeID() core.RecordRef {
+	return n.nodeID
+}
+
+func (n *testBlockingNetwork) SendMessage(nodeID core.RecordRef, method string, msg core.Parcel) ([]byte, error) {
+	<-n.release
+	return nil, errors.New("released")
+}
+
+func TestMessageBus_Send_Deadline(t *testing.T) {
+	mc := minimock.NewController(t)
+	defer mc.Finish()
+
+	ctx := inslogger.TestContext(t)
+	pm := testutils.NewPulseManagerMock(mc)
+	pm.CurrentMock.Return(&core.Pulse{PulseNumber: core.FirstPulseNumber}, nil)
+	network := &testBlockingNetwork{nodeID: testutils.RandomRef(), release: make(chan struct{})}
+	defer close(network.release)
+
+	mb, err := NewMessageBus(configuration.NewConfiguration())
+	require.NoError(t, err)
+	mb.Service = network
+	mb.Ledger = &testRedirectLedger{pm: pm}
+	mb.ParcelFactory = &testParcelFactory{}
+
+	remote := testutils.RandomRef()
+	_, err = mb.Send(
+		ctx,
+		&message.GetCode{},
+		core.SendOptionDestination(&remote),
+		core.SendOptionDeadline(time.Now().Add(10*time.Millisecond)),
+	)
+	require.Error(t, err)
+	assert.Equal(t, core.ErrDeadlineExceeded, errors.Cause(err))
+
+	t.Run("cancels handler context on local delivery", func(t *testing.T) {
+		cancelled := make(chan struct{})
+		mb.MustRegister(core.TypeGetCode, func(ctx context.Context, parcel core.Parcel) (core.Reply, error) {
+			_, ok := ctx.Deadline()
+			assert.True(t, ok)
+			<-ctx.Done()
+			close(cancelled)
+			return &reply.OK{}, nil
+		})
+
+		_, err := mb.Send(
+			ctx,
+			&message.GetCode{},
+			core.SendOptionDestination(&network.nodeID),
+			core.SendOptionDeadline(time.Now().Add(10*time.Millisecond)),
+		)
+		require.Error(t, err)
+		assert.Equal(t, core.ErrDeadlineExceeded, errors.Cause(err))
+		select {
+		case <-cancelled:
+		case <-time.After(time.Second):
+			t.Fatal("handler context is not cancelled")
+		}
+	})
+
+	t.Run("stops waiting when caller context is cancelled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(ctx)
+		time.AfterFunc(10*time.Millisecond, cancel)
+
+		_, err := mb.Send(ctx, &message.GetCode{}, core.SendOptionDestination(&remote))
+		require.Error(t, err)
+		assert.Equal(t, context.Canceled, errors.Cause(err))
+	})
+
+	t.Run("cancels handler context on local delivery when caller context is cancelled", func(t *testing.T) {
+		cancelled := make(chan struct{})
+		mb.MustRegister(core.TypeGetObject, func(ctx context.Context, parcel core.Parcel) (core.Reply, error) {
+			<-ctx.Done()
+			close(cancelled)
+			return &reply.OK{}, nil
+		})
+		ctx, cancel := context.WithCancel(ctx)
+		time.AfterFunc(10*time.Millisecond, cancel)
+
+		_, err := mb.Send(ctx, &message.GetObject{}, core.SendOptionDestination(&network.nodeID))
+		require.Error(t, err)
+		assert.Equal(t, context.Canceled, errors.Cause(err))
+		select {
+		case <-cancelled:
+		case <-time.After(time.Second):
+			t.Fatal("handler context is not cancelled")
+		}
+	})
+}
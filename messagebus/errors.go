@@ -23,4 +23,6 @@ import (
 var (
 	// ErrNoReply is returned from player when there is no stored reply for provided message.
 	ErrNoReply = errors.New("no such reply")
+	// ErrTooManyRedirects is returned from MessageBus when redirect limit is exceeded for a message.
+	ErrTooManyRedirects = errors.New("too many redirects")
 )
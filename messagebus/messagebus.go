@@ -30,7 +30,6 @@ import (
 	"github.com/insolar/insolar/core"
 	"github.com/insolar/insolar/core/message"
 	"github.com/insolar/insolar/core/reply"
-	"github.com/insolar/insolar/instrumentation/hack"
 )
 
 const deliverRPCMethodName = "MessageBus.Deliver"
@@ -49,6 +48,10 @@ type MessageBus struct {
 
 	handlers     map[core.MessageType]core.MessageHandler
 	signmessages bool
+	maxRedirects int
+
+	sendMiddlewares    Middlewares
+	deliverMiddlewares Middlewares
 
 	globalLock sync.RWMutex
 }
@@ -56,10 +59,17 @@ type MessageBus struct {
 // NewMessageBus creates plain MessageBus instance. It can be used to create Player and Recorder instances that
 // wrap it, providing additional functionality.
 func NewMessageBus(config configuration.Configuration) (*MessageBus, error) {
-	return &MessageBus{
+	maxRedirects := config.MessageBus.MaxRedirects
+	if maxRedirects == 0 {
+		maxRedirects = configuration.NewMessageBus().MaxRedirects
+	}
+	mb := &MessageBus{
 		handlers:     map[core.MessageType]core.MessageHandler{},
 		signmessages: config.Host.SignMessages,
-	}, nil
+		maxRedirects: maxRedirects,
+	}
+	mb.deliverMiddlewares.Add(skipValidation)
+	return mb, nil
 }
 
 // NewPlayer creates a new player from stream. This is a very long operation, as it saves replies in storage until the
@@ -136,6 +146,18 @@ func (mb *MessageBus) MustRegister(p core.MessageType, handler core.MessageHandl
 	}
 }
 
+// AddSendMiddleware appends middleware to the send chain. If types are provided, middleware is applied only to
+// messages of these types.
+func (mb *MessageBus) AddSendMiddleware(middleware core.Middleware, types ...core.MessageType) {
+	mb.sendMiddlewares.Add(middleware, types...)
+}
+
+// AddDeliverMiddleware appends middleware to the deliver chain. If types are provided, middleware is applied only to
+// messages of these types.
+func (mb *MessageBus) AddDeliverMiddleware(middleware core.Middleware, types ...core.MessageType) {
+	mb.deliverMiddlewares.Add(middleware, types...)
+}
+
 // Send an `Message` and get a `Value` or error from remote host.
 func (mb *MessageBus) Send(ctx context.Context, msg core.Message, optionSetter ...core.SendOption) (core.Reply, error) {
 	var options *core.SendOptions
@@ -160,7 +182,30 @@ func (mb *MessageBus) CreateParcel(ctx context.Context, msg core.Message, option
 }
 
 // SendParcel sends provided message via network.
+//
+// Send middlewares are called before sending. Redirect replies are followed until a non-redirect reply is received or
+// the redirect limit is exceeded.
+//
+// If quorum option is set and message has several recipients, replies of all recipients are returned as reply.Multi.
 func (mb *MessageBus) SendParcel(ctx context.Context, msg core.Parcel, options *core.SendOptions) (core.Reply, error) {
+	send := func(ctx context.Context, msg core.Parcel) (core.Reply, error) {
+		rep, err := mb.sendParcel(ctx, msg, options)
+		if err != nil {
+			// Aggregated reply is returned along with quorum error.
+			return rep, err
+		}
+		if redirect, ok := rep.(core.RedirectReply); ok {
+			return mb.followRedirects(ctx, msg.Message(), options, redirect)
+		}
+		return rep, nil
+	}
+	return mb.sendMiddlewares.Wrap(msg.Type(), send)(ctx, msg)
+}
+
+func (mb *MessageBus) sendParcel(ctx context.Context, msg core.Parcel, options *core.SendOptions) (core.Reply, error) {
+	ctx, cancel := contextWithDeadline(ctx, msg.GetDeadline())
+	defer cancel()
+
 	scope := newReaderScope(&mb.globalLock)
 	scope.Lock()
 	defer scope.Unlock()
@@ -184,6 +229,11 @@ func (mb *MessageBus) SendParcel(ctx context.Context, msg core.Parcel, options *
 	}
 
 	if len(nodes) > 1 {
+		if options != nil && options.Quorum != core.QuorumNone {
+			// Replies are collected without blocking pulse change.
+			scope.Unlock()
+			return mb.sendToAll(ctx, msg, nodes, options.Quorum)
+		}
 		cascade := core.Cascade{
 			NodeIds:           nodes,
 			Entropy:           pulse.Entropy,
@@ -193,18 +243,31 @@ func (mb *MessageBus) SendParcel(ctx context.Context, msg core.Parcel, options *
 		return nil, err
 	}
 
-	// Short path when sending to self node. Skip serialization
-	if nodes[0].Equal(mb.Service.GetNodeID()) {
-		return mb.doDeliver(msg.Context(context.Background()), msg)
-	}
-
-	res, err := mb.Service.SendMessage(nodes[0], deliverRPCMethodName, msg)
+	res, err := awaitReply(ctx, msg, func(ctx context.Context) (core.Reply, error) {
+		return mb.sendToNode(ctx, msg, nodes[0])
+	})
 	if err != nil {
 		return nil, err
 	}
 
 	scope.Unlock()
 
+	return res, nil
+}
+
+// sendToNode sends message to provided node and returns its reply.
+//
+// Local handler receives provided context. Network request to other nodes is not waited for after message deadline.
+func (mb *MessageBus) sendToNode(ctx context.Context, msg core.Parcel, node core.RecordRef) (core.Reply, error) {
+	// Short path when sending to self node. Skip serialization
+	if node.Equal(mb.Service.GetNodeID()) {
+		return mb.doDeliver(msg.Context(ctx), msg)
+	}
+
+	res, err := mb.Service.SendMessage(node, deliverRPCMethodName, msg)
+	if err != nil {
+		return nil, err
+	}
 	return reply.Deserialize(bytes.NewBuffer(res))
 }
 
@@ -222,8 +285,7 @@ func (mb *MessageBus) doDeliver(ctx context.Context, msg core.Parcel) (core.Repl
 		return nil, errors.New("no handler for received message type")
 	}
 
-	ctx = hack.SetSkipValidation(ctx, true)
-	resp, err := handler(ctx, msg)
+	resp, err := mb.deliverMiddlewares.Wrap(msg.Type(), handler)(ctx, msg)
 	if err != nil {
 		return nil, &serializableError{
 			S: err.Error(),
@@ -258,7 +320,11 @@ func (mb *MessageBus) deliver(args [][]byte) (result []byte, err error) {
 		}
 	}
 
-	ctx := parcel.Context(context.Background())
+	ctx, cancel := contextWithDeadline(parcel.Context(context.Background()), parcel.GetDeadline())
+	defer cancel()
+	if ctx.Err() != nil {
+		return nil, errors.Wrapf(core.ErrDeadlineExceeded, "message %s received after deadline", parcel.Type())
+	}
 
 	if parcel.DelegationToken() != nil {
 		valid, err := mb.DelegationTokenFactory.Verify(parcel)
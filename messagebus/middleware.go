@@ -0,0 +1,73 @@
+/*
+ *    Copyright 2018 Insolar
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ */
+
+package messagebus
+
+import (
+	"context"
+
+	"github.com/insolar/insolar/core"
+	"github.com/insolar/insolar/instrumentation/hack"
+)
+
+type middleware struct {
+	wrap  core.Middleware
+	types map[core.MessageType]struct{}
+}
+
+func (m *middleware) appliesTo(t core.MessageType) bool {
+	if m.types == nil {
+		return true
+	}
+	_, ok := m.types[t]
+	return ok
+}
+
+// Middlewares is an ordered chain of middlewares. Every middleware is applied either to all message types or to
+// the provided ones only.
+type Middlewares struct {
+	list []middleware
+}
+
+// Add appends middleware to the chain. If types are provided, middleware is applied only to messages of these types.
+func (m *Middlewares) Add(wrap core.Middleware, types ...core.MessageType) {
+	mw := middleware{wrap: wrap}
+	if len(types) > 0 {
+		mw.types = make(map[core.MessageType]struct{}, len(types))
+		for _, t := range types {
+			mw.types[t] = struct{}{}
+		}
+	}
+	m.list = append(m.list, mw)
+}
+
+// Wrap returns handler wrapped with middlewares applied to provided message type. The first added middleware is
+// the outermost one, so it is called first.
+func (m *Middlewares) Wrap(t core.MessageType, handler core.MessageHandler) core.MessageHandler {
+	for i := len(m.list) - 1; i >= 0; i-- {
+		if m.list[i].appliesTo(t) {
+			handler = m.list[i].wrap(handler)
+		}
+	}
+	return handler
+}
+
+// skipValidation marks delivered messages to skip validation.
+func skipValidation(next core.MessageHandler) core.MessageHandler {
+	return func(ctx context.Context, parcel core.Parcel) (core.Reply, error) {
+		return next(hack.SetSkipValidation(ctx, true), parcel)
+	}
+}
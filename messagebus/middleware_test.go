@@ -0,0 +1,110 @@
+/*
+ *    Copyright 2018 Insolar
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ */
+
+package messagebus
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/insolar/insolar/configuration"
+	"github.com/insolar/insolar/core"
+	"github.com/insolar/insolar/core/message"
+	"github.com/insolar/insolar/core/reply"
+	"github.com/insolar/insolar/instrumentation/hack"
+	"github.com/insolar/insolar/instrumentation/inslogger"
+)
+
+func recordingMiddleware(name string, calls *[]string) core.Middleware {
+	return func(next core.MessageHandler) core.MessageHandler {
+		return func(ctx context.Context, parcel core.Parcel) (core.Reply, error) {
+			*calls = append(*calls, name)
+			return next(ctx, parcel)
+		}
+	}
+}
+
+func TestMiddlewares_Wrap(t *testing.T) {
+	ctx := inslogger.TestContext(t)
+	var calls []string
+	handler := func(ctx context.Context, parcel core.Parcel) (core.Reply, error) {
+		calls = append(calls, "handler")
+		return &reply.OK{}, nil
+	}
+
+	var mw Middlewares
+	mw.Add(recordingMiddleware("global", &calls))
+	mw.Add(recordingMiddleware("get_code", &calls), core.TypeGetCode)
+	mw.Add(recordingMiddleware("get_object", &calls), core.TypeGetObject, core.TypeGetChildren)
+
+	_, err := mw.Wrap(core.TypeGetObject, handler)(ctx, &message.Parcel{Msg: &message.GetObject{}})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"global", "get_object", "handler"}, calls)
+
+	calls = nil
+	_, err = mw.Wrap(core.TypeGetCode, handler)(ctx, &message.Parcel{Msg: &message.GetCode{}})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"global", "get_code", "handler"}, calls)
+
+	t.Run("short-circuits the chain", func(t *testing.T) {
+		calls = nil
+		mw.Add(func(next core.MessageHandler) core.MessageHandler {
+			return func(ctx context.Context, parcel core.Parcel) (core.Reply, error) {
+				return &reply.Error{ErrType: reply.ErrDeactivated}, nil
+			}
+		}, core.TypeGetCode)
+
+		rep, err := mw.Wrap(core.TypeGetCode, handler)(ctx, &message.Parcel{Msg: &message.GetCode{}})
+		require.NoError(t, err)
+		assert.Equal(t, &reply.Error{ErrType: reply.ErrDeactivated}, rep)
+		assert.Equal(t, []string{"global", "get_code"}, calls)
+	})
+}
+
+func TestMessageBus_Middlewares(t *testing.T) {
+	ctx := inslogger.TestContext(t)
+	mb, err := NewMessageBus(configuration.NewConfiguration())
+	require.NoError(t, err)
+
+	var calls []string
+	mb.MustRegister(core.TypeGetCode, func(ctx context.Context, parcel core.Parcel) (core.Reply, error) {
+		calls = append(calls, "handler")
+		assert.True(t, hack.SkipValidation(ctx))
+		return &reply.OK{}, nil
+	})
+	mb.AddDeliverMiddleware(recordingMiddleware("deliver", &calls), core.TypeGetCode)
+	mb.AddDeliverMiddleware(recordingMiddleware("other", &calls), core.TypeGetObject)
+
+	rep, err := mb.doDeliver(ctx, &message.Parcel{Msg: &message.GetCode{}})
+	require.NoError(t, err)
+	assert.Equal(t, &reply.OK{}, rep)
+	assert.Equal(t, []string{"deliver", "handler"}, calls)
+
+	t.Run("send middleware can reply without sending", func(t *testing.T) {
+		mb.AddSendMiddleware(func(next core.MessageHandler) core.MessageHandler {
+			return func(ctx context.Context, parcel core.Parcel) (core.Reply, error) {
+				return &reply.OK{}, nil
+			}
+		})
+
+		rep, err := mb.SendParcel(ctx, &message.Parcel{Msg: &message.GetCode{}}, nil)
+		require.NoError(t, err)
+		assert.Equal(t, &reply.OK{}, rep)
+	})
+}
@@ -0,0 +1,106 @@
+/*
+ *    Copyright 2018 Insolar
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ */
+
+package messagebus
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/insolar/insolar/core"
+	"github.com/insolar/insolar/core/reply"
+)
+
+// defaultQuorumTimeout limits collecting of replies for messages without deadline.
+const defaultQuorumTimeout = 10 * time.Second
+
+// sendToAll sends message to every node and collects their replies into reply.Multi.
+//
+// Replies are collected until quorum is reached, quorum can't be reached anymore or message deadline passes. Messages
+// without deadline are waited for defaultQuorumTimeout. Nodes not replied by then are considered failed and their
+// handling is cancelled. If quorum is not reached, ErrQuorumNotReached is returned along with collected replies.
+func (mb *MessageBus) sendToAll(
+	ctx context.Context, msg core.Parcel, nodes []core.RecordRef, quorum core.Quorum,
+) (core.Reply, error) {
+	var cancel context.CancelFunc
+	if _, ok := ctx.Deadline(); ok {
+		ctx, cancel = context.WithCancel(ctx)
+	} else {
+		ctx, cancel = context.WithTimeout(ctx, defaultQuorumTimeout)
+	}
+	defer cancel()
+
+	type nodeResult struct {
+		index int
+		rep   core.Reply
+		err   error
+	}
+	results := make(chan nodeResult, len(nodes))
+	for i, node := range nodes {
+		go func(i int, node core.RecordRef) {
+			rep, err := mb.sendToNode(ctx, msg, node)
+			results <- nodeResult{index: i, rep: rep, err: err}
+		}(i, node)
+	}
+
+	multi := &reply.Multi{Replies: make([]reply.NodeReply, len(nodes))}
+	received := make([]bool, len(nodes))
+	for i, node := range nodes {
+		multi.Replies[i].Node = node
+	}
+	notReceived := func(reason string) {
+		for i := range received {
+			if !received[i] {
+				multi.Replies[i].Error = reason
+			}
+		}
+	}
+
+	required := quorum.Required(len(nodes))
+	succeeded := 0
+collect:
+	for pending := len(nodes); pending > 0; pending-- {
+		if succeeded >= required || succeeded+pending < required {
+			notReceived("reply is not awaited, quorum is decided")
+			break
+		}
+		select {
+		case res := <-results:
+			received[res.index] = true
+			if res.err != nil {
+				multi.Replies[res.index].Error = res.err.Error()
+				continue
+			}
+			multi.Replies[res.index].Reply = res.rep
+			if multi.Replies[res.index].Succeeded() {
+				succeeded++
+			}
+		case <-ctx.Done():
+			notReceived(deadlineError(ctx, msg).Error())
+			break collect
+		}
+	}
+
+	if succeeded < required {
+		return multi, errors.Wrapf(
+			core.ErrQuorumNotReached, "message %s handled by %d of %d nodes, %d required",
+			msg.Type(), succeeded, len(nodes), required,
+		)
+	}
+	return multi, nil
+}
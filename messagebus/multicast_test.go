@@ -0,0 +1,136 @@
+/*
+ *    Copyright 2018 Insolar
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ */
+
+package messagebus
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/insolar/insolar/configuration"
+	"github.com/insolar/insolar/core"
+	"github.com/insolar/insolar/core/message"
+	"github.com/insolar/insolar/core/reply"
+	"github.com/insolar/insolar/instrumentation/inslogger"
+	"github.com/insolar/insolar/instrumentation/instracer"
+	"github.com/insolar/insolar/testutils"
+)
+
+// testPartialNetwork replies for known nodes and blocks for others until released.
+type testPartialNetwork struct {
+	core.Network
+	replies map[core.RecordRef]core.Reply
+	release chan struct{}
+}
+
+func (n *testPartialNetwork) GetNodeID() core.RecordRef {
+	return core.RecordRef{}
+}
+
+func (n *testPartialNetwork) SendMessage(nodeID core.RecordRef, method string, msg core.Parcel) ([]byte, error) {
+	rep, ok := n.replies[nodeID]
+	if !ok {
+		<-n.release
+		return nil, errors.New("released")
+	}
+	rd, err := reply.Serialize(rep)
+	if err != nil {
+		return nil, err
+	}
+	buf := new(bytes.Buffer)
+	_, err = buf.ReadFrom(rd)
+	return buf.Bytes(), err
+}
+
+func TestMessageBus_SendToAll(t *testing.T) {
+	ctx := inslogger.TestContext(t)
+	succeeded := testutils.RandomRef()
+	rejected := testutils.RandomRef()
+	unavailable := testutils.RandomRef()
+	nodes := []core.RecordRef{succeeded, rejected, unavailable}
+
+	mb, err := NewMessageBus(configuration.NewConfiguration())
+	require.NoError(t, err)
+	mb.Service = &testRedirectNetwork{
+		nodeID: testutils.RandomRef(),
+		replies: map[core.RecordRef]core.Reply{
+			succeeded: &reply.OK{},
+			rejected:  &reply.Error{ErrType: reply.ErrStateRejected},
+		},
+		parcels: map[core.RecordRef]core.Parcel{},
+	}
+	parcel := &message.Parcel{Msg: &message.ValidateRecord{}, TraceSpanData: instracer.MustSerialize(ctx)}
+
+	rep, err := mb.sendToAll(ctx, parcel, nodes, core.QuorumAny)
+	require.NoError(t, err)
+	multi, ok := rep.(*reply.Multi)
+	require.True(t, ok)
+	require.Len(t, multi.Replies, 3)
+	assert.Equal(t, &reply.OK{}, multi.Replies[0].Reply)
+	assert.Equal(t, []core.RecordRef{succeeded}, multi.Succeeded())
+	assert.Equal(t, []core.RecordRef{rejected, unavailable}, multi.Failed())
+	assert.NotEmpty(t, multi.Replies[2].Error)
+
+	rep, err = mb.sendToAll(ctx, parcel, nodes, core.QuorumMajority)
+	require.Error(t, err)
+	assert.Equal(t, core.ErrQuorumNotReached, errors.Cause(err))
+	assert.Equal(t, []core.RecordRef{succeeded}, rep.(*reply.Multi).Succeeded())
+
+	t.Run("nodes not replied before deadline are failed", func(t *testing.T) {
+		network := &testBlockingNetwork{nodeID: testutils.RandomRef(), release: make(chan struct{})}
+		defer close(network.release)
+		mb.Service = network
+
+		deadline := time.Now().Add(10 * time.Millisecond)
+		ctx, cancel := context.WithDeadline(ctx, deadline)
+		defer cancel()
+		parcel := &message.Parcel{Msg: &message.ValidateRecord{}, Deadline: deadline}
+
+		rep, err := mb.sendToAll(ctx, parcel, nodes, core.QuorumAny)
+		require.Error(t, err)
+		assert.Equal(t, core.ErrQuorumNotReached, errors.Cause(err))
+		assert.Equal(t, nodes, rep.(*reply.Multi).Failed())
+	})
+
+	t.Run("returns once quorum is decided", func(t *testing.T) {
+		network := &testPartialNetwork{
+			replies: map[core.RecordRef]core.Reply{
+				succeeded: &reply.OK{},
+				rejected:  &reply.Error{ErrType: reply.ErrStateRejected},
+			},
+			release: make(chan struct{}),
+		}
+		defer close(network.release)
+		mb.Service = network
+		parcel := &message.Parcel{Msg: &message.ValidateRecord{}}
+
+		rep, err := mb.sendToAll(ctx, parcel, nodes, core.QuorumAny)
+		require.NoError(t, err)
+		assert.Equal(t, []core.RecordRef{succeeded}, rep.(*reply.Multi).Succeeded())
+		assert.NotEmpty(t, rep.(*reply.Multi).Replies[2].Error)
+
+		rep, err = mb.sendToAll(ctx, parcel, nodes, core.QuorumAll)
+		require.Error(t, err)
+		assert.Equal(t, core.ErrQuorumNotReached, errors.Cause(err))
+		assert.Contains(t, rep.(*reply.Multi).Failed(), unavailable)
+	})
+}
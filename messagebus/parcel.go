@@ -19,6 +19,7 @@ package messagebus
 import (
 	"context"
 	"crypto"
+	"time"
 
 	"github.com/insolar/insolar/core"
 	"github.com/insolar/insolar/core/message"
@@ -47,29 +48,49 @@ func (pf *parcelFactory) Create(
 		return nil, errors.New("failed to signature a nil message")
 	}
 
-	var token core.DelegationToken
+	var (
+		token    core.DelegationToken
+		deadline time.Time
+	)
 	if sendOptions != nil {
 		token = sendOptions.Token
+		deadline = sendOptions.Deadline
 	}
 
-	serialized := message.ToBytes(msg)
-	signature, err := pf.Cryptography.Sign(serialized)
-	if err != nil {
-		return nil, err
-	}
-
-	return &message.Parcel{
+	parcel := &message.Parcel{
 		Msg:           msg,
-		Signature:     signature.Bytes(),
 		LogTraceID:    inslogger.TraceID(ctx),
 		TraceSpanData: instracer.MustSerialize(ctx),
 		Sender:        sender,
 		Token:         token,
-	}, nil
+		Deadline:      deadline,
+	}
+	signedData, err := parcel.SignedData()
+	if err != nil {
+		return nil, err
+	}
+	signature, err := pf.Cryptography.Sign(signedData)
+	if err != nil {
+		return nil, err
+	}
+	parcel.Signature = signature.Bytes()
+
+	return parcel, nil
 }
 
 func (pf *parcelFactory) Validate(publicKey crypto.PublicKey, parcel core.Parcel) error {
-	ok := pf.Cryptography.Verify(publicKey, core.SignatureFromBytes(parcel.GetSign()), message.ToBytes(parcel.Message()))
+	signed, ok := parcel.(*message.Parcel)
+	if !ok {
+		return errors.Errorf("can't validate parcel of type %T", parcel)
+	}
+	if signed.Msg == nil {
+		return errors.New("parcel has no message")
+	}
+	signedData, err := signed.SignedData()
+	if err != nil {
+		return err
+	}
+	ok = pf.Cryptography.Verify(publicKey, core.SignatureFromBytes(signed.Signature), signedData)
 	if !ok {
 		return errors.New("parcel isn't valid")
 	}
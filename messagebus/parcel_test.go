@@ -0,0 +1,143 @@
+/*
+ *    Copyright 2018 Insolar
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ */
+
+package messagebus
+
+import (
+	"bytes"
+	"crypto"
+	"io/ioutil"
+	"testing"
+	"time"
+
+	"github.com/gojuno/minimock"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/insolar/insolar/configuration"
+	"github.com/insolar/insolar/core"
+	"github.com/insolar/insolar/core/message"
+	"github.com/insolar/insolar/core/reply"
+	"github.com/insolar/insolar/core/wire"
+	"github.com/insolar/insolar/instrumentation/inslogger"
+	"github.com/insolar/insolar/testutils"
+	"github.com/insolar/insolar/version/manager"
+)
+
+func TestParcelFactory_Validate(t *testing.T) {
+	ctx := inslogger.TestContext(t)
+	// Signature is the signed data itself.
+	cs := testutils.NewCryptographyServiceMock(t)
+	cs.SignFunc = func(data []byte) (*core.Signature, error) {
+		sign := core.SignatureFromBytes(data)
+		return &sign, nil
+	}
+	cs.VerifyFunc = func(key crypto.PublicKey, sign core.Signature, data []byte) bool {
+		return bytes.Equal(sign.Bytes(), data)
+	}
+	pf := &parcelFactory{Cryptography: cs}
+
+	parcel, err := pf.Create(
+		ctx,
+		&message.GetObject{Head: testutils.RandomRef()},
+		testutils.RandomRef(),
+		&core.SendOptions{Deadline: time.Now().Add(time.Minute)},
+	)
+	require.NoError(t, err)
+	receive := func(t *testing.T, parcel core.Parcel) core.Parcel {
+		buf, err := message.ParcelToBytes(parcel)
+		require.NoError(t, err)
+		received, err := message.DeserializeParcel(bytes.NewBuffer(buf))
+		require.NoError(t, err)
+		return received
+	}
+	assert.NoError(t, pf.Validate(nil, receive(t, parcel)))
+
+	t.Run("deadline is signed", func(t *testing.T) {
+		received := receive(t, parcel)
+		received.(*message.Parcel).Deadline = received.GetDeadline().Add(time.Hour)
+		assert.Error(t, pf.Validate(nil, received))
+	})
+
+	t.Run("message is signed", func(t *testing.T) {
+		tampered := *parcel.(*message.Parcel)
+		tampered.Msg = &message.GetObject{Head: testutils.RandomRef()}
+		assert.Error(t, pf.Validate(nil, receive(t, &tampered)))
+	})
+}
+
+// testSerializingNetwork encodes sent parcels as network does.
+type testSerializingNetwork struct {
+	core.Network
+	nodeID core.RecordRef
+}
+
+func (n *testSerializingNetwork) GetNodeID() core.RecordRef {
+	return n.nodeID
+}
+
+func (n *testSerializingNetwork) SendMessage(nodeID core.RecordRef, method string, msg core.Parcel) ([]byte, error) {
+	if _, err := message.ParcelToBytes(msg); err != nil {
+		return nil, err
+	}
+	rep, err := reply.Serialize(&reply.OK{})
+	if err != nil {
+		return nil, err
+	}
+	return ioutil.ReadAll(rep)
+}
+
+func TestMessageBus_Send_GateClosed(t *testing.T) {
+	mc := minimock.NewController(t)
+	defer mc.Finish()
+
+	ctx := inslogger.TestContext(t)
+	vm, err := manager.NewVersionManager(configuration.NewVersionManager())
+	require.NoError(t, err)
+	wire.SetGate(vm)
+	defer wire.SetGate(nil)
+
+	pm := testutils.NewPulseManagerMock(mc)
+	pm.CurrentMock.Return(&core.Pulse{PulseNumber: core.FirstPulseNumber}, nil)
+	cs := testutils.NewCryptographyServiceMock(mc)
+	cs.SignFunc = func(data []byte) (*core.Signature, error) {
+		sign := core.SignatureFromBytes(data)
+		return &sign, nil
+	}
+
+	mb, err := NewMessageBus(configuration.NewConfiguration())
+	require.NoError(t, err)
+	mb.Service = &testSerializingNetwork{nodeID: testutils.RandomRef()}
+	mb.Ledger = &testRedirectLedger{pm: pm}
+	mb.ParcelFactory = &parcelFactory{Cryptography: cs}
+
+	// Fields of versions not allowed by the gate fail sending instead of the node.
+	remote := testutils.RandomRef()
+	_, err = mb.Send(ctx, &message.GetChildren{Count: true}, core.SendOptionDestination(&remote))
+	assert.Equal(t, wire.ErrVersionNotAllowed, errors.Cause(err))
+	_, err = mb.Send(
+		ctx,
+		&message.GetChildren{Amount: 1},
+		core.SendOptionDestination(&remote),
+		core.SendOptionDeadline(time.Now().Add(time.Minute)),
+	)
+	assert.Equal(t, wire.ErrVersionNotAllowed, errors.Cause(err))
+
+	rep, err := mb.Send(ctx, &message.GetChildren{Amount: 1}, core.SendOptionDestination(&remote))
+	require.NoError(t, err)
+	assert.Equal(t, &reply.OK{}, rep)
+}
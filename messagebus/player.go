@@ -61,7 +61,10 @@ func (r *player) Send(ctx context.Context, msg core.Message, optionSetter ...cor
 	if err != nil{
 		return nil, err
 	}
-	id := GetMessageHash(r.scheme, parcel)
+	id, err := GetMessageHash(r.scheme, parcel)
+	if err != nil {
+		return nil, err
+	}
 
 	// Value from storageTape.
 	rep, err = r.tape.GetReply(ctx, id)
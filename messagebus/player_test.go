@@ -40,7 +40,8 @@ func TestPlayer_Send(t *testing.T) {
 	ctx := inslogger.TestContext(t)
 	msg := message.GenesisRequest{Name: "test"}
 	parcel := message.Parcel{Msg: &msg}
-	msgHash := GetMessageHash(pcs, &parcel)
+	msgHash, err := GetMessageHash(pcs, &parcel)
+	require.NoError(t, err)
 	pm := testutils.NewPulseManagerMock(mc)
 	s := NewsenderMock(mc)
 	s.CreateParcelFunc = func(p context.Context, p2 core.Message, p3 *core.SendOptions) (r core.Parcel, r1 error) {
@@ -62,7 +62,10 @@ func (r *recorder) Send(ctx context.Context, msg core.Message, optionSetter ...c
 	if err != nil{
 		return nil, err
 	}
-	id := GetMessageHash(r.scheme, parcel)
+	id, err := GetMessageHash(r.scheme, parcel)
+	if err != nil {
+		return nil, err
+	}
 
 	// Check if Value for this message is already stored.
 	rep, err = r.tape.GetReply(ctx, id)
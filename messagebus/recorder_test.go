@@ -39,7 +39,8 @@ func TestRecorder_Send(t *testing.T) {
 	ctx := inslogger.TestContext(t)
 	msg := message.GenesisRequest{Name: "test"}
 	parcel := message.Parcel{Msg: &msg}
-	msgHash := GetMessageHash(pcs, &parcel)
+	msgHash, err := GetMessageHash(pcs, &parcel)
+	require.NoError(t, err)
 	expectedRep := reply.Object{Memory: []byte{1, 2, 3}}
 	pm := testutils.NewPulseManagerMock(mc)
 	s := NewsenderMock(mc)
@@ -0,0 +1,88 @@
+/*
+ *    Copyright 2018 Insolar
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ */
+
+package messagebus
+
+import (
+	"context"
+	"strings"
+
+	"github.com/pkg/errors"
+	"go.opencensus.io/trace"
+
+	"github.com/insolar/insolar/core"
+	"github.com/insolar/insolar/instrumentation/inslogger"
+	"github.com/insolar/insolar/instrumentation/instracer"
+)
+
+// followRedirects resends message to redirect destinations until a non-redirect reply is received.
+//
+// Every hop is sent with the delegation token provided by the redirect. Passed nodes are recorded in the trace span,
+// so remote handlers of the redirected messages are traced as its children.
+func (mb *MessageBus) followRedirects(
+	ctx context.Context, msg core.Message, options *core.SendOptions, redirect core.RedirectReply,
+) (core.Reply, error) {
+	ctx, span := instracer.StartSpan(ctx, "MessageBus.followRedirects")
+	span.AddAttributes(trace.StringAttribute("message_type", msg.Type().String()))
+	defer span.End()
+
+	var hops []string
+	for {
+		if len(hops) >= mb.maxRedirects {
+			return nil, errors.Wrapf(
+				ErrTooManyRedirects, "message %s redirected via %s", msg.Type(), strings.Join(hops, " -> "),
+			)
+		}
+		receiver := redirect.GetReceiver()
+		if receiver == nil {
+			return nil, errors.Errorf("redirect for message %s has no receiver", msg.Type())
+		}
+		hops = append(hops, receiver.String())
+		span.Annotate([]trace.Attribute{
+			trace.Int64Attribute("hop", int64(len(hops))),
+			trace.StringAttribute("receiver", receiver.String()),
+		}, "redirect")
+
+		redirected, err := redirect.Redirected(msg)
+		if err != nil {
+			return nil, err
+		}
+		hopOptions := core.SendOptions{}
+		if options != nil {
+			hopOptions = *options
+		}
+		hopOptions.Receiver = receiver
+		hopOptions.Token = redirect.GetToken()
+
+		parcel, err := mb.CreateParcel(ctx, redirected, &hopOptions)
+		if err != nil {
+			return nil, err
+		}
+		rep, err := mb.sendParcel(ctx, parcel, &hopOptions)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to send redirected message to %s", receiver)
+		}
+
+		var ok bool
+		redirect, ok = rep.(core.RedirectReply)
+		if !ok {
+			span.AddAttributes(trace.StringAttribute("redirect_chain", strings.Join(hops, " -> ")))
+			inslogger.FromContext(ctx).Debugf("message %s redirected via %s", msg.Type(), strings.Join(hops, " -> "))
+			return rep, nil
+		}
+		msg = redirected
+	}
+}
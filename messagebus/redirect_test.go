@@ -0,0 +1,148 @@
+/*
+ *    Copyright 2018 Insolar
+ *
+ *    Licensed under the Apache License, Version 2.0 (the "License");
+ *    you may not use this file except in compliance with the License.
+ *    You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *    Unless required by applicable law or agreed to in writing, software
+ *    distributed under the License is distributed on an "AS IS" BASIS,
+ *    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *    See the License for the specific language governing permissions and
+ *    limitations under the License.
+ */
+
+package messagebus
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/gojuno/minimock"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/insolar/insolar/configuration"
+	"github.com/insolar/insolar/core"
+	"github.com/insolar/insolar/core/delegationtoken"
+	"github.com/insolar/insolar/core/message"
+	"github.com/insolar/insolar/core/reply"
+	"github.com/insolar/insolar/instrumentation/inslogger"
+	"github.com/insolar/insolar/instrumentation/instracer"
+	"github.com/insolar/insolar/testutils"
+)
+
+type testRedirectNetwork struct {
+	core.Network
+	nodeID  core.RecordRef
+	replies map[core.RecordRef]core.Reply
+	parcels map[core.RecordRef]core.Parcel
+}
+
+func (n *testRedirectNetwork) GetNodeID() core.RecordRef {
+	return n.nodeID
+}
+
+func (n *testRedirectNetwork) SendMessage(nodeID core.RecordRef, method string, msg core.Parcel) ([]byte, error) {
+	n.parcels[nodeID] = msg
+	rep, ok := n.replies[nodeID]
+	if !ok {
+		return nil, errors.New("unknown node")
+	}
+	rd, err := reply.Serialize(rep)
+	if err != nil {
+		return nil, err
+	}
+	buf := new(bytes.Buffer)
+	_, err = buf.ReadFrom(rd)
+	return buf.Bytes(), err
+}
+
+type testRedirectLedger struct {
+	core.Ledger
+	pm core.PulseManager
+}
+
+func (l *testRedirectLedger) GetPulseManager() core.PulseManager {
+	return l.pm
+}
+
+type testParcelFactory struct {
+	message.ParcelFactory
+}
+
+func (*testParcelFactory) Create(
+	ctx context.Context, msg core.Message, sender core.RecordRef, options *core.SendOptions,
+) (core.Parcel, error) {
+	parcel := &message.Parcel{Msg: msg, Sender: sender, TraceSpanData: instracer.MustSerialize(ctx)}
+	if options != nil {
+		parcel.Token = options.Token
+		parcel.Deadline = options.Deadline
+	}
+	return parcel, nil
+}
+
+func TestMessageBus_Send_FollowsRedirects(t *testing.T) {
+	mc := minimock.NewController(t)
+	defer mc.Finish()
+
+	ctx := inslogger.TestContext(t)
+	first := testutils.RandomRef()
+	second := testutils.RandomRef()
+	state := testutils.RandomID()
+	token := &delegationtoken.GetObjectRedirect{Signature: []byte{1, 2, 3}}
+
+	pm := testutils.NewPulseManagerMock(mc)
+	pm.CurrentMock.Return(&core.Pulse{PulseNumber: core.FirstPulseNumber}, nil)
+	network := &testRedirectNetwork{
+		nodeID: testutils.RandomRef(),
+		replies: map[core.RecordRef]core.Reply{
+			first:  &reply.GetObjectRedirectReply{To: &second, StateID: &state, Token: token},
+			second: &reply.Object{Memory: []byte{4, 5, 6}},
+		},
+		parcels: map[core.RecordRef]core.Parcel{},
+	}
+
+	cfg := configuration.NewConfiguration()
+	cfg.MessageBus.MaxRedirects = 1
+	mb, err := NewMessageBus(cfg)
+	require.NoError(t, err)
+	mb.Service = network
+	mb.Ledger = &testRedirectLedger{pm: pm}
+	mb.ParcelFactory = &testParcelFactory{}
+
+	head := testutils.RandomRef()
+	rep, err := mb.Send(ctx, &message.GetObject{Head: head}, core.SendOptionDestination(&first))
+	require.NoError(t, err)
+	assert.Equal(t, &reply.Object{Memory: []byte{4, 5, 6}}, rep)
+
+	redirected := network.parcels[second]
+	require.NotNil(t, redirected)
+	assert.Equal(t, token, redirected.DelegationToken())
+	assert.Equal(t, &message.GetObject{Head: head, State: &state}, redirected.Message())
+
+	t.Run("fails when redirect limit is exceeded", func(t *testing.T) {
+		network.replies[second] = &reply.GetObjectRedirectReply{To: &first, StateID: &state}
+
+		_, err := mb.Send(ctx, &message.GetObject{Head: head}, core.SendOptionDestination(&first))
+		require.Error(t, err)
+		assert.Equal(t, ErrTooManyRedirects, errors.Cause(err))
+	})
+
+	t.Run("fails when redirect does not match the message", func(t *testing.T) {
+		network.replies[first] = &reply.GetCodeRedirect{To: &second}
+
+		_, err := mb.Send(ctx, &message.GetObject{Head: head}, core.SendOptionDestination(&first))
+		require.Error(t, err)
+	})
+}
+
+func TestNewMessageBus_DefaultMaxRedirects(t *testing.T) {
+	mb, err := NewMessageBus(configuration.Configuration{})
+	require.NoError(t, err)
+	assert.Equal(t, configuration.NewMessageBus().MaxRedirects, mb.maxRedirects)
+}
@@ -31,6 +31,16 @@ type senderMock struct {
 	MustRegisterPreCounter uint64
 	MustRegisterMock       msenderMockMustRegister
 
+	AddDeliverMiddlewareFunc       func(p core.Middleware, p1 ...core.MessageType)
+	AddDeliverMiddlewareCounter    uint64
+	AddDeliverMiddlewarePreCounter uint64
+	AddDeliverMiddlewareMock       msenderMockAddDeliverMiddleware
+
+	AddSendMiddlewareFunc       func(p core.Middleware, p1 ...core.MessageType)
+	AddSendMiddlewareCounter    uint64
+	AddSendMiddlewarePreCounter uint64
+	AddSendMiddlewareMock       msenderMockAddSendMiddleware
+
 	NewPlayerFunc       func(p context.Context, p1 io.Reader) (r core.MessageBus, r1 error)
 	NewPlayerCounter    uint64
 	NewPlayerPreCounter uint64
@@ -72,6 +82,8 @@ func NewsenderMock(t minimock.Tester) *senderMock {
 
 	m.CreateParcelMock = msenderMockCreateParcel{mock: m}
 	m.MustRegisterMock = msenderMockMustRegister{mock: m}
+	m.AddDeliverMiddlewareMock = msenderMockAddDeliverMiddleware{mock: m}
+	m.AddSendMiddlewareMock = msenderMockAddSendMiddleware{mock: m}
 	m.NewPlayerMock = msenderMockNewPlayer{mock: m}
 	m.NewRecorderMock = msenderMockNewRecorder{mock: m}
 	m.RegisterMock = msenderMockRegister{mock: m}
@@ -217,6 +229,140 @@ func (m *senderMock) MustRegisterMinimockPreCounter() uint64 {
 	return atomic.LoadUint64(&m.MustRegisterPreCounter)
 }
 
+type msenderMockAddDeliverMiddleware struct {
+	mock             *senderMock
+	mockExpectations *senderMockAddDeliverMiddlewareParams
+}
+
+//senderMockAddDeliverMiddlewareParams represents input parameters of the sender.AddDeliverMiddleware
+type senderMockAddDeliverMiddlewareParams struct {
+	p  core.Middleware
+	p1 []core.MessageType
+}
+
+//Expect sets up expected params for the sender.AddDeliverMiddleware
+func (m *msenderMockAddDeliverMiddleware) Expect(p core.Middleware, p1 ...core.MessageType) *msenderMockAddDeliverMiddleware {
+	m.mockExpectations = &senderMockAddDeliverMiddlewareParams{p, p1}
+	return m
+}
+
+//Return sets up a mock for sender.AddDeliverMiddleware to return Return's arguments
+func (m *msenderMockAddDeliverMiddleware) Return() *senderMock {
+	m.mock.AddDeliverMiddlewareFunc = func(p core.Middleware, p1 ...core.MessageType) {
+		return
+	}
+	return m.mock
+}
+
+//Set uses given function f as a mock of sender.AddDeliverMiddleware method
+func (m *msenderMockAddDeliverMiddleware) Set(f func(p core.Middleware, p1 ...core.MessageType)) *senderMock {
+	m.mock.AddDeliverMiddlewareFunc = f
+	m.mockExpectations = nil
+	return m.mock
+}
+
+//AddDeliverMiddleware implements github.com/insolar/insolar/messagebus.sender interface
+func (m *senderMock) AddDeliverMiddleware(p core.Middleware, p1 ...core.MessageType) {
+	atomic.AddUint64(&m.AddDeliverMiddlewarePreCounter, 1)
+	defer atomic.AddUint64(&m.AddDeliverMiddlewareCounter, 1)
+
+	if m.AddDeliverMiddlewareMock.mockExpectations != nil {
+		testify_assert.Equal(m.t, *m.AddDeliverMiddlewareMock.mockExpectations, senderMockAddDeliverMiddlewareParams{p, p1},
+			"sender.AddDeliverMiddleware got unexpected parameters")
+
+		if m.AddDeliverMiddlewareFunc == nil {
+
+			m.t.Fatal("No results are set for the senderMock.AddDeliverMiddleware")
+
+			return
+		}
+	}
+
+	if m.AddDeliverMiddlewareFunc == nil {
+		m.t.Fatal("Unexpected call to senderMock.AddDeliverMiddleware")
+		return
+	}
+
+	m.AddDeliverMiddlewareFunc(p, p1...)
+}
+
+//AddDeliverMiddlewareMinimockCounter returns a count of senderMock.AddDeliverMiddlewareFunc invocations
+func (m *senderMock) AddDeliverMiddlewareMinimockCounter() uint64 {
+	return atomic.LoadUint64(&m.AddDeliverMiddlewareCounter)
+}
+
+//AddDeliverMiddlewareMinimockPreCounter returns the value of senderMock.AddDeliverMiddleware invocations
+func (m *senderMock) AddDeliverMiddlewareMinimockPreCounter() uint64 {
+	return atomic.LoadUint64(&m.AddDeliverMiddlewarePreCounter)
+}
+
+type msenderMockAddSendMiddleware struct {
+	mock             *senderMock
+	mockExpectations *senderMockAddSendMiddlewareParams
+}
+
+//senderMockAddSendMiddlewareParams represents input parameters of the sender.AddSendMiddleware
+type senderMockAddSendMiddlewareParams struct {
+	p  core.Middleware
+	p1 []core.MessageType
+}
+
+//Expect sets up expected params for the sender.AddSendMiddleware
+func (m *msenderMockAddSendMiddleware) Expect(p core.Middleware, p1 ...core.MessageType) *msenderMockAddSendMiddleware {
+	m.mockExpectations = &senderMockAddSendMiddlewareParams{p, p1}
+	return m
+}
+
+//Return sets up a mock for sender.AddSendMiddleware to return Return's arguments
+func (m *msenderMockAddSendMiddleware) Return() *senderMock {
+	m.mock.AddSendMiddlewareFunc = func(p core.Middleware, p1 ...core.MessageType) {
+		return
+	}
+	return m.mock
+}
+
+//Set uses given function f as a mock of sender.AddSendMiddleware method
+func (m *msenderMockAddSendMiddleware) Set(f func(p core.Middleware, p1 ...core.MessageType)) *senderMock {
+	m.mock.AddSendMiddlewareFunc = f
+	m.mockExpectations = nil
+	return m.mock
+}
+
+//AddSendMiddleware implements github.com/insolar/insolar/messagebus.sender interface
+func (m *senderMock) AddSendMiddleware(p core.Middleware, p1 ...core.MessageType) {
+	atomic.AddUint64(&m.AddSendMiddlewarePreCounter, 1)
+	defer atomic.AddUint64(&m.AddSendMiddlewareCounter, 1)
+
+	if m.AddSendMiddlewareMock.mockExpectations != nil {
+		testify_assert.Equal(m.t, *m.AddSendMiddlewareMock.mockExpectations, senderMockAddSendMiddlewareParams{p, p1},
+			"sender.AddSendMiddleware got unexpected parameters")
+
+		if m.AddSendMiddlewareFunc == nil {
+
+			m.t.Fatal("No results are set for the senderMock.AddSendMiddleware")
+
+			return
+		}
+	}
+
+	if m.AddSendMiddlewareFunc == nil {
+		m.t.Fatal("Unexpected call to senderMock.AddSendMiddleware")
+		return
+	}
+
+	m.AddSendMiddlewareFunc(p, p1...)
+}
+
+//AddSendMiddlewareMinimockCounter returns a count of senderMock.AddSendMiddlewareFunc invocations
+func (m *senderMock) AddSendMiddlewareMinimockCounter() uint64 {
+	return atomic.LoadUint64(&m.AddSendMiddlewareCounter)
+}
+
+//AddSendMiddlewareMinimockPreCounter returns the value of senderMock.AddSendMiddleware invocations
+func (m *senderMock) AddSendMiddlewareMinimockPreCounter() uint64 {
+	return atomic.LoadUint64(&m.AddSendMiddlewarePreCounter)
+}
+
 type msenderMockNewPlayer struct {
 	mock             *senderMock
 	mockExpectations *senderMockNewPlayerParams
@@ -632,6 +778,14 @@ func (m *senderMock) ValidateCallCounters() {
 		m.t.Fatal("Expected call to senderMock.MustRegister")
 	}
 
+	if m.AddDeliverMiddlewareFunc != nil && atomic.LoadUint64(&m.AddDeliverMiddlewareCounter) == 0 {
+		m.t.Fatal("Expected call to senderMock.AddDeliverMiddleware")
+	}
+
+	if m.AddSendMiddlewareFunc != nil && atomic.LoadUint64(&m.AddSendMiddlewareCounter) == 0 {
+		m.t.Fatal("Expected call to senderMock.AddSendMiddleware")
+	}
+
 	if m.NewPlayerFunc != nil && atomic.LoadUint64(&m.NewPlayerCounter) == 0 {
 		m.t.Fatal("Expected call to senderMock.NewPlayer")
 	}
@@ -681,6 +835,14 @@ func (m *senderMock) MinimockFinish() {
 		m.t.Fatal("Expected call to senderMock.MustRegister")
 	}
 
+	if m.AddDeliverMiddlewareFunc != nil && atomic.LoadUint64(&m.AddDeliverMiddlewareCounter) == 0 {
+		m.t.Fatal("Expected call to senderMock.AddDeliverMiddleware")
+	}
+
+	if m.AddSendMiddlewareFunc != nil && atomic.LoadUint64(&m.AddSendMiddlewareCounter) == 0 {
+		m.t.Fatal("Expected call to senderMock.AddSendMiddleware")
+	}
+
 	if m.NewPlayerFunc != nil && atomic.LoadUint64(&m.NewPlayerCounter) == 0 {
 		m.t.Fatal("Expected call to senderMock.NewPlayer")
 	}
@@ -721,6 +883,8 @@ func (m *senderMock) MinimockWait(timeout time.Duration) {
 		ok := true
 		ok = ok && (m.CreateParcelFunc == nil || atomic.LoadUint64(&m.CreateParcelCounter) > 0)
 		ok = ok && (m.MustRegisterFunc == nil || atomic.LoadUint64(&m.MustRegisterCounter) > 0)
+		ok = ok && (m.AddDeliverMiddlewareFunc == nil || atomic.LoadUint64(&m.AddDeliverMiddlewareCounter) > 0)
+		ok = ok && (m.AddSendMiddlewareFunc == nil || atomic.LoadUint64(&m.AddSendMiddlewareCounter) > 0)
 		ok = ok && (m.NewPlayerFunc == nil || atomic.LoadUint64(&m.NewPlayerCounter) > 0)
 		ok = ok && (m.NewRecorderFunc == nil || atomic.LoadUint64(&m.NewRecorderCounter) > 0)
 		ok = ok && (m.RegisterFunc == nil || atomic.LoadUint64(&m.RegisterCounter) > 0)
@@ -743,6 +907,14 @@ func (m *senderMock) MinimockWait(timeout time.Duration) {
 				m.t.Error("Expected call to senderMock.MustRegister")
 			}
 
+			if m.AddDeliverMiddlewareFunc != nil && atomic.LoadUint64(&m.AddDeliverMiddlewareCounter) == 0 {
+				m.t.Error("Expected call to senderMock.AddDeliverMiddleware")
+			}
+
+			if m.AddSendMiddlewareFunc != nil && atomic.LoadUint64(&m.AddSendMiddlewareCounter) == 0 {
+				m.t.Error("Expected call to senderMock.AddSendMiddleware")
+			}
+
 			if m.NewPlayerFunc != nil && atomic.LoadUint64(&m.NewPlayerCounter) == 0 {
 				m.t.Error("Expected call to senderMock.NewPlayer")
 			}
@@ -787,6 +959,14 @@ func (m *senderMock) AllMocksCalled() bool {
 		return false
 	}
 
+	if m.AddDeliverMiddlewareFunc != nil && atomic.LoadUint64(&m.AddDeliverMiddlewareCounter) == 0 {
+		return false
+	}
+
+	if m.AddSendMiddlewareFunc != nil && atomic.LoadUint64(&m.AddSendMiddlewareCounter) == 0 {
+		return false
+	}
+
 	if m.NewPlayerFunc != nil && atomic.LoadUint64(&m.NewPlayerCounter) == 0 {
 		return false
 	}
@@ -36,7 +36,9 @@ import (
 
 func TestGetMessageHash(t *testing.T) {
 	pcs := platformpolicy.NewPlatformCryptographyScheme()
-	require.Equal(t, 64, len(GetMessageHash(pcs, &message.Parcel{Msg: &message.GenesisRequest{}})))
+	hash, err := GetMessageHash(pcs, &message.Parcel{Msg: &message.GenesisRequest{}})
+	require.NoError(t, err)
+	require.Equal(t, 64, len(hash))
 }
 
 func TestTape_SetReply(t *testing.T) {
@@ -22,6 +22,10 @@ import (
 )
 
 // GetMessageHash calculates message hash.
-func GetMessageHash(scheme core.PlatformCryptographyScheme, msg core.Parcel) []byte {
-	return scheme.IntegrityHasher().Hash(message.ParcelToBytes(msg))
+func GetMessageHash(scheme core.PlatformCryptographyScheme, msg core.Parcel) ([]byte, error) {
+	buf, err := message.ParcelToBytes(msg)
+	if err != nil {
+		return nil, err
+	}
+	return scheme.IntegrityHasher().Hash(buf), nil
 }
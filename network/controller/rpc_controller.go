@@ -87,7 +87,11 @@ func (rpc *RPCController) SendCascadeMessage(data core.Cascade, method string, m
 		return errors.New("message is nil")
 	}
 	ctx := msg.Context(context.Background())
-	return rpc.initCascadeSendMessage(ctx, data, false, method, [][]byte{message.ParcelToBytes(msg)})
+	buf, err := message.ParcelToBytes(msg)
+	if err != nil {
+		return err
+	}
+	return rpc.initCascadeSendMessage(ctx, data, false, method, [][]byte{buf})
 }
 
 func (rpc *RPCController) initCascadeSendMessage(ctx context.Context, data core.Cascade,
@@ -172,15 +176,24 @@ func (rpc *RPCController) SendMessage(nodeID core.RecordRef, name string, msg co
 	ctx := msg.Context(context.Background())
 	inslogger.FromContext(ctx).Debugf("SendParcel with nodeID = %s method = %s, message reference = %s", nodeID.String(),
 		name, message.ExtractTarget(msg).String())
+	buf, err := message.ParcelToBytes(msg)
+	if err != nil {
+		return nil, err
+	}
 	request := rpc.hostNetwork.NewRequestBuilder().Type(types.RPC).Data(&RequestRPC{
 		Method: name,
-		Data:   [][]byte{message.ParcelToBytes(msg)},
+		Data:   [][]byte{buf},
 	}).Build()
 	future, err := rpc.hostNetwork.SendRequest(request, nodeID)
 	if err != nil {
 		return nil, errors.Wrapf(err, "Error sending RPC request to node %s", nodeID.String())
 	}
-	response, err := future.GetResponse(rpc.options.PacketTimeout)
+	timeout := rpc.options.PacketTimeout
+	if deadline := msg.GetDeadline(); !deadline.IsZero() && time.Until(deadline) < timeout {
+		// Sender stops waiting for reply after message deadline.
+		timeout = time.Until(deadline)
+	}
+	response, err := future.GetResponse(timeout)
 	if err != nil {
 		return nil, errors.Wrapf(err, "Error getting RPC response from node %s", nodeID.String())
 	}
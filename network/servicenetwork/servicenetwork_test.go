@@ -37,6 +37,7 @@ import (
 	"github.com/insolar/insolar/network/nodenetwork"
 	"github.com/insolar/insolar/platformpolicy"
 	"github.com/insolar/insolar/testutils"
+	networkUtils "github.com/insolar/insolar/testutils/network"
 	"github.com/stretchr/testify/require"
 )
 
@@ -67,7 +68,7 @@ func mockParcelFactory(t *testing.T) message.ParcelFactory {
 	parcelFactory := messagebus.NewParcelFactory()
 	cm := &component.Manager{}
 	cm.Register(platformpolicy.NewPlatformCryptographyScheme())
-	cm.Inject(delegationTokenFactory, parcelFactory, mock)
+	cm.Inject(delegationTokenFactory, parcelFactory, mock, networkUtils.NewNodeNetworkMock(t))
 	return parcelFactory
 }
 
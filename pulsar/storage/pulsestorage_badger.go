@@ -18,12 +18,17 @@ package pulsarstorage
 
 import (
 	"bytes"
+	"context"
 	"encoding/gob"
 	"path/filepath"
+	"sync"
+	"time"
 
 	"github.com/dgraph-io/badger"
 	"github.com/insolar/insolar/configuration"
 	"github.com/insolar/insolar/core"
+	"github.com/insolar/insolar/encryption"
+	"github.com/insolar/insolar/instrumentation/inslogger"
 	"github.com/pkg/errors"
 )
 
@@ -32,12 +37,34 @@ type RecordID string
 const (
 	LastPulseRecordID RecordID = "lastPulse"
 	PulseRecordID     RecordID = "pulse"
+	// EncryptionRecordID keeps state of values encryption. Its value is stored as is.
+	EncryptionRecordID RecordID = "encryption"
+)
+
+const (
+	// reencryptBatchSize is a number of values encrypted in one transaction.
+	reencryptBatchSize = 1000
+	// reencryptRetryDelay is a pause before retrying of failed re-encryption.
+	reencryptRetryDelay = 5 * time.Second
+
+	// encryptionMigrating state is followed by the key to continue encryption of plain values from.
+	encryptionMigrating byte = 1
+	// encryptionDone state means that all values are encrypted.
+	encryptionDone byte = 2
 )
 
 // NewDB returns pulsar.storage.db with BadgerDB instance initialized by opts.
 // Creates database in provided dir or in current directory if dir parameter is empty.
-func NewStorageBadger(conf configuration.Pulsar, opts *badger.Options) (PulsarStorage, error) {
+//
+// If encryption is enabled in configuration without key file, encryption key is derived from keyStore.
+// Values written before encryption was enabled are encrypted before storage is returned, values written
+// with previous keys are re-encrypted in background.
+func NewStorageBadger(conf configuration.Pulsar, opts *badger.Options, keyStore core.KeyStore) (PulsarStorage, error) {
 	gob.Register(core.Pulse{})
+	ring, err := encryption.NewKeyRingFromConfig(conf.Storage.Encryption, keyStore)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to load storage encryption keys")
+	}
 	opts = setOptions(opts)
 	dir, err := filepath.Abs(conf.Storage.DataDirectory)
 	if err != nil {
@@ -53,7 +80,13 @@ func NewStorageBadger(conf configuration.Pulsar, opts *badger.Options) (PulsarSt
 	}
 
 	db := &BadgerStorageImpl{
-		db: bdb,
+		db:   bdb,
+		ring: ring,
+		stop: make(chan struct{}),
+	}
+	if err = db.migrate(); err != nil {
+		bdb.Close()
+		return nil, err
 	}
 
 	pulse, err := db.GetLastPulse()
@@ -68,6 +101,14 @@ func NewStorageBadger(conf configuration.Pulsar, opts *badger.Options) (PulsarSt
 		}
 	}
 
+	if ring != nil {
+		db.wg.Add(1)
+		go func() {
+			defer db.wg.Done()
+			db.reencryptInBackground(inslogger.FromContext(context.Background()))
+		}()
+	}
+
 	return db, nil
 }
 
@@ -83,6 +124,154 @@ func setOptions(o *badger.Options) *badger.Options {
 
 type BadgerStorageImpl struct {
 	db *badger.DB
+
+	// ring encrypts stored values, nil if encryption is disabled.
+	ring *encryption.KeyRing
+	stop chan struct{}
+	wg   sync.WaitGroup
+}
+
+func (storage *BadgerStorageImpl) encrypt(key, value []byte) ([]byte, error) {
+	if storage.ring == nil {
+		return value, nil
+	}
+	return storage.ring.Encrypt(key, value)
+}
+
+func (storage *BadgerStorageImpl) decrypt(key, value []byte) ([]byte, error) {
+	if storage.ring == nil {
+		return value, nil
+	}
+	return storage.ring.Decrypt(key, value)
+}
+
+// encryptionState returns the key to continue encryption of plain values from or true if all values are encrypted.
+func (storage *BadgerStorageImpl) encryptionState() ([]byte, bool, error) {
+	var state []byte
+	err := storage.db.View(func(txn *badger.Txn) error {
+		item, err := txn.Get([]byte(EncryptionRecordID))
+		if err != nil {
+			return err
+		}
+		state, err = item.ValueCopy(nil)
+		return err
+	})
+	if err == badger.ErrKeyNotFound {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	switch {
+	case len(state) == 1 && state[0] == encryptionDone:
+		return nil, true, nil
+	case len(state) > 1 && state[0] == encryptionMigrating:
+		return state[1:], false, nil
+	}
+	return nil, false, errors.Errorf("unknown encryption state %x", state)
+}
+
+// migrate encrypts values written before encryption was enabled. Migration is done in batches and continues
+// from the last batch if it was interrupted.
+//
+// Returns error if encryption is disabled for encrypted storage.
+func (storage *BadgerStorageImpl) migrate() error {
+	for {
+		from, done, err := storage.encryptionState()
+		if err != nil {
+			return err
+		}
+		if storage.ring == nil {
+			if done || from != nil {
+				return errors.New("storage is encrypted, encryption should be enabled")
+			}
+			return nil
+		}
+		if done {
+			return nil
+		}
+		_, err = storage.convertBatch(from, storage.ring.Encrypt, func(next []byte) []byte {
+			if next == nil {
+				return []byte{encryptionDone}
+			}
+			return append([]byte{encryptionMigrating}, next...)
+		})
+		if err != nil {
+			return errors.Wrap(err, "failed to encrypt plain values")
+		}
+	}
+}
+
+// convertBatch replaces up to reencryptBatchSize values starting from key 'from' with converted ones and
+// returns the key to continue from. Values for which convert returns nil are kept.
+// If state is provided, its result is saved as encryption state in the same transaction.
+func (storage *BadgerStorageImpl) convertBatch(
+	from []byte,
+	convert func(key, value []byte) ([]byte, error),
+	state func(next []byte) []byte,
+) ([]byte, error) {
+	var keys [][]byte
+	var next []byte
+	err := storage.db.View(func(txn *badger.Txn) error {
+		it := txn.NewIterator(badger.DefaultIteratorOptions)
+		defer it.Close()
+
+		if from == nil {
+			it.Rewind()
+		} else {
+			it.Seek(from)
+		}
+		for ; it.Valid(); it.Next() {
+			key := it.Item().KeyCopy(nil)
+			if bytes.Equal(key, []byte(EncryptionRecordID)) {
+				continue
+			}
+			if len(keys) >= reencryptBatchSize {
+				next = key
+				return nil
+			}
+			keys = append(keys, key)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Values are read again in update transaction, so concurrent writes cause conflict instead of being overwritten.
+	err = storage.db.Update(func(txn *badger.Txn) error {
+		for _, key := range keys {
+			item, err := txn.Get(key)
+			if err == badger.ErrKeyNotFound {
+				continue
+			}
+			if err != nil {
+				return err
+			}
+			value, err := item.ValueCopy(nil)
+			if err != nil {
+				return err
+			}
+			converted, err := convert(key, value)
+			if err != nil {
+				return err
+			}
+			if converted == nil {
+				continue
+			}
+			if err = txn.Set(key, converted); err != nil {
+				return err
+			}
+		}
+		if state == nil {
+			return nil
+		}
+		return txn.Set([]byte(EncryptionRecordID), state(next))
+	})
+	if err != nil {
+		return nil, err
+	}
+	return next, nil
 }
 
 func (storage *BadgerStorageImpl) GetLastPulse() (*core.Pulse, error) {
@@ -97,6 +286,10 @@ func (storage *BadgerStorageImpl) GetLastPulse() (*core.Pulse, error) {
 		if err != nil {
 			return err
 		}
+		val, err = storage.decrypt([]byte(LastPulseRecordID), val)
+		if err != nil {
+			return err
+		}
 
 		r := bytes.NewBuffer(val)
 		decoder := gob.NewDecoder(r)
@@ -117,8 +310,12 @@ func (storage *BadgerStorageImpl) SetLastPulse(pulse *core.Pulse) error {
 	if err != nil {
 		return err
 	}
+	value, err := storage.encrypt([]byte(LastPulseRecordID), buffer.Bytes())
+	if err != nil {
+		return err
+	}
 	return storage.db.Update(func(txn *badger.Txn) error {
-		err := txn.Set([]byte(LastPulseRecordID), buffer.Bytes())
+		err := txn.Set([]byte(LastPulseRecordID), value)
 		return err
 	})
 }
@@ -134,12 +331,67 @@ func (storage *BadgerStorageImpl) SavePulse(pulse *core.Pulse) error {
 	key := []byte(PulseRecordID)
 	key = append(key, pulseNumber...)
 
+	value, err := storage.encrypt(key, buffer.Bytes())
+	if err != nil {
+		return err
+	}
 	return storage.db.Update(func(txn *badger.Txn) error {
-		err := txn.Set(key, buffer.Bytes())
+		err := txn.Set(key, value)
 		return err
 	})
 }
 
 func (storage *BadgerStorageImpl) Close() error {
+	close(storage.stop)
+	storage.wg.Wait()
 	return storage.db.Close()
 }
+
+// Reencrypt re-encrypts all stored values with active encryption key after key rotation.
+//
+// Values are re-encrypted in batches. Stops on storage close.
+func (storage *BadgerStorageImpl) Reencrypt() error {
+	if storage.ring == nil {
+		return nil
+	}
+	var from []byte
+	for {
+		select {
+		case <-storage.stop:
+			return nil
+		default:
+		}
+
+		next, err := storage.convertBatch(from, func(key, value []byte) ([]byte, error) {
+			if storage.ring.IsActual(value) {
+				return nil, nil
+			}
+			plain, err := storage.ring.Decrypt(key, value)
+			if err != nil {
+				return nil, err
+			}
+			return storage.ring.Encrypt(key, plain)
+		}, nil)
+		if err != nil || next == nil {
+			return err
+		}
+		from = next
+	}
+}
+
+// reencryptInBackground runs Reencrypt until it succeeds or storage is closed.
+func (storage *BadgerStorageImpl) reencryptInBackground(logger core.Logger) {
+	for {
+		err := storage.Reencrypt()
+		if err == nil {
+			return
+		}
+		logger.Error(errors.Wrap(err, "pulsar storage re-encryption failed"))
+
+		select {
+		case <-storage.stop:
+			return
+		case <-time.After(reencryptRetryDelay):
+		}
+	}
+}
@@ -55,6 +55,16 @@ type ArtifactManagerMock struct {
 	GetChildrenPreCounter uint64
 	GetChildrenMock       mArtifactManagerMockGetChildren
 
+	GetChildrenPageFunc       func(p context.Context, p1 core.RecordRef, p2 core.ChildrenQuery) (r *core.ChildrenPage, r1 error)
+	GetChildrenPageCounter    uint64
+	GetChildrenPagePreCounter uint64
+	GetChildrenPageMock       mArtifactManagerMockGetChildrenPage
+
+	CountChildrenFunc       func(p context.Context, p1 core.RecordRef, p2 core.ChildrenQuery) (r int, r1 error)
+	CountChildrenCounter    uint64
+	CountChildrenPreCounter uint64
+	CountChildrenMock       mArtifactManagerMockCountChildren
+
 	GetCodeFunc       func(p context.Context, p1 core.RecordRef) (r core.CodeDescriptor, r1 error)
 	GetCodeCounter    uint64
 	GetCodePreCounter uint64
@@ -65,11 +75,36 @@ type ArtifactManagerMock struct {
 	GetDelegatePreCounter uint64
 	GetDelegateMock       mArtifactManagerMockGetDelegate
 
-	GetObjectFunc       func(p context.Context, p1 core.RecordRef, p2 *core.RecordID, p3 bool) (r core.ObjectDescriptor, r1 error)
+	GetHistoryFunc       func(p context.Context, p1 core.RecordRef, p2 *core.PulseNumber) (r core.ObjectHistoryIterator, r1 error)
+	GetHistoryCounter    uint64
+	GetHistoryPreCounter uint64
+	GetHistoryMock       mArtifactManagerMockGetHistory
+
+	GetObjectFunc       func(p context.Context, p1 core.RecordRef, p2 *core.RecordID, p3 *core.PulseNumber, p4 bool) (r core.ObjectDescriptor, r1 error)
 	GetObjectCounter    uint64
 	GetObjectPreCounter uint64
 	GetObjectMock       mArtifactManagerMockGetObject
 
+	GetObjectsFunc       func(p context.Context, p1 []core.ObjectHead, p2 bool) (r []core.ObjectDescriptor, r1 error)
+	GetObjectsCounter    uint64
+	GetObjectsPreCounter uint64
+	GetObjectsMock       mArtifactManagerMockGetObjects
+
+	GetObjectStatusFunc       func(p context.Context, p1 core.RecordRef) (r *core.ObjectStatus, r1 error)
+	GetObjectStatusCounter    uint64
+	GetObjectStatusPreCounter uint64
+	GetObjectStatusMock       mArtifactManagerMockGetObjectStatus
+
+	GetObjectsByPrototypeFunc       func(p context.Context, p1 core.ObjectsQuery) (r *core.ObjectsPage, r1 error)
+	GetObjectsByPrototypeCounter    uint64
+	GetObjectsByPrototypePreCounter uint64
+	GetObjectsByPrototypeMock       mArtifactManagerMockGetObjectsByPrototype
+
+	GetRecordProofFunc       func(p context.Context, p1 core.RecordID) (r *core.RecordProof, r1 error)
+	GetRecordProofCounter    uint64
+	GetRecordProofPreCounter uint64
+	GetRecordProofMock       mArtifactManagerMockGetRecordProof
+
 	RegisterRequestFunc       func(p context.Context, p1 core.Parcel) (r *core.RecordID, r1 error)
 	RegisterRequestCounter    uint64
 	RegisterRequestPreCounter uint64
@@ -116,9 +151,16 @@ func NewArtifactManagerMock(t minimock.Tester) *ArtifactManagerMock {
 	m.DeployCodeMock = mArtifactManagerMockDeployCode{mock: m}
 	m.GenesisRefMock = mArtifactManagerMockGenesisRef{mock: m}
 	m.GetChildrenMock = mArtifactManagerMockGetChildren{mock: m}
+	m.GetChildrenPageMock = mArtifactManagerMockGetChildrenPage{mock: m}
+	m.CountChildrenMock = mArtifactManagerMockCountChildren{mock: m}
 	m.GetCodeMock = mArtifactManagerMockGetCode{mock: m}
 	m.GetDelegateMock = mArtifactManagerMockGetDelegate{mock: m}
+	m.GetHistoryMock = mArtifactManagerMockGetHistory{mock: m}
 	m.GetObjectMock = mArtifactManagerMockGetObject{mock: m}
+	m.GetObjectsMock = mArtifactManagerMockGetObjects{mock: m}
+	m.GetObjectStatusMock = mArtifactManagerMockGetObjectStatus{mock: m}
+	m.GetObjectsByPrototypeMock = mArtifactManagerMockGetObjectsByPrototype{mock: m}
+	m.GetRecordProofMock = mArtifactManagerMockGetRecordProof{mock: m}
 	m.RegisterRequestMock = mArtifactManagerMockRegisterRequest{mock: m}
 	m.RegisterResultMock = mArtifactManagerMockRegisterResult{mock: m}
 	m.RegisterValidationMock = mArtifactManagerMockRegisterValidation{mock: m}
@@ -590,6 +632,142 @@ func (m *ArtifactManagerMock) GetChildrenMinimockPreCounter() uint64 {
 	return atomic.LoadUint64(&m.GetChildrenPreCounter)
 }
 
+type mArtifactManagerMockGetChildrenPage struct {
+	mock             *ArtifactManagerMock
+	mockExpectations *ArtifactManagerMockGetChildrenPageParams
+}
+
+//ArtifactManagerMockGetChildrenPageParams represents input parameters of the ArtifactManager.GetChildrenPage
+type ArtifactManagerMockGetChildrenPageParams struct {
+	p  context.Context
+	p1 core.RecordRef
+	p2 core.ChildrenQuery
+}
+
+//Expect sets up expected params for the ArtifactManager.GetChildrenPage
+func (m *mArtifactManagerMockGetChildrenPage) Expect(p context.Context, p1 core.RecordRef, p2 core.ChildrenQuery) *mArtifactManagerMockGetChildrenPage {
+	m.mockExpectations = &ArtifactManagerMockGetChildrenPageParams{p, p1, p2}
+	return m
+}
+
+//Return sets up a mock for ArtifactManager.GetChildrenPage to return Return's arguments
+func (m *mArtifactManagerMockGetChildrenPage) Return(r *core.ChildrenPage, r1 error) *ArtifactManagerMock {
+	m.mock.GetChildrenPageFunc = func(p context.Context, p1 core.RecordRef, p2 core.ChildrenQuery) (*core.ChildrenPage, error) {
+		return r, r1
+	}
+	return m.mock
+}
+
+//Set uses given function f as a mock of ArtifactManager.GetChildrenPage method
+func (m *mArtifactManagerMockGetChildrenPage) Set(f func(p context.Context, p1 core.RecordRef, p2 core.ChildrenQuery) (r *core.ChildrenPage, r1 error)) *ArtifactManagerMock {
+	m.mock.GetChildrenPageFunc = f
+	m.mockExpectations = nil
+	return m.mock
+}
+
+//GetChildrenPage implements github.com/insolar/insolar/core.ArtifactManager interface
+func (m *ArtifactManagerMock) GetChildrenPage(p context.Context, p1 core.RecordRef, p2 core.ChildrenQuery) (r *core.ChildrenPage, r1 error) {
+	atomic.AddUint64(&m.GetChildrenPagePreCounter, 1)
+	defer atomic.AddUint64(&m.GetChildrenPageCounter, 1)
+
+	if m.GetChildrenPageMock.mockExpectations != nil {
+		testify_assert.Equal(m.t, *m.GetChildrenPageMock.mockExpectations, ArtifactManagerMockGetChildrenPageParams{p, p1, p2},
+			"ArtifactManager.GetChildrenPage got unexpected parameters")
+
+		if m.GetChildrenPageFunc == nil {
+
+			m.t.Fatal("No results are set for the ArtifactManagerMock.GetChildrenPage")
+
+			return
+		}
+	}
+
+	if m.GetChildrenPageFunc == nil {
+		m.t.Fatal("Unexpected call to ArtifactManagerMock.GetChildrenPage")
+		return
+	}
+
+	return m.GetChildrenPageFunc(p, p1, p2)
+}
+
+//GetChildrenPageMinimockCounter returns a count of ArtifactManagerMock.GetChildrenPageFunc invocations
+func (m *ArtifactManagerMock) GetChildrenPageMinimockCounter() uint64 {
+	return atomic.LoadUint64(&m.GetChildrenPageCounter)
+}
+
+//GetChildrenPageMinimockPreCounter returns the value of ArtifactManagerMock.GetChildrenPage invocations
+func (m *ArtifactManagerMock) GetChildrenPageMinimockPreCounter() uint64 {
+	return atomic.LoadUint64(&m.GetChildrenPagePreCounter)
+}
+
+type mArtifactManagerMockCountChildren struct {
+	mock             *ArtifactManagerMock
+	mockExpectations *ArtifactManagerMockCountChildrenParams
+}
+
+//ArtifactManagerMockCountChildrenParams represents input parameters of the ArtifactManager.CountChildren
+type ArtifactManagerMockCountChildrenParams struct {
+	p  context.Context
+	p1 core.RecordRef
+	p2 core.ChildrenQuery
+}
+
+//Expect sets up expected params for the ArtifactManager.CountChildren
+func (m *mArtifactManagerMockCountChildren) Expect(p context.Context, p1 core.RecordRef, p2 core.ChildrenQuery) *mArtifactManagerMockCountChildren {
+	m.mockExpectations = &ArtifactManagerMockCountChildrenParams{p, p1, p2}
+	return m
+}
+
+//Return sets up a mock for ArtifactManager.CountChildren to return Return's arguments
+func (m *mArtifactManagerMockCountChildren) Return(r int, r1 error) *ArtifactManagerMock {
+	m.mock.CountChildrenFunc = func(p context.Context, p1 core.RecordRef, p2 core.ChildrenQuery) (int, error) {
+		return r, r1
+	}
+	return m.mock
+}
+
+//Set uses given function f as a mock of ArtifactManager.CountChildren method
+func (m *mArtifactManagerMockCountChildren) Set(f func(p context.Context, p1 core.RecordRef, p2 core.ChildrenQuery) (r int, r1 error)) *ArtifactManagerMock {
+	m.mock.CountChildrenFunc = f
+	m.mockExpectations = nil
+	return m.mock
+}
+
+//CountChildren implements github.com/insolar/insolar/core.ArtifactManager interface
+func (m *ArtifactManagerMock) CountChildren(p context.Context, p1 core.RecordRef, p2 core.ChildrenQuery) (r int, r1 error) {
+	atomic.AddUint64(&m.CountChildrenPreCounter, 1)
+	defer atomic.AddUint64(&m.CountChildrenCounter, 1)
+
+	if m.CountChildrenMock.mockExpectations != nil {
+		testify_assert.Equal(m.t, *m.CountChildrenMock.mockExpectations, ArtifactManagerMockCountChildrenParams{p, p1, p2},
+			"ArtifactManager.CountChildren got unexpected parameters")
+
+		if m.CountChildrenFunc == nil {
+
+			m.t.Fatal("No results are set for the ArtifactManagerMock.CountChildren")
+
+			return
+		}
+	}
+
+	if m.CountChildrenFunc == nil {
+		m.t.Fatal("Unexpected call to ArtifactManagerMock.CountChildren")
+		return
+	}
+
+	return m.CountChildrenFunc(p, p1, p2)
+}
+
+//CountChildrenMinimockCounter returns a count of ArtifactManagerMock.CountChildrenFunc invocations
+func (m *ArtifactManagerMock) CountChildrenMinimockCounter() uint64 {
+	return atomic.LoadUint64(&m.CountChildrenCounter)
+}
+
+//CountChildrenMinimockPreCounter returns the value of ArtifactManagerMock.CountChildren invocations
+func (m *ArtifactManagerMock) CountChildrenMinimockPreCounter() uint64 {
+	return atomic.LoadUint64(&m.CountChildrenPreCounter)
+}
+
 type mArtifactManagerMockGetCode struct {
 	mock             *ArtifactManagerMock
 	mockExpectations *ArtifactManagerMockGetCodeParams
@@ -725,6 +903,74 @@ func (m *ArtifactManagerMock) GetDelegateMinimockPreCounter() uint64 {
 	return atomic.LoadUint64(&m.GetDelegatePreCounter)
 }
 
+type mArtifactManagerMockGetHistory struct {
+	mock             *ArtifactManagerMock
+	mockExpectations *ArtifactManagerMockGetHistoryParams
+}
+
+//ArtifactManagerMockGetHistoryParams represents input parameters of the ArtifactManager.GetHistory
+type ArtifactManagerMockGetHistoryParams struct {
+	p  context.Context
+	p1 core.RecordRef
+	p2 *core.PulseNumber
+}
+
+//Expect sets up expected params for the ArtifactManager.GetHistory
+func (m *mArtifactManagerMockGetHistory) Expect(p context.Context, p1 core.RecordRef, p2 *core.PulseNumber) *mArtifactManagerMockGetHistory {
+	m.mockExpectations = &ArtifactManagerMockGetHistoryParams{p, p1, p2}
+	return m
+}
+
+//Return sets up a mock for ArtifactManager.GetHistory to return Return's arguments
+func (m *mArtifactManagerMockGetHistory) Return(r core.ObjectHistoryIterator, r1 error) *ArtifactManagerMock {
+	m.mock.GetHistoryFunc = func(p context.Context, p1 core.RecordRef, p2 *core.PulseNumber) (core.ObjectHistoryIterator, error) {
+		return r, r1
+	}
+	return m.mock
+}
+
+//Set uses given function f as a mock of ArtifactManager.GetHistory method
+func (m *mArtifactManagerMockGetHistory) Set(f func(p context.Context, p1 core.RecordRef, p2 *core.PulseNumber) (r core.ObjectHistoryIterator, r1 error)) *ArtifactManagerMock {
+	m.mock.GetHistoryFunc = f
+	m.mockExpectations = nil
+	return m.mock
+}
+
+//GetHistory implements github.com/insolar/insolar/core.ArtifactManager interface
+func (m *ArtifactManagerMock) GetHistory(p context.Context, p1 core.RecordRef, p2 *core.PulseNumber) (r core.ObjectHistoryIterator, r1 error) {
+	atomic.AddUint64(&m.GetHistoryPreCounter, 1)
+	defer atomic.AddUint64(&m.GetHistoryCounter, 1)
+
+	if m.GetHistoryMock.mockExpectations != nil {
+		testify_assert.Equal(m.t, *m.GetHistoryMock.mockExpectations, ArtifactManagerMockGetHistoryParams{p, p1, p2},
+			"ArtifactManager.GetHistory got unexpected parameters")
+
+		if m.GetHistoryFunc == nil {
+
+			m.t.Fatal("No results are set for the ArtifactManagerMock.GetHistory")
+
+			return
+		}
+	}
+
+	if m.GetHistoryFunc == nil {
+		m.t.Fatal("Unexpected call to ArtifactManagerMock.GetHistory")
+		return
+	}
+
+	return m.GetHistoryFunc(p, p1, p2)
+}
+
+//GetHistoryMinimockCounter returns a count of ArtifactManagerMock.GetHistoryFunc invocations
+func (m *ArtifactManagerMock) GetHistoryMinimockCounter() uint64 {
+	return atomic.LoadUint64(&m.GetHistoryCounter)
+}
+
+//GetHistoryMinimockPreCounter returns the value of ArtifactManagerMock.GetHistory invocations
+func (m *ArtifactManagerMock) GetHistoryMinimockPreCounter() uint64 {
+	return atomic.LoadUint64(&m.GetHistoryPreCounter)
+}
+
 type mArtifactManagerMockGetObject struct {
 	mock             *ArtifactManagerMock
 	mockExpectations *ArtifactManagerMockGetObjectParams
@@ -735,37 +981,38 @@ type ArtifactManagerMockGetObjectParams struct {
 	p  context.Context
 	p1 core.RecordRef
 	p2 *core.RecordID
-	p3 bool
+	p3 *core.PulseNumber
+	p4 bool
 }
 
 //Expect sets up expected params for the ArtifactManager.GetObject
-func (m *mArtifactManagerMockGetObject) Expect(p context.Context, p1 core.RecordRef, p2 *core.RecordID, p3 bool) *mArtifactManagerMockGetObject {
-	m.mockExpectations = &ArtifactManagerMockGetObjectParams{p, p1, p2, p3}
+func (m *mArtifactManagerMockGetObject) Expect(p context.Context, p1 core.RecordRef, p2 *core.RecordID, p3 *core.PulseNumber, p4 bool) *mArtifactManagerMockGetObject {
+	m.mockExpectations = &ArtifactManagerMockGetObjectParams{p, p1, p2, p3, p4}
 	return m
 }
 
 //Return sets up a mock for ArtifactManager.GetObject to return Return's arguments
 func (m *mArtifactManagerMockGetObject) Return(r core.ObjectDescriptor, r1 error) *ArtifactManagerMock {
-	m.mock.GetObjectFunc = func(p context.Context, p1 core.RecordRef, p2 *core.RecordID, p3 bool) (core.ObjectDescriptor, error) {
+	m.mock.GetObjectFunc = func(p context.Context, p1 core.RecordRef, p2 *core.RecordID, p3 *core.PulseNumber, p4 bool) (core.ObjectDescriptor, error) {
 		return r, r1
 	}
 	return m.mock
 }
 
 //Set uses given function f as a mock of ArtifactManager.GetObject method
-func (m *mArtifactManagerMockGetObject) Set(f func(p context.Context, p1 core.RecordRef, p2 *core.RecordID, p3 bool) (r core.ObjectDescriptor, r1 error)) *ArtifactManagerMock {
+func (m *mArtifactManagerMockGetObject) Set(f func(p context.Context, p1 core.RecordRef, p2 *core.RecordID, p3 *core.PulseNumber, p4 bool) (r core.ObjectDescriptor, r1 error)) *ArtifactManagerMock {
 	m.mock.GetObjectFunc = f
 	m.mockExpectations = nil
 	return m.mock
 }
 
 //GetObject implements github.com/insolar/insolar/core.ArtifactManager interface
-func (m *ArtifactManagerMock) GetObject(p context.Context, p1 core.RecordRef, p2 *core.RecordID, p3 bool) (r core.ObjectDescriptor, r1 error) {
+func (m *ArtifactManagerMock) GetObject(p context.Context, p1 core.RecordRef, p2 *core.RecordID, p3 *core.PulseNumber, p4 bool) (r core.ObjectDescriptor, r1 error) {
 	atomic.AddUint64(&m.GetObjectPreCounter, 1)
 	defer atomic.AddUint64(&m.GetObjectCounter, 1)
 
 	if m.GetObjectMock.mockExpectations != nil {
-		testify_assert.Equal(m.t, *m.GetObjectMock.mockExpectations, ArtifactManagerMockGetObjectParams{p, p1, p2, p3},
+		testify_assert.Equal(m.t, *m.GetObjectMock.mockExpectations, ArtifactManagerMockGetObjectParams{p, p1, p2, p3, p4},
 			"ArtifactManager.GetObject got unexpected parameters")
 
 		if m.GetObjectFunc == nil {
@@ -781,7 +1028,7 @@ func (m *ArtifactManagerMock) GetObject(p context.Context, p1 core.RecordRef, p2
 		return
 	}
 
-	return m.GetObjectFunc(p, p1, p2, p3)
+	return m.GetObjectFunc(p, p1, p2, p3, p4)
 }
 
 //GetObjectMinimockCounter returns a count of ArtifactManagerMock.GetObjectFunc invocations
@@ -794,6 +1041,275 @@ func (m *ArtifactManagerMock) GetObjectMinimockPreCounter() uint64 {
 	return atomic.LoadUint64(&m.GetObjectPreCounter)
 }
 
+type mArtifactManagerMockGetObjects struct {
+	mock             *ArtifactManagerMock
+	mockExpectations *ArtifactManagerMockGetObjectsParams
+}
+
+//ArtifactManagerMockGetObjectsParams represents input parameters of the ArtifactManager.GetObjects
+type ArtifactManagerMockGetObjectsParams struct {
+	p  context.Context
+	p1 []core.ObjectHead
+	p2 bool
+}
+
+//Expect sets up expected params for the ArtifactManager.GetObjects
+func (m *mArtifactManagerMockGetObjects) Expect(p context.Context, p1 []core.ObjectHead, p2 bool) *mArtifactManagerMockGetObjects {
+	m.mockExpectations = &ArtifactManagerMockGetObjectsParams{p, p1, p2}
+	return m
+}
+
+//Return sets up a mock for ArtifactManager.GetObjects to return Return's arguments
+func (m *mArtifactManagerMockGetObjects) Return(r []core.ObjectDescriptor, r1 error) *ArtifactManagerMock {
+	m.mock.GetObjectsFunc = func(p context.Context, p1 []core.ObjectHead, p2 bool) ([]core.ObjectDescriptor, error) {
+		return r, r1
+	}
+	return m.mock
+}
+
+//Set uses given function f as a mock of ArtifactManager.GetObjects method
+func (m *mArtifactManagerMockGetObjects) Set(f func(p context.Context, p1 []core.ObjectHead, p2 bool) (r []core.ObjectDescriptor, r1 error)) *ArtifactManagerMock {
+	m.mock.GetObjectsFunc = f
+	m.mockExpectations = nil
+	return m.mock
+}
+
+//GetObjects implements github.com/insolar/insolar/core.ArtifactManager interface
+func (m *ArtifactManagerMock) GetObjects(p context.Context, p1 []core.ObjectHead, p2 bool) (r []core.ObjectDescriptor, r1 error) {
+	atomic.AddUint64(&m.GetObjectsPreCounter, 1)
+	defer atomic.AddUint64(&m.GetObjectsCounter, 1)
+
+	if m.GetObjectsMock.mockExpectations != nil {
+		testify_assert.Equal(m.t, *m.GetObjectsMock.mockExpectations, ArtifactManagerMockGetObjectsParams{p, p1, p2},
+			"ArtifactManager.GetObjects got unexpected parameters")
+
+		if m.GetObjectsFunc == nil {
+
+			m.t.Fatal("No results are set for the ArtifactManagerMock.GetObjects")
+
+			return
+		}
+	}
+
+	if m.GetObjectsFunc == nil {
+		m.t.Fatal("Unexpected call to ArtifactManagerMock.GetObjects")
+		return
+	}
+
+	return m.GetObjectsFunc(p, p1, p2)
+}
+
+//GetObjectsMinimockCounter returns a count of ArtifactManagerMock.GetObjectsFunc invocations
+func (m *ArtifactManagerMock) GetObjectsMinimockCounter() uint64 {
+	return atomic.LoadUint64(&m.GetObjectsCounter)
+}
+
+//GetObjectsMinimockPreCounter returns the value of ArtifactManagerMock.GetObjects invocations
+func (m *ArtifactManagerMock) GetObjectsMinimockPreCounter() uint64 {
+	return atomic.LoadUint64(&m.GetObjectsPreCounter)
+}
+
+type mArtifactManagerMockGetObjectStatus struct {
+	mock             *ArtifactManagerMock
+	mockExpectations *ArtifactManagerMockGetObjectStatusParams
+}
+
+//ArtifactManagerMockGetObjectStatusParams represents input parameters of the ArtifactManager.GetObjectStatus
+type ArtifactManagerMockGetObjectStatusParams struct {
+	p  context.Context
+	p1 core.RecordRef
+}
+
+//Expect sets up expected params for the ArtifactManager.GetObjectStatus
+func (m *mArtifactManagerMockGetObjectStatus) Expect(p context.Context, p1 core.RecordRef) *mArtifactManagerMockGetObjectStatus {
+	m.mockExpectations = &ArtifactManagerMockGetObjectStatusParams{p, p1}
+	return m
+}
+
+//Return sets up a mock for ArtifactManager.GetObjectStatus to return Return's arguments
+func (m *mArtifactManagerMockGetObjectStatus) Return(r *core.ObjectStatus, r1 error) *ArtifactManagerMock {
+	m.mock.GetObjectStatusFunc = func(p context.Context, p1 core.RecordRef) (*core.ObjectStatus, error) {
+		return r, r1
+	}
+	return m.mock
+}
+
+//Set uses given function f as a mock of ArtifactManager.GetObjectStatus method
+func (m *mArtifactManagerMockGetObjectStatus) Set(f func(p context.Context, p1 core.RecordRef) (r *core.ObjectStatus, r1 error)) *ArtifactManagerMock {
+	m.mock.GetObjectStatusFunc = f
+	m.mockExpectations = nil
+	return m.mock
+}
+
+//GetObjectStatus implements github.com/insolar/insolar/core.ArtifactManager interface
+func (m *ArtifactManagerMock) GetObjectStatus(p context.Context, p1 core.RecordRef) (r *core.ObjectStatus, r1 error) {
+	atomic.AddUint64(&m.GetObjectStatusPreCounter, 1)
+	defer atomic.AddUint64(&m.GetObjectStatusCounter, 1)
+
+	if m.GetObjectStatusMock.mockExpectations != nil {
+		testify_assert.Equal(m.t, *m.GetObjectStatusMock.mockExpectations, ArtifactManagerMockGetObjectStatusParams{p, p1},
+			"ArtifactManager.GetObjectStatus got unexpected parameters")
+
+		if m.GetObjectStatusFunc == nil {
+
+			m.t.Fatal("No results are set for the ArtifactManagerMock.GetObjectStatus")
+
+			return
+		}
+	}
+
+	if m.GetObjectStatusFunc == nil {
+		m.t.Fatal("Unexpected call to ArtifactManagerMock.GetObjectStatus")
+		return
+	}
+
+	return m.GetObjectStatusFunc(p, p1)
+}
+
+//GetObjectStatusMinimockCounter returns a count of ArtifactManagerMock.GetObjectStatusFunc invocations
+func (m *ArtifactManagerMock) GetObjectStatusMinimockCounter() uint64 {
+	return atomic.LoadUint64(&m.GetObjectStatusCounter)
+}
+
+//GetObjectStatusMinimockPreCounter returns the value of ArtifactManagerMock.GetObjectStatus invocations
+func (m *ArtifactManagerMock) GetObjectStatusMinimockPreCounter() uint64 {
+	return atomic.LoadUint64(&m.GetObjectStatusPreCounter)
+}
+
+type mArtifactManagerMockGetObjectsByPrototype struct {
+	mock             *ArtifactManagerMock
+	mockExpectations *ArtifactManagerMockGetObjectsByPrototypeParams
+}
+
+//ArtifactManagerMockGetObjectsByPrototypeParams represents input parameters of the ArtifactManager.GetObjectsByPrototype
+type ArtifactManagerMockGetObjectsByPrototypeParams struct {
+	p  context.Context
+	p1 core.ObjectsQuery
+}
+
+//Expect sets up expected params for the ArtifactManager.GetObjectsByPrototype
+func (m *mArtifactManagerMockGetObjectsByPrototype) Expect(p context.Context, p1 core.ObjectsQuery) *mArtifactManagerMockGetObjectsByPrototype {
+	m.mockExpectations = &ArtifactManagerMockGetObjectsByPrototypeParams{p, p1}
+	return m
+}
+
+//Return sets up a mock for ArtifactManager.GetObjectsByPrototype to return Return's arguments
+func (m *mArtifactManagerMockGetObjectsByPrototype) Return(r *core.ObjectsPage, r1 error) *ArtifactManagerMock {
+	m.mock.GetObjectsByPrototypeFunc = func(p context.Context, p1 core.ObjectsQuery) (*core.ObjectsPage, error) {
+		return r, r1
+	}
+	return m.mock
+}
+
+//Set uses given function f as a mock of ArtifactManager.GetObjectsByPrototype method
+func (m *mArtifactManagerMockGetObjectsByPrototype) Set(f func(p context.Context, p1 core.ObjectsQuery) (r *core.ObjectsPage, r1 error)) *ArtifactManagerMock {
+	m.mock.GetObjectsByPrototypeFunc = f
+	m.mockExpectations = nil
+	return m.mock
+}
+
+//GetObjectsByPrototype implements github.com/insolar/insolar/core.ArtifactManager interface
+func (m *ArtifactManagerMock) GetObjectsByPrototype(p context.Context, p1 core.ObjectsQuery) (r *core.ObjectsPage, r1 error) {
+	atomic.AddUint64(&m.GetObjectsByPrototypePreCounter, 1)
+	defer atomic.AddUint64(&m.GetObjectsByPrototypeCounter, 1)
+
+	if m.GetObjectsByPrototypeMock.mockExpectations != nil {
+		testify_assert.Equal(m.t, *m.GetObjectsByPrototypeMock.mockExpectations, ArtifactManagerMockGetObjectsByPrototypeParams{p, p1},
+			"ArtifactManager.GetObjectsByPrototype got unexpected parameters")
+
+		if m.GetObjectsByPrototypeFunc == nil {
+
+			m.t.Fatal("No results are set for the ArtifactManagerMock.GetObjectsByPrototype")
+
+			return
+		}
+	}
+
+	if m.GetObjectsByPrototypeFunc == nil {
+		m.t.Fatal("Unexpected call to ArtifactManagerMock.GetObjectsByPrototype")
+		return
+	}
+
+	return m.GetObjectsByPrototypeFunc(p, p1)
+}
+
+//GetObjectsByPrototypeMinimockCounter returns a count of ArtifactManagerMock.GetObjectsByPrototypeFunc invocations
+func (m *ArtifactManagerMock) GetObjectsByPrototypeMinimockCounter() uint64 {
+	return atomic.LoadUint64(&m.GetObjectsByPrototypeCounter)
+}
+
+//GetObjectsByPrototypeMinimockPreCounter returns the value of ArtifactManagerMock.GetObjectsByPrototype invocations
+func (m *ArtifactManagerMock) GetObjectsByPrototypeMinimockPreCounter() uint64 {
+	return atomic.LoadUint64(&m.GetObjectsByPrototypePreCounter)
+}
+
+type mArtifactManagerMockGetRecordProof struct {
+	mock             *ArtifactManagerMock
+	mockExpectations *ArtifactManagerMockGetRecordProofParams
+}
+
+//ArtifactManagerMockGetRecordProofParams represents input parameters of the ArtifactManager.GetRecordProof
+type ArtifactManagerMockGetRecordProofParams struct {
+	p  context.Context
+	p1 core.RecordID
+}
+
+//Expect sets up expected params for the ArtifactManager.GetRecordProof
+func (m *mArtifactManagerMockGetRecordProof) Expect(p context.Context, p1 core.RecordID) *mArtifactManagerMockGetRecordProof {
+	m.mockExpectations = &ArtifactManagerMockGetRecordProofParams{p, p1}
+	return m
+}
+
+//Return sets up a mock for ArtifactManager.GetRecordProof to return Return's arguments
+func (m *mArtifactManagerMockGetRecordProof) Return(r *core.RecordProof, r1 error) *ArtifactManagerMock {
+	m.mock.GetRecordProofFunc = func(p context.Context, p1 core.RecordID) (*core.RecordProof, error) {
+		return r, r1
+	}
+	return m.mock
+}
+
+//Set uses given function f as a mock of ArtifactManager.GetRecordProof method
+func (m *mArtifactManagerMockGetRecordProof) Set(f func(p context.Context, p1 core.RecordID) (r *core.RecordProof, r1 error)) *ArtifactManagerMock {
+	m.mock.GetRecordProofFunc = f
+	m.mockExpectations = nil
+	return m.mock
+}
+
+//GetRecordProof implements github.com/insolar/insolar/core.ArtifactManager interface
+func (m *ArtifactManagerMock) GetRecordProof(p context.Context, p1 core.RecordID) (r *core.RecordProof, r1 error) {
+	atomic.AddUint64(&m.GetRecordProofPreCounter, 1)
+	defer atomic.AddUint64(&m.GetRecordProofCounter, 1)
+
+	if m.GetRecordProofMock.mockExpectations != nil {
+		testify_assert.Equal(m.t, *m.GetRecordProofMock.mockExpectations, ArtifactManagerMockGetRecordProofParams{p, p1},
+			"ArtifactManager.GetRecordProof got unexpected parameters")
+
+		if m.GetRecordProofFunc == nil {
+
+			m.t.Fatal("No results are set for the ArtifactManagerMock.GetRecordProof")
+
+			return
+		}
+	}
+
+	if m.GetRecordProofFunc == nil {
+		m.t.Fatal("Unexpected call to ArtifactManagerMock.GetRecordProof")
+		return
+	}
+
+	return m.GetRecordProofFunc(p, p1)
+}
+
+//GetRecordProofMinimockCounter returns a count of ArtifactManagerMock.GetRecordProofFunc invocations
+func (m *ArtifactManagerMock) GetRecordProofMinimockCounter() uint64 {
+	return atomic.LoadUint64(&m.GetRecordProofCounter)
+}
+
+//GetRecordProofMinimockPreCounter returns the value of ArtifactManagerMock.GetRecordProof invocations
+func (m *ArtifactManagerMock) GetRecordProofMinimockPreCounter() uint64 {
+	return atomic.LoadUint64(&m.GetRecordProofPreCounter)
+}
+
 type mArtifactManagerMockRegisterRequest struct {
 	mock             *ArtifactManagerMock
 	mockExpectations *ArtifactManagerMockRegisterRequestParams
@@ -1214,6 +1730,14 @@ func (m *ArtifactManagerMock) ValidateCallCounters() {
 		m.t.Fatal("Expected call to ArtifactManagerMock.GetChildren")
 	}
 
+	if m.GetChildrenPageFunc != nil && atomic.LoadUint64(&m.GetChildrenPageCounter) == 0 {
+		m.t.Fatal("Expected call to ArtifactManagerMock.GetChildrenPage")
+	}
+
+	if m.CountChildrenFunc != nil && atomic.LoadUint64(&m.CountChildrenCounter) == 0 {
+		m.t.Fatal("Expected call to ArtifactManagerMock.CountChildren")
+	}
+
 	if m.GetCodeFunc != nil && atomic.LoadUint64(&m.GetCodeCounter) == 0 {
 		m.t.Fatal("Expected call to ArtifactManagerMock.GetCode")
 	}
@@ -1222,10 +1746,30 @@ func (m *ArtifactManagerMock) ValidateCallCounters() {
 		m.t.Fatal("Expected call to ArtifactManagerMock.GetDelegate")
 	}
 
+	if m.GetHistoryFunc != nil && atomic.LoadUint64(&m.GetHistoryCounter) == 0 {
+		m.t.Fatal("Expected call to ArtifactManagerMock.GetHistory")
+	}
+
 	if m.GetObjectFunc != nil && atomic.LoadUint64(&m.GetObjectCounter) == 0 {
 		m.t.Fatal("Expected call to ArtifactManagerMock.GetObject")
 	}
 
+	if m.GetObjectsFunc != nil && atomic.LoadUint64(&m.GetObjectsCounter) == 0 {
+		m.t.Fatal("Expected call to ArtifactManagerMock.GetObjects")
+	}
+
+	if m.GetObjectStatusFunc != nil && atomic.LoadUint64(&m.GetObjectStatusCounter) == 0 {
+		m.t.Fatal("Expected call to ArtifactManagerMock.GetObjectStatus")
+	}
+
+	if m.GetObjectsByPrototypeFunc != nil && atomic.LoadUint64(&m.GetObjectsByPrototypeCounter) == 0 {
+		m.t.Fatal("Expected call to ArtifactManagerMock.GetObjectsByPrototype")
+	}
+
+	if m.GetRecordProofFunc != nil && atomic.LoadUint64(&m.GetRecordProofCounter) == 0 {
+		m.t.Fatal("Expected call to ArtifactManagerMock.GetRecordProof")
+	}
+
 	if m.RegisterRequestFunc != nil && atomic.LoadUint64(&m.RegisterRequestCounter) == 0 {
 		m.t.Fatal("Expected call to ArtifactManagerMock.RegisterRequest")
 	}
@@ -1295,6 +1839,14 @@ func (m *ArtifactManagerMock) MinimockFinish() {
 		m.t.Fatal("Expected call to ArtifactManagerMock.GetChildren")
 	}
 
+	if m.GetChildrenPageFunc != nil && atomic.LoadUint64(&m.GetChildrenPageCounter) == 0 {
+		m.t.Fatal("Expected call to ArtifactManagerMock.GetChildrenPage")
+	}
+
+	if m.CountChildrenFunc != nil && atomic.LoadUint64(&m.CountChildrenCounter) == 0 {
+		m.t.Fatal("Expected call to ArtifactManagerMock.CountChildren")
+	}
+
 	if m.GetCodeFunc != nil && atomic.LoadUint64(&m.GetCodeCounter) == 0 {
 		m.t.Fatal("Expected call to ArtifactManagerMock.GetCode")
 	}
@@ -1303,10 +1855,30 @@ func (m *ArtifactManagerMock) MinimockFinish() {
 		m.t.Fatal("Expected call to ArtifactManagerMock.GetDelegate")
 	}
 
+	if m.GetHistoryFunc != nil && atomic.LoadUint64(&m.GetHistoryCounter) == 0 {
+		m.t.Fatal("Expected call to ArtifactManagerMock.GetHistory")
+	}
+
 	if m.GetObjectFunc != nil && atomic.LoadUint64(&m.GetObjectCounter) == 0 {
 		m.t.Fatal("Expected call to ArtifactManagerMock.GetObject")
 	}
 
+	if m.GetObjectsFunc != nil && atomic.LoadUint64(&m.GetObjectsCounter) == 0 {
+		m.t.Fatal("Expected call to ArtifactManagerMock.GetObjects")
+	}
+
+	if m.GetObjectStatusFunc != nil && atomic.LoadUint64(&m.GetObjectStatusCounter) == 0 {
+		m.t.Fatal("Expected call to ArtifactManagerMock.GetObjectStatus")
+	}
+
+	if m.GetObjectsByPrototypeFunc != nil && atomic.LoadUint64(&m.GetObjectsByPrototypeCounter) == 0 {
+		m.t.Fatal("Expected call to ArtifactManagerMock.GetObjectsByPrototype")
+	}
+
+	if m.GetRecordProofFunc != nil && atomic.LoadUint64(&m.GetRecordProofCounter) == 0 {
+		m.t.Fatal("Expected call to ArtifactManagerMock.GetRecordProof")
+	}
+
 	if m.RegisterRequestFunc != nil && atomic.LoadUint64(&m.RegisterRequestCounter) == 0 {
 		m.t.Fatal("Expected call to ArtifactManagerMock.RegisterRequest")
 	}
@@ -1352,9 +1924,16 @@ func (m *ArtifactManagerMock) MinimockWait(timeout time.Duration) {
 		ok = ok && (m.DeployCodeFunc == nil || atomic.LoadUint64(&m.DeployCodeCounter) > 0)
 		ok = ok && (m.GenesisRefFunc == nil || atomic.LoadUint64(&m.GenesisRefCounter) > 0)
 		ok = ok && (m.GetChildrenFunc == nil || atomic.LoadUint64(&m.GetChildrenCounter) > 0)
+		ok = ok && (m.GetChildrenPageFunc == nil || atomic.LoadUint64(&m.GetChildrenPageCounter) > 0)
+		ok = ok && (m.CountChildrenFunc == nil || atomic.LoadUint64(&m.CountChildrenCounter) > 0)
 		ok = ok && (m.GetCodeFunc == nil || atomic.LoadUint64(&m.GetCodeCounter) > 0)
 		ok = ok && (m.GetDelegateFunc == nil || atomic.LoadUint64(&m.GetDelegateCounter) > 0)
+		ok = ok && (m.GetHistoryFunc == nil || atomic.LoadUint64(&m.GetHistoryCounter) > 0)
 		ok = ok && (m.GetObjectFunc == nil || atomic.LoadUint64(&m.GetObjectCounter) > 0)
+		ok = ok && (m.GetObjectsFunc == nil || atomic.LoadUint64(&m.GetObjectsCounter) > 0)
+		ok = ok && (m.GetObjectStatusFunc == nil || atomic.LoadUint64(&m.GetObjectStatusCounter) > 0)
+		ok = ok && (m.GetObjectsByPrototypeFunc == nil || atomic.LoadUint64(&m.GetObjectsByPrototypeCounter) > 0)
+		ok = ok && (m.GetRecordProofFunc == nil || atomic.LoadUint64(&m.GetRecordProofCounter) > 0)
 		ok = ok && (m.RegisterRequestFunc == nil || atomic.LoadUint64(&m.RegisterRequestCounter) > 0)
 		ok = ok && (m.RegisterResultFunc == nil || atomic.LoadUint64(&m.RegisterResultCounter) > 0)
 		ok = ok && (m.RegisterValidationFunc == nil || atomic.LoadUint64(&m.RegisterValidationCounter) > 0)
@@ -1397,6 +1976,14 @@ func (m *ArtifactManagerMock) MinimockWait(timeout time.Duration) {
 				m.t.Error("Expected call to ArtifactManagerMock.GetChildren")
 			}
 
+			if m.GetChildrenPageFunc != nil && atomic.LoadUint64(&m.GetChildrenPageCounter) == 0 {
+				m.t.Error("Expected call to ArtifactManagerMock.GetChildrenPage")
+			}
+
+			if m.CountChildrenFunc != nil && atomic.LoadUint64(&m.CountChildrenCounter) == 0 {
+				m.t.Error("Expected call to ArtifactManagerMock.CountChildren")
+			}
+
 			if m.GetCodeFunc != nil && atomic.LoadUint64(&m.GetCodeCounter) == 0 {
 				m.t.Error("Expected call to ArtifactManagerMock.GetCode")
 			}
@@ -1405,10 +1992,30 @@ func (m *ArtifactManagerMock) MinimockWait(timeout time.Duration) {
 				m.t.Error("Expected call to ArtifactManagerMock.GetDelegate")
 			}
 
+			if m.GetHistoryFunc != nil && atomic.LoadUint64(&m.GetHistoryCounter) == 0 {
+				m.t.Error("Expected call to ArtifactManagerMock.GetHistory")
+			}
+
 			if m.GetObjectFunc != nil && atomic.LoadUint64(&m.GetObjectCounter) == 0 {
 				m.t.Error("Expected call to ArtifactManagerMock.GetObject")
 			}
 
+			if m.GetObjectsFunc != nil && atomic.LoadUint64(&m.GetObjectsCounter) == 0 {
+				m.t.Error("Expected call to ArtifactManagerMock.GetObjects")
+			}
+
+			if m.GetObjectStatusFunc != nil && atomic.LoadUint64(&m.GetObjectStatusCounter) == 0 {
+				m.t.Error("Expected call to ArtifactManagerMock.GetObjectStatus")
+			}
+
+			if m.GetObjectsByPrototypeFunc != nil && atomic.LoadUint64(&m.GetObjectsByPrototypeCounter) == 0 {
+				m.t.Error("Expected call to ArtifactManagerMock.GetObjectsByPrototype")
+			}
+
+			if m.GetRecordProofFunc != nil && atomic.LoadUint64(&m.GetRecordProofCounter) == 0 {
+				m.t.Error("Expected call to ArtifactManagerMock.GetRecordProof")
+			}
+
 			if m.RegisterRequestFunc != nil && atomic.LoadUint64(&m.RegisterRequestCounter) == 0 {
 				m.t.Error("Expected call to ArtifactManagerMock.RegisterRequest")
 			}
@@ -1473,6 +2080,14 @@ func (m *ArtifactManagerMock) AllMocksCalled() bool {
 		return false
 	}
 
+	if m.GetChildrenPageFunc != nil && atomic.LoadUint64(&m.GetChildrenPageCounter) == 0 {
+		return false
+	}
+
+	if m.CountChildrenFunc != nil && atomic.LoadUint64(&m.CountChildrenCounter) == 0 {
+		return false
+	}
+
 	if m.GetCodeFunc != nil && atomic.LoadUint64(&m.GetCodeCounter) == 0 {
 		return false
 	}
@@ -1481,10 +2096,30 @@ func (m *ArtifactManagerMock) AllMocksCalled() bool {
 		return false
 	}
 
+	if m.GetHistoryFunc != nil && atomic.LoadUint64(&m.GetHistoryCounter) == 0 {
+		return false
+	}
+
 	if m.GetObjectFunc != nil && atomic.LoadUint64(&m.GetObjectCounter) == 0 {
 		return false
 	}
 
+	if m.GetObjectsFunc != nil && atomic.LoadUint64(&m.GetObjectsCounter) == 0 {
+		return false
+	}
+
+	if m.GetObjectStatusFunc != nil && atomic.LoadUint64(&m.GetObjectStatusCounter) == 0 {
+		return false
+	}
+
+	if m.GetObjectsByPrototypeFunc != nil && atomic.LoadUint64(&m.GetObjectsByPrototypeCounter) == 0 {
+		return false
+	}
+
+	if m.GetRecordProofFunc != nil && atomic.LoadUint64(&m.GetRecordProofCounter) == 0 {
+		return false
+	}
+
 	if m.RegisterRequestFunc != nil && atomic.LoadUint64(&m.RegisterRequestCounter) == 0 {
 		return false
 	}
@@ -26,6 +26,16 @@ type MessageBusMock struct {
 	MustRegisterPreCounter uint64
 	MustRegisterMock       mMessageBusMockMustRegister
 
+	AddDeliverMiddlewareFunc       func(p core.Middleware, p1 ...core.MessageType)
+	AddDeliverMiddlewareCounter    uint64
+	AddDeliverMiddlewarePreCounter uint64
+	AddDeliverMiddlewareMock       mMessageBusMockAddDeliverMiddleware
+
+	AddSendMiddlewareFunc       func(p core.Middleware, p1 ...core.MessageType)
+	AddSendMiddlewareCounter    uint64
+	AddSendMiddlewarePreCounter uint64
+	AddSendMiddlewareMock       mMessageBusMockAddSendMiddleware
+
 	NewPlayerFunc       func(p context.Context, p1 io.Reader) (r core.MessageBus, r1 error)
 	NewPlayerCounter    uint64
 	NewPlayerPreCounter uint64
@@ -61,6 +71,8 @@ func NewMessageBusMock(t minimock.Tester) *MessageBusMock {
 	}
 
 	m.MustRegisterMock = mMessageBusMockMustRegister{mock: m}
+	m.AddDeliverMiddlewareMock = mMessageBusMockAddDeliverMiddleware{mock: m}
+	m.AddSendMiddlewareMock = mMessageBusMockAddSendMiddleware{mock: m}
 	m.NewPlayerMock = mMessageBusMockNewPlayer{mock: m}
 	m.NewRecorderMock = mMessageBusMockNewRecorder{mock: m}
 	m.RegisterMock = mMessageBusMockRegister{mock: m}
@@ -137,6 +149,140 @@ func (m *MessageBusMock) MustRegisterMinimockPreCounter() uint64 {
 	return atomic.LoadUint64(&m.MustRegisterPreCounter)
 }
 
+type mMessageBusMockAddDeliverMiddleware struct {
+	mock             *MessageBusMock
+	mockExpectations *MessageBusMockAddDeliverMiddlewareParams
+}
+
+//MessageBusMockAddDeliverMiddlewareParams represents input parameters of the MessageBus.AddDeliverMiddleware
+type MessageBusMockAddDeliverMiddlewareParams struct {
+	p  core.Middleware
+	p1 []core.MessageType
+}
+
+//Expect sets up expected params for the MessageBus.AddDeliverMiddleware
+func (m *mMessageBusMockAddDeliverMiddleware) Expect(p core.Middleware, p1 ...core.MessageType) *mMessageBusMockAddDeliverMiddleware {
+	m.mockExpectations = &MessageBusMockAddDeliverMiddlewareParams{p, p1}
+	return m
+}
+
+//Return sets up a mock for MessageBus.AddDeliverMiddleware to return Return's arguments
+func (m *mMessageBusMockAddDeliverMiddleware) Return() *MessageBusMock {
+	m.mock.AddDeliverMiddlewareFunc = func(p core.Middleware, p1 ...core.MessageType) {
+		return
+	}
+	return m.mock
+}
+
+//Set uses given function f as a mock of MessageBus.AddDeliverMiddleware method
+func (m *mMessageBusMockAddDeliverMiddleware) Set(f func(p core.Middleware, p1 ...core.MessageType)) *MessageBusMock {
+	m.mock.AddDeliverMiddlewareFunc = f
+	m.mockExpectations = nil
+	return m.mock
+}
+
+//AddDeliverMiddleware implements github.com/insolar/insolar/core.MessageBus interface
+func (m *MessageBusMock) AddDeliverMiddleware(p core.Middleware, p1 ...core.MessageType) {
+	atomic.AddUint64(&m.AddDeliverMiddlewarePreCounter, 1)
+	defer atomic.AddUint64(&m.AddDeliverMiddlewareCounter, 1)
+
+	if m.AddDeliverMiddlewareMock.mockExpectations != nil {
+		testify_assert.Equal(m.t, *m.AddDeliverMiddlewareMock.mockExpectations, MessageBusMockAddDeliverMiddlewareParams{p, p1},
+			"MessageBus.AddDeliverMiddleware got unexpected parameters")
+
+		if m.AddDeliverMiddlewareFunc == nil {
+
+			m.t.Fatal("No results are set for the MessageBusMock.AddDeliverMiddleware")
+
+			return
+		}
+	}
+
+	if m.AddDeliverMiddlewareFunc == nil {
+		m.t.Fatal("Unexpected call to MessageBusMock.AddDeliverMiddleware")
+		return
+	}
+
+	m.AddDeliverMiddlewareFunc(p, p1...)
+}
+
+//AddDeliverMiddlewareMinimockCounter returns a count of MessageBusMock.AddDeliverMiddlewareFunc invocations
+func (m *MessageBusMock) AddDeliverMiddlewareMinimockCounter() uint64 {
+	return atomic.LoadUint64(&m.AddDeliverMiddlewareCounter)
+}
+
+//AddDeliverMiddlewareMinimockPreCounter returns the value of MessageBusMock.AddDeliverMiddleware invocations
+func (m *MessageBusMock) AddDeliverMiddlewareMinimockPreCounter() uint64 {
+	return atomic.LoadUint64(&m.AddDeliverMiddlewarePreCounter)
+}
+
+type mMessageBusMockAddSendMiddleware struct {
+	mock             *MessageBusMock
+	mockExpectations *MessageBusMockAddSendMiddlewareParams
+}
+
+//MessageBusMockAddSendMiddlewareParams represents input parameters of the MessageBus.AddSendMiddleware
+type MessageBusMockAddSendMiddlewareParams struct {
+	p  core.Middleware
+	p1 []core.MessageType
+}
+
+//Expect sets up expected params for the MessageBus.AddSendMiddleware
+func (m *mMessageBusMockAddSendMiddleware) Expect(p core.Middleware, p1 ...core.MessageType) *mMessageBusMockAddSendMiddleware {
+	m.mockExpectations = &MessageBusMockAddSendMiddlewareParams{p, p1}
+	return m
+}
+
+//Return sets up a mock for MessageBus.AddSendMiddleware to return Return's arguments
+func (m *mMessageBusMockAddSendMiddleware) Return() *MessageBusMock {
+	m.mock.AddSendMiddlewareFunc = func(p core.Middleware, p1 ...core.MessageType) {
+		return
+	}
+	return m.mock
+}
+
+//Set uses given function f as a mock of MessageBus.AddSendMiddleware method
+func (m *mMessageBusMockAddSendMiddleware) Set(f func(p core.Middleware, p1 ...core.MessageType)) *MessageBusMock {
+	m.mock.AddSendMiddlewareFunc = f
+	m.mockExpectations = nil
+	return m.mock
+}
+
+//AddSendMiddleware implements github.com/insolar/insolar/core.MessageBus interface
+func (m *MessageBusMock) AddSendMiddleware(p core.Middleware, p1 ...core.MessageType) {
+	atomic.AddUint64(&m.AddSendMiddlewarePreCounter, 1)
+	defer atomic.AddUint64(&m.AddSendMiddlewareCounter, 1)
+
+	if m.AddSendMiddlewareMock.mockExpectations != nil {
+		testify_assert.Equal(m.t, *m.AddSendMiddlewareMock.mockExpectations, MessageBusMockAddSendMiddlewareParams{p, p1},
+			"MessageBus.AddSendMiddleware got unexpected parameters")
+
+		if m.AddSendMiddlewareFunc == nil {
+
+			m.t.Fatal("No results are set for the MessageBusMock.AddSendMiddleware")
+
+			return
+		}
+	}
+
+	if m.AddSendMiddlewareFunc == nil {
+		m.t.Fatal("Unexpected call to MessageBusMock.AddSendMiddleware")
+		return
+	}
+
+	m.AddSendMiddlewareFunc(p, p1...)
+}
+
+//AddSendMiddlewareMinimockCounter returns a count of MessageBusMock.AddSendMiddlewareFunc invocations
+func (m *MessageBusMock) AddSendMiddlewareMinimockCounter() uint64 {
+	return atomic.LoadUint64(&m.AddSendMiddlewareCounter)
+}
+
+//AddSendMiddlewareMinimockPreCounter returns the value of MessageBusMock.AddSendMiddleware invocations
+func (m *MessageBusMock) AddSendMiddlewareMinimockPreCounter() uint64 {
+	return atomic.LoadUint64(&m.AddSendMiddlewarePreCounter)
+}
+
 type mMessageBusMockNewPlayer struct {
 	mock             *MessageBusMock
 	mockExpectations *MessageBusMockNewPlayerParams
@@ -480,6 +626,14 @@ func (m *MessageBusMock) ValidateCallCounters() {
 		m.t.Fatal("Expected call to MessageBusMock.MustRegister")
 	}
 
+	if m.AddDeliverMiddlewareFunc != nil && atomic.LoadUint64(&m.AddDeliverMiddlewareCounter) == 0 {
+		m.t.Fatal("Expected call to MessageBusMock.AddDeliverMiddleware")
+	}
+
+	if m.AddSendMiddlewareFunc != nil && atomic.LoadUint64(&m.AddSendMiddlewareCounter) == 0 {
+		m.t.Fatal("Expected call to MessageBusMock.AddSendMiddleware")
+	}
+
 	if m.NewPlayerFunc != nil && atomic.LoadUint64(&m.NewPlayerCounter) == 0 {
 		m.t.Fatal("Expected call to MessageBusMock.NewPlayer")
 	}
@@ -521,6 +675,14 @@ func (m *MessageBusMock) MinimockFinish() {
 		m.t.Fatal("Expected call to MessageBusMock.MustRegister")
 	}
 
+	if m.AddDeliverMiddlewareFunc != nil && atomic.LoadUint64(&m.AddDeliverMiddlewareCounter) == 0 {
+		m.t.Fatal("Expected call to MessageBusMock.AddDeliverMiddleware")
+	}
+
+	if m.AddSendMiddlewareFunc != nil && atomic.LoadUint64(&m.AddSendMiddlewareCounter) == 0 {
+		m.t.Fatal("Expected call to MessageBusMock.AddSendMiddleware")
+	}
+
 	if m.NewPlayerFunc != nil && atomic.LoadUint64(&m.NewPlayerCounter) == 0 {
 		m.t.Fatal("Expected call to MessageBusMock.NewPlayer")
 	}
@@ -556,6 +718,8 @@ func (m *MessageBusMock) MinimockWait(timeout time.Duration) {
 	for {
 		ok := true
 		ok = ok && (m.MustRegisterFunc == nil || atomic.LoadUint64(&m.MustRegisterCounter) > 0)
+		ok = ok && (m.AddDeliverMiddlewareFunc == nil || atomic.LoadUint64(&m.AddDeliverMiddlewareCounter) > 0)
+		ok = ok && (m.AddSendMiddlewareFunc == nil || atomic.LoadUint64(&m.AddSendMiddlewareCounter) > 0)
 		ok = ok && (m.NewPlayerFunc == nil || atomic.LoadUint64(&m.NewPlayerCounter) > 0)
 		ok = ok && (m.NewRecorderFunc == nil || atomic.LoadUint64(&m.NewRecorderCounter) > 0)
 		ok = ok && (m.RegisterFunc == nil || atomic.LoadUint64(&m.RegisterCounter) > 0)
@@ -573,6 +737,14 @@ func (m *MessageBusMock) MinimockWait(timeout time.Duration) {
 				m.t.Error("Expected call to MessageBusMock.MustRegister")
 			}
 
+			if m.AddDeliverMiddlewareFunc != nil && atomic.LoadUint64(&m.AddDeliverMiddlewareCounter) == 0 {
+				m.t.Error("Expected call to MessageBusMock.AddDeliverMiddleware")
+			}
+
+			if m.AddSendMiddlewareFunc != nil && atomic.LoadUint64(&m.AddSendMiddlewareCounter) == 0 {
+				m.t.Error("Expected call to MessageBusMock.AddSendMiddleware")
+			}
+
 			if m.NewPlayerFunc != nil && atomic.LoadUint64(&m.NewPlayerCounter) == 0 {
 				m.t.Error("Expected call to MessageBusMock.NewPlayer")
 			}
@@ -609,6 +781,14 @@ func (m *MessageBusMock) AllMocksCalled() bool {
 		return false
 	}
 
+	if m.AddDeliverMiddlewareFunc != nil && atomic.LoadUint64(&m.AddDeliverMiddlewareCounter) == 0 {
+		return false
+	}
+
+	if m.AddSendMiddlewareFunc != nil && atomic.LoadUint64(&m.AddSendMiddlewareCounter) == 0 {
+		return false
+	}
+
 	if m.NewPlayerFunc != nil && atomic.LoadUint64(&m.NewPlayerCounter) == 0 {
 		return false
 	}
@@ -32,12 +32,16 @@ import (
 	"github.com/insolar/insolar/messagebus"
 	"github.com/insolar/insolar/platformpolicy"
 	"github.com/insolar/insolar/testutils"
+	"github.com/insolar/insolar/testutils/network"
 )
 
 type TestMessageBus struct {
 	handlers    map[core.MessageType]core.MessageHandler
 	pf          message.ParcelFactory
 	PulseNumber core.PulseNumber
+
+	sendMiddlewares    messagebus.Middlewares
+	deliverMiddlewares messagebus.Middlewares
 }
 
 func (mb *TestMessageBus) NewPlayer(ctx context.Context, reader io.Reader) (core.MessageBus, error) {
@@ -62,7 +66,7 @@ func NewTestMessageBus(t *testing.T) *TestMessageBus {
 	parcelFactory := messagebus.NewParcelFactory()
 	cm := &component.Manager{}
 	cm.Register(platformpolicy.NewPlatformCryptographyScheme())
-	cm.Inject(delegationTokenFactory, parcelFactory, mock)
+	cm.Inject(delegationTokenFactory, parcelFactory, mock, network.NewNodeNetworkMock(t))
 
 	return &TestMessageBus{handlers: map[core.MessageType]core.MessageHandler{}, pf: parcelFactory}
 }
@@ -88,6 +92,14 @@ func (mb *TestMessageBus) MustRegister(p core.MessageType, handler core.MessageH
 	}
 }
 
+func (mb *TestMessageBus) AddSendMiddleware(middleware core.Middleware, types ...core.MessageType) {
+	mb.sendMiddlewares.Add(middleware, types...)
+}
+
+func (mb *TestMessageBus) AddDeliverMiddleware(middleware core.Middleware, types ...core.MessageType) {
+	mb.deliverMiddlewares.Add(middleware, types...)
+}
+
 func (mb *TestMessageBus) Send(ctx context.Context, m core.Message, setters ...core.SendOption) (core.Reply, error) {
 	parcel, err := mb.pf.Create(ctx, m, testutils.RandomRef(), nil)
 	if err != nil {
@@ -99,5 +111,6 @@ func (mb *TestMessageBus) Send(ctx context.Context, m core.Message, setters ...c
 		return nil, errors.New(fmt.Sprint("no handler for message type:", t.String()))
 	}
 
-	return handler(ctx, parcel)
+	deliver := mb.deliverMiddlewares.Wrap(t, handler)
+	return mb.sendMiddlewares.Wrap(t, deliver)(ctx, parcel)
 }
@@ -21,6 +21,7 @@ import (
 	"fmt"
 	"io/ioutil"
 	"path"
+	"sort"
 
 	"github.com/insolar/insolar/log"
 	"gopkg.in/yaml.v2"
@@ -67,7 +68,13 @@ func parseYaml(vtContent []byte) (*bytes.Buffer, error) {
 		fmt.Fprintln(buffer, "	var err error")
 		fmt.Fprintln(buffer, "")
 	}
-	for key, value := range vt.V {
+	keys := make([]string, 0, len(vt.V))
+	for key := range vt.V {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		value := vt.V[key]
 		fmt.Fprintln(buffer, `	vm.VersionTable["`+key+`"], err = NewFeature("`+key+`","`+value.StartVersion+`", "`+value.Description+`")`)
 		fmt.Fprintln(buffer, "	if(err!=nil){")
 		fmt.Fprintln(buffer, `		log.Warn("Error loading from versiontable.yml, verify structure, key='`+key+"', startVersion='"+value.StartVersion+
@@ -77,6 +84,6 @@ func parseYaml(vtContent []byte) (*bytes.Buffer, error) {
 	}
 
 	fmt.Fprintln(buffer, "	return")
-	fmt.Fprintf(buffer, "}")
+	fmt.Fprintln(buffer, "}")
 	return buffer, nil
 }
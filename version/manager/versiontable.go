@@ -2,6 +2,32 @@
 
 package manager
 
+import (
+	"github.com/insolar/insolar/log"
+)
+
 func (vm *VersionManager) loadVersionTable() {
+	var err error
+
+	vm.VersionTable["delegationtoken.getobjectredirect.v2"], err = NewFeature("delegationtoken.getobjectredirect.v2","v0.3.0", "Issuer of object redirect tokens")
+	if(err!=nil){
+		log.Warn("Error loading from versiontable.yml, verify structure, key='delegationtoken.getobjectredirect.v2', startVersion='v0.3.0', message: "+ err.Error())
+	}
+
+	vm.VersionTable["message.getchildren.v2"], err = NewFeature("message.getchildren.v2","v0.3.0", "Prototype filter and children count in GetChildren message")
+	if(err!=nil){
+		log.Warn("Error loading from versiontable.yml, verify structure, key='message.getchildren.v2', startVersion='v0.3.0', message: "+ err.Error())
+	}
+
+	vm.VersionTable["message.parcel.v2"], err = NewFeature("message.parcel.v2","v0.3.0", "Message deadline in parcels")
+	if(err!=nil){
+		log.Warn("Error loading from versiontable.yml, verify structure, key='message.parcel.v2', startVersion='v0.3.0', message: "+ err.Error())
+	}
+
+	vm.VersionTable["reply.children.v2"], err = NewFeature("reply.children.v2","v0.3.0", "Children count in Children reply")
+	if(err!=nil){
+		log.Warn("Error loading from versiontable.yml, verify structure, key='reply.children.v2', startVersion='v0.3.0', message: "+ err.Error())
+	}
+
 	return
-}
\ No newline at end of file
+}